@@ -9,14 +9,20 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/V4T54L/mafia/internal/adapter/audit"
 	httpAdapter "github.com/V4T54L/mafia/internal/adapter/http"
 	"github.com/V4T54L/mafia/internal/adapter/sfu"
+	"github.com/V4T54L/mafia/internal/adapter/store"
 	"github.com/V4T54L/mafia/internal/adapter/ws"
 	"github.com/V4T54L/mafia/internal/domain/service"
 	"github.com/V4T54L/mafia/internal/pkg/config"
 	"github.com/V4T54L/mafia/internal/pkg/logger"
 )
 
+// shutdownNoticeDelay gives clients time to receive the shutdown notice
+// before their connections are closed
+const shutdownNoticeDelay = time.Second
+
 func main() {
 	// Load configuration
 	cfg := config.Load()
@@ -25,27 +31,71 @@ func main() {
 	log := logger.New(cfg.IsDev())
 
 	// Load SFU config early for logging
-	sfuConfig := sfu.DefaultConfig()
+	sfuConfig, err := sfu.DefaultConfig()
+	if err != nil {
+		log.Error("invalid SFU configuration", "error", err)
+		os.Exit(1)
+	}
 
 	log.Info("starting server",
 		"port", cfg.Port,
 		"env", cfg.Env,
 		"staticDir", cfg.StaticDir,
 		"sfuUdpPorts", fmt.Sprintf("%d-%d", sfuConfig.UDPPortMin, sfuConfig.UDPPortMax),
-		"sfuStunServer", sfuConfig.STUNServer,
+		"sfuIceServers", sfuConfig.ICEServerURLs(),
 	)
 
 	// Create services
-	roomService := service.NewRoomService(log)
+	roomService, err := service.NewRoomService(log)
+	if err != nil {
+		log.Error("failed to create room service", "error", err)
+		os.Exit(1)
+	}
+	if cfg.ReconnectTokenKey != "" {
+		roomService.SetReconnectKey([]byte(cfg.ReconnectTokenKey))
+	}
 	gameService := service.NewGameService(roomService, log)
 
-	// Create SFU for voice chat
+	// Record finished games for players who opt into history
+	gameStore := store.NewMemoryGameStore()
+	gameService.SetGameStore(gameStore)
+
+	// Write every game event to the audit log if configured
+	var auditLogger *audit.FileLogger
+	if cfg.AuditLogPath != "" {
+		auditLogger, err = audit.NewFileLogger(cfg.AuditLogPath, log)
+		if err != nil {
+			log.Error("failed to open audit log", "path", cfg.AuditLogPath, "error", err)
+			os.Exit(1)
+		}
+		gameService.SetAuditLogger(auditLogger.Log)
+		log.Info("audit log enabled", "path", cfg.AuditLogPath)
+	}
+
+	// Create SFU for voice chat. Games still run without it (the router
+	// accepts a nil SFU and clients are told voice is unavailable); the
+	// server never reports ready when voice is required.
 	sfuInstance, err := sfu.New(sfuConfig, log)
 	if err != nil {
-		log.Error("failed to create SFU", "error", err)
-		os.Exit(1)
+		if cfg.SFURequired {
+			log.Error("failed to create SFU, voice chat disabled", "error", err, "required", true)
+		} else {
+			log.Warn("failed to create SFU, continuing without voice chat", "error", err)
+		}
 	}
-	defer sfuInstance.Close()
+
+	// Sweep rooms left playing with nobody connected and no running game
+	roomService.SetActiveGameChecker(gameService.HasActiveGame)
+	roomService.SetRoomReapedHandler(func(roomCode string) {
+		gameService.DiscardGame(roomCode)
+		if sfuInstance != nil {
+			sfuInstance.RemoveRoom(roomCode)
+		}
+	})
+	roomService.SetRoomDeletedHandler(gameService.RoomDeleted)
+	reaperStop := make(chan struct{})
+	defer close(reaperStop)
+	roomService.StartReaper(cfg.RoomReapInterval, reaperStop)
 
 	// Create WebSocket hub
 	hub := ws.NewHub(log)
@@ -53,19 +103,50 @@ func main() {
 
 	// Create message router
 	router := ws.NewRouter(hub, roomService, gameService, sfuInstance, log)
+	for msgType, limit := range cfg.WSPayloadLimits {
+		router.SetPayloadLimit(msgType, limit)
+	}
+	if cfg.LobbyIdleTimeout > 0 {
+		idleStop := make(chan struct{})
+		defer close(idleStop)
+		router.StartIdleSweeper(cfg.LobbyIdleTimeout, cfg.LobbyIdleTimeout/4, idleStop)
+	}
 
 	// Create WebSocket handler
-	wsHandler := ws.NewHandler(hub, log, router.HandleMessage, router.HandleDisconnect)
+	origins := ws.NewOriginPolicy(cfg.AllowedOrigins, cfg.IsDev())
+	limits := ws.NewConnLimiter(cfg.MaxConnsPerIP)
+	wsHandler := ws.NewHandler(hub, log, origins, limits, router.HandleMessage, router.HandleDisconnect)
+	wsHandler.SetCompression(cfg.WSCompressThreshold)
+	wsHandler.SetVoiceAvailable(sfuInstance != nil)
 
 	// Create HTTP server
-	server := httpAdapter.NewServer(log, cfg.StaticDir, wsHandler)
+	server := httpAdapter.NewServer(log, cfg.StaticDir, wsHandler, gameStore)
+	server.SetTrustedProxies(cfg.TrustedProxies)
+	health := httpAdapter.HealthSources{
+		RoomCount:   roomService.RoomCount,
+		GameCount:   gameService.GameCount,
+		GameLimit:   cfg.MaxActiveGames,
+		QueuedGames: gameService.QueuedStarts,
+		Connections: func() int { return hub.Stats().Clients },
+		SFURequired: cfg.SFURequired,
+		HubRunning:  hub.Running,
+	}
+	if sfuInstance != nil {
+		health.VoiceParticipants = sfuInstance.ParticipantCount
+	}
+	server.SetHealthSources(health)
+	server.SetReplayExporter(gameService.ExportReplay, cfg.OperatorToken)
+	server.SetRoomLookup(roomService.GetRoom)
+	server.SetGameEnder(gameService.ForceEndGame)
+	gameService.SetDebugDeals(cfg.IsDev())
+	gameService.SetActiveGameLimit(cfg.MaxActiveGames, cfg.QueueGameStarts)
 
 	httpServer := &http.Server{
 		Addr:         cfg.Addr(),
 		Handler:      server,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 30 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
 	}
 
 	// Start server in goroutine
@@ -84,6 +165,17 @@ func main() {
 
 	log.Info("shutting down server...")
 
+	// Warn clients, give them a moment to show it, then close their sockets
+	// (hijacked WebSocket connections are not closed by httpServer.Shutdown)
+	hub.BroadcastAll(ws.MustMessage(ws.EventTypeServerShuttingDown, ws.ServerShuttingDownPayload{
+		Message: "Server is restarting",
+	}))
+	time.Sleep(shutdownNoticeDelay)
+	hub.Shutdown()
+	if sfuInstance != nil {
+		sfuInstance.Close()
+	}
+
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -92,5 +184,12 @@ func main() {
 		log.Error("server forced to shutdown", "error", err)
 	}
 
+	// Closed here rather than deferred: a deferred close is skipped by os.Exit
+	if auditLogger != nil {
+		if err := auditLogger.Close(); err != nil {
+			log.Error("failed to close audit log", "error", err)
+		}
+	}
+
 	log.Info("server stopped")
 }
@@ -7,6 +7,7 @@ require (
 	github.com/go-chi/cors v1.2.2
 	github.com/gorilla/websocket v1.5.3
 	github.com/pion/webrtc/v4 v4.0.10
+	golang.org/x/text v0.21.0
 )
 
 require (
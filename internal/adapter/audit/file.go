@@ -0,0 +1,68 @@
+package audit
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/service"
+)
+
+// Record is one line of the audit log
+type Record struct {
+	Timestamp      time.Time `json:"timestamp"`
+	RoomCode       string    `json:"room"`
+	Type           string    `json:"type"`
+	Private        bool      `json:"private"` // only delivered to TargetPlayerID
+	TargetPlayerID string    `json:"target_player_id,omitempty"`
+	Data           any       `json:"data,omitempty"`
+}
+
+// FileLogger writes game events as newline-delimited JSON
+type FileLogger struct {
+	file    *os.File
+	encoder *json.Encoder
+	logger  *slog.Logger
+	mu      sync.Mutex
+}
+
+// NewFileLogger opens (or creates) the audit log at path for appending
+func NewFileLogger(path string, logger *slog.Logger) (*FileLogger, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileLogger{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		logger:  logger,
+	}, nil
+}
+
+// Log appends a game event to the audit log
+func (l *FileLogger) Log(event service.GameEvent) {
+	record := Record{
+		Timestamp:      event.Timestamp,
+		RoomCode:       event.RoomCode,
+		Type:           string(event.Type),
+		Private:        event.IsPrivate(),
+		TargetPlayerID: event.TargetPlayerID,
+		Data:           event.Data,
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.encoder.Encode(record); err != nil {
+		l.logger.Warn("failed to write audit record", "type", event.Type, "room", event.RoomCode, "error", err)
+	}
+}
+
+// Close closes the audit log file
+func (l *FileLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Close()
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+// getJSON serves a GET for path and decodes the JSON body
+func getJSON(t *testing.T, s *Server, path string) (int, map[string]any) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s: decode %q: %v", path, rec.Body.String(), err)
+	}
+	return rec.Code, body
+}
+
+func TestHealthReportsSubsystems(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil)
+	s.SetHealthSources(HealthSources{
+		RoomCount:         func() int { return 3 },
+		GameCount:         func() int { return 2 },
+		Connections:       func() int { return 11 },
+		VoiceParticipants: func() int { return 4 },
+		SFURequired:       true,
+	})
+
+	code, body := getJSON(t, s, "/api/health")
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Fatalf("health = %d %v, want 200 ok", code, body)
+	}
+	for key, want := range map[string]float64{"rooms": 3, "active_games": 2, "connections": 11} {
+		if body[key] != want {
+			t.Errorf("%s = %v, want %v", key, body[key], want)
+		}
+	}
+	if _, ok := body["uptime_seconds"].(float64); !ok {
+		t.Errorf("uptime_seconds = %v", body["uptime_seconds"])
+	}
+	sfu, _ := body["sfu"].(map[string]any)
+	if sfu["available"] != true || sfu["required"] != true || sfu["participants"] != float64(4) {
+		t.Errorf("sfu = %v", sfu)
+	}
+
+	if code, _ := getJSON(t, s, "/health"); code != http.StatusOK {
+		t.Errorf("root health = %d, want 200", code)
+	}
+}
+
+func TestHealthDegradedWithoutRequiredSFU(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil)
+	s.SetHealthSources(HealthSources{SFURequired: true})
+
+	// Liveness stays up; only readiness takes the instance out of rotation
+	code, body := getJSON(t, s, "/api/health")
+	if code != http.StatusOK || body["status"] != "degraded" {
+		t.Errorf("health = %d %v, want 200 degraded", code, body)
+	}
+	if sfu, _ := body["sfu"].(map[string]any); sfu["available"] != false {
+		t.Errorf("sfu = %v, want unavailable", sfu)
+	}
+
+	// An optional SFU failing is fine
+	s.SetHealthSources(HealthSources{})
+	if code, body := getJSON(t, s, "/api/health"); code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("health with optional sfu = %d %v, want 200 ok", code, body)
+	}
+}
+
+func TestReadyOnceHubRunsAndSFUStarts(t *testing.T) {
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil)
+	var hubRunning atomic.Bool
+	sources := HealthSources{SFURequired: true, HubRunning: hubRunning.Load}
+	s.SetHealthSources(sources)
+
+	code, body := getJSON(t, s, "/api/ready")
+	if code != http.StatusServiceUnavailable || body["status"] != "not_ready" {
+		t.Fatalf("ready before start = %d %v, want 503 not_ready", code, body)
+	}
+	if code, _ := getJSON(t, s, "/health"); code != http.StatusOK {
+		t.Errorf("health while starting = %d, want 200", code)
+	}
+
+	// The hub alone is not enough while a required SFU is missing
+	hubRunning.Store(true)
+	code, body = getJSON(t, s, "/api/ready")
+	if checks, _ := body["checks"].(map[string]any); code != http.StatusServiceUnavailable || checks["hub"] != true || checks["sfu"] != false {
+		t.Fatalf("ready without sfu = %d %v, want 503 with only the hub up", code, body)
+	}
+
+	sources.VoiceParticipants = func() int { return 0 }
+	s.SetHealthSources(sources)
+	if code, body := getJSON(t, s, "/ready"); code != http.StatusOK || body["status"] != "ready" {
+		t.Errorf("ready = %d %v, want 200 ready", code, body)
+	}
+
+	// Shutting the hub down takes the instance out of rotation
+	hubRunning.Store(false)
+	if code, body := getJSON(t, s, "/ready"); code != http.StatusServiceUnavailable || body["status"] != "not_ready" {
+		t.Errorf("ready after hub shutdown = %d %v, want 503 not_ready", code, body)
+	}
+}
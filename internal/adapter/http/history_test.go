@@ -0,0 +1,82 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/adapter/store"
+	"github.com/V4T54L/mafia/internal/domain/entity"
+	"github.com/V4T54L/mafia/internal/domain/service"
+)
+
+func TestPlayerHistoryRecordsOptedInPlayers(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rooms, err := service.NewRoomService(logger)
+	if err != nil {
+		t.Fatalf("room service: %v", err)
+	}
+	games := service.NewGameService(rooms, logger)
+	history := store.NewMemoryGameStore()
+	games.SetGameStore(history)
+
+	room, err := rooms.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := range 7 {
+		id := fmt.Sprintf("p%d", i)
+		if _, err := rooms.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		rooms.SetReady(room.Code, id, true)
+	}
+	if err := rooms.SetHistoryKey(room.Code, "p0", "key-p0"); err != nil {
+		t.Fatalf("opt in: %v", err)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+
+	// Town wins once every mafioso is gone
+	game := games.GetGame(room.Code)
+	for _, id := range room.PlayerOrder {
+		if game.GetPlayerRole(id).GetTeam() == entity.TeamMafia {
+			room.GetPlayer(id).Status = entity.PlayerStatusDead
+		}
+	}
+	if !games.EndIfDecided(room.Code) {
+		t.Fatal("game did not end with the mafia dead")
+	}
+
+	s := NewServer(logger, "", nil, history)
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/players/key-p0/history", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body struct {
+		Games []service.GameRecord `json:"games"`
+		Total int                  `json:"total"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Total != 1 || len(body.Games) != 1 {
+		t.Fatalf("history = %+v, want one game", body)
+	}
+	record := body.Games[0]
+	if record.RoomCode != room.Code || record.Winner != string(entity.TeamTown) || record.Role != string(game.GetPlayerRole("p0")) {
+		t.Errorf("record = %+v, want town win in %s as %s", record, room.Code, game.GetPlayerRole("p0"))
+	}
+
+	// Players who never opted in leave nothing behind
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/players/p1/history", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("history without opting in: status = %d, want 404", rec.Code)
+	}
+}
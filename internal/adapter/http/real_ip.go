@@ -0,0 +1,85 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// SetTrustedProxies sets the reverse proxies (IPs or CIDR ranges) whose
+// X-Forwarded-For and X-Real-IP headers are believed. Requests from any
+// other peer keep their socket address, so clients cannot forge their IP.
+func (s *Server) SetTrustedProxies(proxies []string) {
+	s.trustedProxies = nil
+	for _, raw := range proxies {
+		raw = strings.TrimSpace(raw)
+		if prefix, err := netip.ParsePrefix(raw); err == nil {
+			s.trustedProxies = append(s.trustedProxies, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(raw); err == nil {
+			s.trustedProxies = append(s.trustedProxies, netip.PrefixFrom(addr, addr.BitLen()))
+		} else {
+			s.logger.Warn("ignoring invalid trusted proxy", "value", raw)
+		}
+	}
+}
+
+// realIP rewrites RemoteAddr to the client's address as reported by a
+// trusted proxy. The forwarding headers are ignored from anyone else.
+func (s *Server) realIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.isTrustedProxy(hostOf(r.RemoteAddr)) {
+			if ip := s.forwardedIP(r); ip != "" {
+				r.RemoteAddr = ip
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// forwardedIP returns the client address from a trusted proxy's headers.
+// X-Forwarded-For is read right to left, skipping proxies we trust, so an
+// address the client prepended itself is never used.
+func (s *Server) forwardedIP(r *http.Request) string {
+	if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+		hops := strings.Split(strings.Join(xff, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if _, err := netip.ParseAddr(hop); err != nil {
+				return ""
+			}
+			if !s.isTrustedProxy(hop) {
+				return hop
+			}
+		}
+		return ""
+	}
+	if ip := strings.TrimSpace(r.Header.Get("X-Real-IP")); ip != "" {
+		if _, err := netip.ParseAddr(ip); err == nil {
+			return ip
+		}
+	}
+	return ""
+}
+
+func (s *Server) isTrustedProxy(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// hostOf strips the port from a host:port address
+func hostOf(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
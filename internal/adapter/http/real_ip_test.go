@@ -0,0 +1,47 @@
+package http
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForwardedIPOnlyBelievedFromTrustedProxies(t *testing.T) {
+	var seen string
+	capture := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.RemoteAddr
+	})
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), "", capture, nil)
+	s.SetTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "not-an-ip"})
+
+	tests := []struct {
+		name   string
+		peer   string
+		header http.Header
+		want   string
+	}{
+		{"untrusted peer forging a header", "198.51.100.7:4000", http.Header{"X-Forwarded-For": {"203.0.113.9"}}, "198.51.100.7:4000"},
+		{"untrusted peer forging x-real-ip", "198.51.100.7:4000", http.Header{"X-Real-Ip": {"203.0.113.9"}}, "198.51.100.7:4000"},
+		{"trusted proxy", "10.1.2.3:4000", http.Header{"X-Forwarded-For": {"203.0.113.9"}}, "203.0.113.9"},
+		{"trusted proxy with x-real-ip", "192.0.2.1:4000", http.Header{"X-Real-Ip": {"203.0.113.9"}}, "203.0.113.9"},
+		{"client-prepended hop is skipped", "10.1.2.3:4000", http.Header{"X-Forwarded-For": {"1.1.1.1, 203.0.113.9"}}, "203.0.113.9"},
+		{"chain of trusted proxies", "10.1.2.3:4000", http.Header{"X-Forwarded-For": {"203.0.113.9, 10.9.9.9"}}, "203.0.113.9"},
+		{"trusted proxy without a header", "10.1.2.3:4000", nil, "10.1.2.3:4000"},
+		{"garbage header", "10.1.2.3:4000", http.Header{"X-Forwarded-For": {"nonsense"}}, "10.1.2.3:4000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+			req.RemoteAddr = tt.peer
+			for name, values := range tt.header {
+				req.Header[name] = values
+			}
+			s.router.ServeHTTP(httptest.NewRecorder(), req)
+			if seen != tt.want {
+				t.Errorf("handler saw %q, want %q", seen, tt.want)
+			}
+		})
+	}
+}
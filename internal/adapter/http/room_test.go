@@ -0,0 +1,118 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+	"github.com/V4T54L/mafia/internal/domain/service"
+)
+
+func TestRoomInfoDuringGame(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	rooms, err := service.NewRoomService(logger)
+	if err != nil {
+		t.Fatalf("room service: %v", err)
+	}
+	games := service.NewGameService(rooms, logger)
+
+	room, err := rooms.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := range 7 {
+		id := fmt.Sprintf("p%d", i)
+		if _, err := rooms.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		rooms.SetReady(room.Code, id, true)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+
+	room.GetPlayer("p1").Status = entity.PlayerStatusDead
+
+	s := NewServer(logger, "", nil, nil)
+	s.SetRoomLookup(rooms.GetRoom)
+
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/rooms/"+room.Code, nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body map[string]any
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["state"] != "playing" || body["player_count"] != float64(7) || body["spectator_count"] != float64(0) {
+		t.Errorf("room = state %v, %v players, %v spectators; want playing, 7, 0",
+			body["state"], body["player_count"], body["spectator_count"])
+	}
+
+	// Mid-game the list never says who is what, or who is already dead
+	players, _ := body["players"].([]any)
+	if len(players) != 7 {
+		t.Fatalf("players = %v, want 7 entries", body["players"])
+	}
+	for _, p := range players {
+		player := p.(map[string]any)
+		for _, key := range []string{"role", "team", "status"} {
+			if value, ok := player[key]; ok {
+				t.Errorf("%v: %s = %v leaked during the game", player["nickname"], key, value)
+			}
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/rooms/NOPE", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown room status = %d, want 404", rec.Code)
+	}
+}
+
+func TestForceEndRequiresOperatorToken(t *testing.T) {
+	var ended []string
+	s := NewServer(slog.New(slog.NewTextHandler(io.Discard, nil)), "", nil, nil)
+	s.SetReplayExporter(nil, "secret")
+	s.SetGameEnder(func(roomCode, reason string) error {
+		if roomCode == "IDLE" {
+			return service.ErrNoActiveGame
+		}
+		ended = append(ended, roomCode+": "+reason)
+		return nil
+	})
+
+	post := func(path, auth string) int {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		s.router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	for _, auth := range []string{"", "Bearer wrong", "secret"} {
+		if code := post("/api/rooms/abcd/end", auth); code != http.StatusUnauthorized {
+			t.Errorf("auth %q: status = %d, want 401", auth, code)
+		}
+	}
+	if len(ended) != 0 {
+		t.Fatalf("unauthorized requests ended games: %v", ended)
+	}
+
+	if code := post("/api/rooms/abcd/end", "Bearer secret"); code != http.StatusOK {
+		t.Errorf("operator status = %d, want 200", code)
+	}
+	if len(ended) != 1 || ended[0] != "ABCD: ended by operator" {
+		t.Errorf("ended = %v, want ABCD with the default reason", ended)
+	}
+	if code := post("/api/rooms/idle/end", "Bearer secret"); code != http.StatusNotFound {
+		t.Errorf("no game status = %d, want 404", code)
+	}
+}
@@ -1,39 +1,130 @@
 package http
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/V4T54L/mafia/internal/domain/entity"
+	"github.com/V4T54L/mafia/internal/domain/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
 )
 
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// HealthSources supplies the subsystem numbers reported by the health check.
+// Nil funcs are left out of the report.
+type HealthSources struct {
+	RoomCount   func() int
+	GameCount   func() int
+	Connections func() int
+
+	// GameLimit is the cap on active games (0 = none); QueuedGames counts
+	// starts waiting for a slot
+	GameLimit   int
+	QueuedGames func() int
+
+	// VoiceParticipants is nil when the SFU failed to start
+	VoiceParticipants func() int
+	// SFURequired keeps the server from reporting ready without an SFU
+	SFURequired bool
+
+	// HubRunning reports whether the WebSocket hub's event loop has
+	// started; nil leaves the server never ready
+	HubRunning func() bool
+}
+
+// ReplayExporter serializes a room's last finished game
+type ReplayExporter func(roomCode string, includePrivate bool) ([]byte, error)
+
+// RoomLookup finds a live room by code
+type RoomLookup func(code string) (*entity.Room, error)
+
+// GameEnder aborts a room's game in progress
+type GameEnder func(roomCode, reason string) error
+
+// roomPlayer is the public view of a seat; it never carries a role, and
+// alive/dead status is left out while a game is in progress
+type roomPlayer struct {
+	Nickname    string `json:"nickname"`
+	IsHost      bool   `json:"is_host"`
+	IsBot       bool   `json:"is_bot"`
+	IsConnected bool   `json:"is_connected"`
+	Status      string `json:"status,omitempty"`
+}
+
 type Server struct {
 	router    *chi.Mux
 	logger    *slog.Logger
 	staticDir string
 	wsHandler http.Handler
+	history   service.GameStore
+	health    HealthSources
+	startedAt time.Time
+
+	replays       ReplayExporter
+	operatorToken string // unlocks private replays and operator actions (empty = never)
+
+	rooms   RoomLookup
+	endGame GameEnder
+
+	// Proxies whose forwarding headers are believed (see SetTrustedProxies)
+	trustedProxies []netip.Prefix
 }
 
-func NewServer(logger *slog.Logger, staticDir string, wsHandler http.Handler) *Server {
+func NewServer(logger *slog.Logger, staticDir string, wsHandler http.Handler, history service.GameStore) *Server {
 	s := &Server{
 		router:    chi.NewRouter(),
 		logger:    logger,
 		staticDir: staticDir,
 		wsHandler: wsHandler,
+		history:   history,
+		startedAt: time.Now(),
 	}
 	s.setupMiddleware()
 	s.setupRoutes()
 	return s
 }
 
+// SetHealthSources sets where the health check reads subsystem status from
+func (s *Server) SetHealthSources(sources HealthSources) {
+	s.health = sources
+}
+
+// SetReplayExporter enables replay downloads. Private events are only included
+// for requests bearing operatorToken; an empty token keeps replays public-only.
+func (s *Server) SetReplayExporter(exporter ReplayExporter, operatorToken string) {
+	s.replays = exporter
+	s.operatorToken = operatorToken
+}
+
+// SetRoomLookup enables the room info endpoint
+func (s *Server) SetRoomLookup(lookup RoomLookup) {
+	s.rooms = lookup
+}
+
+// SetGameEnder enables the operator endpoint for force-ending games. It is
+// only usable with the operator token passed to SetReplayExporter.
+func (s *Server) SetGameEnder(ender GameEnder) {
+	s.endGame = ender
+}
+
 func (s *Server) setupMiddleware() {
 	s.router.Use(middleware.RequestID)
-	s.router.Use(middleware.RealIP)
+	s.router.Use(s.realIP)
 	s.router.Use(middleware.Logger)
 	s.router.Use(middleware.Recoverer)
 	s.router.Use(cors.Handler(cors.Options{
@@ -46,12 +137,20 @@ func (s *Server) setupMiddleware() {
 }
 
 func (s *Server) setupRoutes() {
-	// Health check at root level (for container health checks)
+	// Liveness and readiness at root level (for container health checks)
 	s.router.Get("/health", s.handleHealth)
+	s.router.Get("/ready", s.handleReady)
 
 	// API routes
 	s.router.Route("/api", func(r chi.Router) {
 		r.Get("/health", s.handleHealth) // Also available at /api/health
+		r.Get("/ready", s.handleReady)
+		if s.history != nil {
+			r.Get("/players/{id}/history", s.handlePlayerHistory)
+		}
+		r.Get("/rooms/{code}", s.handleRoom)
+		r.Get("/rooms/{code}/replay", s.handleReplay)
+		r.Post("/rooms/{code}/end", s.handleForceEnd)
 	})
 
 	// WebSocket endpoint
@@ -63,10 +162,258 @@ func (s *Server) setupRoutes() {
 	s.serveStaticFiles()
 }
 
+// handleHealth is the liveness check: it answers 200 while the process is
+// up, reporting subsystem status and "degraded" when a required subsystem
+// is down. Use /ready to take a degraded instance out of rotation.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	report := map[string]any{
+		"status":         "ok",
+		"uptime_seconds": int64(time.Since(s.startedAt).Seconds()),
+	}
+	if s.health.RoomCount != nil {
+		report["rooms"] = s.health.RoomCount()
+	}
+	if s.health.GameCount != nil {
+		report["active_games"] = s.health.GameCount()
+	}
+	if s.health.Connections != nil {
+		report["connections"] = s.health.Connections()
+	}
+	if s.health.GameLimit > 0 {
+		report["max_active_games"] = s.health.GameLimit
+	}
+	if s.health.QueuedGames != nil {
+		report["queued_games"] = s.health.QueuedGames()
+	}
+
+	voice := map[string]any{
+		"available": s.health.VoiceParticipants != nil,
+		"required":  s.health.SFURequired,
+	}
+	if s.health.VoiceParticipants != nil {
+		voice["participants"] = s.health.VoiceParticipants()
+	}
+	report["sfu"] = voice
+
+	if s.health.VoiceParticipants == nil && s.health.SFURequired {
+		report["status"] = "degraded"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// handleReady is the readiness check: 200 once the hub is running and, when
+// required, the SFU started; 503 until then so no traffic is routed here
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]bool{
+		"hub": s.health.HubRunning != nil && s.health.HubRunning(),
+		"sfu": s.health.VoiceParticipants != nil || !s.health.SFURequired,
+	}
+
+	report := map[string]any{
+		"status": "ready",
+		"checks": checks,
+	}
+	status := http.StatusOK
+	for _, ok := range checks {
+		if !ok {
+			report["status"] = "not_ready"
+			status = http.StatusServiceUnavailable
+			break
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// handlePlayerHistory returns a page of finished games for a history key
+func (s *Server) handlePlayerHistory(w http.ResponseWriter, r *http.Request) {
+	historyKey := chi.URLParam(r, "id")
+
+	limit, err := queryInt(r, "limit", defaultHistoryLimit)
+	if err != nil || limit <= 0 {
+		writeJSONError(w, http.StatusBadRequest, "limit must be a positive integer")
+		return
+	}
+	if limit > maxHistoryLimit {
+		limit = maxHistoryLimit
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		writeJSONError(w, http.StatusBadRequest, "offset must be a non-negative integer")
+		return
+	}
+
+	records, err := s.history.GetPlayerHistory(historyKey)
+	if err != nil {
+		if errors.Is(err, service.ErrNoHistory) {
+			writeJSONError(w, http.StatusNotFound, "no history for player")
+			return
+		}
+		s.logger.Error("failed to load player history", "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to load history")
+		return
+	}
+
+	total := len(records)
+	start := min(offset, total)
+	end := min(start+limit, total)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"games":  records[start:end],
+		"total":  total,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// handleRoom describes a room for lobby browsers and operators
+func (s *Server) handleRoom(w http.ResponseWriter, r *http.Request) {
+	if s.rooms == nil {
+		writeJSONError(w, http.StatusNotFound, "room lookup is not enabled")
+		return
+	}
+
+	room, err := s.rooms(strings.ToUpper(chi.URLParam(r, "code")))
+	if err != nil {
+		writeJSONError(w, http.StatusNotFound, "room not found")
+		return
+	}
+
+	playing := room.State == entity.RoomStatePlaying
+	players := make([]roomPlayer, 0)
+	for _, p := range room.GetPlayersDTO() {
+		player := roomPlayer{
+			Nickname:    p.Nickname,
+			IsHost:      p.IsHost,
+			IsBot:       p.IsBot,
+			IsConnected: p.IsConnected,
+		}
+		if !playing {
+			player.Status = p.Status
+		}
+		players = append(players, player)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"code":         room.Code,
+		"state":        room.State,
+		"settings":     room.Settings,
+		"has_password": room.HasPassword(),
+		"max_players":  room.MaxPlayers,
+		"players":      players,
+		"player_count": len(players),
+		// Rooms have no spectator seats; everyone connected holds a player slot
+		"spectator_count": 0,
+	})
+}
+
+// handleReplay returns a finished game's replay; private events need the operator token
+func (s *Server) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if s.replays == nil {
+		writeJSONError(w, http.StatusNotFound, "replays are not enabled")
+		return
+	}
+
+	includePrivate := false
+	if r.Header.Get("Authorization") != "" {
+		if !s.isOperator(r) {
+			writeJSONError(w, http.StatusUnauthorized, "invalid operator token")
+			return
+		}
+		includePrivate = true
+	}
+
+	data, err := s.replays(strings.ToUpper(chi.URLParam(r, "code")), includePrivate)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNoReplay):
+			writeJSONError(w, http.StatusNotFound, "no replay for room")
+		case errors.Is(err, service.ErrReplayNotFinished):
+			writeJSONError(w, http.StatusConflict, "game has not ended")
+		default:
+			s.logger.Error("failed to export replay", "error", err)
+			writeJSONError(w, http.StatusInternalServerError, "failed to export replay")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// handleForceEnd aborts a hung game; operators only
+func (s *Server) handleForceEnd(w http.ResponseWriter, r *http.Request) {
+	if s.endGame == nil {
+		writeJSONError(w, http.StatusNotFound, "force end is not enabled")
+		return
+	}
+	if !s.isOperator(r) {
+		writeJSONError(w, http.StatusUnauthorized, "invalid operator token")
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if body.Reason == "" {
+		body.Reason = "ended by operator"
+	}
+
+	code := strings.ToUpper(chi.URLParam(r, "code"))
+	s.logger.Warn("operator force-ending game",
+		"room", code,
+		"reason", body.Reason,
+		"remote", r.RemoteAddr,
+		"request_id", middleware.GetReqID(r.Context()),
+	)
+	if err := s.endGame(code, body.Reason); err != nil {
+		if errors.Is(err, service.ErrNoActiveGame) {
+			writeJSONError(w, http.StatusNotFound, "no active game in room")
+			return
+		}
+		s.logger.Error("failed to force-end game", "room", code, "error", err)
+		writeJSONError(w, http.StatusInternalServerError, "failed to end game")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"code":   code,
+		"status": "ended",
+	})
+}
+
+// isOperator reports whether the request bears the operator token
+func (s *Server) isOperator(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.operatorToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.operatorToken)) == 1
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	val := r.URL.Query().Get(key)
+	if val == "" {
+		return fallback, nil
+	}
+	return strconv.Atoi(val)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
 	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(map[string]string{
-		"status": "ok",
+		"error": message,
 	})
 }
 
@@ -0,0 +1,104 @@
+package sfu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// AudioCodec is the single audio codec the SFU negotiates with clients
+type AudioCodec struct {
+	Name     string // opus, g722, pcmu or pcma
+	Channels int    // 1 = mono, 2 = stereo (Opus only)
+	Bitrate  int    // target bits per second for Opus (0 = encoder default)
+}
+
+// DefaultAudioCodec returns Opus mono at a bitrate suited to speech
+func DefaultAudioCodec() AudioCodec {
+	return AudioCodec{
+		Name:     "opus",
+		Channels: 1,
+		Bitrate:  32000,
+	}
+}
+
+// orDefault returns the default codec in place of the zero value
+func (c AudioCodec) orDefault() AudioCodec {
+	if c == (AudioCodec{}) {
+		return DefaultAudioCodec()
+	}
+	return c
+}
+
+// audioCodecSpec holds the fixed RTP parameters of a supported codec
+type audioCodecSpec struct {
+	mimeType    string
+	clockRate   uint32
+	payloadType webrtc.PayloadType
+}
+
+var audioCodecSpecs = map[string]audioCodecSpec{
+	"opus": {webrtc.MimeTypeOpus, 48000, 111},
+	"g722": {webrtc.MimeTypeG722, 8000, 9},
+	"pcmu": {webrtc.MimeTypePCMU, 8000, 0},
+	"pcma": {webrtc.MimeTypePCMA, 8000, 8},
+}
+
+// validate checks the codec name and Opus-only options; the zero value is
+// the default codec and always valid
+func (c AudioCodec) validate() error {
+	c = c.orDefault()
+	if _, ok := audioCodecSpecs[c.Name]; !ok {
+		return fmt.Errorf("unsupported audio codec %q (supported: opus, g722, pcmu, pcma)", c.Name)
+	}
+	if c.Channels != 1 && c.Channels != 2 {
+		return fmt.Errorf("audio channels must be 1 or 2, got %d", c.Channels)
+	}
+	if c.Bitrate < 0 {
+		return fmt.Errorf("audio bitrate must not be negative, got %d", c.Bitrate)
+	}
+	return nil
+}
+
+// parameters returns the RTP codec parameters registered on the media engine.
+// The bitrate cap and channel count travel in the Opus fmtp line, which
+// browsers apply to their encoder.
+func (c AudioCodec) parameters() webrtc.RTPCodecParameters {
+	spec := audioCodecSpecs[c.Name]
+	capability := webrtc.RTPCodecCapability{
+		MimeType:  spec.mimeType,
+		ClockRate: spec.clockRate,
+		Channels:  1,
+	}
+
+	if c.Name == "opus" {
+		// Opus is always signalled as 2 channels (RFC 7587); mono is requested via stereo=0
+		capability.Channels = 2
+		fmtp := []string{"minptime=10", "useinbandfec=1"}
+		if c.Channels == 1 {
+			fmtp = append(fmtp, "stereo=0", "sprop-stereo=0")
+		} else {
+			fmtp = append(fmtp, "stereo=1", "sprop-stereo=1")
+		}
+		if c.Bitrate > 0 {
+			fmtp = append(fmtp, "maxaveragebitrate="+strconv.Itoa(c.Bitrate))
+		}
+		capability.SDPFmtpLine = strings.Join(fmtp, ";")
+	}
+
+	return webrtc.RTPCodecParameters{
+		RTPCodecCapability: capability,
+		PayloadType:        spec.payloadType,
+	}
+}
+
+// registerAudioCodec registers only the configured audio codec
+func registerAudioCodec(mediaEngine *webrtc.MediaEngine, codec AudioCodec) error {
+	codec = codec.orDefault()
+	if err := codec.validate(); err != nil {
+		return err
+	}
+	return mediaEngine.RegisterCodec(codec.parameters(), webrtc.RTPCodecTypeAudio)
+}
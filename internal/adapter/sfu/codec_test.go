@@ -0,0 +1,85 @@
+package sfu
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// discardLogger returns a logger that drops every record
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// audioOffer returns an audio offer from an SFU using codec
+func audioOffer(t *testing.T, codec AudioCodec) string {
+	t.Helper()
+	s, err := New(&Config{UDPPortMin: 5000, UDPPortMax: 5100, AudioCodec: codec}, discardLogger())
+	if err != nil {
+		t.Fatalf("new sfu: %v", err)
+	}
+	pc, err := s.CreatePeerConnection()
+	if err != nil {
+		t.Fatalf("peer connection: %v", err)
+	}
+	defer pc.Close()
+	if _, err := pc.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio); err != nil {
+		t.Fatalf("add transceiver: %v", err)
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		t.Fatalf("create offer: %v", err)
+	}
+	return offer.SDP
+}
+
+// sdpLines returns the lines of sdp that start with prefix
+func sdpLines(sdp, prefix string) []string {
+	var lines []string
+	for _, line := range strings.Split(sdp, "\r\n") {
+		if strings.HasPrefix(line, prefix) {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestMediaEngineAdvertisesOnlyConfiguredCodec(t *testing.T) {
+	for _, tt := range []struct {
+		codec  AudioCodec
+		rtpmap string
+		fmtp   []string
+	}{
+		{DefaultAudioCodec(), "a=rtpmap:111 opus/48000/2", []string{"stereo=0", "maxaveragebitrate=32000"}},
+		{AudioCodec{Name: "opus", Channels: 2, Bitrate: 64000}, "a=rtpmap:111 opus/48000/2", []string{"stereo=1", "maxaveragebitrate=64000"}},
+		{AudioCodec{Name: "pcmu", Channels: 1}, "a=rtpmap:0 PCMU/8000/1", nil},
+		{AudioCodec{}, "a=rtpmap:111 opus/48000/2", []string{"stereo=0", "maxaveragebitrate=32000"}},
+	} {
+		sdp := audioOffer(t, tt.codec)
+		if got := sdpLines(sdp, "a=rtpmap:"); !slices.Equal(got, []string{tt.rtpmap}) {
+			t.Errorf("%+v: rtpmap = %v, want only %s", tt.codec, got, tt.rtpmap)
+		}
+		fmtp := strings.Join(sdpLines(sdp, "a=fmtp:"), "\n")
+		for _, param := range tt.fmtp {
+			if !strings.Contains(fmtp, param) {
+				t.Errorf("%+v: fmtp %q lacks %s", tt.codec, fmtp, param)
+			}
+		}
+	}
+}
+
+func TestUnsupportedCodecRejected(t *testing.T) {
+	for _, codec := range []AudioCodec{
+		{Name: "vp8", Channels: 1},
+		{Name: "opus", Channels: 3},
+		{Name: "opus", Channels: 1, Bitrate: -1},
+	} {
+		if _, err := New(&Config{AudioCodec: codec}, discardLogger()); err == nil {
+			t.Errorf("%+v accepted", codec)
+		}
+	}
+}
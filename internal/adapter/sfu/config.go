@@ -1,27 +1,144 @@
 package sfu
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
+// SpeakingDetection selects who decides whether a participant is speaking
+type SpeakingDetection string
+
+const (
+	// SpeakingDetectionClient trusts speaking_state messages sent by clients
+	SpeakingDetectionClient SpeakingDetection = "client"
+	// SpeakingDetectionServer derives speaking state from incoming RTP traffic
+	SpeakingDetectionServer SpeakingDetection = "server"
+)
+
+// ICEServer describes a STUN or TURN server used for NAT traversal
+type ICEServer struct {
+	URLs       []string `json:"urls"`
+	Username   string   `json:"username,omitempty"`
+	Credential string   `json:"credential,omitempty"`
+}
+
 // Config holds SFU configuration
 type Config struct {
 	// UDP port range for WebRTC media
 	UDPPortMin int
 	UDPPortMax int
 
-	// STUN server for NAT traversal
-	STUNServer string
+	// STUN/TURN servers for NAT traversal
+	ICEServers []ICEServer
+
+	// LANMode skips STUN/TURN entirely and relies on host candidates. It
+	// avoids the STUN round trip (and its failure modes) on a local network,
+	// but clients outside that network will not be able to connect.
+	LANMode bool
+
+	// Interface restricts ICE candidates, and so the UDP port range, to one
+	// network interface such as "eth0" (empty = all interfaces)
+	Interface string
+
+	// Source of truth for speaking indicators
+	SpeakingDetection SpeakingDetection
+
+	// The only audio codec offered to clients
+	AudioCodec AudioCodec
+}
+
+// DefaultConfig returns default SFU configuration.
+// ICE servers come from SFU_ICE_SERVERS (a JSON array) when set,
+// otherwise from the single SFU_STUN_SERVER URL; SFU_LAN_MODE uses none.
+func DefaultConfig() (*Config, error) {
+	defaultCodec := DefaultAudioCodec()
+	config := &Config{
+		UDPPortMin:        getEnvInt("SFU_UDP_PORT_MIN", 5000),
+		UDPPortMax:        getEnvInt("SFU_UDP_PORT_MAX", 5100),
+		SpeakingDetection: SpeakingDetection(getEnv("SFU_SPEAKING_DETECTION", string(SpeakingDetectionClient))),
+		LANMode:           getEnvBool("SFU_LAN_MODE", false),
+		Interface:         getEnv("SFU_INTERFACE", ""),
+		AudioCodec: AudioCodec{
+			Name:     strings.ToLower(getEnv("SFU_AUDIO_CODEC", defaultCodec.Name)),
+			Channels: getEnvInt("SFU_AUDIO_CHANNELS", defaultCodec.Channels),
+			Bitrate:  getEnvInt("SFU_AUDIO_BITRATE", defaultCodec.Bitrate),
+		},
+	}
+	// LAN mode uses host candidates only, so there is nothing to read
+	if !config.LANMode {
+		if raw := strings.TrimSpace(os.Getenv("SFU_ICE_SERVERS")); raw != "" {
+			servers, err := parseICEServers(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid SFU_ICE_SERVERS: %w", err)
+			}
+			config.ICEServers = servers
+		}
+		// An empty list would leave clients behind NAT with no way through
+		if len(config.ICEServers) == 0 {
+			config.ICEServers = []ICEServer{
+				{URLs: []string{getEnv("SFU_STUN_SERVER", "stun:stun.l.google.com:19302")}},
+			}
+		}
+	}
+
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// validate checks the config and applies LAN mode, which drops any STUN or
+// TURN servers however the config was built. New calls it as well, so a
+// config assembled by hand gets the same treatment as one from the environment.
+func (c *Config) validate() error {
+	if err := c.AudioCodec.validate(); err != nil {
+		return fmt.Errorf("invalid SFU audio codec: %w", err)
+	}
+	if c.LANMode {
+		c.ICEServers = nil
+	}
+	return nil
 }
 
-// DefaultConfig returns default SFU configuration
-func DefaultConfig() *Config {
-	return &Config{
-		UDPPortMin: getEnvInt("SFU_UDP_PORT_MIN", 5000),
-		UDPPortMax: getEnvInt("SFU_UDP_PORT_MAX", 5100),
-		STUNServer: getEnv("SFU_STUN_SERVER", "stun:stun.l.google.com:19302"),
+// ICEServerURLs returns all configured ICE server URLs (without credentials)
+func (c *Config) ICEServerURLs() []string {
+	urls := make([]string, 0, len(c.ICEServers))
+	for _, server := range c.ICEServers {
+		urls = append(urls, server.URLs...)
+	}
+	return urls
+}
+
+// parseICEServers decodes and validates a JSON array of ICE servers
+func parseICEServers(raw string) ([]ICEServer, error) {
+	var servers []ICEServer
+	if err := json.Unmarshal([]byte(raw), &servers); err != nil {
+		return nil, fmt.Errorf("expected JSON array of {urls, username, credential}: %w", err)
+	}
+
+	for i, server := range servers {
+		if len(server.URLs) == 0 {
+			return nil, fmt.Errorf("server %d has no urls", i)
+		}
+		isTURN := false
+		for _, url := range server.URLs {
+			switch {
+			case strings.HasPrefix(url, "stun:"), strings.HasPrefix(url, "stuns:"):
+			case strings.HasPrefix(url, "turn:"), strings.HasPrefix(url, "turns:"):
+				isTURN = true
+			default:
+				return nil, fmt.Errorf("server %d: url %q must start with stun:, stuns:, turn: or turns:", i, url)
+			}
+		}
+		if isTURN && (server.Username == "" || server.Credential == "") {
+			return nil, fmt.Errorf("server %d: TURN servers require username and credential", i)
+		}
 	}
+
+	return servers, nil
 }
 
 func getEnv(key, fallback string) string {
@@ -39,3 +156,12 @@ func getEnvInt(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func getEnvBool(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
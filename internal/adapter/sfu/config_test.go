@@ -0,0 +1,84 @@
+package sfu
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLANModeConfiguresNoICEServers(t *testing.T) {
+	const stun = "stun:stun.example.com:3478"
+	t.Setenv("SFU_ICE_SERVERS", `[{"urls":["`+stun+`"]}]`)
+	t.Setenv("SFU_INTERFACE", "eth1")
+
+	for _, lan := range []bool{false, true} {
+		if lan {
+			t.Setenv("SFU_LAN_MODE", "true")
+		}
+		config, err := DefaultConfig()
+		if err != nil {
+			t.Fatalf("lan %v: default config: %v", lan, err)
+		}
+		if config.LANMode != lan || config.Interface != "eth1" {
+			t.Errorf("lan %v: config lan mode %v, interface %q", lan, config.LANMode, config.Interface)
+		}
+
+		s, err := New(config, discardLogger())
+		if err != nil {
+			t.Fatalf("lan %v: new sfu: %v", lan, err)
+		}
+		pc, err := s.CreatePeerConnection()
+		if err != nil {
+			t.Fatalf("lan %v: peer connection: %v", lan, err)
+		}
+		servers := pc.GetConfiguration().ICEServers
+		pc.Close()
+
+		if lan && (len(servers) != 0 || len(config.ICEServerURLs()) != 0) {
+			t.Errorf("lan %v: ICE servers %v configured", lan, servers)
+		}
+		if !lan && (len(servers) != 1 || !slices.Equal(servers[0].URLs, []string{stun})) {
+			t.Errorf("lan %v: ICE servers %v, want %s", lan, servers, stun)
+		}
+	}
+}
+
+func TestLANModeAppliesToHandBuiltConfig(t *testing.T) {
+	config := &Config{
+		UDPPortMin: 5000,
+		UDPPortMax: 5100,
+		LANMode:    true,
+		ICEServers: []ICEServer{{URLs: []string{"stun:stun.example.com:3478"}}},
+	}
+	s, err := New(config, discardLogger())
+	if err != nil {
+		t.Fatalf("new sfu: %v", err)
+	}
+	pc, err := s.CreatePeerConnection()
+	if err != nil {
+		t.Fatalf("peer connection: %v", err)
+	}
+	defer pc.Close()
+
+	if servers := pc.GetConfiguration().ICEServers; len(servers) != 0 {
+		t.Errorf("ICE servers %v configured in LAN mode", servers)
+	}
+	if urls := config.ICEServerURLs(); len(urls) != 0 {
+		t.Errorf("config still lists ICE servers %v in LAN mode", urls)
+	}
+}
+
+func TestEmptyICEServersFallBackToSTUNServer(t *testing.T) {
+	const stun = "stun:stun.example.com:3478"
+	t.Setenv("SFU_STUN_SERVER", stun)
+
+	for _, raw := range []string{"", "  ", "[]"} {
+		t.Setenv("SFU_ICE_SERVERS", raw)
+		config, err := DefaultConfig()
+		if err != nil {
+			t.Fatalf("SFU_ICE_SERVERS=%q: default config: %v", raw, err)
+		}
+		if urls := config.ICEServerURLs(); !slices.Equal(urls, []string{stun}) {
+			t.Errorf("SFU_ICE_SERVERS=%q: ICE servers %v, want %s", raw, urls, stun)
+		}
+	}
+}
@@ -15,7 +15,9 @@ type Participant struct {
 	AudioSender  *webrtc.RTPSender
 	CanSpeak     bool
 	CanHear      []string // list of participant IDs this participant can hear
+	muted        map[string]bool // participants this one has personally muted
 	IsSpeaking   bool
+	Detector     *SpeakingDetector // server-side voice activity detection
 	mu           sync.RWMutex
 }
 
@@ -26,6 +28,8 @@ func NewParticipant(id, roomCode string) *Participant {
 		RoomCode: roomCode,
 		CanSpeak: true,
 		CanHear:  make([]string, 0),
+		muted:    make(map[string]bool),
+		Detector: NewSpeakingDetector(),
 	}
 }
 
@@ -64,12 +68,17 @@ func (p *Participant) SetCanHear(ids []string) {
 	p.CanHear = ids
 }
 
-// GetCanHear returns the list of participant IDs this participant can hear
+// GetCanHear returns the list of participant IDs this participant can hear:
+// the routed set minus anyone they have personally muted
 func (p *Participant) GetCanHear() []string {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
-	result := make([]string, len(p.CanHear))
-	copy(result, p.CanHear)
+	result := make([]string, 0, len(p.CanHear))
+	for _, hearID := range p.CanHear {
+		if !p.muted[hearID] {
+			result = append(result, hearID)
+		}
+	}
 	return result
 }
 
@@ -77,6 +86,9 @@ func (p *Participant) GetCanHear() []string {
 func (p *Participant) CanHearParticipant(id string) bool {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
+	if p.muted[id] {
+		return false
+	}
 	for _, hearID := range p.CanHear {
 		if hearID == id {
 			return true
@@ -85,6 +97,25 @@ func (p *Participant) CanHearParticipant(id string) bool {
 	return false
 }
 
+// SetMuted adds or removes id from this participant's personal mute set.
+// Personal mutes survive routing changes; they only ever narrow CanHear.
+func (p *Participant) SetMuted(id string, muted bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if muted {
+		p.muted[id] = true
+	} else {
+		delete(p.muted, id)
+	}
+}
+
+// IsMuted reports whether this participant has personally muted id
+func (p *Participant) IsMuted(id string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.muted[id]
+}
+
 // Close closes the peer connection
 func (p *Participant) Close() error {
 	p.mu.Lock()
@@ -0,0 +1,38 @@
+package sfu
+
+import (
+	"io"
+	"log/slog"
+	"slices"
+	"testing"
+)
+
+func TestPersonalMuteNarrowsCanHear(t *testing.T) {
+	room := NewVoiceRoom("ROOM", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	everyone := []string{"a", "b", "c"}
+	for _, id := range everyone {
+		p := NewParticipant(id, room.Code)
+		p.SetCanHear(everyone)
+		room.AddParticipant(p)
+	}
+	a := room.GetParticipant("a")
+
+	a.SetMuted("b", true)
+	if hear := a.GetCanHear(); slices.Contains(hear, "b") || len(hear) != 2 {
+		t.Errorf("a can hear %v, want the routed set minus b", hear)
+	}
+	if hear := room.GetParticipant("c").GetCanHear(); !slices.Contains(hear, "b") {
+		t.Errorf("c can hear %v; a's mute leaked to them", hear)
+	}
+
+	// A routing change widens CanHear but never overrides a personal mute
+	room.GetRouter().SubscribeToOnly("a", []string{"b", "c"})
+	if a.CanHearParticipant("b") || slices.Contains(a.GetCanHear(), "b") {
+		t.Error("routing change undid a personal mute")
+	}
+
+	a.SetMuted("b", false)
+	if hear := a.GetCanHear(); !slices.Contains(hear, "b") {
+		t.Errorf("a can hear %v after unmute, want b back", hear)
+	}
+}
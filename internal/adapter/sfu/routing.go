@@ -1,5 +1,7 @@
 package sfu
 
+import "slices"
+
 // GamePhase represents the current game phase for voice routing
 type GamePhase string
 
@@ -31,16 +33,33 @@ type PlayerVoiceState struct {
 type VoiceRoutingState struct {
 	Phase   GamePhase
 	Players []PlayerVoiceState
+	Options RoutingOptions
+}
+
+// RoutingOptions holds the room settings that change voice routing
+type RoutingOptions struct {
+	// DeadDayWhisper lets dead players talk among themselves during the
+	// day while still listening to the living, who never hear them
+	DeadDayWhisper bool
+
+	// DeadNightChat lets dead players talk among themselves at night,
+	// unheard by the living; without it the dead are muted at night
+	DeadNightChat bool
+
+	// Speaker, when set during the day, is the only living player who may
+	// speak (turn-based voting); everyone still hears the living
+	Speaker string
 }
 
 // CalculateRouting determines voice permissions based on game phase
 // Returns a map of playerID -> PlayerVoiceState
-func CalculateRouting(phase GamePhase, players []PlayerInfo) map[string]PlayerVoiceState {
+func CalculateRouting(phase GamePhase, players []PlayerInfo, opts RoutingOptions) map[string]PlayerVoiceState {
 	result := make(map[string]PlayerVoiceState)
 
 	// Separate players by team and status
-	var aliveTown, aliveMafia, deadPlayers []string
+	var aliveTown, aliveMafia []string
 	allAlive := make([]string, 0)
+	deadPlayers := make([]string, 0)
 
 	for _, p := range players {
 		if p.IsAlive {
@@ -74,7 +93,11 @@ func CalculateRouting(phase GamePhase, players []PlayerInfo) map[string]PlayerVo
 			state.CanHear = allPlayers
 
 		case PhaseNight:
-			if !p.IsAlive {
+			if !p.IsAlive && opts.DeadNightChat {
+				// Dead: a ghost channel, speak + hear only other dead
+				state.CanSpeak = true
+				state.CanHear = slices.Clone(deadPlayers)
+			} else if !p.IsAlive {
 				// Dead: muted, can't hear anyone
 				state.CanSpeak = false
 				state.CanHear = []string{}
@@ -89,13 +112,17 @@ func CalculateRouting(phase GamePhase, players []PlayerInfo) map[string]PlayerVo
 			}
 
 		case PhaseDay:
-			if !p.IsAlive {
+			if !p.IsAlive && opts.DeadDayWhisper {
+				// Dead: a ghost channel overlaid on listening to the living
+				state.CanSpeak = true
+				state.CanHear = slices.Concat(allAlive, deadPlayers)
+			} else if !p.IsAlive {
 				// Dead: muted, can hear alive players
 				state.CanSpeak = false
 				state.CanHear = allAlive
 			} else {
-				// Alive: speak + hear all alive
-				state.CanSpeak = true
+				// Alive: speak + hear all alive, speaking in turn if turns are on
+				state.CanSpeak = opts.Speaker == "" || opts.Speaker == p.ID
 				state.CanHear = allAlive
 			}
 
@@ -130,7 +157,7 @@ func NewRouter(room *VoiceRoom) *Router {
 
 // ApplyRouting applies voice routing based on game state
 func (r *Router) ApplyRouting(state VoiceRoutingState) {
-	routing := CalculateRouting(state.Phase, convertToPlayerInfo(state.Players))
+	routing := CalculateRouting(state.Phase, convertToPlayerInfo(state.Players), state.Options)
 
 	for playerID, voiceState := range routing {
 		participant := r.room.GetParticipant(playerID)
@@ -0,0 +1,127 @@
+package sfu
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNightRoutingGivesDeadTheirOwnChannel(t *testing.T) {
+	players := []PlayerInfo{
+		{ID: "town", Team: TeamTown, IsAlive: true},
+		{ID: "mafia", Team: TeamMafia, IsAlive: true},
+		{ID: "ghost1", Team: TeamTown, IsAlive: false},
+		{ID: "ghost2", Team: TeamMafia, IsAlive: false},
+	}
+	routing := CalculateRouting(PhaseNight, players, RoutingOptions{DeadNightChat: true})
+
+	for _, ghost := range []string{"ghost1", "ghost2"} {
+		state := routing[ghost]
+		if !state.CanSpeak || !slices.Equal(state.CanHear, []string{"ghost1", "ghost2"}) {
+			t.Errorf("%s: speak %v, hear %v; want speak and hear the dead only", ghost, state.CanSpeak, state.CanHear)
+		}
+	}
+
+	// The living never hear the ghost channel
+	for _, living := range []string{"town", "mafia"} {
+		for _, ghost := range []string{"ghost1", "ghost2"} {
+			if slices.Contains(routing[living].CanHear, ghost) {
+				t.Errorf("%s can hear %s at night", living, ghost)
+			}
+		}
+	}
+	if !slices.Equal(routing["mafia"].CanHear, []string{"mafia"}) {
+		t.Errorf("mafia hears %v, want only living mafia", routing["mafia"].CanHear)
+	}
+}
+
+func TestNightRoutingMutesDeadByDefault(t *testing.T) {
+	players := []PlayerInfo{
+		{ID: "town", Team: TeamTown, IsAlive: true},
+		{ID: "ghost1", Team: TeamTown, IsAlive: false},
+		{ID: "ghost2", Team: TeamMafia, IsAlive: false},
+	}
+	routing := CalculateRouting(PhaseNight, players, RoutingOptions{})
+
+	for _, ghost := range []string{"ghost1", "ghost2"} {
+		if state := routing[ghost]; state.CanSpeak || len(state.CanHear) != 0 {
+			t.Errorf("%s: speak %v, hear %v; want muted, hearing nobody", ghost, state.CanSpeak, state.CanHear)
+		}
+	}
+}
+
+func TestNightRoutingCopiesDeadListPerPlayer(t *testing.T) {
+	players := []PlayerInfo{
+		{ID: "ghost1", Team: TeamTown, IsAlive: false},
+		{ID: "ghost2", Team: TeamMafia, IsAlive: false},
+	}
+	routing := CalculateRouting(PhaseNight, players, RoutingOptions{DeadNightChat: true})
+
+	// Narrowing one ghost's list must not change another's
+	routing["ghost1"].CanHear[0] = "changed"
+	if routing["ghost2"].CanHear[0] != "ghost1" {
+		t.Errorf("ghost2 hears %v after ghost1's list changed", routing["ghost2"].CanHear)
+	}
+}
+
+func TestDayRoutingDeadListenOnly(t *testing.T) {
+	players := []PlayerInfo{
+		{ID: "town", Team: TeamTown, IsAlive: true},
+		{ID: "mafia", Team: TeamMafia, IsAlive: true},
+		{ID: "ghost1", Team: TeamTown, IsAlive: false},
+		{ID: "ghost2", Team: TeamMafia, IsAlive: false},
+	}
+	routing := CalculateRouting(PhaseDay, players, RoutingOptions{})
+
+	for _, ghost := range []string{"ghost1", "ghost2"} {
+		state := routing[ghost]
+		if state.CanSpeak || !slices.Equal(state.CanHear, []string{"town", "mafia"}) {
+			t.Errorf("%s: speak %v, hear %v; want muted, hearing the living", ghost, state.CanSpeak, state.CanHear)
+		}
+	}
+}
+
+func TestDayRoutingDeadWhisper(t *testing.T) {
+	players := []PlayerInfo{
+		{ID: "town", Team: TeamTown, IsAlive: true},
+		{ID: "mafia", Team: TeamMafia, IsAlive: true},
+		{ID: "ghost1", Team: TeamTown, IsAlive: false},
+		{ID: "ghost2", Team: TeamMafia, IsAlive: false},
+	}
+	routing := CalculateRouting(PhaseDay, players, RoutingOptions{DeadDayWhisper: true})
+
+	for _, ghost := range []string{"ghost1", "ghost2"} {
+		state := routing[ghost]
+		if !state.CanSpeak || !slices.Equal(state.CanHear, []string{"town", "mafia", "ghost1", "ghost2"}) {
+			t.Errorf("%s: speak %v, hear %v; want speak, hearing the living and the dead", ghost, state.CanSpeak, state.CanHear)
+		}
+	}
+
+	// The living still only hear each other
+	for _, living := range []string{"town", "mafia"} {
+		state := routing[living]
+		if !state.CanSpeak || !slices.Equal(state.CanHear, []string{"town", "mafia"}) {
+			t.Errorf("%s: speak %v, hear %v; want the living only", living, state.CanSpeak, state.CanHear)
+		}
+	}
+}
+
+func TestDayRoutingSpeakingTurns(t *testing.T) {
+	players := []PlayerInfo{
+		{ID: "p0", Team: TeamTown, IsAlive: true},
+		{ID: "p1", Team: TeamMafia, IsAlive: true},
+		{ID: "p2", Team: TeamTown, IsAlive: true},
+		{ID: "ghost", Team: TeamTown, IsAlive: false},
+	}
+
+	for _, speaker := range []string{"p0", "p1", "p2"} {
+		routing := CalculateRouting(PhaseDay, players, RoutingOptions{Speaker: speaker})
+		for _, p := range players {
+			if got, want := routing[p.ID].CanSpeak, p.ID == speaker; got != want {
+				t.Errorf("%s's turn: %s can speak = %v, want %v", speaker, p.ID, got, want)
+			}
+			if p.IsAlive && !slices.Equal(routing[p.ID].CanHear, []string{"p0", "p1", "p2"}) {
+				t.Errorf("%s's turn: %s hears %v, want every living player", speaker, p.ID, routing[p.ID].CanHear)
+			}
+		}
+	}
+}
@@ -1,13 +1,32 @@
 package sfu
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/pion/webrtc/v4"
 )
 
+// ErrNegotiationFailed indicates an offer failed and the peer connection was reset
+var ErrNegotiationFailed = errors.New("voice negotiation failed")
+
+// ErrInvalidOffer indicates an offer was rejected before reaching negotiation
+var ErrInvalidOffer = errors.New("invalid voice offer")
+
+// maxOfferSize bounds the SDP accepted from clients (audio-only offers are a few KB)
+const maxOfferSize = 64 * 1024
+
+// vadTickInterval is how often silence is re-evaluated when no packets arrive
+const vadTickInterval = 100 * time.Millisecond
+
+// SpeakingChangeHandler is called when server-side detection flips a speaking state
+type SpeakingChangeHandler func(roomCode, playerID string, speaking bool)
+
 // SFU manages WebRTC connections and audio routing
 type SFU struct {
 	config   *Config
@@ -15,19 +34,31 @@ type SFU struct {
 	api      *webrtc.API
 	logger   *slog.Logger
 	mu       sync.RWMutex
+
+	onSpeakingChange SpeakingChangeHandler
 }
 
 // New creates a new SFU instance
 func New(config *Config, logger *slog.Logger) (*SFU, error) {
-	// Create media engine
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+
+	// Create media engine with only the configured audio codec (voice only, no video)
 	mediaEngine := &webrtc.MediaEngine{}
-	if err := mediaEngine.RegisterDefaultCodecs(); err != nil {
+	if err := registerAudioCodec(mediaEngine, config.AudioCodec); err != nil {
 		return nil, fmt.Errorf("failed to register codecs: %w", err)
 	}
 
 	// Create setting engine with UDP port range
 	settingEngine := webrtc.SettingEngine{}
 	settingEngine.SetEphemeralUDPPortRange(uint16(config.UDPPortMin), uint16(config.UDPPortMax))
+	if config.Interface != "" {
+		iface := config.Interface
+		settingEngine.SetInterfaceFilter(func(name string) bool {
+			return name == iface
+		})
+	}
 
 	// Create WebRTC API
 	api := webrtc.NewAPI(
@@ -44,12 +75,27 @@ func New(config *Config, logger *slog.Logger) (*SFU, error) {
 
 	logger.Info("SFU initialized",
 		"udp_port_range", fmt.Sprintf("%d-%d", config.UDPPortMin, config.UDPPortMax),
-		"stun_server", config.STUNServer,
+		"ice_servers", config.ICEServerURLs(),
+		"lan_mode", config.LANMode,
+		"interface", config.Interface,
+		"speaking_detection", config.SpeakingDetection,
+		"audio_codec", config.AudioCodec.orDefault().Name,
+		"audio_bitrate", config.AudioCodec.orDefault().Bitrate,
 	)
 
 	return sfu, nil
 }
 
+// SetSpeakingChangeHandler sets the callback for server-detected speaking changes
+func (s *SFU) SetSpeakingChangeHandler(handler SpeakingChangeHandler) {
+	s.onSpeakingChange = handler
+}
+
+// ServerSpeakingDetection returns true if speaking state is derived server-side
+func (s *SFU) ServerSpeakingDetection() bool {
+	return s.config.SpeakingDetection == SpeakingDetectionServer
+}
+
 // GetOrCreateRoom gets or creates a voice room
 func (s *SFU) GetOrCreateRoom(roomCode string) *VoiceRoom {
 	s.mu.Lock()
@@ -65,6 +111,15 @@ func (s *SFU) GetOrCreateRoom(roomCode string) *VoiceRoom {
 	return room
 }
 
+// GetParticipant returns a voice participant if they joined voice in the room
+func (s *SFU) GetParticipant(roomCode, playerID string) *Participant {
+	room := s.GetRoom(roomCode)
+	if room == nil {
+		return nil
+	}
+	return room.GetParticipant(playerID)
+}
+
 // GetRoom returns a voice room if it exists
 func (s *SFU) GetRoom(roomCode string) *VoiceRoom {
 	s.mu.RLock()
@@ -84,14 +139,34 @@ func (s *SFU) RemoveRoom(roomCode string) {
 	}
 }
 
+// ParticipantCount returns the number of voice participants across all rooms
+func (s *SFU) ParticipantCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, room := range s.rooms {
+		count += len(room.GetParticipantIDs())
+	}
+	return count
+}
+
 // CreatePeerConnection creates a new WebRTC peer connection
 func (s *SFU) CreatePeerConnection() (*webrtc.PeerConnection, error) {
+	iceServers := make([]webrtc.ICEServer, 0, len(s.config.ICEServers))
+	for _, server := range s.config.ICEServers {
+		iceServer := webrtc.ICEServer{
+			URLs: server.URLs,
+		}
+		if server.Username != "" {
+			iceServer.Username = server.Username
+			iceServer.Credential = server.Credential
+		}
+		iceServers = append(iceServers, iceServer)
+	}
+
 	config := webrtc.Configuration{
-		ICEServers: []webrtc.ICEServer{
-			{
-				URLs: []string{s.config.STUNServer},
-			},
-		},
+		ICEServers: iceServers,
 	}
 
 	return s.api.NewPeerConnection(config)
@@ -165,6 +240,54 @@ func (s *SFU) HandleOffer(roomCode, playerID string, offer webrtc.SessionDescrip
 		return nil, fmt.Errorf("peer connection not found for: %s", playerID)
 	}
 
+	if err := ValidateOffer(offer.SDP); err != nil {
+		return nil, err
+	}
+
+	answer, err := negotiate(pc, offer)
+	if err != nil {
+		s.logger.Warn("voice negotiation failed, resetting peer connection",
+			"room", roomCode,
+			"player", playerID,
+			"error", err,
+		)
+		// Without a fresh connection there is nothing to renegotiate on
+		if resetErr := s.resetPeerConnection(participant); resetErr != nil {
+			return nil, fmt.Errorf("negotiation failed: %v (reset failed: %w)", err, resetErr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrNegotiationFailed, err)
+	}
+
+	return answer, nil
+}
+
+// ValidateOffer rejects SDP that is empty, oversized or clearly not an offer
+// so garbage never reaches pion
+func ValidateOffer(sdp string) error {
+	if strings.TrimSpace(sdp) == "" {
+		return fmt.Errorf("%w: empty sdp", ErrInvalidOffer)
+	}
+	if len(sdp) > maxOfferSize {
+		return fmt.Errorf("%w: sdp exceeds %d bytes", ErrInvalidOffer, maxOfferSize)
+	}
+	if !strings.HasPrefix(sdp, "v=0") {
+		return fmt.Errorf("%w: sdp must start with v=0", ErrInvalidOffer)
+	}
+	if !strings.Contains(sdp, "\nm=") {
+		return fmt.Errorf("%w: sdp has no media section", ErrInvalidOffer)
+	}
+	return nil
+}
+
+// negotiate applies a remote offer and produces the local answer.
+// A panic inside pion is turned into an error so it cannot take down the caller.
+func negotiate(pc *webrtc.PeerConnection, offer webrtc.SessionDescription) (_ *webrtc.SessionDescription, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic during negotiation: %v", r)
+		}
+	}()
+
 	// Set remote description (offer)
 	if err := pc.SetRemoteDescription(offer); err != nil {
 		return nil, fmt.Errorf("failed to set remote description: %w", err)
@@ -184,6 +307,20 @@ func (s *SFU) HandleOffer(roomCode, playerID string, offer webrtc.SessionDescrip
 	return pc.LocalDescription(), nil
 }
 
+// resetPeerConnection closes a half-negotiated peer connection and replaces it with a fresh one
+func (s *SFU) resetPeerConnection(participant *Participant) error {
+	if err := participant.Close(); err != nil {
+		s.logger.Debug("error closing peer connection", "player", participant.ID, "error", err)
+	}
+
+	pc, err := s.CreatePeerConnection()
+	if err != nil {
+		return fmt.Errorf("failed to create peer connection: %w", err)
+	}
+	participant.SetPeerConnection(pc)
+	return nil
+}
+
 // AddICECandidate adds an ICE candidate to a peer connection
 func (s *SFU) AddICECandidate(roomCode, playerID string, candidate webrtc.ICECandidateInit) error {
 	room := s.GetRoom(roomCode)
@@ -204,6 +341,73 @@ func (s *SFU) AddICECandidate(roomCode, playerID string, candidate webrtc.ICECan
 	return pc.AddICECandidate(candidate)
 }
 
+// ConsumeTrack reads RTP from a participant's incoming audio track until it ends.
+// In server detection mode, packets feed the participant's speaking detector.
+func (s *SFU) ConsumeTrack(roomCode, playerID string, track *webrtc.TrackRemote) {
+	room := s.GetRoom(roomCode)
+	if room == nil {
+		return
+	}
+	participant := room.GetParticipant(playerID)
+	if participant == nil {
+		return
+	}
+
+	detect := s.ServerSpeakingDetection()
+	done := make(chan struct{})
+	defer close(done)
+
+	if detect {
+		go func() {
+			ticker := time.NewTicker(vadTickInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case now := <-ticker.C:
+					if speaking, changed := participant.Detector.Tick(now); changed {
+						s.updateSpeaking(participant, speaking)
+					}
+				}
+			}
+		}()
+	}
+
+	for {
+		packet, _, err := track.ReadRTP()
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				s.logger.Debug("audio track read ended",
+					"room", roomCode,
+					"player", playerID,
+					"error", err,
+				)
+			}
+			break
+		}
+
+		if !detect {
+			continue
+		}
+		if speaking, changed := participant.Detector.Observe(time.Now(), len(packet.Payload)); changed {
+			s.updateSpeaking(participant, speaking)
+		}
+	}
+
+	if detect && participant.Detector.Reset() {
+		s.updateSpeaking(participant, false)
+	}
+}
+
+// updateSpeaking stores a detected speaking state and notifies the handler
+func (s *SFU) updateSpeaking(participant *Participant, speaking bool) {
+	participant.SetSpeakingState(speaking)
+	if s.onSpeakingChange != nil {
+		s.onSpeakingChange(participant.RoomCode, participant.ID, speaking)
+	}
+}
+
 // SetSpeakingState updates speaking indicator for a player
 func (s *SFU) SetSpeakingState(roomCode, playerID string, speaking bool) {
 	room := s.GetRoom(roomCode)
@@ -221,6 +425,18 @@ func (s *SFU) GetSpeakingStates(roomCode string) map[string]bool {
 	return room.GetSpeakingStates()
 }
 
+// SetPersonalMute adds or removes targetID from playerID's personal mute
+// set, so the target drops out of what they can hear whatever the routing
+// says
+func (s *SFU) SetPersonalMute(roomCode, playerID, targetID string, muted bool) error {
+	participant := s.GetParticipant(roomCode, playerID)
+	if participant == nil {
+		return fmt.Errorf("participant not found: %s", playerID)
+	}
+	participant.SetMuted(targetID, muted)
+	return nil
+}
+
 // ApplyVoiceRouting applies voice routing rules to a room
 func (s *SFU) ApplyVoiceRouting(roomCode string, state VoiceRoutingState) {
 	room := s.GetRoom(roomCode)
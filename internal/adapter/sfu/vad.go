@@ -0,0 +1,90 @@
+package sfu
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// vadWindow is the sliding window over which voice packets are counted
+	vadWindow = 300 * time.Millisecond
+
+	// vadMinPayload is the smallest RTP payload treated as voice.
+	// Opus DTX/comfort-noise frames are only a few bytes.
+	vadMinPayload = 10
+
+	// vadStartPackets is how many voice packets in the window start speaking
+	// (Opus sends a 20ms frame, so ~15 packets per 300ms of continuous audio)
+	vadStartPackets = 8
+
+	// vadStopPackets is the count at or below which speaking stops
+	vadStopPackets = 2
+)
+
+// SpeakingDetector derives a speaking flag from RTP packet rate and size
+type SpeakingDetector struct {
+	packets  []time.Time // arrival times of voice packets inside the window
+	speaking bool
+	mu       sync.Mutex
+}
+
+// NewSpeakingDetector creates a new speaking detector
+func NewSpeakingDetector() *SpeakingDetector {
+	return &SpeakingDetector{
+		packets: make([]time.Time, 0, 32),
+	}
+}
+
+// Observe records an RTP packet and returns the speaking state and whether it changed
+func (d *SpeakingDetector) Observe(at time.Time, payloadLen int) (bool, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if payloadLen >= vadMinPayload {
+		d.packets = append(d.packets, at)
+	}
+	return d.evaluateLocked(at)
+}
+
+// Tick re-evaluates the window without a new packet (e.g., when audio stops)
+func (d *SpeakingDetector) Tick(at time.Time) (bool, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.evaluateLocked(at)
+}
+
+// Reset clears the window and returns true if the participant was speaking
+func (d *SpeakingDetector) Reset() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	wasSpeaking := d.speaking
+	d.packets = d.packets[:0]
+	d.speaking = false
+	return wasSpeaking
+}
+
+// Speaking returns the current detected state
+func (d *SpeakingDetector) Speaking() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.speaking
+}
+
+func (d *SpeakingDetector) evaluateLocked(at time.Time) (bool, bool) {
+	// Drop packets that fell out of the window
+	cutoff := at.Add(-vadWindow)
+	i := 0
+	for i < len(d.packets) && !d.packets[i].After(cutoff) {
+		i++
+	}
+	d.packets = d.packets[i:]
+
+	count := len(d.packets)
+	previous := d.speaking
+	if !d.speaking && count >= vadStartPackets {
+		d.speaking = true
+	} else if d.speaking && count <= vadStopPackets {
+		d.speaking = false
+	}
+	return d.speaking, d.speaking != previous
+}
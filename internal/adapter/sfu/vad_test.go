@@ -0,0 +1,65 @@
+package sfu
+
+import (
+	"testing"
+	"time"
+)
+
+// burst feeds n voice packets of size bytes at Opus's 20ms frame rate and
+// returns the time after the last one and every state change seen
+func burst(d *SpeakingDetector, start time.Time, n, size int) (time.Time, []bool) {
+	var changes []bool
+	at := start
+	for range n {
+		if speaking, changed := d.Observe(at, size); changed {
+			changes = append(changes, speaking)
+		}
+		at = at.Add(20 * time.Millisecond)
+	}
+	return at, changes
+}
+
+func TestSpeakingDetectorFollowsRTPBursts(t *testing.T) {
+	d := NewSpeakingDetector()
+	start := time.Unix(0, 0)
+
+	// DTX comfort-noise frames never count as speech
+	at, changes := burst(d, start, 30, vadMinPayload-1)
+	if len(changes) != 0 || d.Speaking() {
+		t.Fatalf("comfort noise changed state: %v", changes)
+	}
+
+	// Speaking starts once enough voice packets land inside the window
+	at, changes = burst(d, at, vadStartPackets-1, 80)
+	if d.Speaking() {
+		t.Fatalf("speaking after %d packets, want %d", vadStartPackets-1, vadStartPackets)
+	}
+	at, changes = burst(d, at, 1, 80)
+	if len(changes) != 1 || !changes[0] {
+		t.Fatalf("changes at the threshold = %v, want [true]", changes)
+	}
+
+	// Continuous speech holds the state without further changes
+	at, changes = burst(d, at, 50, 80)
+	if len(changes) != 0 || !d.Speaking() {
+		t.Fatalf("steady speech changed state: %v", changes)
+	}
+
+	// Silence drains the window and stops speaking exactly once
+	speaking, changed := d.Tick(at.Add(vadWindow))
+	if speaking || !changed {
+		t.Fatalf("after silence: speaking %v, changed %v", speaking, changed)
+	}
+	if _, changed := d.Tick(at.Add(2 * vadWindow)); changed {
+		t.Error("second tick of silence reported a change")
+	}
+
+	// A new burst starts speaking again; Reset reports and clears it
+	_, changes = burst(d, at.Add(time.Second), vadStartPackets, 80)
+	if len(changes) != 1 || !changes[0] {
+		t.Fatalf("second burst changes = %v, want [true]", changes)
+	}
+	if !d.Reset() || d.Speaking() {
+		t.Error("Reset did not report and clear speaking")
+	}
+}
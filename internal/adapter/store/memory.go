@@ -0,0 +1,53 @@
+package store
+
+import (
+	"sync"
+
+	"github.com/V4T54L/mafia/internal/domain/service"
+)
+
+// maxRecordsPerPlayer bounds memory used by a single history key
+const maxRecordsPerPlayer = 100
+
+// MemoryGameStore keeps game history in memory (lost on restart)
+type MemoryGameStore struct {
+	records map[string][]service.GameRecord // history key -> records, oldest first
+	mu      sync.RWMutex
+}
+
+// NewMemoryGameStore creates a new in-memory game store
+func NewMemoryGameStore() *MemoryGameStore {
+	return &MemoryGameStore{
+		records: make(map[string][]service.GameRecord),
+	}
+}
+
+// SaveRecord stores a finished game for a player
+func (s *MemoryGameStore) SaveRecord(historyKey string, record service.GameRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records := append(s.records[historyKey], record)
+	if len(records) > maxRecordsPerPlayer {
+		records = records[len(records)-maxRecordsPerPlayer:]
+	}
+	s.records[historyKey] = records
+	return nil
+}
+
+// GetPlayerHistory returns a player's games, newest first
+func (s *MemoryGameStore) GetPlayerHistory(historyKey string) ([]service.GameRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records, ok := s.records[historyKey]
+	if !ok || len(records) == 0 {
+		return nil, service.ErrNoHistory
+	}
+
+	result := make([]service.GameRecord, len(records))
+	for i, record := range records {
+		result[len(records)-1-i] = record
+	}
+	return result, nil
+}
@@ -0,0 +1,32 @@
+package ws
+
+import "sync/atomic"
+
+// maxAckIDLength bounds client-supplied ack IDs
+const maxAckIDLength = 64
+
+// ackCapture records the first error sent to a client while one of its
+// acknowledged messages is handled
+type ackCapture struct {
+	code atomic.Pointer[ErrorCode]
+}
+
+// captureErrors starts recording errors sent to c for an acknowledged message
+func (c *Client) captureErrors() *ackCapture {
+	capture := &ackCapture{}
+	c.ack.Store(capture)
+	return capture
+}
+
+// acknowledge stops capturing and answers ackID with whether handling the
+// message sent the client an error
+func (r *Router) acknowledge(client *Client, ackID string, capture *ackCapture) {
+	client.ack.CompareAndSwap(capture, nil)
+
+	payload := AckPayload{AckID: ackID, OK: true}
+	if code := capture.code.Load(); code != nil {
+		payload.OK = false
+		payload.ErrorCode = string(*code)
+	}
+	client.Send(MustMessage(EventTypeAck, payload))
+}
@@ -0,0 +1,46 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAckReportsVoteOutcome(t *testing.T) {
+	r := newTestRouter(t, false)
+	room, clients := startTestGame(t, r, 5, nil)
+	r.gameService.GetGame(room.Code).StartDay(time.Minute)
+
+	ackFor := func(c *Client, ackID string) AckPayload {
+		t.Helper()
+		var ack AckPayload
+		if !lastOfType(t, c, EventTypeAck, &ack) {
+			t.Fatalf("no ack for %s", ackID)
+		}
+		if ack.AckID != ackID {
+			t.Fatalf("ack_id = %q, want %q", ack.AckID, ackID)
+		}
+		return ack
+	}
+
+	vote := MustMessage(MsgTypeDayVote, DayVotePayload{TargetID: "p1"})
+	vote.AckID = "vote-1"
+	r.HandleMessage(clients["p0"], vote)
+	if ack := ackFor(clients["p0"], "vote-1"); !ack.OK || ack.ErrorCode != "" {
+		t.Errorf("valid vote ack = %+v, want ok", ack)
+	}
+
+	bad := MustMessage(MsgTypeDayVote, DayVotePayload{TargetID: "nobody"})
+	bad.AckID = "vote-2"
+	r.HandleMessage(clients["p2"], bad)
+	if ack := ackFor(clients["p2"], "vote-2"); ack.OK || ack.ErrorCode != string(ErrCodeInvalidTarget) {
+		t.Errorf("bad vote ack = %+v, want error %s", ack, ErrCodeInvalidTarget)
+	}
+
+	// Messages without an ack_id are not acknowledged
+	r.HandleMessage(clients["p3"], MustMessage(MsgTypeDayVote, DayVotePayload{TargetID: "p1"}))
+	for _, msg := range drainMessages(t, clients["p3"]) {
+		if msg.Type == EventTypeAck {
+			t.Error("ack sent for a message without an ack_id")
+		}
+	}
+}
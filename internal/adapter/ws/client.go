@@ -2,6 +2,9 @@ package ws
 
 import (
 	"log/slog"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -14,11 +17,19 @@ const (
 	// Time allowed to read the next pong message from the peer
 	pongWait = 60 * time.Second
 
-	// Send pings to peer with this period (must be less than pongWait)
-	pingPeriod = (pongWait * 9) / 10
+	// Send pings to peer with this period (must be less than pongWait).
+	// Pings carry a timestamp so they double as latency probes.
+	pingPeriod = 5 * time.Second
 
-	// Maximum message size allowed from peer
-	maxMessageSize = 4096
+	// Maximum message size allowed from peer. Exceeding it closes the
+	// connection, so it sits well above every per-type payload limit.
+	maxMessageSize = 64 * 1024
+
+	// Minimum time between resyncs served to one client
+	resyncInterval = 2 * time.Second
+
+	// Minimum time between one client's lobby chat messages
+	lobbyChatInterval = 500 * time.Millisecond
 )
 
 // Client represents a single WebSocket connection
@@ -37,19 +48,107 @@ type Client struct {
 	// Current room (empty if not in a room)
 	RoomCode string
 
+	// Watching RoomCode without a seat (see Hub.Spectate)
+	spectating atomic.Bool
+
 	// Logger
 	logger *slog.Logger
 
+	// logger with this client's player_id and room attached (see Logger)
+	scoped atomic.Pointer[slog.Logger]
+
 	// Message handler callback
 	onMessage func(*Client, *Message)
 
 	// Disconnect handler callback
 	onDisconnect func(*Client)
+
+	// Called once the connection is closed (releases the per-IP slot)
+	onClose func()
+
+	// Writes at least this many bytes are compressed when the peer negotiated
+	// permessage-deflate (0 = never)
+	compressThreshold int
+
+	// Last measured ping round-trip time in nanoseconds (0 = not measured yet)
+	rtt atomic.Int64
+
+	// Consecutive sends that found the buffer full
+	overflows atomic.Int32
+
+	// Held while stamping and queueing, so messages are queued in sequence
+	// order; guards seq and sendClosed
+	sendMu     sync.Mutex
+	seq        uint64 // last sequence number stamped for this connection
+	sendClosed bool
+
+	// Set when the hub drops the client; sent as the close frame's reason
+	closeHint atomic.Pointer[ReconnectHint]
+
+	// When the last resync was served, in Unix nanoseconds
+	lastResync atomic.Int64
+
+	// When the last lobby chat message was accepted, in Unix nanoseconds
+	lastLobbyChat atomic.Int64
+
+	// When the client last sent a message or joined a room, in Unix nanoseconds
+	lastActivity atomic.Int64
+
+	// Set while a message with an ack_id is handled (see Router.HandleMessage)
+	ack atomic.Pointer[ackCapture]
+}
+
+// Latency buckets reported to clients
+const (
+	LatencyGood = "good"
+	LatencyOK   = "ok"
+	LatencyPoor = "poor"
+)
+
+// allowResync reports whether a resync may be served now, and if so starts
+// a new rate-limit window
+func (c *Client) allowResync(now time.Time) bool {
+	return allowEvery(&c.lastResync, resyncInterval, now)
+}
+
+// allowLobbyChat reports whether a lobby chat message may be sent now
+func (c *Client) allowLobbyChat(now time.Time) bool {
+	return allowEvery(&c.lastLobbyChat, lobbyChatInterval, now)
+}
+
+// allowEvery admits one action per interval, tracking the last admitted
+// time in last
+func allowEvery(last *atomic.Int64, interval time.Duration, now time.Time) bool {
+	prev := last.Load()
+	if prev != 0 && now.Sub(time.Unix(0, prev)) < interval {
+		return false
+	}
+	return last.CompareAndSwap(prev, now.UnixNano())
+}
+
+// RTT returns the last measured round-trip time (0 if not measured yet)
+func (c *Client) RTT() time.Duration {
+	return time.Duration(c.rtt.Load())
+}
+
+// LatencyBucket returns the connection quality bucket, or empty if not measured yet
+func (c *Client) LatencyBucket() string {
+	rtt := c.RTT()
+	switch {
+	case rtt <= 0:
+		return ""
+	case rtt < 150*time.Millisecond:
+		return LatencyGood
+	case rtt < 400*time.Millisecond:
+		return LatencyOK
+	default:
+		return LatencyPoor
+	}
 }
 
 // NewClient creates a new Client
 func NewClient(hub *Hub, conn *websocket.Conn, playerID string, logger *slog.Logger, onMessage func(*Client, *Message), onDisconnect func(*Client)) *Client {
-	return &Client{
+	c := &Client{
 		hub:          hub,
 		conn:         conn,
 		send:         make(chan []byte, 256),
@@ -58,6 +157,23 @@ func NewClient(hub *Hub, conn *websocket.Conn, playerID string, logger *slog.Log
 		onMessage:    onMessage,
 		onDisconnect: onDisconnect,
 	}
+	c.rescopeLogger()
+	return c
+}
+
+// Logger returns a logger that tags every record with this client's
+// player_id and, while it is in one, its room
+func (c *Client) Logger() *slog.Logger {
+	return c.scoped.Load()
+}
+
+// rescopeLogger rebuilds the scoped logger after PlayerID or RoomCode changes
+func (c *Client) rescopeLogger() {
+	logger := c.logger.With("player_id", c.PlayerID)
+	if c.RoomCode != "" {
+		logger = logger.With("room", c.RoomCode)
+	}
+	c.scoped.Store(logger)
 }
 
 // ReadPump pumps messages from the websocket connection to the hub
@@ -68,12 +184,19 @@ func (c *Client) ReadPump() {
 		}
 		c.hub.Unregister(c)
 		c.conn.Close()
+		if c.onClose != nil {
+			c.onClose()
+		}
 	}()
 
 	c.conn.SetReadLimit(maxMessageSize)
 	c.conn.SetReadDeadline(time.Now().Add(pongWait))
-	c.conn.SetPongHandler(func(string) error {
+	c.conn.SetPongHandler(func(appData string) error {
 		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		// Pong echoes the ping's send timestamp
+		if sent, err := strconv.ParseInt(appData, 10, 64); err == nil {
+			c.rtt.Store(time.Now().UnixNano() - sent)
+		}
 		return nil
 	})
 
@@ -81,19 +204,19 @@ func (c *Client) ReadPump() {
 		_, data, err := c.conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-				c.logger.Warn("websocket read error", "error", err, "player_id", c.PlayerID)
+				c.Logger().Warn("websocket read error", "error", err)
 			}
 			break
 		}
 
 		msg, err := ParseMessage(data)
 		if err != nil {
-			c.logger.Warn("failed to parse message", "error", err, "player_id", c.PlayerID)
-			c.SendError("invalid_message", "Failed to parse message")
+			c.Logger().Warn("failed to parse message", "error", err)
+			c.SendErrorCode(ErrCodeInvalidMessage, "Failed to parse message")
 			continue
 		}
 
-		c.logger.Debug("received message", "type", msg.Type, "player_id", c.PlayerID)
+		c.Logger().Debug("received message", "type", msg.Type)
 
 		if c.onMessage != nil {
 			c.onMessage(c, msg)
@@ -115,49 +238,73 @@ func (c *Client) WritePump() {
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// Hub closed the channel
-				c.conn.WriteMessage(websocket.CloseMessage, []byte{})
+				closeMsg := []byte{}
+				if hint := c.closeHint.Load(); hint != nil {
+					closeMsg = websocket.FormatCloseMessage(websocket.CloseTryAgainLater, hint.closeReason())
+				}
+				c.conn.WriteMessage(websocket.CloseMessage, closeMsg)
 				return
 			}
 
+			// Add queued messages to the current websocket message. Broadcasts
+			// share their buffer between clients, so batch into a copy.
+			if n := len(c.send); n > 0 {
+				batch := append([]byte{}, message...)
+				for i := 0; i < n; i++ {
+					batch = append(batch, '\n')
+					batch = append(batch, <-c.send...)
+				}
+				message = batch
+			}
+
+			// Only large writes are worth compressing; control frames never are
+			c.conn.EnableWriteCompression(c.compressThreshold > 0 && len(message) >= c.compressThreshold)
+
 			w, err := c.conn.NextWriter(websocket.TextMessage)
 			if err != nil {
 				return
 			}
 			w.Write(message)
 
-			// Add queued messages to the current websocket message
-			n := len(c.send)
-			for i := 0; i < n; i++ {
-				w.Write([]byte{'\n'})
-				w.Write(<-c.send)
-			}
-
 			if err := w.Close(); err != nil {
 				return
 			}
 
 		case <-ticker.C:
 			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
-			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+			sentAt := strconv.FormatInt(time.Now().UnixNano(), 10)
+			if err := c.conn.WriteMessage(websocket.PingMessage, []byte(sentAt)); err != nil {
 				return
 			}
 		}
 	}
 }
 
-// Send sends a message to this client
-func (c *Client) Send(msg *Message) {
-	select {
-	case c.send <- msg.Bytes():
-	default:
-		c.logger.Warn("client send buffer full", "player_id", c.PlayerID)
+// closeSend closes the outbound queue once; later sends are discarded
+func (c *Client) closeSend() {
+	c.sendMu.Lock()
+	defer c.sendMu.Unlock()
+	if !c.sendClosed {
+		c.sendClosed = true
+		close(c.send)
 	}
 }
 
-// SendError sends an error message to this client
-func (c *Client) SendError(code, message string) {
+// Send sends a message to this client, stamped with its next sequence number
+func (c *Client) Send(msg *Message) {
+	c.hub.SendToClient(c, msg)
+}
+
+// SendErrorCode sends an error message with a typed code to this client
+func (c *Client) SendErrorCode(code ErrorCode, message string) {
+	if !code.IsKnown() {
+		c.Logger().Warn("sending undeclared error code", "code", code)
+	}
+	if capture := c.ack.Load(); capture != nil {
+		capture.code.CompareAndSwap(nil, &code)
+	}
 	msg := MustMessage(EventTypeError, ErrorPayload{
-		Code:    code,
+		Code:    string(code),
 		Message: message,
 	})
 	c.Send(msg)
@@ -0,0 +1,81 @@
+package ws
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+// captureHandler records each log line's message and attributes, including
+// those attached with Logger.With
+type captureHandler struct {
+	mu      *sync.Mutex
+	records *[]map[string]any
+	attrs   []slog.Attr
+}
+
+func newCaptureHandler() *captureHandler {
+	return &captureHandler{mu: &sync.Mutex{}, records: &[]map[string]any{}}
+}
+
+func (h *captureHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *captureHandler) Handle(_ context.Context, record slog.Record) error {
+	fields := map[string]any{"msg": record.Message}
+	for _, attr := range h.attrs {
+		fields[attr.Key] = attr.Value.Any()
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		fields[attr.Key] = attr.Value.Any()
+		return true
+	})
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.records = append(*h.records, fields)
+	return nil
+}
+
+func (h *captureHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *captureHandler) WithGroup(string) slog.Handler { return h }
+
+// find returns the last record with the given message
+func (h *captureHandler) find(t *testing.T, msg string) map[string]any {
+	t.Helper()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(*h.records) - 1; i >= 0; i-- {
+		if (*h.records)[i]["msg"] == msg {
+			return (*h.records)[i]
+		}
+	}
+	t.Fatalf("no %q log record", msg)
+	return nil
+}
+
+func TestHandlerLogsCarryClientContext(t *testing.T) {
+	r := newTestRouter(t, false)
+	capture := newCaptureHandler()
+	c := NewClient(r.hub, nil, "p1", slog.New(capture), nil, nil)
+	r.hub.JoinRoom(c, "ROOM1")
+
+	r.HandleMessage(c, &Message{Type: MsgTypeResync})
+	record := capture.find(t, "client requested resync")
+	if record["player_id"] != "p1" || record["room"] != "ROOM1" {
+		t.Errorf("resync log = %v, want player_id p1 and room ROOM1", record)
+	}
+
+	// Leaving the room and rebinding the player are reflected straight away
+	r.hub.LeaveRoom(c)
+	r.hub.RebindPlayer(c, "p2")
+	c.Logger().Info("after leaving")
+	record = capture.find(t, "after leaving")
+	if _, ok := record["room"]; ok || record["player_id"] != "p2" {
+		t.Errorf("log after leaving = %v, want player_id p2 and no room", record)
+	}
+}
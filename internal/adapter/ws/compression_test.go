@@ -0,0 +1,88 @@
+package ws
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// countingConn counts the bytes read off the wire
+type countingConn struct {
+	net.Conn
+	read *atomic.Int64
+}
+
+func (c countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(int64(n))
+	return n, err
+}
+
+func TestLargeMessagesCompressed(t *testing.T) {
+	const threshold = 1024
+	blobs := map[string]string{
+		"big":   strings.Repeat("night falls on the village. ", 2000),
+		"small": strings.Repeat("z", threshold/4),
+	}
+
+	h := NewHub(discardLogger())
+	go h.Run()
+	reply := func(c *Client, msg *Message) {
+		c.Send(MustMessage(EventTypeGameState, map[string]string{"blob": blobs[msg.Type]}))
+	}
+	handler := NewHandler(h, discardLogger(), NewOriginPolicy(nil, true), nil, reply, nil)
+	handler.SetCompression(threshold)
+	srv := newWSServer(t, handler)
+
+	for _, size := range []string{"big", "small"} {
+		var read atomic.Int64
+		dialer := websocket.Dialer{
+			EnableCompression: true,
+			NetDial: func(network, addr string) (net.Conn, error) {
+				conn, err := net.Dial(network, addr)
+				return countingConn{conn, &read}, err
+			},
+		}
+		conn, _, err := dialer.Dial(srv, nil)
+		if err != nil {
+			t.Fatalf("%s: dial: %v", size, err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		if _, _, err := conn.ReadMessage(); err != nil {
+			t.Fatalf("%s: read connected: %v", size, err)
+		}
+		handshake := read.Load()
+		if err := conn.WriteJSON(Message{Type: size}); err != nil {
+			t.Fatalf("%s: write: %v", size, err)
+		}
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			t.Fatalf("%s: read: %v", size, err)
+		}
+
+		var msg Message
+		var payload map[string]string
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("%s: decode %q: %v", size, data, err)
+		}
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload["blob"] != blobs[size] {
+			t.Fatalf("%s: payload did not round-trip (err %v)", size, err)
+		}
+
+		wire := read.Load() - handshake
+		if size == "big" && wire*4 > int64(len(data)) {
+			t.Errorf("big: %d bytes on the wire for a %d-byte message", wire, len(data))
+		}
+		if size == "small" && wire < int64(len(data)) {
+			t.Errorf("small: %d bytes on the wire for a %d-byte message, want it uncompressed", wire, len(data))
+		}
+
+	}
+}
@@ -0,0 +1,20 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfirmKillWithoutGodfatherDecidesGetsItsOwnCode(t *testing.T) {
+	r := newTestRouter(t, false)
+	room, clients := startTestGame(t, r, 5, nil)
+	r.gameService.GetGame(room.Code).StartNight(time.Minute)
+	client := clients["p0"]
+	drainMessages(t, client)
+
+	r.HandleMessage(client, MustMessage(MsgTypeConfirmKill, nil))
+
+	if code := lastErrorCode(t, client); code != string(ErrCodeConfirmNotRequired) {
+		t.Errorf("error code = %q, want %q", code, ErrCodeConfirmNotRequired)
+	}
+}
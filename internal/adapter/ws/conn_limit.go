@@ -0,0 +1,72 @@
+package ws
+
+import (
+	"net"
+	"sync"
+)
+
+// ConnLimiter caps simultaneous WebSocket connections per client IP
+type ConnLimiter struct {
+	max int // 0 = unlimited
+
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+// NewConnLimiter creates a limiter allowing perIP connections per IP
+func NewConnLimiter(perIP int) *ConnLimiter {
+	return &ConnLimiter{
+		max:    perIP,
+		counts: make(map[string]int),
+	}
+}
+
+// Acquire reserves a connection slot for ip; false means the cap is reached
+func (l *ConnLimiter) Acquire(ip string) bool {
+	if l == nil || l.max <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.counts[ip] >= l.max {
+		return false
+	}
+	l.counts[ip]++
+	return true
+}
+
+// Release frees a slot taken by Acquire
+func (l *ConnLimiter) Release(ip string) {
+	if l == nil || l.max <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.counts[ip] <= 1 {
+		delete(l.counts, ip)
+		return
+	}
+	l.counts[ip]--
+}
+
+// Count returns the connections currently held by ip
+func (l *ConnLimiter) Count(ip string) int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.counts[ip]
+}
+
+// clientIP returns the request's IP without port. RemoteAddr is the socket
+// peer, or the client a trusted proxy forwarded for (see the HTTP server's
+// trusted proxies); forwarding headers from anyone else are never read.
+func clientIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
@@ -0,0 +1,59 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestConnLimitRejectsPastCapAndReleasesOnClose(t *testing.T) {
+	h := NewHub(discardLogger())
+	go h.Run()
+
+	limits := NewConnLimiter(2)
+	handler := NewHandler(h, discardLogger(), NewOriginPolicy(nil, true), limits, nil, nil)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	var conns []*websocket.Conn
+	for range 2 {
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial within cap: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+		conns = append(conns, conn)
+	}
+	if got := limits.Count("127.0.0.1"); got != 2 {
+		t.Fatalf("count = %d, want 2", got)
+	}
+
+	// A forged forwarding header does not buy another slot
+	spoofed := http.Header{"X-Forwarded-For": {"203.0.113.9"}, "X-Real-Ip": {"203.0.113.9"}}
+	_, resp, err := websocket.DefaultDialer.Dial(url, spoofed)
+	if err == nil {
+		t.Fatal("dial past the cap succeeded")
+	}
+	if resp == nil || resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("dial past the cap: response %v, want 429", resp)
+	}
+
+	conns[0].Close()
+	for deadline := time.Now().Add(time.Second); limits.Count("127.0.0.1") != 1; {
+		if time.Now().After(deadline) {
+			t.Fatalf("count = %d after close, want 1", limits.Count("127.0.0.1"))
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial after a slot was freed: %v", err)
+	}
+	conn.Close()
+}
@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestDeadReadDayChatOnlyWhenAllowed(t *testing.T) {
+	for _, deadCanSee := range []bool{false, true} {
+		r := newTestRouter(t, false)
+		room, clients := startTestGame(t, r, 7, func(s *entity.GameSettings) {
+			s.DeadCanSeeLiveChat = deadCanSee
+		})
+		room.GetPlayer("p1").Status = entity.PlayerStatusDead
+		r.gameService.GetGame(room.Code).StartDay(time.Minute)
+		for _, c := range clients {
+			drainMessages(t, c)
+		}
+
+		// The dead never post, whatever they may read
+		r.HandleMessage(clients["p1"], MustMessage(MsgTypeDayChat, DayChatPayload{Message: "boo"}))
+		var errPayload ErrorPayload
+		if !lastOfType(t, clients["p1"], EventTypeError, &errPayload) || errPayload.Code != string(ErrCodePlayerDead) {
+			t.Errorf("see %v: dead post error = %+v, want %s", deadCanSee, errPayload, ErrCodePlayerDead)
+		}
+		if lastOfType(t, clients["p0"], EventTypeDayChatBroadcast, &DayChatBroadcastPayload{}) {
+			t.Errorf("see %v: dead player's message reached the living", deadCanSee)
+		}
+
+		r.HandleMessage(clients["p0"], MustMessage(MsgTypeDayChat, DayChatPayload{Message: "hi"}))
+		var got DayChatBroadcastPayload
+		if !lastOfType(t, clients["p2"], EventTypeDayChatBroadcast, &got) || got.FromID != "p0" {
+			t.Errorf("see %v: living p2 got %+v", deadCanSee, got)
+		}
+		if read := lastOfType(t, clients["p1"], EventTypeDayChatBroadcast, &got); read != deadCanSee {
+			t.Errorf("see %v: dead p1 read day chat = %v", deadCanSee, read)
+		}
+	}
+}
+
+func TestInvestigationClaimsRelayedVerbatimDuringDay(t *testing.T) {
+	r := newTestRouter(t, false)
+	room, clients := startTestGame(t, r, 7, nil)
+	game := r.gameService.GetGame(room.Code)
+	claim := MustMessage(MsgTypeClaimInvestigation, ClaimInvestigationPayload{TargetID: "p2", IsMafia: true})
+
+	// Nights are for acting, not claiming
+	game.StartNight(time.Minute)
+	for _, c := range clients {
+		drainMessages(t, c)
+	}
+	r.HandleMessage(clients["p0"], claim)
+	var errPayload ErrorPayload
+	if !lastOfType(t, clients["p0"], EventTypeError, &errPayload) || errPayload.Code != string(ErrCodeInvalidPhase) {
+		t.Errorf("night claim error = %+v, want %s", errPayload, ErrCodeInvalidPhase)
+	}
+	if lastOfType(t, clients["p1"], EventTypeInvestigationClaim, &InvestigationClaimPayload{}) {
+		t.Error("night claim was broadcast")
+	}
+
+	// By day any living player may claim anything, true or not
+	game.StartDay(time.Minute)
+	for _, c := range clients {
+		drainMessages(t, c)
+	}
+	r.HandleMessage(clients["p0"], claim)
+	var got InvestigationClaimPayload
+	if !lastOfType(t, clients["p1"], EventTypeInvestigationClaim, &got) {
+		t.Fatal("day claim was not broadcast")
+	}
+	if got.FromID != "p0" || got.TargetID != "p2" || got.TargetNickname != "Player p2" || !got.IsMafia || got.Verified {
+		t.Errorf("claim relayed as %+v", got)
+	}
+
+	room.GetPlayer("p3").Status = entity.PlayerStatusDead
+	r.HandleMessage(clients["p3"], claim)
+	if !lastOfType(t, clients["p3"], EventTypeError, &errPayload) || errPayload.Code != string(ErrCodePlayerDead) {
+		t.Errorf("dead claim error = %+v, want %s", errPayload, ErrCodePlayerDead)
+	}
+}
@@ -0,0 +1,126 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestLastMafiaDepartureEndsGameMidNight(t *testing.T) {
+	for _, how := range []string{"leave", "reconnect timeout"} {
+		r := newTestRouter(t, false)
+		go r.hub.Run()
+		room, clients := startTestGame(t, r, 7, nil)
+		game := r.gameService.GetGame(room.Code)
+		var mafia []string
+		for _, id := range room.PlayerOrder {
+			if game.Roles[id].GetTeam() == entity.TeamMafia {
+				mafia = append(mafia, id)
+			}
+		}
+
+		// One mafioso is already gone; the other walks out before dawn
+		game.StartNight(time.Minute)
+		room.GetPlayer(mafia[1]).Status = entity.PlayerStatusDead
+		if r.gameService.GameCount() != 1 {
+			t.Fatalf("%s: %d games running, want 1", how, r.gameService.GameCount())
+		}
+		switch how {
+		case "leave":
+			r.handleLeaveRoom(clients[mafia[0]])
+		case "reconnect timeout":
+			r.handleReconnectTimeout(room.Code, mafia[0])
+		}
+
+		if phase := game.GetPhase(); phase != entity.PhaseGameOver {
+			t.Errorf("%s: phase %s after the last mafioso left, want %s", how, phase, entity.PhaseGameOver)
+		}
+		if game.Winner != entity.TeamTown {
+			t.Errorf("%s: winner %q, want %q", how, game.Winner, entity.TeamTown)
+		}
+		if n := r.gameService.GameCount(); n != 0 {
+			t.Errorf("%s: %d games still running", how, n)
+		}
+
+		var over GameOverPayload
+		waitFor(t, "game_over", func() bool {
+			return lastOfType(t, clients[game.Room.PlayerOrder[len(game.Room.PlayerOrder)-1]], EventTypeGameOver, &over)
+		})
+		if over.Winner != string(entity.TeamTown) || over.Aborted || len(over.Players) != 6 {
+			t.Errorf("%s: game_over = %+v, want a town win listing the 6 remaining seats", how, over)
+		}
+		for _, p := range over.Players {
+			if p.Role != string(game.Roles[p.ID]) {
+				t.Errorf("%s: %s revealed as %q, want %s", how, p.ID, p.Role, game.Roles[p.ID])
+			}
+		}
+	}
+}
+
+func TestLeaveVersusDropDuringGame(t *testing.T) {
+	r := newTestRouter(t, false)
+	go r.hub.Run()
+	room, clients := startTestGame(t, r, 7, nil)
+	t.Cleanup(func() { r.roomService.CancelReconnectTimer("p1") })
+	drainMessages(t, clients["p0"])
+
+	// A dropped connection keeps the seat for the reconnect window
+	r.HandleDisconnect(clients["p1"])
+	if p := room.GetPlayer("p1"); p == nil || p.IsConnected {
+		t.Fatalf("dropped player = %+v, want a disconnected seat", p)
+	}
+	waitFor(t, "player_disconnected", func() bool {
+		return lastOfType(t, clients["p0"], EventTypePlayerDisconnected, &map[string]any{})
+	})
+
+	// An explicit leave gives the seat up at once
+	r.handleLeaveRoom(clients["p2"])
+	if room.GetPlayer("p2") != nil {
+		t.Error("leaving player kept their seat")
+	}
+	var left PlayerLeftPayload
+	waitFor(t, "player_left", func() bool {
+		return lastOfType(t, clients["p0"], EventTypePlayerLeft, &left)
+	})
+	if left.PlayerID != "p2" || !left.Voluntary {
+		t.Errorf("leave announced as %+v, want voluntary departure of p2", left)
+	}
+	if _, err := r.roomService.ReconnectPlayer("p2"); err != entity.ErrPlayerNotFound {
+		t.Errorf("reconnect after leaving: err = %v, want ErrPlayerNotFound", err)
+	}
+
+	// The dropped player can still come back
+	if _, err := r.roomService.ReconnectPlayer("p1"); err != nil {
+		t.Errorf("reconnect after drop: %v", err)
+	}
+}
+
+func TestLeavingLastVoterEndsTheVoteAndLeavesVoice(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, clients := startTestGame(t, r, 7, nil)
+	game := r.gameService.GetGame(room.Code)
+	game.StartDay(time.Minute)
+
+	r.HandleMessage(clients["p6"], MustMessage(MsgTypeVoiceJoin, nil))
+	if r.sfu.GetParticipant(room.Code, "p6") == nil {
+		t.Fatal("p6 did not join voice")
+	}
+	// Everyone else has voted; the day waits only on p6
+	for _, id := range room.PlayerOrder[:6] {
+		if err := r.gameService.SubmitDayVote(room.Code, id, ""); err != nil {
+			t.Fatalf("%s votes: %v", id, err)
+		}
+	}
+	if phase := game.GetPhase(); phase != entity.PhaseDay {
+		t.Fatalf("phase %s before p6 left, want %s", phase, entity.PhaseDay)
+	}
+
+	r.handleLeaveRoom(clients["p6"])
+	if phase := game.GetPhase(); phase != entity.PhaseDayResult {
+		t.Errorf("phase %s after the last voter left, want %s", phase, entity.PhaseDayResult)
+	}
+	if r.sfu.GetParticipant(room.Code, "p6") != nil {
+		t.Error("departed player still routed in voice")
+	}
+}
@@ -0,0 +1,94 @@
+package ws
+
+// ErrorCode identifies an error sent to clients in the error event payload
+type ErrorCode string
+
+// Error codes (server -> client), each registered as a known code
+var (
+	// General errors
+	ErrCodeInvalidMessage  = errorCode("invalid_message")
+	ErrCodeInvalidPayload  = errorCode("invalid_payload")
+	ErrCodeUnknownMessage  = errorCode("unknown_message")
+	ErrCodeNotInRoom       = errorCode("not_in_room")
+	ErrCodeRateLimited     = errorCode("rate_limited")
+	ErrCodePayloadTooLarge = errorCode("payload_too_large")
+
+	// Room errors
+	ErrCodeCreateFailed    = errorCode("create_failed")
+	ErrCodeJoinFailed      = errorCode("join_failed")
+	ErrCodeLeaveFailed     = errorCode("leave_failed")
+	ErrCodeInvalidNickname = errorCode("invalid_nickname")
+	ErrCodeInvalidRoomCode = errorCode("invalid_room_code")
+	ErrCodeRoomNotFound    = errorCode("room_not_found")
+	ErrCodeWrongPassword   = errorCode("wrong_password")
+	ErrCodeInvalidPassword = errorCode("invalid_password")
+	ErrCodeRoomFull        = errorCode("room_full")
+	ErrCodeNicknameInUse   = errorCode("nickname_in_use")
+	ErrCodeGameStarted     = errorCode("game_started")
+	ErrCodeGameInProgress  = errorCode("game_in_progress")
+	ErrCodeReconnectFailed = errorCode("reconnect_failed")
+	ErrCodeReconnectDenied = errorCode("reconnect_unauthorized")
+	ErrCodePlayerNotFound  = errorCode("player_not_found")
+	ErrCodeSpectating      = errorCode("spectating")
+
+	// Lobby errors
+	ErrCodeReadyFailed       = errorCode("ready_failed")
+	ErrCodeSettingsFailed    = errorCode("settings_failed")
+	ErrCodeNotHost           = errorCode("not_host")
+	ErrCodeNotEnoughPlayers  = errorCode("not_enough_players")
+	ErrCodeNotAllReady       = errorCode("not_all_ready")
+	ErrCodeStartFailed       = errorCode("start_failed")
+	ErrCodeTooManyGames      = errorCode("too_many_active_games")
+	ErrCodeInvalidRoleConfig = errorCode("invalid_role_config")
+	ErrCodeInvalidMaxPlayers = errorCode("invalid_max_players")
+	ErrCodePresetNotFound    = errorCode("preset_not_found")
+	ErrCodePresetDoesNotFit  = errorCode("preset_does_not_fit")
+	ErrCodeTransferFailed    = errorCode("transfer_failed")
+	ErrCodeGameNotEnded      = errorCode("game_not_ended")
+	ErrCodeUnbalancedTeams   = errorCode("unbalanced_teams")
+	ErrCodeInvalidDuration   = errorCode("invalid_duration")
+	ErrCodeInvalidMaxRevotes = errorCode("invalid_max_revotes")
+	ErrCodeAddBotFailed      = errorCode("add_bot_failed")
+	ErrCodeDebugDisabled     = errorCode("debug_disabled")
+
+	// Game errors
+	ErrCodeGameNotFound        = errorCode("game_not_found")
+	ErrCodeVotingNotOpen       = errorCode("voting_not_open")
+	ErrCodeInvalidPhase        = errorCode("invalid_phase")
+	ErrCodePlayerDead          = errorCode("player_dead")
+	ErrCodeInvalidTarget       = errorCode("invalid_target")
+	ErrCodeActionFailed        = errorCode("action_failed")
+	ErrCodeAlreadyActed        = errorCode("already_acted")
+	ErrCodeVoteFailed          = errorCode("vote_failed")
+	ErrCodeVoteLocked          = errorCode("vote_locked")
+	ErrCodeSelfHealLimit       = errorCode("self_heal_limit")
+	ErrCodeNotDead             = errorCode("not_dead")
+	ErrCodeNotGodfather        = errorCode("not_godfather")
+	ErrCodeConfirmNotRequired  = errorCode("confirm_not_required")
+	ErrCodeNotCupid            = errorCode("not_cupid")
+	ErrCodeNotYourTurn         = errorCode("not_your_turn")
+	ErrCodeAlreadyInvestigated = errorCode("already_investigated")
+	ErrCodeSameTargetTwice     = errorCode("same_target_not_allowed")
+
+	// Voice errors
+	ErrCodeVoiceUnavailable = errorCode("voice_unavailable")
+	ErrCodeVoiceJoinFailed  = errorCode("voice_join_failed")
+	ErrCodeVoiceOfferFailed = errorCode("voice_offer_failed")
+	ErrCodeVoiceDisabled    = errorCode("voice_disabled")
+	ErrCodeNotInVoice       = errorCode("not_in_voice")
+)
+
+// knownErrorCodes is the set of codes clients may receive
+var knownErrorCodes = make(map[ErrorCode]bool)
+
+// errorCode declares a code clients may receive and registers it as known
+func errorCode(code string) ErrorCode {
+	c := ErrorCode(code)
+	knownErrorCodes[c] = true
+	return c
+}
+
+// IsKnown returns true if the code is one of the declared error codes
+func (c ErrorCode) IsKnown() bool {
+	return knownErrorCodes[c]
+}
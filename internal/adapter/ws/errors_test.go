@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// declaredErrorCodes parses the package source for every ErrCode declaration,
+// failing on one that is not registered through errorCode or reuses a code
+func declaredErrorCodes(t *testing.T) map[ErrorCode]string {
+	t.Helper()
+	paths, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatalf("list sources: %v", err)
+	}
+
+	fset := token.NewFileSet()
+	declared := make(map[ErrorCode]string)
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			t.Fatalf("parse %s: %v", path, err)
+		}
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || (gen.Tok != token.CONST && gen.Tok != token.VAR) {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				for i, name := range value.Names {
+					if !strings.HasPrefix(name.Name, "ErrCode") {
+						continue
+					}
+					lit, ok := registeredCode(value.Values[i])
+					if !ok {
+						t.Errorf("%s is not declared with errorCode", name.Name)
+						continue
+					}
+					code, err := strconv.Unquote(lit.Value)
+					if err != nil {
+						t.Fatalf("%s: %v", name.Name, err)
+					}
+					if other, dup := declared[ErrorCode(code)]; dup {
+						t.Errorf("%s and %s share the code %q", other, name.Name, code)
+					}
+					declared[ErrorCode(code)] = name.Name
+				}
+			}
+		}
+	}
+	return declared
+}
+
+// registeredCode returns the literal in an errorCode("...") call
+func registeredCode(expr ast.Expr) (*ast.BasicLit, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) != 1 {
+		return nil, false
+	}
+	if fn, ok := call.Fun.(*ast.Ident); !ok || fn.Name != "errorCode" {
+		return nil, false
+	}
+	lit, ok := call.Args[0].(*ast.BasicLit)
+	return lit, ok && lit.Kind == token.STRING
+}
+
+func TestKnownErrorCodesMatchDeclarations(t *testing.T) {
+	declared := declaredErrorCodes(t)
+	if len(declared) == 0 {
+		t.Fatal("found no ErrorCode constants")
+	}
+	for code, name := range declared {
+		if !code.IsKnown() {
+			t.Errorf("%s (%q) is declared but missing from knownErrorCodes", name, code)
+		}
+	}
+	for code := range knownErrorCodes {
+		if _, ok := declared[code]; !ok {
+			t.Errorf("knownErrorCodes lists %q, which is not a declared constant", code)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package ws
 import (
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/V4T54L/mafia/internal/pkg/id"
 	"github.com/gorilla/websocket"
@@ -12,8 +13,7 @@ var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
 	CheckOrigin: func(r *http.Request) bool {
-		// Allow all origins in development
-		// TODO: Restrict in production
+		// Origins are checked by the handler's OriginPolicy before upgrading
 		return true
 	},
 }
@@ -22,37 +22,103 @@ var upgrader = websocket.Upgrader{
 type Handler struct {
 	hub          *Hub
 	logger       *slog.Logger
+	origins      *OriginPolicy
+	limits       *ConnLimiter
 	onMessage    func(*Client, *Message)
 	onDisconnect func(*Client)
+
+	// Writes at least this many bytes are compressed (0 = compression off)
+	compressThreshold int
+
+	// Reported in the connected event so clients can hide voice UI
+	voiceUnavailable bool
 }
 
-// NewHandler creates a new WebSocket handler
-func NewHandler(hub *Hub, logger *slog.Logger, onMessage func(*Client, *Message), onDisconnect func(*Client)) *Handler {
+// NewHandler creates a new WebSocket handler; a nil limiter allows any number
+// of connections per IP
+func NewHandler(hub *Hub, logger *slog.Logger, origins *OriginPolicy, limits *ConnLimiter, onMessage func(*Client, *Message), onDisconnect func(*Client)) *Handler {
 	return &Handler{
 		hub:          hub,
 		logger:       logger,
+		origins:      origins,
+		limits:       limits,
 		onMessage:    onMessage,
 		onDisconnect: onDisconnect,
 	}
 }
 
+// SetCompression enables permessage-deflate for clients that support it.
+// Only writes of at least threshold bytes are compressed; smaller ones cost
+// more CPU than they save. A threshold of 0 disables compression.
+func (h *Handler) SetCompression(threshold int) {
+	h.compressThreshold = threshold
+}
+
+// SetVoiceAvailable records whether the server has voice chat; clients are
+// told on connect. Voice is assumed available until set.
+func (h *Handler) SetVoiceAvailable(available bool) {
+	h.voiceUnavailable = !available
+}
+
 // ServeHTTP handles WebSocket upgrade requests
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+	if origin := r.Header.Get("Origin"); !h.origins.Allowed(origin, r.Host) {
+		h.logger.Warn("websocket origin rejected", "origin", origin, "remote_addr", r.RemoteAddr)
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return
+	}
+
+	ip := clientIP(r.RemoteAddr)
+	if !h.limits.Acquire(ip) {
+		h.logger.Warn("websocket connection limit reached", "ip", ip)
+		http.Error(w, "too many connections", http.StatusTooManyRequests)
+		return
+	}
+
+	// The server's read/write timeouts are meant for plain HTTP requests and
+	// would otherwise stay armed on the hijacked connection; the pumps manage
+	// their own deadlines from here on.
+	rc := http.NewResponseController(w)
+	rc.SetReadDeadline(time.Time{})
+	rc.SetWriteDeadline(time.Time{})
+
+	up := upgrader
+	up.EnableCompression = h.compressThreshold > 0
+	up.Subprotocols = supportedProtocols
+	conn, err := up.Upgrade(w, r, nil)
 	if err != nil {
 		h.logger.Error("websocket upgrade failed", "error", err)
+		h.limits.Release(ip)
 		return
 	}
 
+	// The upgrader picks the first requested version we support; the
+	// connection is still upgraded when there is none so the client gets a
+	// close code rather than a bare handshake failure
+	protocol := conn.Subprotocol()
+	if protocol == "" {
+		if requested := websocket.Subprotocols(r); len(requested) > 0 {
+			h.logger.Warn("websocket protocol rejected", "requested", requested, "remote_addr", r.RemoteAddr)
+			rejectProtocol(conn)
+			h.limits.Release(ip)
+			return
+		}
+		protocol = ProtocolDefault
+	}
+
 	// Generate a unique player ID
 	playerID := id.Generate()
 
 	client := NewClient(h.hub, conn, playerID, h.logger, h.onMessage, h.onDisconnect)
+	client.onClose = func() { h.limits.Release(ip) }
+	client.compressThreshold = h.compressThreshold
 	h.hub.Register(client)
 
 	// Send connected event
 	client.Send(MustMessage(EventTypeConnected, ConnectedPayload{
-		PlayerID: playerID,
+		PlayerID:         playerID,
+		Protocol:         protocol,
+		VoiceUnavailable: h.voiceUnavailable,
 	}))
 
 	// Start client pumps
@@ -0,0 +1,53 @@
+package ws
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketOutlivesServerTimeouts(t *testing.T) {
+	h := NewHub(discardLogger())
+	go h.Run()
+	echo := func(c *Client, msg *Message) { c.Send(msg) }
+	handler := NewHandler(h, discardLogger(), NewOriginPolicy(nil, true), nil, echo, nil)
+
+	srv := httptest.NewUnstartedServer(handler)
+	srv.Config.ReadTimeout = 50 * time.Millisecond
+	srv.Config.WriteTimeout = 50 * time.Millisecond
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	// Both deadlines would have fired on a plain HTTP connection by now
+	time.Sleep(200 * time.Millisecond)
+	if err := conn.WriteJSON(MustMessage(MsgTypeRequestState, nil)); err != nil {
+		t.Fatalf("write after timeouts: %v", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("socket closed after server timeouts: %v", err)
+		}
+		if msg.Type == MsgTypeRequestState {
+			return
+		}
+	}
+}
+
+// newWSServer serves handler and returns its ws:// URL
+func newWSServer(t *testing.T, handler *Handler) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return "ws" + strings.TrimPrefix(srv.URL, "http")
+}
@@ -2,9 +2,20 @@ package ws
 
 import (
 	"log/slog"
+	"maps"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
+// latencyReportInterval is how often room latency buckets are checked and broadcast
+const latencyReportInterval = 5 * time.Second
+
+// defaultMaxOverflows is how many consecutive overflows drop a client
+const defaultMaxOverflows = 3
+
 // Hub manages all WebSocket clients and message routing
 type Hub struct {
 	// All connected clients
@@ -25,7 +36,25 @@ type Hub struct {
 	// Logger
 	logger *slog.Logger
 
-	// Mutex for room operations
+	// Last latency buckets broadcast per room (only touched by Run)
+	lastLatency map[string]map[string]string
+
+	// Recent room broadcasts replayed to spectators (guarded by mu)
+	history map[string]*eventRing
+
+	// Consecutive overflows tolerated (see SetSlowClientPolicy)
+	maxOverflows int32
+
+	// Recent slow-consumer drops per player, for escalating reconnect hints
+	overflowDrops overflowDrops
+
+	// Set by Shutdown; no more clients are accepted (guarded by mu)
+	closed bool
+
+	// Set once Run's event loop is live, cleared by Shutdown
+	started atomic.Bool
+
+	// Mutex for room operations. Lock order: mu before Client.sendMu.
 	mu sync.RWMutex
 }
 
@@ -45,36 +74,150 @@ func NewHub(logger *slog.Logger) *Hub {
 		unregister: make(chan *Client),
 		broadcast:  make(chan *RoomMessage, 256),
 		logger:     logger,
+
+		lastLatency: make(map[string]map[string]string),
+		history:     make(map[string]*eventRing),
+
+		maxOverflows: defaultMaxOverflows,
 	}
 }
 
+// SetSlowClientPolicy sets how many consecutive overflows are tolerated
+// before the client is dropped. Call before Run.
+func (h *Hub) SetSlowClientPolicy(maxOverflows int) {
+	h.maxOverflows = int32(maxOverflows)
+}
+
 // Run starts the hub's main loop
 func (h *Hub) Run() {
+	latencyTicker := time.NewTicker(latencyReportInterval)
+	defer latencyTicker.Stop()
+
+	h.started.Store(true)
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
+			if h.closed {
+				// Connections that race Shutdown get the same notice
+				closeGoingAway(client)
+				h.mu.Unlock()
+				client.Logger().Debug("client rejected after shutdown")
+				continue
+			}
 			h.clients[client] = true
 			h.mu.Unlock()
-			h.logger.Debug("client registered", "player_id", client.PlayerID)
+			client.Logger().Debug("client registered")
 
 		case client := <-h.unregister:
 			h.mu.Lock()
 			if _, ok := h.clients[client]; ok {
 				h.leaveRoomLocked(client)
 				delete(h.clients, client)
-				close(client.send)
-				h.logger.Debug("client unregistered", "player_id", client.PlayerID)
+				client.closeSend()
+				client.Logger().Debug("client unregistered")
 			}
 			h.mu.Unlock()
 
 		case roomMsg := <-h.broadcast:
 			h.broadcastToRoom(roomMsg)
+
+		case <-latencyTicker.C:
+			h.reportLatency()
+		}
+	}
+}
+
+// Running reports whether Run has started processing registrations and
+// broadcasts and the hub has not been shut down
+func (h *Hub) Running() bool {
+	return h.started.Load()
+}
+
+// reportLatency broadcasts each room's latency buckets when they have changed
+func (h *Hub) reportLatency() {
+	h.mu.RLock()
+	current := make(map[string]map[string]string, len(h.rooms))
+	for roomCode, room := range h.rooms {
+		buckets := make(map[string]string, len(room))
+		for client := range room {
+			if bucket := client.LatencyBucket(); bucket != "" {
+				buckets[client.PlayerID] = bucket
+			}
 		}
+		current[roomCode] = buckets
+	}
+	h.mu.RUnlock()
+
+	for roomCode, buckets := range current {
+		if len(buckets) == 0 || maps.Equal(buckets, h.lastLatency[roomCode]) {
+			continue
+		}
+		h.broadcastToRoom(&RoomMessage{
+			RoomCode: roomCode,
+			Message: MustMessage(EventTypePlayerLatency, PlayerLatencyPayload{
+				Players: buckets,
+			}),
+		})
+	}
+	h.lastLatency = current
+}
+
+// HubStats is a snapshot of connection counts
+type HubStats struct {
+	Clients int `json:"clients"`
+	Rooms   int `json:"rooms"`
+}
+
+// Stats returns the current number of connected clients and occupied rooms
+func (h *Hub) Stats() HubStats {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return HubStats{
+		Clients: len(h.clients),
+		Rooms:   len(h.rooms),
+	}
+}
+
+// BroadcastAll sends a message to every connected client, in a room or not
+func (h *Hub) BroadcastAll(msg *Message) {
+	h.mu.RLock()
+	recipients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		recipients = append(recipients, client)
+	}
+	h.mu.RUnlock()
+
+	h.deliverAll(recipients, timestamped(msg))
+}
+
+// Shutdown closes every connection with a going-away close frame and stops
+// queueing messages. Pending messages already queued are still flushed by
+// the write pumps before they exit.
+func (h *Hub) Shutdown() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	h.started.Store(false)
+	for client := range h.clients {
+		closeGoingAway(client)
+		delete(h.clients, client)
 	}
+	h.rooms = make(map[string]map[*Client]bool)
+	h.history = make(map[string]*eventRing)
+	h.logger.Info("hub shut down")
 }
 
 // Register registers a client with the hub
+// closeGoingAway tells the client the server is going away and closes its
+// outbound queue
+func closeGoingAway(client *Client) {
+	closeMsg := websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down")
+	client.conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(writeWait))
+	client.closeSend()
+}
+
 func (h *Hub) Register(client *Client) {
 	h.register <- client
 }
@@ -84,11 +227,23 @@ func (h *Hub) Unregister(client *Client) {
 	h.unregister <- client
 }
 
+// RebindPlayer changes the player a connection speaks for (used on reconnect)
+func (h *Hub) RebindPlayer(client *Client, playerID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	client.PlayerID = playerID
+	client.rescopeLogger()
+}
+
 // JoinRoom adds a client to a room
 func (h *Hub) JoinRoom(client *Client, roomCode string) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.joinLocked(client, roomCode)
+}
 
+// joinLocked moves client into roomCode. Caller must hold mu.
+func (h *Hub) joinLocked(client *Client, roomCode string) {
 	// Leave current room if any
 	if client.RoomCode != "" {
 		h.leaveRoomLocked(client)
@@ -100,8 +255,10 @@ func (h *Hub) JoinRoom(client *Client, roomCode string) {
 	}
 	h.rooms[roomCode][client] = true
 	client.RoomCode = roomCode
+	client.rescopeLogger()
+	client.lastActivity.Store(time.Now().UnixNano())
 
-	h.logger.Debug("client joined room", "player_id", client.PlayerID, "room", roomCode)
+	client.Logger().Debug("client joined room")
 }
 
 // LeaveRoom removes a client from their current room
@@ -120,12 +277,15 @@ func (h *Hub) leaveRoomLocked(client *Client) {
 		delete(room, client)
 		if len(room) == 0 {
 			delete(h.rooms, client.RoomCode)
+			delete(h.history, client.RoomCode)
 			h.logger.Debug("room deleted (empty)", "room", client.RoomCode)
 		}
 	}
 
-	h.logger.Debug("client left room", "player_id", client.PlayerID, "room", client.RoomCode)
+	client.Logger().Debug("client left room")
 	client.RoomCode = ""
+	client.rescopeLogger()
+	client.spectating.Store(false)
 }
 
 func (h *Hub) removeClientFromRoom(client *Client) {
@@ -144,36 +304,87 @@ func (h *Hub) BroadcastToRoom(roomCode string, msg *Message, exclude *Client) {
 }
 
 func (h *Hub) broadcastToRoom(roomMsg *RoomMessage) {
-	h.mu.RLock()
+	// Recording and taking the recipients happen together, so a spectator
+	// joining concurrently gets the message either replayed or live, never
+	// both; delivery happens after the lock is released
+	h.mu.Lock()
 	room, ok := h.rooms[roomMsg.RoomCode]
-	h.mu.RUnlock()
-
 	if !ok {
+		h.mu.Unlock()
 		return
 	}
 
-	data := roomMsg.Message.Bytes()
+	msg := timestamped(roomMsg.Message)
+	h.recordLocked(roomMsg.RoomCode, msg)
+	recipients := make([]*Client, 0, len(room))
 	for client := range room {
-		if client == roomMsg.Exclude {
-			continue
+		if client != roomMsg.Exclude {
+			recipients = append(recipients, client)
 		}
-		select {
-		case client.send <- data:
-		default:
-			// Client's send buffer is full, close connection
-			h.logger.Warn("client send buffer full, closing", "player_id", client.PlayerID)
+	}
+	h.mu.Unlock()
+
+	h.deliverAll(recipients, msg)
+}
+
+// deliverAll delivers msg to each recipient. Must be called without mu held.
+func (h *Hub) deliverAll(recipients []*Client, msg *Message) {
+	for _, client := range recipients {
+		h.deliver(client, msg)
+	}
+}
+
+// deliver stamps msg with the client's next sequence number and queues it.
+// Sequence numbers are per connection, so a client that sees a gap has
+// really lost a message and can resync.
+func (h *Hub) deliver(client *Client, msg *Message) {
+	client.sendMu.Lock()
+	defer client.sendMu.Unlock()
+	h.deliverLocked(client, msg)
+}
+
+// deliverLocked queues msg for a client without ever waiting: a full buffer
+// drops the message, and the client is only dropped after maxOverflows
+// consecutive overflows, so a brief stall costs a message (which the client
+// sees as a gap and resyncs) rather than the connection.
+// Caller must hold client.sendMu.
+func (h *Hub) deliverLocked(client *Client, msg *Message) {
+	if client.sendClosed {
+		return
+	}
+
+	// A dropped message still uses up its number, leaving the gap
+	client.seq++
+	stamped := *msg
+	stamped.Seq = client.seq
+	data := stamped.Bytes()
+
+	select {
+	case client.send <- data:
+		client.overflows.Store(0)
+	default:
+		overflows := client.overflows.Add(1)
+		if overflows >= h.maxOverflows {
+			hint := h.overflowDrops.record(client.PlayerID, time.Now())
+			client.closeHint.Store(&hint)
+			client.Logger().Warn("client send buffer full, closing", "overflows", overflows, "retry_after_ms", hint.RetryAfterMS)
 			go h.Unregister(client)
+			return
 		}
+		client.Logger().Warn("client send buffer full, message dropped", "overflows", overflows)
 	}
 }
 
+// timestamped returns a copy of msg carrying the server time
+func timestamped(msg *Message) *Message {
+	stamped := *msg
+	stamped.TS = time.Now().UnixMilli()
+	return &stamped
+}
+
 // SendToClient sends a message to a specific client
 func (h *Hub) SendToClient(client *Client, msg *Message) {
-	select {
-	case client.send <- msg.Bytes():
-	default:
-		h.logger.Warn("client send buffer full", "player_id", client.PlayerID)
-	}
+	h.deliver(client, timestamped(msg))
 }
 
 // GetRoomClients returns all clients in a room
@@ -228,28 +439,20 @@ func (h *Hub) GetClient(playerID string) *Client {
 
 // BroadcastToPlayers sends a message to specific players in a room
 func (h *Hub) BroadcastToPlayers(roomCode string, playerIDs []string, msg *Message) {
-	h.mu.RLock()
-	room, ok := h.rooms[roomCode]
-	h.mu.RUnlock()
-
-	if !ok {
-		return
-	}
-
 	// Create a set of target player IDs for O(1) lookup
 	targetSet := make(map[string]bool, len(playerIDs))
 	for _, id := range playerIDs {
 		targetSet[id] = true
 	}
 
-	data := msg.Bytes()
-	for client := range room {
+	h.mu.RLock()
+	var recipients []*Client
+	for client := range h.rooms[roomCode] {
 		if targetSet[client.PlayerID] {
-			select {
-			case client.send <- data:
-			default:
-				h.logger.Warn("client send buffer full", "player_id", client.PlayerID)
-			}
+			recipients = append(recipients, client)
 		}
 	}
+	h.mu.RUnlock()
+
+	h.deliverAll(recipients, timestamped(msg))
 }
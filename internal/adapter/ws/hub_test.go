@@ -0,0 +1,258 @@
+package ws
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestStalledClientRecoversWithoutBeingDropped(t *testing.T) {
+	h := NewHub(discardLogger())
+	h.SetSlowClientPolicy(3)
+	c := newTestClient(h, "p1", 1)
+	h.SendToClient(c, MustMessage("fills_buffer", nil))
+
+	// A send to the full buffer returns at once and costs only that message
+	h.SendToClient(c, MustMessage("dropped", nil))
+	if got := c.overflows.Load(); got != 1 {
+		t.Fatalf("overflows = %d, want 1", got)
+	}
+
+	// Once the client drains, the next send lands and leaves a visible gap
+	<-c.send
+	h.SendToClient(c, MustMessage("delivered", nil))
+	got := drainMessages(t, c)
+	if len(got) != 1 || got[0].Type != "delivered" || got[0].Seq != 3 {
+		t.Fatalf("queued after the stall: %+v", got)
+	}
+	if n := c.overflows.Load(); n != 0 {
+		t.Errorf("overflows = %d after a successful send, want 0", n)
+	}
+	select {
+	case dropped := <-h.unregister:
+		t.Fatalf("client %s was dropped", dropped.PlayerID)
+	default:
+	}
+}
+
+func TestClientDroppedAfterMaxOverflows(t *testing.T) {
+	h := NewHub(discardLogger())
+	h.SetSlowClientPolicy(3)
+	c := newTestClient(h, "p1", 1)
+	h.SendToClient(c, MustMessage("fills_buffer", nil))
+
+	for i := range 2 {
+		h.SendToClient(c, MustMessage("dropped", nil))
+		if got := c.overflows.Load(); got != int32(i+1) {
+			t.Fatalf("overflows = %d, want %d", got, i+1)
+		}
+	}
+	select {
+	case <-h.unregister:
+		t.Fatal("client dropped before reaching the overflow limit")
+	default:
+	}
+
+	h.SendToClient(c, MustMessage("dropped", nil))
+	select {
+	case dropped := <-h.unregister:
+		if dropped != c {
+			t.Errorf("dropped %s, want p1", dropped.PlayerID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("client kept after three consecutive overflows")
+	}
+}
+
+func TestReconnectHintEscalatesOnRepeatedDrops(t *testing.T) {
+	h := NewHub(discardLogger())
+	h.SetSlowClientPolicy(1)
+
+	// drop overflows a fresh connection for playerID until the hub drops it
+	drop := func(playerID string) int64 {
+		t.Helper()
+		c := newTestClient(h, playerID, 1)
+		h.SendToClient(c, MustMessage("fills_buffer", nil))
+		h.SendToClient(c, MustMessage("dropped", nil))
+		<-h.unregister
+		hint := c.closeHint.Load()
+		if hint == nil {
+			t.Fatalf("%s dropped without a reconnect hint", playerID)
+		}
+		if hint.Reason != ReconnectHintReason {
+			t.Errorf("reason = %q, want %q", hint.Reason, ReconnectHintReason)
+		}
+		return hint.RetryAfterMS
+	}
+
+	var got []int64
+	for range 7 {
+		got = append(got, drop("p1"))
+	}
+	want := []int64{1000, 2000, 4000, 8000, 16000, 30000, 30000}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("retry_after_ms across drops = %v, want %v", got, want)
+	}
+
+	if first := drop("p2"); first != 1000 {
+		t.Errorf("another player's first drop hinted %dms, want 1000", first)
+	}
+}
+
+func TestDroppedClientGetsRetryAfterInCloseFrame(t *testing.T) {
+	server, peer := connPair(t)
+	c := NewClient(NewHub(discardLogger()), server, "p1", discardLogger(), nil, nil)
+	c.closeHint.Store(&ReconnectHint{Reason: ReconnectHintReason, RetryAfterMS: 4000})
+	go c.WritePump()
+	close(c.send)
+
+	peer.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := peer.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) {
+		t.Fatalf("read error = %v, want a close frame", err)
+	}
+	if closeErr.Code != websocket.CloseTryAgainLater {
+		t.Errorf("close code = %d, want %d", closeErr.Code, websocket.CloseTryAgainLater)
+	}
+	if want := `{"reason":"slow_consumer","retry_after_ms":4000}`; closeErr.Text != want {
+		t.Errorf("close reason = %s, want %s", closeErr.Text, want)
+	}
+}
+
+func TestSeqContiguousPerClientWithTargetedSends(t *testing.T) {
+	h := NewHub(discardLogger())
+	a := newTestClient(h, "a", 1024)
+	b := newTestClient(h, "b", 1024)
+	for _, client := range []*Client{a, b} {
+		h.JoinRoom(client, "ROOM")
+	}
+
+	// Each client also gets messages the other never sees; neither may
+	// observe a gap for them
+	const perSender = 50
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for range perSender {
+				h.broadcastToRoom(&RoomMessage{RoomCode: "ROOM", Message: MustMessage("room", nil)})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range perSender {
+				h.SendToClient(a, MustMessage("unicast", nil))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range perSender {
+				h.BroadcastToPlayers("ROOM", []string{"b"}, MustMessage("targeted", nil))
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, client := range []*Client{a, b} {
+		msgs := drainMessages(t, client)
+		if len(msgs) != 4*perSender*2 {
+			t.Fatalf("%s received %d messages, want %d", client.PlayerID, len(msgs), 4*perSender*2)
+		}
+		for i, msg := range msgs {
+			if msg.Seq != uint64(i+1) || msg.TS == 0 {
+				t.Fatalf("%s message %d has seq %d, ts %d; want seq %d", client.PlayerID, i, msg.Seq, msg.TS, i+1)
+			}
+		}
+	}
+}
+
+func TestShutdownDeliversNoticeAndClosesConnections(t *testing.T) {
+	h := NewHub(discardLogger())
+	go h.Run()
+
+	var peers []*websocket.Conn
+	for i, roomCode := range []string{"ROOM1", "ROOM2", ""} {
+		server, peer := connPair(t)
+		c := NewClient(h, server, fmt.Sprintf("p%d", i), h.logger, nil, nil)
+		h.Register(c)
+		if roomCode != "" {
+			h.JoinRoom(c, roomCode)
+		}
+		go c.WritePump()
+		peers = append(peers, peer)
+	}
+
+	// Register returns once Run has taken the client, not once it is added
+	for deadline := time.Now().Add(time.Second); h.Stats().Clients < len(peers); {
+		if time.Now().After(deadline) {
+			t.Fatal("clients were not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	h.BroadcastAll(MustMessage(EventTypeServerShuttingDown, ServerShuttingDownPayload{Message: "bye"}))
+	for i, peer := range peers {
+		peer.SetReadDeadline(time.Now().Add(time.Second))
+		_, data, err := peer.ReadMessage()
+		if err != nil {
+			t.Fatalf("peer %d: read notice: %v", i, err)
+		}
+		if msg, err := ParseMessage(data); err != nil || msg.Type != EventTypeServerShuttingDown {
+			t.Fatalf("peer %d: got %s, want shutdown notice", i, data)
+		}
+	}
+
+	h.Shutdown()
+	for i, peer := range peers {
+		peer.SetReadDeadline(time.Now().Add(time.Second))
+		_, _, err := peer.ReadMessage()
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+			t.Fatalf("peer %d: read error = %v, want going-away close", i, err)
+		}
+	}
+	if n := h.Stats().Clients; n != 0 {
+		t.Errorf("%d clients still registered after shutdown", n)
+	}
+}
+
+func TestRegisterAfterShutdownIsRejected(t *testing.T) {
+	h := NewHub(discardLogger())
+	go h.Run()
+	h.Shutdown()
+
+	server, peer := connPair(t)
+	c := NewClient(h, server, "late", h.logger, nil, nil)
+	h.Register(c)
+
+	peer.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := peer.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Fatalf("read error = %v, want going-away close", err)
+	}
+	if n := h.Stats().Clients; n != 0 {
+		t.Errorf("%d clients registered after shutdown", n)
+	}
+	h.SendToClient(c, MustMessage("ignored", nil))
+}
+
+func TestHubRunningOnceRunStarts(t *testing.T) {
+	h := NewHub(discardLogger())
+	if h.Running() {
+		t.Fatal("hub reports running before Run")
+	}
+	go h.Run()
+	waitFor(t, "hub to run", h.Running)
+
+	h.Shutdown()
+	if h.Running() {
+		t.Error("hub still reports running after shutdown")
+	}
+}
@@ -0,0 +1,78 @@
+package ws
+
+import "time"
+
+// KickReasonIdle marks a lobby player removed for sending nothing for too long
+const KickReasonIdle = "idle"
+
+// StartIdleSweeper kicks lobby players whose client has sent nothing for
+// timeout, checking every interval until stop is closed. Rooms with a game
+// under way are left alone; dropped players there go through reconnect.
+func (r *Router) StartIdleSweeper(timeout, interval time.Duration, stop <-chan struct{}) {
+	r.idleTimeout = timeout
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				r.sweepIdle(now)
+			}
+		}
+	}()
+}
+
+// sweepIdle kicks every lobby player idle for at least idleTimeout at now
+func (r *Router) sweepIdle(now time.Time) {
+	if r.idleTimeout <= 0 {
+		return
+	}
+	for client, roomCode := range r.hub.idleClients(now.Add(-r.idleTimeout)) {
+		r.kickPlayer(client, roomCode, KickReasonIdle)
+	}
+}
+
+// kickPlayer removes the client's player from roomCode's lobby, telling them
+// and everyone left behind why. Nobody is kicked once a game has started,
+// even one starting while the kick is under way.
+func (r *Router) kickPlayer(client *Client, roomCode, reason string) {
+	player, newHostID, err := r.roomService.LeaveLobby(roomCode, client.PlayerID)
+	if err != nil {
+		return
+	}
+
+	kicked := MustMessage(EventTypePlayerKicked, PlayerKickedPayload{
+		PlayerID: player.ID,
+		Reason:   reason,
+		NewHost:  newHostID,
+	})
+	client.Send(kicked)
+	r.hub.LeaveRoom(client)
+	r.hub.BroadcastToRoom(roomCode, kicked, nil)
+	r.broadcastLobbyStatus(roomCode)
+
+	client.Logger().Info("player kicked", "room", roomCode, "reason", reason)
+}
+
+// idleClients returns the seated clients whose last activity was before
+// cutoff, mapped to their room code
+func (h *Hub) idleClients(cutoff time.Time) map[*Client]string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	idle := make(map[*Client]string)
+	for roomCode, room := range h.rooms {
+		for client := range room {
+			if client.spectating.Load() {
+				continue
+			}
+			if time.Unix(0, client.lastActivity.Load()).Before(cutoff) {
+				idle[client] = roomCode
+			}
+		}
+	}
+	return idle
+}
@@ -0,0 +1,58 @@
+package ws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIdleLobbyPlayerIsKicked(t *testing.T) {
+	r := newTestRouter(t, false)
+	r.idleTimeout = time.Minute
+
+	room, err := r.roomService.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	clients := make(map[string]*Client)
+	for _, id := range []string{"idle", "active"} {
+		if _, err := r.roomService.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		clients[id] = newTestClient(r.hub, id, 256)
+		r.hub.JoinRoom(clients[id], room.Code)
+		clients[id].lastActivity.Store(time.Now().Add(-time.Hour).UnixNano())
+	}
+
+	// Any inbound message counts as activity
+	r.HandleMessage(clients["active"], MustMessage(MsgTypeReady, ReadyPayload{Ready: true}))
+	r.sweepIdle(time.Now())
+
+	if room.GetPlayer("idle") != nil || clients["idle"].RoomCode != "" {
+		t.Error("idle player still seated after the threshold")
+	}
+	if room.GetPlayer("active") == nil || clients["active"].RoomCode != room.Code {
+		t.Error("active player was kicked")
+	}
+	var kicked PlayerKickedPayload
+	if !lastOfType(t, clients["idle"], EventTypePlayerKicked, &kicked) {
+		t.Fatal("kicked player was not told")
+	}
+	if kicked.PlayerID != "idle" || kicked.Reason != KickReasonIdle || kicked.NewHost != "active" {
+		t.Errorf("player_kicked = %+v, want idle kicked with host handed to active", kicked)
+	}
+}
+
+func TestIdleSweepSkipsActiveGames(t *testing.T) {
+	r := newTestRouter(t, false)
+	r.idleTimeout = time.Minute
+	room, clients := startTestGame(t, r, 5, nil)
+	for _, c := range clients {
+		c.lastActivity.Store(time.Now().Add(-time.Hour).UnixNano())
+	}
+
+	r.sweepIdle(time.Now())
+
+	if room.PlayerCount() != 5 {
+		t.Errorf("%d players left after sweeping a running game, want 5", room.PlayerCount())
+	}
+}
@@ -0,0 +1,56 @@
+package ws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestLobbyChat(t *testing.T) {
+	r := newTestRouter(t, false)
+	go r.hub.Run()
+
+	room, err := r.roomService.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	clients := make(map[string]*Client)
+	for _, id := range []string{"p0", "p1", "p2"} {
+		if _, err := r.roomService.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		clients[id] = newTestClient(r.hub, id, 64)
+		r.hub.JoinRoom(clients[id], room.Code)
+	}
+
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeLobbyChat, LobbyChatPayload{Message: "ready when you are"}))
+	for _, id := range []string{"p0", "p1", "p2"} {
+		var got LobbyChatBroadcastPayload
+		waitFor(t, id+" lobby_chat", func() bool {
+			return lastOfType(t, clients[id], EventTypeLobbyChat, &got)
+		})
+		if got.FromID != "p1" || got.FromNickname != "Player p1" || got.Message != "ready when you are" {
+			t.Errorf("%s got %+v", id, got)
+		}
+	}
+
+	// Too fast, then empty and overlong messages are refused
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeLobbyChat, LobbyChatPayload{Message: "again"}))
+	if code := lastErrorCode(t, clients["p1"]); code != string(ErrCodeRateLimited) {
+		t.Errorf("rapid message error = %q, want %s", code, ErrCodeRateLimited)
+	}
+	for _, message := range []string{"", strings.Repeat("x", 501)} {
+		r.HandleMessage(clients["p2"], MustMessage(MsgTypeLobbyChat, LobbyChatPayload{Message: message}))
+		if code := lastErrorCode(t, clients["p2"]); code != string(ErrCodeInvalidMessage) {
+			t.Errorf("%d-byte message error = %q, want %s", len(message), code, ErrCodeInvalidMessage)
+		}
+	}
+
+	// Once the game starts the lobby chat is closed
+	room.State = entity.RoomStatePlaying
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeLobbyChat, LobbyChatPayload{Message: "gl hf"}))
+	if code := lastErrorCode(t, clients["p0"]); code != string(ErrCodeGameStarted) {
+		t.Errorf("in-game message error = %q, want %s", code, ErrCodeGameStarted)
+	}
+}
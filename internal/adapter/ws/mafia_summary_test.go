@@ -0,0 +1,58 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestMafiaNightSummaryReachesOnlyMafia(t *testing.T) {
+	r := newTestRouter(t, false)
+	room, clients := startTestGame(t, r, 7, func(s *entity.GameSettings) {
+		s.AllowFirstNightKill = true
+	})
+	game := r.gameService.GetGame(room.Code)
+	byRole := map[entity.Role][]string{}
+	for _, id := range room.PlayerOrder {
+		byRole[game.Roles[id]] = append(byRole[game.Roles[id]], id)
+	}
+	victim := byRole[entity.RoleVillager][0]
+
+	// The doctor guards the mafia's target
+	game.StartNight(time.Minute)
+	for _, c := range clients {
+		drainMessages(t, c)
+	}
+	for _, id := range byRole[entity.RoleMafia] {
+		if err := r.gameService.SubmitNightAction(room.Code, id, victim); err != nil {
+			t.Fatalf("mafia %s acts: %v", id, err)
+		}
+	}
+	if err := r.gameService.SubmitNightAction(room.Code, byRole[entity.RoleDoctor][0], victim); err != nil {
+		t.Fatalf("doctor acts: %v", err)
+	}
+	if err := r.gameService.SubmitNightAction(room.Code, byRole[entity.RoleDetective][0], byRole[entity.RoleMafia][0]); err != nil {
+		t.Fatalf("detective acts: %v", err)
+	}
+	if phase := game.GetPhase(); phase != entity.PhaseNightResult {
+		t.Fatalf("night not resolved: phase %s", phase)
+	}
+
+	for id, c := range clients {
+		var summary struct {
+			AttemptedTarget string `json:"attempted_target"`
+			Succeeded       bool   `json:"succeeded"`
+			Saved           bool   `json:"saved"`
+		}
+		got := lastOfType(t, c, EventTypeMafiaNightSummary, &summary)
+		isMafia := game.Roles[id].GetTeam() == entity.TeamMafia
+		if got != isMafia {
+			t.Errorf("%s (%s): got summary = %v", id, game.Roles[id], got)
+			continue
+		}
+		if got && (summary.AttemptedTarget != victim || summary.Succeeded || !summary.Saved) {
+			t.Errorf("%s: summary %+v, want a save of %s", id, summary, victim)
+		}
+	}
+}
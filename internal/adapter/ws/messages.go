@@ -1,6 +1,10 @@
 package ws
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
 
 // Message types (client -> server)
 const (
@@ -8,17 +12,40 @@ const (
 	MsgTypeCreateRoom = "create_room"
 	MsgTypeJoinRoom   = "join_room"
 	MsgTypeLeaveRoom  = "leave_room"
+	MsgTypeSpectate   = "spectate"
 	MsgTypeReconnect  = "reconnect"
 
+	// State sync
+	MsgTypeRequestState = "request_state"
+	MsgTypeResync       = "resync"
+	MsgTypeGetTimeline  = "get_timeline"
+
 	// Lobby actions
 	MsgTypeReady          = "ready"
 	MsgTypeUpdateSettings = "update_settings"
 	MsgTypeStartGame      = "start_game"
+	MsgTypePreviewRoles   = "preview_roles"
+	MsgTypeApplyPreset    = "apply_preset"
+	MsgTypeAutoBalance    = "auto_balance"
+	MsgTypeTransferHost   = "transfer_host"
+	MsgTypeChangeNickname = "change_nickname"
+	MsgTypeAddBot         = "add_bot"
+	MsgTypeRedealRoles    = "redeal_roles"
+	MsgTypeReturnToLobby  = "return_to_lobby"
+	MsgTypeLobbyChat      = "lobby_chat"
+	MsgTypeAssignRole     = "assign_role"
 
 	// Game actions
 	MsgTypeNightAction = "night_action"
+	MsgTypeConfirmKill = "confirm_kill"
 	MsgTypeDayVote     = "day_vote"
 	MsgTypeGhostChat   = "ghost_chat"
+	MsgTypeDayChat     = "day_chat"
+
+	MsgTypeClaimInvestigation = "claim_investigation"
+	MsgTypeConfirmLynch       = "confirm_lynch"
+	MsgTypeCupidSelect        = "cupid_select"
+	MsgTypePassTurn           = "pass_turn"
 
 	// Voice actions
 	MsgTypeVoiceJoin      = "voice_join"
@@ -26,55 +53,92 @@ const (
 	MsgTypeVoiceOffer     = "voice_offer"
 	MsgTypeVoiceAnswer    = "voice_answer"
 	MsgTypeVoiceCandidate = "voice_candidate"
+	MsgTypeMutePlayer     = "mute_player"
+	MsgTypeUnmutePlayer   = "unmute_player"
 	MsgTypeSpeakingState  = "speaking_state"
 )
 
 // Event types (server -> client)
 const (
 	// Connection events
-	EventTypeConnected = "connected"
-	EventTypeError     = "error"
+	EventTypeConnected          = "connected"
+	EventTypeError              = "error"
+	EventTypeAck                = "ack"
+	EventTypeServerShuttingDown = "server_shutting_down"
 
 	// Room events
 	EventTypeRoomCreated  = "room_created"
 	EventTypeRoomJoined   = "room_joined"
 	EventTypePlayerJoined       = "player_joined"
 	EventTypePlayerLeft         = "player_left"
+	EventTypePlayerKicked       = "player_kicked"
 	EventTypePlayerDisconnected = "player_disconnected"
 	EventTypePlayerReconnected  = "player_reconnected"
+	EventTypeReconnected        = "reconnected"
+	EventTypeHostChanged        = "host_changed"
+	EventTypeNicknameChanged    = "nickname_changed"
+	EventTypePlayerLatency      = "player_latency"
 
 	// Lobby events
 	EventTypePlayerReady     = "player_ready"
 	EventTypeSettingsUpdated = "settings_updated"
 	EventTypeGameStarting    = "game_starting"
+	EventTypeForceReadied    = "players_force_readied"
+	EventTypeAutoStart       = "auto_start_countdown"
+	EventTypeGameQueued      = "game_queued"
+	EventTypeRolePreview     = "role_preview"
+	EventTypeLobbyStatus     = "lobby_status"
+	EventTypeReturnedToLobby = "returned_to_lobby"
+	EventTypeDealSeedPinned  = "deal_seed_pinned"
+	EventTypeLobbyChat       = "lobby_chat"
+	EventTypeForcedRoles     = "forced_roles"
 
 	// Game events
 	EventTypeRoleAssigned = "role_assigned"
 	EventTypePhaseChanged = "phase_changed"
 	EventTypeTimerTick    = "timer_tick"
+	EventTypeActionPrompt = "action_prompt"
 	EventTypeNightResult  = "night_result"
 	EventTypeDayResult    = "day_result"
+	EventTypeNightProgress = "night_progress"
+	EventTypeLynchConfirmUpdate = "lynch_confirm_update"
+	EventTypeMafiaNightSummary = "mafia_night_summary"
 	EventTypeGameOver        = "game_over"
 	EventTypeGhostChatBroadcast = "ghost_chat_broadcast"
+	EventTypeDayChatBroadcast   = "day_chat_broadcast"
+	EventTypeInvestigationClaim = "investigation_claim"
+	EventTypeLoversPaired       = "lovers_paired"
+	EventTypeSpeakingTurn       = "speaking_turn"
 
 	// State sync
 	EventTypeRoomState = "room_state"
 	EventTypeGameState = "game_state"
+	EventTypeTimeline  = "game_timeline"
 
 	// Voice events
-	EventTypeVoiceJoined    = "voice_joined"
-	EventTypeVoiceLeft      = "voice_left"
-	EventTypeVoiceOffer     = "voice_offer"
-	EventTypeVoiceAnswer    = "voice_answer"
-	EventTypeVoiceCandidate = "voice_candidate"
-	EventTypeSpeakingState  = "speaking_state"
-	EventTypeVoiceRouting   = "voice_routing"
+	EventTypeVoiceJoined            = "voice_joined"
+	EventTypeVoiceLeft              = "voice_left"
+	EventTypeVoiceOffer             = "voice_offer"
+	EventTypeVoiceAnswer            = "voice_answer"
+	EventTypeVoiceCandidate         = "voice_candidate"
+	EventTypeSpeakingState          = "speaking_state"
+	EventTypeVoiceRouting           = "voice_routing"
+	EventTypeVoiceRenegotiate       = "voice_renegotiate"
+	EventTypeVoiceReconnectRequired = "voice_reconnect_required"
+	EventTypePlayerMuted            = "player_muted"
 )
 
 // Message is the envelope for all WebSocket messages
 type Message struct {
 	Type    string          `json:"type"`
 	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// Set by clients that want an ack event once the message is handled
+	AckID string `json:"ack_id,omitempty"`
+
+	// Set by the hub on outbound messages
+	Seq uint64 `json:"seq,omitempty"` // per-connection sequence number; a gap means a lost message
+	TS  int64  `json:"ts,omitempty"`  // server time in unix milliseconds
 }
 
 // ParseMessage parses a raw JSON message
@@ -118,15 +182,23 @@ func (m *Message) Bytes() []byte {
 
 // CreateRoomPayload is sent by client to create a room
 type CreateRoomPayload struct {
-	Password string `json:"password,omitempty"`
-	Nickname string `json:"nickname"`
+	Password   string `json:"password,omitempty"`
+	Nickname   string `json:"nickname"`
+	HistoryKey string `json:"history_key,omitempty"` // opt-in persistent key for game history
+	MaxPlayers int    `json:"max_players,omitempty"` // 0 = server default
 }
 
 // JoinRoomPayload is sent by client to join a room
 type JoinRoomPayload struct {
-	RoomCode string `json:"room_code"`
-	Password string `json:"password,omitempty"`
-	Nickname string `json:"nickname"`
+	RoomCode   string `json:"room_code"`
+	Password   string `json:"password,omitempty"`
+	Nickname   string `json:"nickname"`
+	HistoryKey string `json:"history_key,omitempty"` // opt-in persistent key for game history
+}
+
+// ReconnectPayload is sent by client to resume a session after a dropped connection
+type ReconnectPayload struct {
+	Token string `json:"token"` // reconnect_token from room_created, room_joined or reconnected
 }
 
 // ReadyPayload is sent by client to toggle ready state
@@ -142,6 +214,78 @@ type SettingsPayload struct {
 	Doctor     int `json:"doctor"`
 	Detective  int `json:"detective"`
 	NightTimer int `json:"night_timer"`
+
+	DiscussionSeconds    int  `json:"discussion_seconds"`
+	RoleRevealSeconds    int  `json:"role_reveal_seconds"` // 0 = default
+	DaySeconds           int  `json:"day_seconds"`         // 0 = twice the night
+	ResultSeconds        int  `json:"result_seconds"`      // 0 = default
+	LimitDoctorSelfHeals bool `json:"limit_doctor_self_heals"`
+	DoctorSelfHealLimit  int  `json:"doctor_self_heal_limit"` // 0 = never, when limited
+	AllowFirstNightKill  bool `json:"allow_first_night_kill"`
+	RevealRolesOnDeath   bool `json:"reveal_roles_on_death"`
+
+	TieBreak         string `json:"tie_break"`      // "no_elimination", "random" or "revote"
+	MaxRevotes       int    `json:"max_revotes"`    // runoffs per tied day, 0 = default
+	VoteThreshold    string `json:"vote_threshold"` // "majority" or "plurality"
+	SkipDisconnected bool   `json:"skip_disconnected"`
+
+	RejectRepeatInvestigations bool `json:"reject_repeat_investigations"`
+	DeadCanSeeLiveChat         bool `json:"dead_can_see_live_chat"`
+
+	MinMafiaPercent int  `json:"min_mafia_percent"`
+	MaxMafiaPercent int  `json:"max_mafia_percent"`
+	AllowUnbalanced bool `json:"allow_unbalanced"` // skip the mafia share check
+
+	GodfatherDecides    bool `json:"godfather_decides"`      // godfather must confirm the kill
+	LockVoteAfterSubmit bool `json:"lock_vote_after_submit"` // day votes cannot be changed
+	AllowActionChange   bool `json:"allow_action_change"`    // doctor/detective may change their target
+	HiddenVoting        bool `json:"hidden_voting"`          // vote targets revealed only in day_result
+	AnonymousVoting     bool `json:"anonymous_voting"`       // counts only, never who voted for whom
+	Lovers              bool `json:"lovers"`                 // two linked players die together
+	Cupid               bool `json:"cupid"`                  // a cupid picks the lovers before night 1
+
+	DoctorNoConsecutiveSameTarget bool `json:"doctor_no_consecutive_same_target"`
+	AutoStartSeconds              int  `json:"auto_start_seconds"` // 0 = host starts the game
+	VoiceEnabled                  bool `json:"voice_enabled"`
+	DeadDayWhisper                bool `json:"dead_day_whisper"` // dead talk among themselves by day
+	DeadNightChat                 bool `json:"dead_night_chat"`   // dead talk among themselves at night
+	TurnBasedVoting               bool `json:"turn_based_voting"` // living speak one at a time while voting
+	GameStats                     bool `json:"game_stats"` // per-player stats in game_over
+	ConfirmGameEndingLynch        bool `json:"confirm_game_ending_lynch"`
+}
+
+// SettingsUpdatedPayload carries the full settings plus, for a host's
+// update, the old and new value of each field that changed
+type SettingsUpdatedPayload struct {
+	SettingsPayload
+	Changes entity.SettingsDiff `json:"changes,omitempty"`
+}
+
+// RedealRolesPayload pins the seed of the next deal (development only);
+// without a seed a fresh one is picked
+type RedealRolesPayload struct {
+	Seed *int64 `json:"seed,omitempty"`
+}
+
+// DealSeedPinnedPayload reports the seed the next game will deal roles with
+type DealSeedPinnedPayload struct {
+	Seed int64 `json:"seed"`
+}
+
+// ApplyPresetPayload is sent by host to load a named settings preset
+type ApplyPresetPayload struct {
+	Name string `json:"name"`
+}
+
+// StartGamePayload is sent by host to start the game
+type StartGamePayload struct {
+	Force bool `json:"force,omitempty"` // ready everyone who isn't ready
+}
+
+// RolePreviewPayload is sent in reply to a role preview request
+type RolePreviewPayload struct {
+	PlayerCount int            `json:"player_count"`
+	Roles       map[string]int `json:"roles"` // role -> count
 }
 
 // NightActionPayload is sent by player during night
@@ -154,6 +298,17 @@ type DayVotePayload struct {
 	TargetID string `json:"target_id,omitempty"` // empty = skip vote
 }
 
+// ConfirmLynchPayload is a player's answer in the vote confirming a
+// game-ending lynch
+type ConfirmLynchPayload struct {
+	Confirm bool `json:"confirm"`
+}
+
+// CupidSelectPayload is Cupid's pick of the two players to link as lovers
+type CupidSelectPayload struct {
+	TargetIDs []string `json:"target_ids"`
+}
+
 // GhostChatPayload is sent by dead players to chat
 type GhostChatPayload struct {
 	Message string `json:"message"`
@@ -167,11 +322,70 @@ type GhostChatBroadcastPayload struct {
 	Timestamp    int64  `json:"timestamp"`
 }
 
+// LobbyChatPayload is sent by players waiting in the lobby
+type LobbyChatPayload struct {
+	Message string `json:"message"`
+}
+
+// LobbyChatBroadcastPayload is sent to everyone in the lobby
+type LobbyChatBroadcastPayload struct {
+	FromID       string `json:"from_id"`
+	FromNickname string `json:"from_nickname"`
+	Message      string `json:"message"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// DayChatPayload is sent by living players to chat during the day
+type DayChatPayload struct {
+	Message string `json:"message"`
+}
+
+// DayChatBroadcastPayload is sent to living players (and dead players when allowed)
+type DayChatBroadcastPayload struct {
+	FromID       string `json:"from_id"`
+	FromNickname string `json:"from_nickname"`
+	Message      string `json:"message"`
+	Timestamp    int64  `json:"timestamp"`
+}
+
+// ClaimInvestigationPayload is sent by living players during the day to
+// announce an investigation result, true or bluffed
+type ClaimInvestigationPayload struct {
+	TargetID string `json:"target_id"`
+	IsMafia  bool   `json:"is_mafia"`
+}
+
+// InvestigationClaimPayload relays a claim to day chat readers. Claims are
+// whatever the player said; Verified is always false.
+type InvestigationClaimPayload struct {
+	FromID         string `json:"from_id"`
+	FromNickname   string `json:"from_nickname"`
+	TargetID       string `json:"target_id"`
+	TargetNickname string `json:"target_nickname"`
+	IsMafia        bool   `json:"is_mafia"`
+	Verified       bool   `json:"verified"`
+	Timestamp      int64  `json:"timestamp"`
+}
+
 // --- Event payloads (server -> client) ---
 
 // ConnectedPayload is sent when client connects
 type ConnectedPayload struct {
 	PlayerID string `json:"player_id"`
+	Protocol string `json:"protocol"` // negotiated version, e.g. "mafia.v1"
+
+	// VoiceUnavailable is true when the server runs without voice chat
+	VoiceUnavailable bool `json:"voice_unavailable"`
+}
+
+// ServerShuttingDownPayload warns clients before the server closes their connections
+type ServerShuttingDownPayload struct {
+	Message string `json:"message"`
+}
+
+// TimelinePayload summarizes the public outcome of each round so far
+type TimelinePayload struct {
+	Rounds []entity.TimelineEntry `json:"rounds"`
 }
 
 // ErrorPayload is sent when an error occurs
@@ -180,18 +394,34 @@ type ErrorPayload struct {
 	Message string `json:"message"`
 }
 
+// AckPayload answers a client message that carried an ack_id
+type AckPayload struct {
+	AckID     string `json:"ack_id"`
+	OK        bool   `json:"ok"`
+	ErrorCode string `json:"error_code,omitempty"` // first error the message caused
+}
+
 // RoomCreatedPayload is sent when room is created
 type RoomCreatedPayload struct {
-	RoomCode string `json:"room_code"`
-	PlayerID string `json:"player_id"`
+	RoomCode       string `json:"room_code"`
+	PlayerID       string `json:"player_id"`
+	ReconnectToken string `json:"reconnect_token"`
 }
 
 // RoomJoinedPayload is sent when player joins room
 type RoomJoinedPayload struct {
-	RoomCode string      `json:"room_code"`
-	PlayerID string      `json:"player_id"`
-	Players  []PlayerDTO `json:"players"`
-	Settings SettingsPayload `json:"settings"`
+	RoomCode       string          `json:"room_code"`
+	PlayerID       string          `json:"player_id"`
+	Players        []PlayerDTO     `json:"players"`
+	Settings       SettingsPayload `json:"settings"`
+	ReconnectToken string          `json:"reconnect_token"`
+}
+
+// ReconnectedPayload confirms a reconnect; the token replaces the previous one
+type ReconnectedPayload struct {
+	RoomCode       string `json:"room_code"`
+	PlayerID       string `json:"player_id"`
+	ReconnectToken string `json:"reconnect_token"`
 }
 
 // PlayerDTO is a player representation for clients
@@ -202,6 +432,7 @@ type PlayerDTO struct {
 	IsReady     bool   `json:"is_ready"`
 	IsConnected bool   `json:"is_connected"`
 	Status      string `json:"status"` // "alive", "dead"
+	IsBot       bool   `json:"is_bot"`
 }
 
 // PlayerJoinedPayload is sent when another player joins
@@ -209,10 +440,86 @@ type PlayerJoinedPayload struct {
 	Player PlayerDTO `json:"player"`
 }
 
+// TransferHostPayload is sent by host to hand host to another player
+type TransferHostPayload struct {
+	TargetID string `json:"target_id"`
+}
+
+// AssignRolePayload is sent by the host to deal a player a specific role;
+// an empty role returns them to the random deal
+type AssignRolePayload struct {
+	PlayerID string `json:"player_id"`
+	Role     string `json:"role"`
+}
+
+// ForcedRolesPayload is sent to the host only, listing the roles they have
+// dealt by hand (player ID -> role)
+type ForcedRolesPayload struct {
+	Assignments map[string]string `json:"assignments"`
+}
+
+// SpectatePayload is sent by a client to watch a room without a seat
+type SpectatePayload struct {
+	RoomCode string `json:"room_code"`
+	Password string `json:"password,omitempty"`
+}
+
+// ChangeNicknamePayload is sent by a player to rename themselves in the lobby
+type ChangeNicknamePayload struct {
+	Nickname string `json:"nickname"`
+}
+
+// NicknameChangedPayload is broadcast when a player renames themselves
+type NicknameChangedPayload struct {
+	PlayerID string `json:"player_id"`
+	Nickname string `json:"nickname"`
+}
+
 // PlayerLeftPayload is sent when a player leaves
 type PlayerLeftPayload struct {
+	PlayerID  string `json:"player_id"`
+	NewHost   string `json:"new_host,omitempty"` // if host left
+	Voluntary bool   `json:"voluntary"`          // left on purpose rather than dropped
+}
+
+// PlayerKickedPayload is sent when the server removes a player from the room
+type PlayerKickedPayload struct {
 	PlayerID string `json:"player_id"`
-	NewHost  string `json:"new_host,omitempty"` // if host left
+	Reason   string `json:"reason"`             // see KickReason*
+	NewHost  string `json:"new_host,omitempty"` // if host was kicked
+}
+
+// HostChangedPayload is sent when host moves without the host leaving the room
+type HostChangedPayload struct {
+	HostID         string `json:"host_id"`
+	PreviousHostID string `json:"previous_host_id"`
+	Temporary      bool   `json:"temporary"` // true while the original host is disconnected
+}
+
+// ReturnedToLobbyPayload is sent when a finished room goes back to the lobby
+type ReturnedToLobbyPayload struct {
+	Players  []PlayerDTO     `json:"players"`
+	Settings SettingsPayload `json:"settings"`
+}
+
+// LobbyStatusPayload tells the lobby whether the host can start the game
+type LobbyStatusPayload struct {
+	CanStart    bool   `json:"can_start"`
+	Reason      string `json:"reason,omitempty"` // e.g. "need 2 more players"
+	ReadyCount  int    `json:"ready_count"`
+	PlayerCount int    `json:"player_count"`
+
+	// VoiceEnabled is false when the host turned voice off or the server has none
+	VoiceEnabled bool `json:"voice_enabled"`
+
+	// VoiceUnavailable is true when the server has no voice at all, so
+	// clients can hide the voice UI and the host's voice setting
+	VoiceUnavailable bool `json:"voice_unavailable"`
+}
+
+// PlayerLatencyPayload reports each player's connection quality bucket
+type PlayerLatencyPayload struct {
+	Players map[string]string `json:"players"` // player ID -> "good", "ok" or "poor"
 }
 
 // PhaseChangedPayload is sent when game phase changes
@@ -246,9 +553,31 @@ type DayResultPayload struct {
 
 // GameOverPayload is sent when game ends
 type GameOverPayload struct {
-	Winner  string            `json:"winner"` // "town" or "mafia"
-	Players []PlayerDTO       `json:"players"`
-	Roles   map[string]string `json:"roles"` // player ID -> role
+	Winner      string              `json:"winner"`       // "town" or "mafia"; empty when aborted
+	WinnerLabel string              `json:"winner_label"` // e.g. "Town wins"
+	Winners     []string            `json:"winners"`      // player IDs credited with the win
+	Players     []RevealedPlayerDTO `json:"players"`
+	DeathLog    []entity.Death      `json:"death_log"`
+	Aborted     bool                `json:"aborted"`          // force-ended by an operator
+	Reason      string              `json:"reason,omitempty"` // the operator's reason when aborted
+
+	// Stats is only present when the game_stats setting is on (player ID -> stats)
+	Stats map[string]PlayerStatsDTO `json:"stats,omitempty"`
+}
+
+// RevealedPlayerDTO is one seat on the game over screen, role included
+type RevealedPlayerDTO struct {
+	ID       string `json:"id"`
+	Nickname string `json:"nickname"`
+	Role     string `json:"role"`
+	Status   string `json:"status"`
+}
+
+// PlayerStatsDTO is one player's line on the end-of-game scoreboard
+type PlayerStatsDTO struct {
+	VotesCast      int  `json:"votes_cast"`
+	NightsSurvived int  `json:"nights_survived"`
+	Investigated   bool `json:"investigated"`
 }
 
 // --- Voice payload types ---
@@ -263,6 +592,11 @@ type VoiceAnswerPayload struct {
 	SDP string `json:"sdp"`
 }
 
+// VoiceRenegotiatePayload asks the client to send a fresh offer after a failed negotiation
+type VoiceRenegotiatePayload struct {
+	Reason string `json:"reason"`
+}
+
 // VoiceCandidatePayload is sent for ICE candidates
 type VoiceCandidatePayload struct {
 	Candidate        string `json:"candidate"`
@@ -299,3 +633,19 @@ type VoiceJoinedPayload struct {
 type VoiceLeftPayload struct {
 	PlayerID string `json:"player_id"`
 }
+
+// MutePlayerPayload names the player to personally mute or unmute
+type MutePlayerPayload struct {
+	TargetID string `json:"target_id"`
+}
+
+// PlayerMutedPayload acknowledges a personal mute change to the muter only
+type PlayerMutedPayload struct {
+	TargetID string `json:"target_id"`
+	Muted    bool   `json:"muted"`
+}
+
+// VoiceReconnectRequiredPayload tells a client to rejoin voice from scratch
+type VoiceReconnectRequiredPayload struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,99 @@
+package ws
+
+import (
+	"net/url"
+	"strings"
+)
+
+// OriginPolicy decides which browser origins may open a WebSocket.
+// Entries are either full origins ("https://mafia.example.com") or bare hosts
+// ("localhost", "*.example.com"); bare hosts match any scheme and port.
+// Same-origin requests are always allowed.
+type OriginPolicy struct {
+	allowAll bool
+	entries  []originEntry
+}
+
+type originEntry struct {
+	scheme string // empty = any scheme
+	host   string // hostname, or suffix like ".example.com" for wildcards
+	port   string // empty = any port
+	suffix bool   // host is a wildcard suffix
+}
+
+// NewOriginPolicy creates a policy from an allowlist. With an empty list,
+// allowAll permits every origin (development); otherwise only same-origin
+// requests are allowed.
+func NewOriginPolicy(allowed []string, allowAll bool) *OriginPolicy {
+	p := &OriginPolicy{allowAll: allowAll && len(allowed) == 0}
+	for _, raw := range allowed {
+		if entry, ok := parseOriginEntry(raw); ok {
+			p.entries = append(p.entries, entry)
+		}
+	}
+	return p
+}
+
+func parseOriginEntry(raw string) (originEntry, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" {
+		return originEntry{}, false
+	}
+
+	var entry originEntry
+	hostPort := raw
+	if scheme, rest, ok := strings.Cut(raw, "://"); ok {
+		entry.scheme = scheme
+		hostPort = rest
+	}
+
+	host, port, ok := strings.Cut(hostPort, ":")
+	if ok {
+		entry.port = port
+	}
+	if strings.HasPrefix(host, "*.") {
+		entry.suffix = true
+		host = host[1:] // keep the leading dot
+	}
+	entry.host = host
+	return entry, host != ""
+}
+
+// Allowed reports whether a request with the given Origin header to requestHost may upgrade
+func (p *OriginPolicy) Allowed(origin, requestHost string) bool {
+	// Non-browser clients send no Origin header
+	if origin == "" || p.allowAll {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	if strings.EqualFold(u.Host, requestHost) {
+		return true
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	port := u.Port()
+	for _, entry := range p.entries {
+		if entry.matches(scheme, host, port) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e originEntry) matches(scheme, host, port string) bool {
+	if e.scheme != "" && e.scheme != scheme {
+		return false
+	}
+	if e.port != "" && e.port != port {
+		return false
+	}
+	if e.suffix {
+		return strings.HasSuffix(host, e.host) && len(host) > len(e.host)
+	}
+	return host == e.host
+}
@@ -0,0 +1,61 @@
+package ws
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOriginPolicy(t *testing.T) {
+	allowlist := NewOriginPolicy([]string{"https://mafia.example.com", "*.example.org", "localhost", "http://127.0.0.1:5173"}, false)
+	devAll := NewOriginPolicy(nil, true)
+	devWithList := NewOriginPolicy([]string{"https://mafia.example.com"}, true)
+	none := NewOriginPolicy(nil, false)
+
+	tests := []struct {
+		name   string
+		policy *OriginPolicy
+		origin string
+		want   bool
+	}{
+		{"exact origin", allowlist, "https://mafia.example.com", true},
+		{"exact origin, other scheme", allowlist, "http://mafia.example.com", false},
+		{"wildcard subdomain", allowlist, "https://play.example.org", true},
+		{"wildcard nested subdomain", allowlist, "https://eu.play.example.org:8443", true},
+		{"wildcard excludes the apex", allowlist, "https://example.org", false},
+		{"wildcard is not a bare suffix", allowlist, "https://evilexample.org", false},
+		{"bare localhost, any port", allowlist, "http://localhost:3000", true},
+		{"localhost with explicit port", allowlist, "http://127.0.0.1:5173", true},
+		{"localhost with other port", allowlist, "http://127.0.0.1:8080", false},
+		{"unknown origin", allowlist, "https://evil.com", false},
+		{"garbage origin", allowlist, "::not a url", false},
+		{"same origin", none, "https://game.host:8080", true},
+		{"no origin header", none, "", true},
+		{"other origin, no allowlist", none, "https://evil.com", false},
+		{"dev mode allows all", devAll, "https://evil.com", true},
+		{"allowlist overrides dev mode", devWithList, "https://evil.com", false},
+	}
+	for _, tt := range tests {
+		if got := tt.policy.Allowed(tt.origin, "game.host:8080"); got != tt.want {
+			t.Errorf("%s: Allowed(%q) = %v, want %v", tt.name, tt.origin, got, tt.want)
+		}
+	}
+}
+
+func TestHandlerRejectsOriginBeforeUpgrade(t *testing.T) {
+	handler := NewHandler(NewHub(discardLogger()), discardLogger(), NewOriginPolicy([]string{"*.example.org"}, false), nil, nil, nil)
+
+	for origin, want := range map[string]int{
+		"https://evil.com": http.StatusForbidden,
+		// Allowed through to the upgrade, which fails on a plain request
+		"https://play.example.org": http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		req.Header.Set("Origin", origin)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("origin %s: status %d, want %d", origin, rec.Code, want)
+		}
+	}
+}
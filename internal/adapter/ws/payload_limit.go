@@ -0,0 +1,33 @@
+package ws
+
+// defaultPayloadLimit bounds the payload of message types without a limit
+// of their own, in bytes
+const defaultPayloadLimit = 4096
+
+// defaultPayloadLimits bounds message types that need more or less room
+// than defaultPayloadLimit. Chat text is capped at 500 characters by its
+// handler; the slack covers JSON escaping.
+var defaultPayloadLimits = map[string]int{
+	MsgTypeGhostChat:      1024,
+	MsgTypeDayChat:        1024,
+	MsgTypeLobbyChat:      1024,
+	MsgTypeVoiceOffer:     32 * 1024,
+	MsgTypeVoiceCandidate: 1024,
+}
+
+// SetPayloadLimit caps the payload size of one message type, in bytes.
+// Limits above the connection read limit are never reached.
+func (r *Router) SetPayloadLimit(msgType string, limit int) {
+	if limit <= 0 {
+		return
+	}
+	r.payloadLimits[msgType] = limit
+}
+
+// payloadLimit returns the largest payload accepted for a message type
+func (r *Router) payloadLimit(msgType string) int {
+	if limit, ok := r.payloadLimits[msgType]; ok {
+		return limit
+	}
+	return defaultPayloadLimit
+}
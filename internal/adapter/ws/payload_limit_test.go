@@ -0,0 +1,54 @@
+package ws
+
+import (
+	"strings"
+	"testing"
+)
+
+// lastErrorCode returns the code of the newest error queued for c
+func lastErrorCode(t *testing.T, c *Client) string {
+	t.Helper()
+	var payload ErrorPayload
+	if !lastOfType(t, c, EventTypeError, &payload) {
+		return ""
+	}
+	return payload.Code
+}
+
+func TestOversizedSDPRejected(t *testing.T) {
+	r := newTestRouter(t, false)
+	client := newTestClient(r.hub, "p0", 16)
+
+	// An SDP past the transport's old 4 KiB cap is still within its own limit
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: strings.Repeat("a", 8*1024)}))
+	if code := lastErrorCode(t, client); code == string(ErrCodePayloadTooLarge) {
+		t.Errorf("8 KiB offer rejected as too large")
+	}
+
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: strings.Repeat("a", 40*1024)}))
+	if code := lastErrorCode(t, client); code != string(ErrCodePayloadTooLarge) {
+		t.Errorf("40 KiB offer: error = %q, want %s", code, ErrCodePayloadTooLarge)
+	}
+
+	r.SetPayloadLimit(MsgTypeVoiceOffer, 48*1024)
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: strings.Repeat("a", 40*1024)}))
+	if code := lastErrorCode(t, client); code == string(ErrCodePayloadTooLarge) {
+		t.Errorf("40 KiB offer rejected after raising the limit")
+	}
+}
+
+func TestOversizedChatRejected(t *testing.T) {
+	r := newTestRouter(t, false)
+	_, clients := startTestGame(t, r, 7, nil)
+	for _, c := range clients {
+		drainMessages(t, c)
+	}
+
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeDayChat, DayChatPayload{Message: strings.Repeat("x", 2000)}))
+	if code := lastErrorCode(t, clients["p0"]); code != string(ErrCodePayloadTooLarge) {
+		t.Errorf("2000-byte chat: error = %q, want %s", code, ErrCodePayloadTooLarge)
+	}
+	if lastOfType(t, clients["p1"], EventTypeDayChatBroadcast, &DayChatBroadcastPayload{}) {
+		t.Error("oversized chat reached other players")
+	}
+}
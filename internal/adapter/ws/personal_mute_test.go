@@ -0,0 +1,90 @@
+package ws
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestPersonalMuteAcknowledgedOnlyToMuter(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, clients := startTestGame(t, r, 7, nil)
+
+	// Muting before joining voice has nowhere to live
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeMutePlayer, MutePlayerPayload{TargetID: "p2"}))
+	if code := lastErrorCode(t, clients["p1"]); code != string(ErrCodeNotInVoice) {
+		t.Fatalf("mute outside voice error = %q, want %s", code, ErrCodeNotInVoice)
+	}
+
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeVoiceJoin, nil))
+	for _, c := range clients {
+		drainMessages(t, c)
+	}
+
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeMutePlayer, MutePlayerPayload{TargetID: "p2"}))
+	msgs := drainMessages(t, clients["p1"])
+	var ack PlayerMutedPayload
+	if len(msgs) == 0 || msgs[0].Type != EventTypePlayerMuted || json.Unmarshal(msgs[0].Payload, &ack) != nil || ack.TargetID != "p2" || !ack.Muted {
+		t.Fatalf("mute replies = %v, want a p2 muted ack first", msgs)
+	}
+	if r.sfu.GetParticipant(room.Code, "p1").CanHearParticipant("p2") {
+		t.Error("muter can still hear the muted player")
+	}
+	if hear := routedCanHear(t, msgs, "p1"); slices.Contains(hear, "p2") || len(hear) == 0 {
+		t.Errorf("muter's routing lets them hear %v, want everyone but p2", hear)
+	}
+	for id, c := range clients {
+		if id != "p1" && len(drainMessages(t, c)) != 0 {
+			t.Errorf("%s was told about a personal mute", id)
+		}
+	}
+
+	// A routing change for the whole room keeps the mute in the muter's copy only
+	r.refreshVoiceRouting(room.Code, nil)
+	if hear := routedCanHear(t, drainMessages(t, clients["p1"]), "p1"); slices.Contains(hear, "p2") {
+		t.Errorf("muter's routing after a refresh lets them hear %v", hear)
+	}
+	if hear := routedCanHear(t, drainMessages(t, clients["p3"]), "p1"); !slices.Contains(hear, "p2") {
+		t.Errorf("p3 sees p1 hearing %v; the mute leaked", hear)
+	}
+
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeUnmutePlayer, MutePlayerPayload{TargetID: "p2"}))
+	msgs = drainMessages(t, clients["p1"])
+	if len(msgs) == 0 || msgs[0].Type != EventTypePlayerMuted || json.Unmarshal(msgs[0].Payload, &ack) != nil || ack.Muted {
+		t.Errorf("unmute replies = %v, want a p2 unmuted ack first", msgs)
+	}
+	if hear := routedCanHear(t, msgs, "p1"); !slices.Contains(hear, "p2") {
+		t.Errorf("muter's routing after unmute lets them hear %v, want p2 back", hear)
+	}
+
+	for _, target := range []string{"p1", "nobody"} {
+		r.HandleMessage(clients["p1"], MustMessage(MsgTypeMutePlayer, MutePlayerPayload{TargetID: target}))
+		if code := lastErrorCode(t, clients["p1"]); code != string(ErrCodeInvalidTarget) {
+			t.Errorf("mute %q error = %q, want %s", target, code, ErrCodeInvalidTarget)
+		}
+	}
+}
+
+// routedCanHear returns playerID's CanHear from the last voice_routing in msgs
+func routedCanHear(t *testing.T, msgs []*Message, playerID string) []string {
+	t.Helper()
+	var routing *VoiceRoutingPayload
+	for _, msg := range msgs {
+		if msg.Type == EventTypeVoiceRouting {
+			routing = new(VoiceRoutingPayload)
+			if err := json.Unmarshal(msg.Payload, routing); err != nil {
+				t.Fatalf("decode voice routing: %v", err)
+			}
+		}
+	}
+	if routing == nil {
+		t.Fatalf("no voice routing among %d messages", len(msgs))
+	}
+	for _, p := range routing.Players {
+		if p.PlayerID == playerID {
+			return p.CanHear
+		}
+	}
+	t.Fatalf("voice routing has no entry for %s", playerID)
+	return nil
+}
@@ -0,0 +1,32 @@
+package ws
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Protocol versions, negotiated as WebSocket subprotocols at connect
+const (
+	ProtocolV1 = "mafia.v1"
+
+	// ProtocolDefault is assumed for clients that request no subprotocol,
+	// which predate versioning
+	ProtocolDefault = ProtocolV1
+)
+
+// supportedProtocols lists the versions this server speaks, preferred first
+var supportedProtocols = []string{ProtocolV1}
+
+// CloseUnsupportedProtocol is the close code sent when none of the client's
+// requested versions is supported
+const CloseUnsupportedProtocol = 4001
+
+// rejectProtocol closes a connection whose client asked only for versions
+// this server does not speak, naming the ones it does
+func rejectProtocol(conn *websocket.Conn) {
+	reason := "unsupported protocol, want one of: " + strings.Join(supportedProtocols, ", ")
+	conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(CloseUnsupportedProtocol, reason), time.Now().Add(writeWait))
+	conn.Close()
+}
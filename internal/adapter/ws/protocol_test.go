@@ -0,0 +1,81 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestProtocolNegotiation(t *testing.T) {
+	h := NewHub(discardLogger())
+	go h.Run()
+	handler := NewHandler(h, discardLogger(), NewOriginPolicy(nil, true), nil, nil, nil)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	url := "ws" + strings.TrimPrefix(srv.URL, "http")
+
+	// connectedProtocol dials with the given subprotocols and returns the
+	// version reported in the connected event
+	connectedProtocol := func(t *testing.T, protocols ...string) string {
+		t.Helper()
+		dialer := websocket.Dialer{Subprotocols: protocols}
+		conn, _, err := dialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		t.Cleanup(func() { conn.Close() })
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		var msg Message
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("read connected: %v", err)
+		}
+		var payload ConnectedPayload
+		if msg.Type != EventTypeConnected || json.Unmarshal(msg.Payload, &payload) != nil {
+			t.Fatalf("first message %s %s, want connected", msg.Type, msg.Payload)
+		}
+		if conn.Subprotocol() != "" && conn.Subprotocol() != payload.Protocol {
+			t.Errorf("handshake chose %q but connected reports %q", conn.Subprotocol(), payload.Protocol)
+		}
+		return payload.Protocol
+	}
+
+	t.Run("supported", func(t *testing.T) {
+		if got := connectedProtocol(t, "mafia.v99", ProtocolV1); got != ProtocolV1 {
+			t.Errorf("protocol = %q, want %q", got, ProtocolV1)
+		}
+	})
+
+	t.Run("none requested", func(t *testing.T) {
+		if got := connectedProtocol(t); got != ProtocolDefault {
+			t.Errorf("protocol = %q, want %q", got, ProtocolDefault)
+		}
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		dialer := websocket.Dialer{Subprotocols: []string{"mafia.v99"}}
+		conn, _, err := dialer.Dial(url, nil)
+		if err != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		defer conn.Close()
+
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, data, err := conn.ReadMessage()
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("read = %s, %v; want a close frame", data, err)
+		}
+		if closeErr.Code != CloseUnsupportedProtocol {
+			t.Errorf("close code = %d, want %d", closeErr.Code, CloseUnsupportedProtocol)
+		}
+		if !strings.Contains(closeErr.Text, ProtocolV1) {
+			t.Errorf("close reason %q does not name the supported versions", closeErr.Text)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+package ws
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Reconnect backoff for clients dropped as slow consumers
+const (
+	// reconnectBackoffBase is the hint after a player's first drop; it doubles
+	// with every further drop
+	reconnectBackoffBase = time.Second
+
+	// reconnectBackoffMax caps the hint
+	reconnectBackoffMax = 30 * time.Second
+
+	// reconnectBackoffWindow is how long a player must go without being
+	// dropped before the hint starts from the base again
+	reconnectBackoffWindow = 5 * time.Minute
+)
+
+// ReconnectHintReason is the only reason the hub drops a client today
+const ReconnectHintReason = "slow_consumer"
+
+// ReconnectHint is sent as the close frame's reason (with code 1013, "try
+// again later") when the hub drops a client, so it can back off instead of
+// reconnecting straight into the same overflow
+type ReconnectHint struct {
+	Reason       string `json:"reason"`
+	RetryAfterMS int64  `json:"retry_after_ms"`
+}
+
+// closeReason encodes the hint for a close frame
+func (h ReconnectHint) closeReason() string {
+	data, _ := json.Marshal(h)
+	return string(data)
+}
+
+// overflowDrops remembers recent slow-consumer drops per player ID, so a
+// client that keeps falling behind is told to wait longer each time
+type overflowDrops struct {
+	mu    sync.Mutex
+	drops map[string]dropRecord
+}
+
+type dropRecord struct {
+	count int
+	last  time.Time
+}
+
+// record counts a drop of playerID at now and returns the hint to send
+func (d *overflowDrops) record(playerID string, now time.Time) ReconnectHint {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.drops == nil {
+		d.drops = make(map[string]dropRecord)
+	}
+	// Forget players that have stayed connected for a while
+	for id, rec := range d.drops {
+		if now.Sub(rec.last) > reconnectBackoffWindow {
+			delete(d.drops, id)
+		}
+	}
+
+	rec := d.drops[playerID]
+	rec.count++
+	rec.last = now
+	d.drops[playerID] = rec
+
+	return ReconnectHint{Reason: ReconnectHintReason, RetryAfterMS: reconnectBackoff(rec.count).Milliseconds()}
+}
+
+// reconnectBackoff is the suggested wait after a player's nth drop
+func reconnectBackoff(n int) time.Duration {
+	backoff := reconnectBackoffBase
+	for i := 1; i < n && backoff < reconnectBackoffMax; i++ {
+		backoff *= 2
+	}
+	return min(backoff, reconnectBackoffMax)
+}
@@ -0,0 +1,130 @@
+package ws
+
+import (
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestReconnectRequiresVoiceRenegotiation(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, _ := startTestGame(t, r, 7, nil)
+
+	// p1 was talking when the socket dropped, leaving its participant behind;
+	// p2 never joined voice
+	if _, err := r.sfu.JoinVoice(room.Code, "p1"); err != nil {
+		t.Fatalf("join voice: %v", err)
+	}
+	for _, tt := range []struct {
+		playerID string
+		inVoice  bool
+	}{
+		{"p1", true},
+		{"p2", false},
+	} {
+		if !r.roomService.MarkPlayerDisconnected(room.Code, tt.playerID, tt.inVoice) {
+			t.Fatalf("%s not marked disconnected", tt.playerID)
+		}
+	}
+
+	for _, tt := range []struct {
+		playerID   string
+		wantSignal bool
+	}{
+		{"p1", true},
+		{"p2", false},
+	} {
+		client := newTestClient(r.hub, "", 64)
+		r.handleReconnect(client, MustMessage(MsgTypeReconnect, ReconnectPayload{
+			Token: r.roomService.IssueReconnectToken(tt.playerID, room.Code),
+		}))
+		if client.PlayerID != tt.playerID {
+			t.Fatalf("reconnect as %s: client is %q", tt.playerID, client.PlayerID)
+		}
+
+		if r.sfu.GetParticipant(room.Code, tt.playerID) != nil {
+			t.Errorf("%s: stale voice participant survived reconnect", tt.playerID)
+		}
+		var payload VoiceReconnectRequiredPayload
+		got := lastOfType(t, client, EventTypeVoiceReconnectRequired, &payload)
+		if got != tt.wantSignal {
+			t.Errorf("%s: voice_reconnect_required sent = %v, want %v", tt.playerID, got, tt.wantSignal)
+		}
+		if got && payload.Reason != "reconnected" {
+			t.Errorf("%s: reason = %q, want %q", tt.playerID, payload.Reason, "reconnected")
+		}
+	}
+}
+
+func TestReconnectedDeadPlayerRejoinsVoiceMuted(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, clients := startTestGame(t, r, 7, nil)
+	game := r.gameService.GetGame(room.Code)
+	game.StartDay(time.Minute)
+	room.GetPlayer("p1").Status = entity.PlayerStatusDead
+
+	if !r.roomService.MarkPlayerDisconnected(room.Code, "p1", true) {
+		t.Fatal("p1 not marked disconnected")
+	}
+	client := newTestClient(r.hub, "", 64)
+	r.handleReconnect(client, MustMessage(MsgTypeReconnect, ReconnectPayload{
+		Token: r.roomService.IssueReconnectToken("p1", room.Code),
+	}))
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceJoin, nil))
+	r.HandleMessage(clients["p2"], MustMessage(MsgTypeVoiceJoin, nil))
+
+	ghost := r.sfu.GetParticipant(room.Code, "p1")
+	if ghost == nil {
+		t.Fatal("p1 did not rejoin voice")
+	}
+	if ghost.CanSpeak {
+		t.Error("dead player may speak during the day after reconnecting")
+	}
+	if !ghost.CanHearParticipant("p2") {
+		t.Error("dead player cannot hear the living during the day")
+	}
+	if living := r.sfu.GetParticipant(room.Code, "p2"); living.CanHearParticipant("p1") {
+		t.Error("living player can hear the reconnected dead player")
+	}
+}
+
+func TestDeadPlayerRejoiningBetweenPhasesStaysMuted(t *testing.T) {
+	for _, tt := range []struct {
+		phase   entity.GamePhase
+		advance func(*entity.Game)
+	}{
+		{entity.PhaseRoleReveal, func(*entity.Game) {}},
+		{entity.PhaseNightResult, func(game *entity.Game) {
+			game.StartNight(time.Minute)
+			game.TryTransition(entity.PhaseNight, entity.PhaseNightResult)
+		}},
+		{entity.PhaseDayResult, func(game *entity.Game) {
+			game.StartDay(time.Minute)
+			game.TryTransition(entity.PhaseDay, entity.PhaseDayResult)
+		}},
+	} {
+		r := newTestRouter(t, true)
+		room, clients := startTestGame(t, r, 7, nil)
+		game := r.gameService.GetGame(room.Code)
+		tt.advance(game)
+		if phase := game.GetPhase(); phase != tt.phase {
+			t.Fatalf("game in %s, want %s", phase, tt.phase)
+		}
+		room.GetPlayer("p1").Status = entity.PlayerStatusDead
+
+		r.HandleMessage(clients["p2"], MustMessage(MsgTypeVoiceJoin, nil))
+		r.HandleMessage(clients["p1"], MustMessage(MsgTypeVoiceJoin, nil))
+
+		ghost := r.sfu.GetParticipant(room.Code, "p1")
+		if ghost == nil {
+			t.Fatalf("%s: p1 did not rejoin voice", tt.phase)
+		}
+		if ghost.CanSpeak {
+			t.Errorf("%s: dead player may speak after rejoining voice", tt.phase)
+		}
+		if living := r.sfu.GetParticipant(room.Code, "p2"); living.CanHearParticipant("p1") {
+			t.Errorf("%s: living player can hear the rejoined dead player", tt.phase)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestResyncAfterLostMessages(t *testing.T) {
+	r := newTestRouter(t, false)
+	room, clients := startTestGame(t, r, 7, nil)
+	game := r.gameService.GetGame(room.Code)
+	p1 := clients["p1"]
+
+	drainMessages(t, p1)
+
+	// p1's buffer is full when nightfall and their own death go out, so
+	// both are dropped; the next message to land shows the gap
+	for range cap(p1.send) {
+		p1.Send(MustMessage("filler", nil))
+	}
+	game.StartNight(time.Minute)
+	room.GetPlayer("p1").Status = entity.PlayerStatusDead
+	r.hub.broadcastToRoom(&RoomMessage{RoomCode: room.Code, Message: MustMessage(EventTypePhaseChanged, PhaseChangedPayload{Phase: string(entity.PhaseNight)})})
+	r.hub.broadcastToRoom(&RoomMessage{RoomCode: room.Code, Message: MustMessage(EventTypeNightResult, nil)})
+	queued := drainMessages(t, p1)
+	lastSeq := queued[len(queued)-1].Seq
+	p1.Send(MustMessage("after_gap", nil))
+	landed := drainMessages(t, p1)
+	if len(landed) != 1 || landed[0].Seq != lastSeq+3 {
+		t.Fatalf("after the drops got %+v, want one message with seq %d", landed, lastSeq+3)
+	}
+	lastSeq = landed[0].Seq
+
+	r.HandleMessage(p1, MustMessage(MsgTypeResync, nil))
+	msgs := drainMessages(t, p1)
+	if len(msgs) != 1 || msgs[0].Type != EventTypeGameState {
+		t.Fatalf("resync replied with %d messages (%v), want one game_state", len(msgs), msgs)
+	}
+	if msgs[0].Seq != lastSeq+1 {
+		t.Errorf("resync seq %d, want %d to continue from the last message received", msgs[0].Seq, lastSeq+1)
+	}
+
+	var state struct {
+		Phase     string      `json:"phase"`
+		Round     int         `json:"round"`
+		MyRole    string      `json:"my_role"`
+		IsAlive   bool        `json:"is_alive"`
+		RoomState string      `json:"room_state"`
+		Players   []PlayerDTO `json:"players"`
+	}
+	if err := json.Unmarshal(msgs[0].Payload, &state); err != nil {
+		t.Fatalf("decode game_state: %v", err)
+	}
+	if state.Phase != string(entity.PhaseNight) || state.Round != game.Round || state.RoomState != string(entity.RoomStatePlaying) {
+		t.Errorf("resync state phase %q round %d room %q", state.Phase, state.Round, state.RoomState)
+	}
+	if state.MyRole != string(game.Roles["p1"]) || state.IsAlive {
+		t.Errorf("resync state role %q alive %v, want %s and dead", state.MyRole, state.IsAlive, game.Roles["p1"])
+	}
+	for _, p := range state.Players {
+		if p.ID == "p1" && p.Status != string(entity.PlayerStatusDead) {
+			t.Errorf("resync lists p1 as %q", p.Status)
+		}
+	}
+
+	// Back-to-back resyncs are refused until the interval passes
+	r.HandleMessage(p1, MustMessage(MsgTypeResync, nil))
+	var errPayload ErrorPayload
+	if !lastOfType(t, p1, EventTypeError, &errPayload) || errPayload.Code != string(ErrCodeRateLimited) {
+		t.Errorf("second resync error = %+v, want %s", errPayload, ErrCodeRateLimited)
+	}
+	if !p1.allowResync(time.Now().Add(resyncInterval)) {
+		t.Error("resync still refused after the interval")
+	}
+}
@@ -0,0 +1,77 @@
+package ws
+
+import (
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestFinishedGameReturnsToLobbyAndRestarts(t *testing.T) {
+	r := newTestRouter(t, true)
+	go r.hub.Run()
+	room, clients := startTestGame(t, r, 5, nil)
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeVoiceJoin, nil))
+	room.GetPlayer("p2").Status = entity.PlayerStatusDead
+
+	// Only a finished game can be left
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeReturnToLobby, nil))
+	var errPayload ErrorPayload
+	if !lastOfType(t, clients["p0"], EventTypeError, &errPayload) || errPayload.Code != string(ErrCodeGameNotEnded) {
+		t.Fatalf("return mid-game: error = %+v, want %s", errPayload, ErrCodeGameNotEnded)
+	}
+
+	r.gameService.GetGame(room.Code).EndGame(entity.TeamTown)
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeReturnToLobby, nil))
+	if !lastOfType(t, clients["p1"], EventTypeError, &errPayload) || errPayload.Code != string(ErrCodeNotHost) {
+		t.Fatalf("return by non-host: error = %+v, want %s", errPayload, ErrCodeNotHost)
+	}
+
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeReturnToLobby, nil))
+	var returned ReturnedToLobbyPayload
+	waitFor(t, "returned_to_lobby", func() bool {
+		return lastOfType(t, clients["p3"], EventTypeReturnedToLobby, &returned)
+	})
+	for _, p := range returned.Players {
+		if p.Status != string(entity.PlayerStatusAlive) || p.IsReady != p.IsHost {
+			t.Errorf("%s after reset: status %s, ready %v", p.ID, p.Status, p.IsReady)
+		}
+	}
+	if room.LobbyStatus().Reason == "game already started" {
+		t.Fatal("room is still marked as in a game")
+	}
+	if r.sfu.GetParticipant(room.Code, "p0") == nil {
+		t.Error("voice was dropped on returning to the lobby")
+	}
+
+	// The same group plays again
+	for id, c := range clients {
+		if id != "p0" {
+			r.HandleMessage(c, MustMessage(MsgTypeReady, ReadyPayload{Ready: true}))
+		}
+	}
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeStartGame, StartGamePayload{}))
+	if game := r.gameService.GetGame(room.Code); game == nil || game.GetPhase() == entity.PhaseGameOver {
+		t.Fatal("second game did not start")
+	}
+}
+
+func TestSettingsRejectedMidGame(t *testing.T) {
+	r := newTestRouter(t, false)
+	go r.hub.Run()
+	room, clients := startTestGame(t, r, 5, nil)
+	before := room.Settings
+
+	for name, msg := range map[string]*Message{
+		"update settings": MustMessage(MsgTypeUpdateSettings, SettingsPayload{NightTimer: before.NightTimer + 10}),
+		"apply preset":    MustMessage(MsgTypeApplyPreset, ApplyPresetPayload{Name: "small-6"}),
+		"auto balance":    MustMessage(MsgTypeAutoBalance, nil),
+	} {
+		r.HandleMessage(clients["p0"], msg)
+		if code := lastErrorCode(t, clients["p0"]); code != string(ErrCodeGameInProgress) {
+			t.Errorf("%s mid-game: error = %q, want %s", name, code, ErrCodeGameInProgress)
+		}
+	}
+	if room.Settings != before {
+		t.Error("settings changed during the game")
+	}
+}
@@ -2,7 +2,12 @@ package ws
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/V4T54L/mafia/internal/adapter/sfu"
@@ -11,6 +16,9 @@ import (
 	"github.com/pion/webrtc/v4"
 )
 
+// maxHistoryKeyLength bounds client-supplied game history keys
+const maxHistoryKeyLength = 64
+
 // Router handles WebSocket message routing
 type Router struct {
 	hub         *Hub
@@ -18,6 +26,12 @@ type Router struct {
 	gameService *service.GameService
 	sfu         *sfu.SFU
 	logger      *slog.Logger
+
+	// Largest accepted payload per message type, in bytes
+	payloadLimits map[string]int
+
+	// Lobby players idle this long are kicked (0 = never; see StartIdleSweeper)
+	idleTimeout time.Duration
 }
 
 // NewRouter creates a new message router
@@ -28,6 +42,8 @@ func NewRouter(hub *Hub, roomService *service.RoomService, gameService *service.
 		gameService: gameService,
 		sfu:         sfuInstance,
 		logger:      logger,
+
+		payloadLimits: maps.Clone(defaultPayloadLimits),
 	}
 
 	// Set up game event handler
@@ -36,32 +52,97 @@ func NewRouter(hub *Hub, roomService *service.RoomService, gameService *service.
 	// Set up reconnect timeout handler
 	roomService.SetReconnectTimeoutHandler(r.handleReconnectTimeout)
 
+	// Broadcast server-detected speaking changes
+	if sfuInstance != nil {
+		sfuInstance.SetSpeakingChangeHandler(r.handleDetectedSpeaking)
+	}
+
 	return r
 }
 
 // HandleMessage routes an incoming message to the appropriate handler
 func (r *Router) HandleMessage(client *Client, msg *Message) {
+	client.lastActivity.Store(time.Now().UnixNano())
+
+	if msg.AckID != "" {
+		if len(msg.AckID) > maxAckIDLength {
+			client.SendErrorCode(ErrCodeInvalidMessage, "ack_id too long")
+			return
+		}
+		defer r.acknowledge(client, msg.AckID, client.captureErrors())
+	}
+
+	if len(msg.Payload) > r.payloadLimit(msg.Type) {
+		client.SendErrorCode(ErrCodePayloadTooLarge, "Payload too large for "+msg.Type)
+		return
+	}
+
+	if client.spectating.Load() && !spectatorMessages[msg.Type] {
+		client.SendErrorCode(ErrCodeSpectating, "Spectators can only watch")
+		return
+	}
+
 	switch msg.Type {
 	case MsgTypeCreateRoom:
 		r.handleCreateRoom(client, msg)
 	case MsgTypeJoinRoom:
 		r.handleJoinRoom(client, msg)
+	case MsgTypeSpectate:
+		r.handleSpectate(client, msg)
 	case MsgTypeLeaveRoom:
 		r.handleLeaveRoom(client)
 	case MsgTypeReconnect:
-		r.handleReconnect(client)
+		r.handleReconnect(client, msg)
+	case MsgTypeRequestState:
+		r.handleRequestState(client)
+	case MsgTypeResync:
+		r.handleResync(client)
+	case MsgTypeGetTimeline:
+		r.handleGetTimeline(client)
 	case MsgTypeReady:
 		r.handleReady(client, msg)
 	case MsgTypeUpdateSettings:
 		r.handleUpdateSettings(client, msg)
+	case MsgTypeApplyPreset:
+		r.handleApplyPreset(client, msg)
+	case MsgTypeAutoBalance:
+		r.handleAutoBalance(client)
+	case MsgTypeTransferHost:
+		r.handleTransferHost(client, msg)
+	case MsgTypeChangeNickname:
+		r.handleChangeNickname(client, msg)
+	case MsgTypeAddBot:
+		r.handleAddBot(client)
+	case MsgTypeRedealRoles:
+		r.handleRedealRoles(client, msg)
+	case MsgTypeReturnToLobby:
+		r.handleReturnToLobby(client)
+	case MsgTypeLobbyChat:
+		r.handleLobbyChat(client, msg)
+	case MsgTypeAssignRole:
+		r.handleAssignRole(client, msg)
 	case MsgTypeStartGame:
-		r.handleStartGame(client)
+		r.handleStartGame(client, msg)
+	case MsgTypePreviewRoles:
+		r.handlePreviewRoles(client)
 	case MsgTypeNightAction:
 		r.handleNightAction(client, msg)
+	case MsgTypeConfirmLynch:
+		r.handleConfirmLynch(client, msg)
+	case MsgTypeCupidSelect:
+		r.handleCupidSelect(client, msg)
+	case MsgTypePassTurn:
+		r.handlePassTurn(client)
+	case MsgTypeConfirmKill:
+		r.handleConfirmKill(client)
 	case MsgTypeDayVote:
 		r.handleDayVote(client, msg)
 	case MsgTypeGhostChat:
 		r.handleGhostChat(client, msg)
+	case MsgTypeDayChat:
+		r.handleDayChat(client, msg)
+	case MsgTypeClaimInvestigation:
+		r.handleClaimInvestigation(client, msg)
 	// Voice handlers
 	case MsgTypeVoiceJoin:
 		r.handleVoiceJoin(client)
@@ -71,21 +152,28 @@ func (r *Router) HandleMessage(client *Client, msg *Message) {
 		r.handleVoiceOffer(client, msg)
 	case MsgTypeVoiceCandidate:
 		r.handleVoiceCandidate(client, msg)
+	case MsgTypeMutePlayer:
+		r.handlePersonalMute(client, msg, true)
+	case MsgTypeUnmutePlayer:
+		r.handlePersonalMute(client, msg, false)
 	case MsgTypeSpeakingState:
 		r.handleSpeakingState(client, msg)
 	default:
-		client.SendError("unknown_message", "Unknown message type: "+msg.Type)
+		client.SendErrorCode(ErrCodeUnknownMessage, "Unknown message type: "+msg.Type)
 	}
 }
 
 // HandleDisconnect handles client disconnection
 func (r *Router) HandleDisconnect(client *Client) {
-	if client.RoomCode == "" {
+	// Spectators hold no seat; the hub forgets them on unregister
+	if client.RoomCode == "" || client.spectating.Load() {
 		return
 	}
 
 	// Leave voice chat
+	inVoice := false
 	if r.sfu != nil {
+		inVoice = r.sfu.GetParticipant(client.RoomCode, client.PlayerID) != nil
 		r.sfu.LeaveVoice(client.RoomCode, client.PlayerID)
 		// Notify others
 		r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeVoiceLeft, VoiceLeftPayload{
@@ -95,26 +183,32 @@ func (r *Router) HandleDisconnect(client *Client) {
 
 	// Check if player can reconnect (active game)
 	// If so, mark as disconnected instead of removing
-	if r.roomService.MarkPlayerDisconnected(client.RoomCode, client.PlayerID) {
+	if r.roomService.MarkPlayerDisconnected(client.RoomCode, client.PlayerID, inVoice) {
 		// Player marked as disconnected, awaiting reconnect
 		r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypePlayerDisconnected, map[string]any{
 			"player_id": client.PlayerID,
 		}), nil)
-		r.logger.Info("player disconnected during game, awaiting reconnect",
-			"room", client.RoomCode,
-			"player_id", client.PlayerID,
-		)
+
+		// Hand host to a connected player until the host returns
+		if newHostID := r.roomService.AssignTemporaryHost(client.RoomCode, client.PlayerID); newHostID != "" {
+			r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeHostChanged, HostChangedPayload{
+				HostID:         newHostID,
+				PreviousHostID: client.PlayerID,
+				Temporary:      true,
+			}), nil)
+		}
+
+		// The phase may have been waiting only on this player
+		r.gameService.PlayerDisconnected(client.RoomCode)
+
+		client.Logger().Info("player disconnected during game, awaiting reconnect")
 		return
 	}
 
 	// Not in active game or reconnect not possible - remove player
 	player, newHostID, err := r.roomService.LeaveRoom(client.RoomCode, client.PlayerID)
 	if err != nil {
-		r.logger.Warn("error removing player on disconnect",
-			"error", err,
-			"player_id", client.PlayerID,
-			"room", client.RoomCode,
-		)
+		client.Logger().Warn("error removing player on disconnect", "error", err)
 		return
 	}
 
@@ -123,165 +217,272 @@ func (r *Router) HandleDisconnect(client *Client) {
 		PlayerID: player.ID,
 		NewHost:  newHostID,
 	}), nil)
+	r.broadcastLobbyStatus(client.RoomCode)
 }
 
 func (r *Router) handleCreateRoom(client *Client, msg *Message) {
 	var payload CreateRoomPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid create room payload")
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid create room payload")
 		return
 	}
 
-	if payload.Nickname == "" {
-		client.SendError("invalid_nickname", "Nickname is required")
+	nickname, err := entity.NormalizeNickname(payload.Nickname)
+	if err != nil {
+		client.SendErrorCode(ErrCodeInvalidNickname, invalidNicknameMessage)
 		return
 	}
 
 	// Create room
-	room, err := r.roomService.CreateRoom(payload.Password)
+	room, err := r.roomService.CreateRoom(payload.Password, payload.MaxPlayers)
 	if err != nil {
-		client.SendError("create_failed", "Failed to create room")
+		if err == entity.ErrInvalidMaxPlayers {
+			client.SendErrorCode(ErrCodeInvalidMaxPlayers, "Max players must be between "+strconv.Itoa(entity.MinPlayers)+" and "+strconv.Itoa(entity.MaxPlayersCeiling))
+			return
+		}
+		if err == entity.ErrInvalidPassword {
+			client.SendErrorCode(ErrCodeInvalidPassword, invalidPasswordMessage)
+			return
+		}
+		client.SendErrorCode(ErrCodeCreateFailed, "Failed to create room")
 		return
 	}
 
 	// Join the creator to the room
-	_, err = r.roomService.JoinRoom(room.Code, payload.Password, client.PlayerID, payload.Nickname)
+	_, err = r.roomService.JoinRoom(room.Code, payload.Password, client.PlayerID, nickname)
 	if err != nil {
-		client.SendError("join_failed", "Failed to join room: "+err.Error())
+		client.SendErrorCode(ErrCodeJoinFailed, "Failed to join room: "+err.Error())
 		return
 	}
+	r.setHistoryKey(room.Code, client.PlayerID, payload.HistoryKey)
 
 	// Add client to hub's room
 	r.hub.JoinRoom(client, room.Code)
 
 	// Send success response
 	client.Send(MustMessage(EventTypeRoomCreated, RoomCreatedPayload{
-		RoomCode: room.Code,
-		PlayerID: client.PlayerID,
+		RoomCode:       room.Code,
+		PlayerID:       client.PlayerID,
+		ReconnectToken: r.roomService.IssueReconnectToken(client.PlayerID, room.Code),
 	}))
 
 	// Send full room state
 	r.sendRoomState(client, room)
+	r.broadcastLobbyStatus(room.Code)
 
-	r.logger.Info("room created and joined",
-		"room", room.Code,
-		"player_id", client.PlayerID,
-		"nickname", payload.Nickname,
-	)
+	client.Logger().Info("room created and joined", "nickname", nickname)
 }
 
 func (r *Router) handleJoinRoom(client *Client, msg *Message) {
 	var payload JoinRoomPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid join room payload")
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid join room payload")
 		return
 	}
 
-	if payload.Nickname == "" {
-		client.SendError("invalid_nickname", "Nickname is required")
+	nickname, err := entity.NormalizeNickname(payload.Nickname)
+	if err != nil {
+		client.SendErrorCode(ErrCodeInvalidNickname, invalidNicknameMessage)
 		return
 	}
 
 	if payload.RoomCode == "" {
-		client.SendError("invalid_room_code", "Room code is required")
+		client.SendErrorCode(ErrCodeInvalidRoomCode, "Room code is required")
 		return
 	}
 
 	// Join room
-	room, err := r.roomService.JoinRoom(payload.RoomCode, payload.Password, client.PlayerID, payload.Nickname)
+	room, err := r.roomService.JoinRoom(payload.RoomCode, payload.Password, client.PlayerID, nickname)
 	if err != nil {
 		switch err {
 		case entity.ErrRoomNotFound:
-			client.SendError("room_not_found", "Room not found")
+			client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
 		case entity.ErrWrongPassword:
-			client.SendError("wrong_password", "Wrong password")
+			client.SendErrorCode(ErrCodeWrongPassword, "Wrong password")
+		case entity.ErrInvalidPassword:
+			client.SendErrorCode(ErrCodeInvalidPassword, invalidPasswordMessage)
 		case entity.ErrRoomFull:
-			client.SendError("room_full", "Room is full")
+			client.SendErrorCode(ErrCodeRoomFull, "Room is full")
 		case entity.ErrNicknameInUse:
-			client.SendError("nickname_in_use", "Nickname already in use")
+			client.SendErrorCode(ErrCodeNicknameInUse, "Nickname already in use")
 		case entity.ErrGameAlreadyStarted:
-			client.SendError("game_started", "Game has already started")
+			client.SendErrorCode(ErrCodeGameStarted, "Game has already started")
 		default:
-			client.SendError("join_failed", "Failed to join room")
+			client.SendErrorCode(ErrCodeJoinFailed, "Failed to join room")
 		}
 		return
 	}
 
+	r.setHistoryKey(room.Code, client.PlayerID, payload.HistoryKey)
+
+	// A repeated join only re-sends the room state; others already saw this player
+	rejoined := client.RoomCode == room.Code
+
 	// Add client to hub's room
 	r.hub.JoinRoom(client, room.Code)
 
 	// Send success response to joining player
 	client.Send(MustMessage(EventTypeRoomJoined, RoomJoinedPayload{
-		RoomCode: room.Code,
-		PlayerID: client.PlayerID,
-		Players:  toPlayerDTOs(room.GetPlayersDTO()),
-		Settings: toSettingsPayload(room.Settings),
+		RoomCode:       room.Code,
+		PlayerID:       client.PlayerID,
+		Players:        toPlayerDTOs(room.GetPlayersDTO()),
+		Settings:       toSettingsPayload(room.Settings),
+		ReconnectToken: r.roomService.IssueReconnectToken(client.PlayerID, room.Code),
 	}))
+	if rejoined {
+		return
+	}
 
 	// Broadcast new player to others in room
 	player := room.GetPlayer(client.PlayerID)
 	r.hub.BroadcastToRoom(room.Code, MustMessage(EventTypePlayerJoined, PlayerJoinedPayload{
 		Player: toPlayerDTO(player.ToDTO()),
 	}), client) // exclude the joining player
+	r.broadcastLobbyStatus(room.Code)
 
-	r.logger.Info("player joined room",
-		"room", room.Code,
-		"player_id", client.PlayerID,
-		"nickname", payload.Nickname,
-	)
+	client.Logger().Info("player joined room", "nickname", nickname)
+}
+
+// handleSpectate lets a client watch a room without taking a seat. They get
+// the public game state and the room's recent broadcasts, and from then on
+// only what the whole room hears.
+func (r *Router) handleSpectate(client *Client, msg *Message) {
+	var payload SpectatePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid spectate payload")
+		return
+	}
+	if client.RoomCode != "" {
+		client.SendErrorCode(ErrCodeSpectating, "Leave your room before spectating")
+		return
+	}
+
+	room, err := r.roomService.Spectate(payload.RoomCode, payload.Password)
+	if err != nil {
+		switch err {
+		case entity.ErrRoomNotFound:
+			client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		case entity.ErrWrongPassword:
+			client.SendErrorCode(ErrCodeWrongPassword, "Wrong password")
+		case entity.ErrInvalidPassword:
+			client.SendErrorCode(ErrCodeInvalidPassword, invalidPasswordMessage)
+		default:
+			client.SendErrorCode(ErrCodeJoinFailed, "Failed to spectate room")
+		}
+		return
+	}
+
+	r.hub.Spectate(client, room.Code)
+	r.sendGameState(client, room)
+	client.Logger().Info("spectator joined")
+}
+
+// setHistoryKey opts a player into game history if they supplied a valid key
+func (r *Router) setHistoryKey(roomCode, playerID, historyKey string) {
+	if historyKey == "" || len(historyKey) > maxHistoryKeyLength {
+		return
+	}
+	if err := r.roomService.SetHistoryKey(roomCode, playerID, historyKey); err != nil {
+		r.logger.Warn("failed to set history key", "error", err, "player_id", playerID)
+	}
 }
 
 func (r *Router) handleLeaveRoom(client *Client) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	if client.spectating.Load() {
+		r.hub.LeaveRoom(client)
 		return
 	}
 
 	roomCode := client.RoomCode
 
+	// Leaving on purpose gives the seat up for good, even mid-game: no
+	// reconnect window is kept for it
+	r.roomService.CancelReconnectTimer(client.PlayerID)
+
 	player, newHostID, err := r.roomService.LeaveRoom(roomCode, client.PlayerID)
 	if err != nil {
-		client.SendError("leave_failed", "Failed to leave room")
+		client.SendErrorCode(ErrCodeLeaveFailed, "Failed to leave room")
 		return
 	}
 
 	// Remove from hub's room
 	r.hub.LeaveRoom(client)
 
+	if r.sfu != nil && r.sfu.GetParticipant(roomCode, player.ID) != nil {
+		r.sfu.LeaveVoice(roomCode, player.ID)
+		r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypeVoiceLeft, VoiceLeftPayload{
+			PlayerID: player.ID,
+		}), nil)
+	}
+
 	// Broadcast player left to remaining players
 	r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypePlayerLeft, PlayerLeftPayload{
-		PlayerID: player.ID,
-		NewHost:  newHostID,
+		PlayerID:  player.ID,
+		NewHost:   newHostID,
+		Voluntary: true,
 	}), nil)
+	r.broadcastLobbyStatus(roomCode)
 
-	r.logger.Info("player left room",
-		"room", roomCode,
-		"player_id", client.PlayerID,
-	)
+	// Leaving mid-game can decide it, or the phase may have waited only on them
+	r.gameService.PlayerLeft(roomCode)
+
+	// The client has left the room, so it is no longer in the scoped logger
+	client.Logger().Info("player left room", "room", roomCode)
 }
 
-func (r *Router) handleReconnect(client *Client) {
-	// Check if player can reconnect
-	dp, ok := r.roomService.CanReconnect(client.PlayerID)
-	if !ok {
-		client.SendError("reconnect_failed", "No active session to reconnect to")
+func (r *Router) handleReconnect(client *Client, msg *Message) {
+	var payload ReconnectPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid reconnect payload")
+		return
+	}
+
+	// Check the token and that the player is awaiting reconnection
+	dp, err := r.roomService.CanReconnect(payload.Token)
+	if err != nil {
+		if err == service.ErrReconnectUnauthorized {
+			client.Logger().Warn("reconnect rejected")
+			client.SendErrorCode(ErrCodeReconnectDenied, "Reconnect token is invalid or expired")
+			return
+		}
+		client.SendErrorCode(ErrCodeReconnectFailed, "No active session to reconnect to")
 		return
 	}
 
 	// Perform reconnection
-	room, err := r.roomService.ReconnectPlayer(client.PlayerID)
+	room, err := r.roomService.ReconnectPlayer(dp.PlayerID)
 	if err != nil {
-		client.SendError("reconnect_failed", "Failed to reconnect: "+err.Error())
+		client.SendErrorCode(ErrCodeReconnectFailed, "Failed to reconnect: "+err.Error())
 		return
 	}
 
-	// Add client back to hub's room
+	// This connection takes over the disconnected player's identity
+	r.hub.RebindPlayer(client, dp.PlayerID)
 	r.hub.JoinRoom(client, room.Code)
+	client.Send(MustMessage(EventTypeReconnected, ReconnectedPayload{
+		RoomCode:       room.Code,
+		PlayerID:       client.PlayerID,
+		ReconnectToken: r.roomService.IssueReconnectToken(client.PlayerID, room.Code),
+	}))
+
+	// Give host back if a temporary host stood in
+	if temporaryID := r.roomService.RestoreHost(room.Code, client.PlayerID); temporaryID != "" {
+		r.hub.BroadcastToRoom(room.Code, MustMessage(EventTypeHostChanged, HostChangedPayload{
+			HostID:         client.PlayerID,
+			PreviousHostID: temporaryID,
+			Temporary:      false,
+		}), nil)
+	}
 
 	// Get game state for the player
 	game := r.gameService.GetGame(room.Code)
 	if game == nil {
-		client.SendError("reconnect_failed", "Game no longer exists")
+		client.SendErrorCode(ErrCodeReconnectFailed, "Game no longer exists")
 		return
 	}
 
@@ -290,7 +491,7 @@ func (r *Router) handleReconnect(client *Client) {
 
 	// Send game state to reconnecting player
 	player := room.GetPlayer(client.PlayerID)
-	role := game.Roles[client.PlayerID]
+	role := game.GetPlayerRole(client.PlayerID)
 	client.Send(MustMessage(EventTypeRoleAssigned, RoleAssignedPayload{
 		Role:      string(role),
 		Team:      string(role.GetTeam()),
@@ -298,28 +499,42 @@ func (r *Router) handleReconnect(client *Client) {
 	}))
 
 	// Send current phase info
+	remaining, _ := r.gameService.RemainingPhaseTime(room.Code)
 	client.Send(MustMessage(EventTypePhaseChanged, PhaseChangedPayload{
-		Phase: string(game.Phase),
-		Timer: int(time.Until(game.PhaseEndTime).Seconds()),
+		Phase: string(game.GetPhase()),
+		Timer: int(remaining.Seconds()),
 	}))
 
+	// Send consolidated snapshot
+	r.sendGameState(client, room)
+
+	// The old peer connection died with the socket; voice must be renegotiated
+	if r.sfu != nil {
+		rejoinVoice := dp.InVoice
+		if r.sfu.GetParticipant(room.Code, client.PlayerID) != nil {
+			// Stale participant left behind by the dropped connection
+			r.sfu.LeaveVoice(room.Code, client.PlayerID)
+			rejoinVoice = true
+		}
+		if rejoinVoice && room.Settings.VoiceEnabled {
+			client.Send(MustMessage(EventTypeVoiceReconnectRequired, VoiceReconnectRequiredPayload{
+				Reason: "reconnected",
+			}))
+		}
+	}
+
 	// Broadcast reconnection to other players
 	r.hub.BroadcastToRoom(room.Code, MustMessage(EventTypePlayerReconnected, map[string]any{
 		"player_id": client.PlayerID,
 		"nickname":  player.Nickname,
 	}), client)
 
-	r.logger.Info("player reconnected",
-		"room", dp.RoomCode,
-		"player_id", client.PlayerID,
-	)
+	client.Logger().Info("player reconnected")
 }
 
 // handleReconnectTimeout is called when a disconnected player's timer expires
 func (r *Router) handleReconnectTimeout(roomCode, playerID string) {
-	// Get the room
-	room, err := r.roomService.GetRoom(roomCode)
-	if err != nil {
+	if _, err := r.roomService.GetRoom(roomCode); err != nil {
 		return
 	}
 
@@ -340,19 +555,8 @@ func (r *Router) handleReconnectTimeout(roomCode, playerID string) {
 		NewHost:  newHostID,
 	}), nil)
 
-	// Check if game should end due to player leaving
-	game := r.gameService.GetGame(roomCode)
-	if game != nil {
-		gameOver, winner := game.CheckWinCondition()
-		if gameOver {
-			game.EndGame(winner)
-			r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypeGameOver, GameOverPayload{
-				Winner:  string(winner),
-				Players: toPlayerDTOs(room.GetPlayersDTO()),
-				Roles:   getRoleStrings(game.Roles),
-			}), nil)
-		}
-	}
+	// End the game or the phase now if the departure settled it
+	r.gameService.PlayerLeft(roomCode)
 
 	r.logger.Info("disconnected player removed after timeout",
 		"room", roomCode,
@@ -360,30 +564,21 @@ func (r *Router) handleReconnectTimeout(roomCode, playerID string) {
 	)
 }
 
-// getRoleStrings converts role map to string map
-func getRoleStrings(roles map[string]entity.Role) map[string]string {
-	result := make(map[string]string)
-	for id, role := range roles {
-		result[id] = string(role)
-	}
-	return result
-}
-
 func (r *Router) handleReady(client *Client, msg *Message) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
 	var payload ReadyPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid ready payload")
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid ready payload")
 		return
 	}
 
 	err := r.roomService.SetReady(client.RoomCode, client.PlayerID, payload.Ready)
 	if err != nil {
-		client.SendError("ready_failed", "Failed to set ready state")
+		client.SendErrorCode(ErrCodeReadyFailed, "Failed to set ready state")
 		return
 	}
 
@@ -392,288 +587,1212 @@ func (r *Router) handleReady(client *Client, msg *Message) {
 		"player_id": client.PlayerID,
 		"ready":     payload.Ready,
 	}), nil)
+	r.broadcastLobbyStatus(client.RoomCode)
 }
 
 func (r *Router) handleUpdateSettings(client *Client, msg *Message) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
-	var payload SettingsPayload
-	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid settings payload")
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil {
+		client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
 		return
 	}
 
-	settings := entity.GameSettings{
-		Villagers:  payload.Villagers,
-		Mafia:      payload.Mafia,
-		Godfather:  payload.Godfather,
-		Doctor:     payload.Doctor,
-		Detective:  payload.Detective,
-		NightTimer: payload.NightTimer,
+	// Start from the current settings so omitted fields keep their values
+	payload := toSettingsPayload(room.Settings)
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid settings payload")
+		return
 	}
 
-	err := r.roomService.UpdateSettings(client.RoomCode, client.PlayerID, settings)
+	settings := toGameSettings(payload)
+
+	changes, err := r.roomService.UpdateSettings(client.RoomCode, client.PlayerID, settings)
 	if err != nil {
-		if err == entity.ErrNotHost {
-			client.SendError("not_host", "Only host can update settings")
-		} else {
-			client.SendError("settings_failed", "Failed to update settings")
+		switch err {
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can update settings")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameInProgress, "Only voice can be changed once the game has started")
+		case entity.ErrInvalidDuration:
+			client.SendErrorCode(ErrCodeInvalidDuration, "Phase duration out of range")
+		case entity.ErrInvalidMaxRevotes:
+			client.SendErrorCode(ErrCodeInvalidMaxRevotes, "Max revotes out of range")
+		case entity.ErrUnbalancedTeams:
+			client.SendErrorCode(ErrCodeUnbalancedTeams, unbalancedTeamsMessage(settings, room.PlayerCount()))
+		default:
+			client.SendErrorCode(ErrCodeSettingsFailed, "Failed to update settings")
 		}
 		return
 	}
 
 	// Broadcast settings change
-	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeSettingsUpdated, payload), nil)
-}
-
-func (r *Router) sendRoomState(client *Client, room *entity.Room) {
-	client.Send(MustMessage(EventTypeRoomState, map[string]any{
-		"room_code": room.Code,
-		"players":   toPlayerDTOs(room.GetPlayersDTO()),
-		"settings":  toSettingsPayload(room.Settings),
-		"state":     string(room.State),
-	}))
-}
-
-// Helper converters
-func toPlayerDTOs(dtos []entity.PlayerDTO) []PlayerDTO {
-	result := make([]PlayerDTO, len(dtos))
-	for i, dto := range dtos {
-		result[i] = toPlayerDTO(dto)
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeSettingsUpdated, SettingsUpdatedPayload{
+		SettingsPayload: payload,
+		Changes:         changes,
+	}), nil)
+	if !settings.VoiceEnabled {
+		r.dropVoice(client.RoomCode)
 	}
-	return result
+	r.broadcastLobbyStatus(client.RoomCode)
 }
 
-func toPlayerDTO(dto entity.PlayerDTO) PlayerDTO {
-	return PlayerDTO{
-		ID:          dto.ID,
-		Nickname:    dto.Nickname,
-		IsHost:      dto.IsHost,
-		IsReady:     dto.IsReady,
-		IsConnected: dto.IsConnected,
-		Status:      dto.Status,
+// dropVoice disconnects everyone in the room's voice chat, e.g. after the
+// host turned voice off
+func (r *Router) dropVoice(roomCode string) {
+	if r.sfu == nil {
+		return
 	}
-}
-
-func toSettingsPayload(s entity.GameSettings) SettingsPayload {
-	return SettingsPayload{
-		Villagers:  s.Villagers,
-		Mafia:      s.Mafia,
-		Godfather:  s.Godfather,
-		Doctor:     s.Doctor,
-		Detective:  s.Detective,
-		NightTimer: s.NightTimer,
+	voiceRoom := r.sfu.GetRoom(roomCode)
+	if voiceRoom == nil {
+		return
+	}
+	for _, playerID := range voiceRoom.GetParticipantIDs() {
+		r.sfu.LeaveVoice(roomCode, playerID)
+		r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypeVoiceLeft, VoiceLeftPayload{
+			PlayerID: playerID,
+		}), nil)
 	}
+	r.logger.Info("voice disabled, participants dropped", "room", roomCode)
 }
 
-// Game handlers
-
-func (r *Router) handleStartGame(client *Client) {
+func (r *Router) handleApplyPreset(client *Client, msg *Message) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload ApplyPresetPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid preset payload")
 		return
 	}
 
-	err := r.gameService.StartGame(client.RoomCode, client.PlayerID)
+	settings, changes, err := r.roomService.ApplyPreset(client.RoomCode, client.PlayerID, payload.Name)
 	if err != nil {
 		switch err {
 		case entity.ErrNotHost:
-			client.SendError("not_host", "Only host can start the game")
-		case entity.ErrNotEnoughPlayers:
-			client.SendError("not_enough_players", "Not enough players")
-		case entity.ErrNotAllReady:
-			client.SendError("not_all_ready", "Not all players are ready")
+			client.SendErrorCode(ErrCodeNotHost, "Only host can apply presets")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameInProgress, "Presets can only be applied in the lobby")
+		case entity.ErrPresetNotFound:
+			client.SendErrorCode(ErrCodePresetNotFound, "Unknown preset: "+payload.Name+" (available: "+strings.Join(entity.PresetNames(), ", ")+")")
+		case entity.ErrPresetDoesNotFit:
+			client.SendErrorCode(ErrCodePresetDoesNotFit, "Preset does not fit the current player count")
 		default:
-			client.SendError("start_failed", "Failed to start game: "+err.Error())
+			client.SendErrorCode(ErrCodeSettingsFailed, "Failed to apply preset")
 		}
 		return
 	}
 
-	r.logger.Info("game started", "room", client.RoomCode, "host", client.PlayerID)
+	// Broadcast settings change
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeSettingsUpdated, SettingsUpdatedPayload{
+		SettingsPayload: toSettingsPayload(settings),
+		Changes:         changes,
+	}), nil)
+	r.broadcastLobbyStatus(client.RoomCode)
 }
 
-func (r *Router) handleNightAction(client *Client, msg *Message) {
+// handleAutoBalance sets the room's role counts to a balanced setup for
+// everyone currently seated (host only)
+func (r *Router) handleAutoBalance(client *Client) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
-	var payload NightActionPayload
-	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid night action payload")
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil {
+		client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
 		return
 	}
 
-	err := r.gameService.SubmitNightAction(client.RoomCode, client.PlayerID, payload.TargetID)
+	balanced := r.gameService.AutoBalance(room.PlayerCount())
+	settings, changes, err := r.roomService.ApplyRoles(client.RoomCode, client.PlayerID, balanced)
 	if err != nil {
 		switch err {
-		case entity.ErrInvalidPhase:
-			client.SendError("invalid_phase", "Cannot perform night action now")
-		case entity.ErrPlayerDead:
-			client.SendError("player_dead", "Dead players cannot act")
-		case entity.ErrInvalidTarget:
-			client.SendError("invalid_target", "Invalid target")
-		case entity.ErrMafiaTargetMafia:
-			client.SendError("invalid_target", "Cannot target fellow mafia")
-		case entity.ErrCannotTargetSelf:
-			client.SendError("invalid_target", "Cannot target yourself")
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can balance roles")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameInProgress, "Roles can only be balanced in the lobby")
 		default:
-			client.SendError("action_failed", "Failed to submit action")
+			client.SendErrorCode(ErrCodeSettingsFailed, "Failed to balance roles")
 		}
 		return
 	}
+
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeSettingsUpdated, SettingsUpdatedPayload{
+		SettingsPayload: toSettingsPayload(settings),
+		Changes:         changes,
+	}), nil)
+	r.broadcastLobbyStatus(client.RoomCode)
 }
 
-func (r *Router) handleDayVote(client *Client, msg *Message) {
+func (r *Router) handleReturnToLobby(client *Client) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
-	var payload DayVotePayload
-	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid vote payload")
+	if err := r.roomService.ReturnToLobby(client.RoomCode, client.PlayerID); err != nil {
+		switch err {
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can return to the lobby")
+		case entity.ErrGameNotEnded:
+			client.SendErrorCode(ErrCodeGameNotEnded, "The game has not ended yet")
+		default:
+			client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		}
 		return
 	}
 
-	err := r.gameService.SubmitDayVote(client.RoomCode, client.PlayerID, payload.TargetID)
+	// An abandoned game can still be registered - make sure the next start is fresh
+	r.gameService.DiscardGame(client.RoomCode)
+
+	room, err := r.roomService.GetRoom(client.RoomCode)
 	if err != nil {
-		switch err {
-		case entity.ErrInvalidPhase:
-			client.SendError("invalid_phase", "Cannot vote now")
-		case entity.ErrPlayerDead:
-			client.SendError("player_dead", "Dead players cannot vote")
-		case entity.ErrInvalidTarget:
-			client.SendError("invalid_target", "Invalid target")
-		case entity.ErrCannotTargetSelf:
-			client.SendError("invalid_target", "Cannot vote for yourself")
-		default:
-			client.SendError("vote_failed", "Failed to submit vote")
-		}
 		return
 	}
+
+	r.hub.BroadcastToRoom(room.Code, MustMessage(EventTypeReturnedToLobby, ReturnedToLobbyPayload{
+		Players:  toPlayerDTOs(room.GetPlayersDTO()),
+		Settings: toSettingsPayload(room.Settings),
+	}), nil)
+	r.applyLobbyVoiceRouting(room, nil)
+	r.broadcastLobbyStatus(room.Code)
 }
 
-func (r *Router) handleGhostChat(client *Client, msg *Message) {
-	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+// unbalancedTeamsMessage describes why the mafia share was rejected
+func unbalancedTeamsMessage(s entity.GameSettings, playerCount int) string {
+	return "Mafia must be " + strconv.Itoa(s.MinMafiaPercent) + "-" + strconv.Itoa(s.MaxMafiaPercent) +
+		"% of players (have " + strconv.Itoa(s.Mafia+s.Godfather) + " of " + strconv.Itoa(playerCount) + ")"
+}
+
+// broadcastLobbyStatus tells a waiting room whether the game can be started
+func (r *Router) broadcastLobbyStatus(roomCode string) {
+	room, err := r.roomService.GetRoom(roomCode)
+	if err != nil || room.State != entity.RoomStateWaiting {
 		return
 	}
 
-	var payload GhostChatPayload
-	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid ghost chat payload")
+	// Readiness may have changed, which arms or cancels an auto-start
+	r.gameService.UpdateAutoStart(roomCode)
+
+	status, err := r.roomService.LobbyStatus(roomCode)
+	if err != nil {
 		return
 	}
+	r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypeLobbyStatus, LobbyStatusPayload{
+		CanStart:    status.CanStart,
+		Reason:      status.Reason,
+		ReadyCount:  status.ReadyCount,
+		PlayerCount: status.PlayerCount,
 
-	// Validate message
-	if payload.Message == "" || len(payload.Message) > 500 {
-		client.SendError("invalid_message", "Message must be 1-500 characters")
+		VoiceEnabled:     r.sfu != nil && room.Settings.VoiceEnabled,
+		VoiceUnavailable: r.sfu == nil,
+	}), nil)
+}
+
+func (r *Router) handleAddBot(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
-	// Get game and verify player is dead
-	game := r.gameService.GetGame(client.RoomCode)
-	if game == nil {
-		client.SendError("game_not_found", "Game not found")
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil {
+		client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		return
+	}
+	if host := room.GetHost(); host == nil || host.ID != client.PlayerID {
+		client.SendErrorCode(ErrCodeNotHost, "Only host can add bots")
 		return
 	}
 
-	player := game.Room.GetPlayer(client.PlayerID)
-	if player == nil {
-		client.SendError("player_not_found", "Player not found")
+	bot, err := r.roomService.AddBot(client.RoomCode)
+	if err != nil {
+		switch err {
+		case entity.ErrRoomFull:
+			client.SendErrorCode(ErrCodeRoomFull, "Room is full")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameStarted, "Bots can only be added in the lobby")
+		default:
+			client.SendErrorCode(ErrCodeAddBotFailed, "Failed to add bot")
+		}
 		return
 	}
 
-	if player.Status != entity.PlayerStatusDead {
-		client.SendError("not_dead", "Only dead players can use ghost chat")
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypePlayerJoined, PlayerJoinedPayload{
+		Player: toPlayerDTO(bot.ToDTO()),
+	}), nil)
+	r.broadcastLobbyStatus(client.RoomCode)
+}
+
+// handleRedealRoles pins the next deal's seed so QA can reproduce a deal
+func (r *Router) handleRedealRoles(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
-	// Get all dead player IDs
-	var deadPlayerIDs []string
-	for _, p := range game.Room.Players {
-		if p.Status == entity.PlayerStatusDead {
-			deadPlayerIDs = append(deadPlayerIDs, p.ID)
+	var payload RedealRolesPayload
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			client.SendErrorCode(ErrCodeInvalidPayload, "Invalid redeal payload")
+			return
 		}
 	}
 
-	// Broadcast to all dead players
-	broadcastPayload := GhostChatBroadcastPayload{
-		FromID:       client.PlayerID,
-		FromNickname: player.Nickname,
-		Message:      payload.Message,
-		Timestamp:    time.Now().UnixMilli(),
+	seed, err := r.gameService.RedealRoles(client.RoomCode, client.PlayerID, payload.Seed)
+	if err != nil {
+		switch err {
+		case entity.ErrRoomNotFound:
+			client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can redeal roles")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameStarted, "Roles can only be redealt in the lobby")
+		case service.ErrDebugDisabled:
+			client.SendErrorCode(ErrCodeDebugDisabled, "Redealing roles is only available in development")
+		default:
+			client.SendErrorCode(ErrCodeStartFailed, "Failed to redeal roles")
+		}
+		return
 	}
 
-	r.hub.BroadcastToPlayers(client.RoomCode, deadPlayerIDs, MustMessage(EventTypeGhostChatBroadcast, broadcastPayload))
-
-	r.logger.Debug("ghost chat sent",
-		"room", client.RoomCode,
-		"from", client.PlayerID,
-		"message_len", len(payload.Message),
-	)
+	client.Send(MustMessage(EventTypeDealSeedPinned, DealSeedPinnedPayload{Seed: seed}))
 }
 
-// --- Voice handlers ---
+// invalidNicknameMessage explains the nickname rules to clients
+var invalidNicknameMessage = "Nickname must be 1-" + strconv.Itoa(entity.MaxNicknameLength) + " characters"
 
-func (r *Router) handleVoiceJoin(client *Client) {
+// invalidPasswordMessage explains the room password rules to clients
+var invalidPasswordMessage = "Password must be at most " + strconv.Itoa(entity.MaxPasswordLength) + " printable characters"
+
+func (r *Router) handleChangeNickname(client *Client, msg *Message) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
-	if r.sfu == nil {
-		client.SendError("voice_unavailable", "Voice chat is not available")
+	var payload ChangeNicknamePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid change nickname payload")
 		return
 	}
 
-	participant, err := r.sfu.JoinVoice(client.RoomCode, client.PlayerID)
+	nickname, err := r.roomService.ChangeNickname(client.RoomCode, client.PlayerID, payload.Nickname)
 	if err != nil {
-		client.SendError("voice_join_failed", "Failed to join voice: "+err.Error())
-		return
+		switch err {
+		case entity.ErrInvalidNickname:
+			client.SendErrorCode(ErrCodeInvalidNickname, invalidNicknameMessage)
+		case entity.ErrNicknameInUse:
+			client.SendErrorCode(ErrCodeNicknameInUse, "Nickname already in use")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameStarted, "Nickname can only be changed in the lobby")
+		default:
+			client.SendErrorCode(ErrCodePlayerNotFound, "Player is not in this room")
+		}
+		return
+	}
+
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeNicknameChanged, NicknameChangedPayload{
+		PlayerID: client.PlayerID,
+		Nickname: nickname,
+	}), nil)
+}
+
+func (r *Router) handleTransferHost(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload TransferHostPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid transfer host payload")
+		return
+	}
+
+	if err := r.roomService.TransferHost(client.RoomCode, client.PlayerID, payload.TargetID); err != nil {
+		switch err {
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can transfer host")
+		case entity.ErrPlayerNotFound:
+			client.SendErrorCode(ErrCodePlayerNotFound, "Player is not in this room")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameStarted, "Host can only be transferred in the lobby")
+		case entity.ErrBotNotAllowed:
+			client.SendErrorCode(ErrCodeTransferFailed, "Bots cannot be host")
+		default:
+			client.SendErrorCode(ErrCodeTransferFailed, "Failed to transfer host")
+		}
+		return
+	}
+
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeHostChanged, HostChangedPayload{
+		HostID:         payload.TargetID,
+		PreviousHostID: client.PlayerID,
+	}), nil)
+}
+
+// handleAssignRole records a host's hand-dealt role. Only the host is told,
+// so the lobby cannot see who will get what.
+func (r *Router) handleAssignRole(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload AssignRolePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid assign role payload")
+		return
+	}
+
+	forced, err := r.roomService.AssignRole(client.RoomCode, client.PlayerID, payload.PlayerID, entity.Role(payload.Role))
+	if err != nil {
+		switch err {
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can assign roles")
+		case entity.ErrPlayerNotFound:
+			client.SendErrorCode(ErrCodePlayerNotFound, "Player is not in this room")
+		case entity.ErrGameAlreadyStarted:
+			client.SendErrorCode(ErrCodeGameStarted, "Roles can only be assigned in the lobby")
+		case entity.ErrUnknownRole:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "Unknown role: "+payload.Role)
+		case entity.ErrForcedRolesExceedCounts:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "More "+payload.Role+" assignments than the settings deal")
+		case entity.ErrTooManyRoles:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "More special roles than players")
+		default:
+			client.SendErrorCode(ErrCodeSettingsFailed, "Failed to assign role")
+		}
+		return
+	}
+
+	assignments := make(map[string]string, len(forced))
+	for playerID, role := range forced {
+		assignments[playerID] = string(role)
+	}
+	client.Send(MustMessage(EventTypeForcedRoles, ForcedRolesPayload{Assignments: assignments}))
+}
+
+func (r *Router) sendRoomState(client *Client, room *entity.Room) {
+	client.Send(MustMessage(EventTypeRoomState, map[string]any{
+		"room_code":   room.Code,
+		"players":     toPlayerDTOs(room.GetPlayersDTO()),
+		"settings":    toSettingsPayload(room.Settings),
+		"state":       string(room.State),
+		"max_players": room.MaxPlayers,
+	}))
+}
+
+func (r *Router) handleRequestState(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil {
+		client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		return
+	}
+
+	r.sendGameState(client, room)
+}
+
+// handleResync serves a client that detected a gap in sequence numbers. The
+// reply is the same snapshot as request_state; its envelope takes the
+// connection's next sequence number, from which the client continues.
+func (r *Router) handleResync(client *Client) {
+	if !client.allowResync(time.Now()) {
+		client.SendErrorCode(ErrCodeRateLimited, "Resync requested too often")
+		return
+	}
+
+	client.Logger().Info("client requested resync")
+	r.handleRequestState(client)
+}
+
+// handleGetTimeline replies with what happened in earlier rounds, for
+// players catching up after being away
+func (r *Router) handleGetTimeline(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	game := r.gameService.GetGame(client.RoomCode)
+	if game == nil {
+		client.SendErrorCode(ErrCodeGameNotFound, "No game in progress")
+		return
+	}
+
+	client.Send(MustMessage(EventTypeTimeline, TimelinePayload{
+		Rounds: game.GetTimeline(),
+	}))
+}
+
+// sendGameState sends a single consolidated snapshot of room and game state
+func (r *Router) sendGameState(client *Client, room *entity.Room) {
+	state := r.gameService.GetGameState(room.Code, client.PlayerID)
+	if state == nil {
+		// No game in progress - lobby or finished
+		state = map[string]any{}
+	}
+
+	state["room_code"] = room.Code
+	state["room_state"] = string(room.State)
+	state["players"] = toPlayerDTOs(room.GetPlayersDTO())
+	state["settings"] = toSettingsPayload(room.Settings)
+	state["max_players"] = room.MaxPlayers
+
+	client.Send(MustMessage(EventTypeGameState, state))
+}
+
+// Helper converters
+func toPlayerDTOs(dtos []entity.PlayerDTO) []PlayerDTO {
+	result := make([]PlayerDTO, len(dtos))
+	for i, dto := range dtos {
+		result[i] = toPlayerDTO(dto)
+	}
+	return result
+}
+
+func toPlayerDTO(dto entity.PlayerDTO) PlayerDTO {
+	return PlayerDTO{
+		ID:          dto.ID,
+		Nickname:    dto.Nickname,
+		IsHost:      dto.IsHost,
+		IsReady:     dto.IsReady,
+		IsConnected: dto.IsConnected,
+		Status:      dto.Status,
+		IsBot:       dto.IsBot,
+	}
+}
+
+func toSettingsPayload(s entity.GameSettings) SettingsPayload {
+	return SettingsPayload{
+		Villagers:  s.Villagers,
+		Mafia:      s.Mafia,
+		Godfather:  s.Godfather,
+		Doctor:     s.Doctor,
+		Detective:  s.Detective,
+		NightTimer: s.NightTimer,
+
+		DiscussionSeconds:    s.DiscussionSeconds,
+		RoleRevealSeconds:    s.RoleRevealSeconds,
+		DaySeconds:           s.DaySeconds,
+		ResultSeconds:        s.ResultSeconds,
+		LimitDoctorSelfHeals: s.LimitDoctorSelfHeals,
+		DoctorSelfHealLimit:  s.DoctorSelfHealLimit,
+		AllowFirstNightKill:  s.AllowFirstNightKill,
+		RevealRolesOnDeath:   s.RevealRolesOnDeath,
+
+		TieBreak:         string(s.TieBreak),
+		MaxRevotes:       s.MaxRevotes,
+		VoteThreshold:    string(s.VoteThreshold),
+		SkipDisconnected: s.SkipDisconnected,
+
+		RejectRepeatInvestigations: s.RejectRepeatInvestigations,
+		DeadCanSeeLiveChat:         s.DeadCanSeeLiveChat,
+
+		MinMafiaPercent: s.MinMafiaPercent,
+		MaxMafiaPercent: s.MaxMafiaPercent,
+		AllowUnbalanced: s.AllowUnbalanced,
+
+		GodfatherDecides:    s.GodfatherDecides,
+		LockVoteAfterSubmit: s.LockVoteAfterSubmit,
+		AllowActionChange:   s.AllowActionChange,
+		HiddenVoting:        s.HiddenVoting,
+		AnonymousVoting:     s.AnonymousVoting,
+		Lovers:              s.Lovers,
+		Cupid:               s.Cupid,
+
+		DoctorNoConsecutiveSameTarget: s.DoctorNoConsecutiveSameTarget,
+		AutoStartSeconds:              s.AutoStartSeconds,
+		VoiceEnabled:                  s.VoiceEnabled,
+		DeadDayWhisper:                s.DeadDayWhisper,
+		DeadNightChat:                 s.DeadNightChat,
+		TurnBasedVoting:               s.TurnBasedVoting,
+		GameStats:                     s.GameStats,
+		ConfirmGameEndingLynch:        s.ConfirmGameEndingLynch,
+	}
+}
+
+func toGameSettings(p SettingsPayload) entity.GameSettings {
+	return entity.GameSettings{
+		Villagers:  p.Villagers,
+		Mafia:      p.Mafia,
+		Godfather:  p.Godfather,
+		Doctor:     p.Doctor,
+		Detective:  p.Detective,
+		NightTimer: p.NightTimer,
+
+		DiscussionSeconds:    p.DiscussionSeconds,
+		RoleRevealSeconds:    p.RoleRevealSeconds,
+		DaySeconds:           p.DaySeconds,
+		ResultSeconds:        p.ResultSeconds,
+		LimitDoctorSelfHeals: p.LimitDoctorSelfHeals,
+		DoctorSelfHealLimit:  p.DoctorSelfHealLimit,
+		AllowFirstNightKill:  p.AllowFirstNightKill,
+		RevealRolesOnDeath:   p.RevealRolesOnDeath,
+
+		TieBreak:         entity.TieBreak(p.TieBreak),
+		MaxRevotes:       p.MaxRevotes,
+		VoteThreshold:    entity.VoteThreshold(p.VoteThreshold),
+		SkipDisconnected: p.SkipDisconnected,
+
+		RejectRepeatInvestigations: p.RejectRepeatInvestigations,
+		DeadCanSeeLiveChat:         p.DeadCanSeeLiveChat,
+
+		MinMafiaPercent: p.MinMafiaPercent,
+		MaxMafiaPercent: p.MaxMafiaPercent,
+		AllowUnbalanced: p.AllowUnbalanced,
+
+		GodfatherDecides:    p.GodfatherDecides,
+		LockVoteAfterSubmit: p.LockVoteAfterSubmit,
+		AllowActionChange:   p.AllowActionChange,
+		HiddenVoting:        p.HiddenVoting,
+		AnonymousVoting:     p.AnonymousVoting,
+		Lovers:              p.Lovers,
+		Cupid:               p.Cupid,
+
+		DoctorNoConsecutiveSameTarget: p.DoctorNoConsecutiveSameTarget,
+		AutoStartSeconds:              p.AutoStartSeconds,
+		VoiceEnabled:                  p.VoiceEnabled,
+		DeadDayWhisper:                p.DeadDayWhisper,
+		DeadNightChat:                 p.DeadNightChat,
+		TurnBasedVoting:               p.TurnBasedVoting,
+		GameStats:                     p.GameStats,
+		ConfirmGameEndingLynch:        p.ConfirmGameEndingLynch,
+	}
+}
+
+// Game handlers
+
+func (r *Router) handleStartGame(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	// Payload is optional - older clients send none
+	var payload StartGamePayload
+	if len(msg.Payload) > 0 {
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			client.SendErrorCode(ErrCodeInvalidPayload, "Invalid start game payload")
+			return
+		}
+	}
+
+	var err error
+	if payload.Force {
+		err = r.gameService.ForceStartGame(client.RoomCode, client.PlayerID)
+	} else {
+		err = r.gameService.StartGame(client.RoomCode, client.PlayerID)
+	}
+	if err != nil {
+		switch err {
+		case service.ErrStartQueued:
+			// The room is told its queue position by a game_queued event
+			client.Logger().Info("game start queued")
+		case service.ErrTooManyActiveGames:
+			client.SendErrorCode(ErrCodeTooManyGames, "The server is running as many games as it can; try again shortly")
+		case entity.ErrNotHost:
+			client.SendErrorCode(ErrCodeNotHost, "Only host can start the game")
+		case entity.ErrNotEnoughPlayers:
+			client.SendErrorCode(ErrCodeNotEnoughPlayers, "Not enough players")
+		case entity.ErrNotAllReady:
+			client.SendErrorCode(ErrCodeNotAllReady, "Not all players are ready")
+		case entity.ErrTooManyRoles:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "More special roles than players")
+		case entity.ErrForcedRolesExceedCounts:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "Assigned roles no longer fit the settings")
+		case entity.ErrUnbalancedTeams:
+			if room, roomErr := r.roomService.GetRoom(client.RoomCode); roomErr == nil {
+				client.SendErrorCode(ErrCodeUnbalancedTeams, unbalancedTeamsMessage(room.Settings, room.PlayerCount()))
+			}
+		default:
+			client.SendErrorCode(ErrCodeStartFailed, "Failed to start game: "+err.Error())
+		}
+		return
+	}
+
+	client.Logger().Info("game started")
+}
+
+func (r *Router) handlePreviewRoles(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	counts, err := r.gameService.PreviewRoles(client.RoomCode)
+	if err != nil {
+		switch err {
+		case entity.ErrRoomNotFound:
+			client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		case entity.ErrTooManyRoles:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "More special roles than players")
+		default:
+			client.SendErrorCode(ErrCodeInvalidRoleConfig, "Failed to preview roles: "+err.Error())
+		}
+		return
+	}
+
+	roles := make(map[string]int, len(counts))
+	playerCount := 0
+	for role, count := range counts {
+		roles[string(role)] = count
+		playerCount += count
+	}
+
+	client.Send(MustMessage(EventTypeRolePreview, RolePreviewPayload{
+		PlayerCount: playerCount,
+		Roles:       roles,
+	}))
+}
+
+func (r *Router) handleNightAction(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload NightActionPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid night action payload")
+		return
+	}
+
+	err := r.gameService.SubmitNightAction(client.RoomCode, client.PlayerID, payload.TargetID)
+	if err != nil {
+		switch err {
+		case entity.ErrInvalidPhase:
+			client.SendErrorCode(ErrCodeInvalidPhase, "Cannot perform night action now")
+		case entity.ErrPlayerDead:
+			client.SendErrorCode(ErrCodePlayerDead, "Dead players cannot act")
+		case entity.ErrInvalidTarget:
+			client.SendErrorCode(ErrCodeInvalidTarget, "Invalid target")
+		case entity.ErrMafiaTargetMafia:
+			client.SendErrorCode(ErrCodeInvalidTarget, "Cannot target fellow mafia")
+		case entity.ErrCannotTargetSelf:
+			client.SendErrorCode(ErrCodeInvalidTarget, "Cannot target yourself")
+		case entity.ErrSelfHealLimit:
+			client.SendErrorCode(ErrCodeSelfHealLimit, "You can no longer protect yourself")
+		case entity.ErrAlreadyInvestigated:
+			client.SendErrorCode(ErrCodeAlreadyInvestigated, "You already investigated this player")
+		case entity.ErrSameTargetTwice:
+			client.SendErrorCode(ErrCodeSameTargetTwice, "You protected this player last night")
+		case entity.ErrAlreadyActed:
+			client.SendErrorCode(ErrCodeAlreadyActed, "Your action for tonight is already locked in")
+		default:
+			client.SendErrorCode(ErrCodeActionFailed, "Failed to submit action")
+		}
+		return
+	}
+}
+
+func (r *Router) handleCupidSelect(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload CupidSelectPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil || len(payload.TargetIDs) != 2 {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Pick exactly two players")
+		return
+	}
+
+	err := r.gameService.SubmitCupidPick(client.RoomCode, client.PlayerID, payload.TargetIDs[0], payload.TargetIDs[1])
+	if err != nil {
+		switch err {
+		case entity.ErrGameNotStarted:
+			client.SendErrorCode(ErrCodeGameNotFound, "Game not found")
+		case entity.ErrInvalidPhase:
+			client.SendErrorCode(ErrCodeInvalidPhase, "Cupid can only pick before the first night")
+		case entity.ErrNotCupid:
+			client.SendErrorCode(ErrCodeNotCupid, "Only Cupid can pick the lovers")
+		case entity.ErrInvalidTarget:
+			client.SendErrorCode(ErrCodeInvalidTarget, "Pick two different living players")
+		case entity.ErrAlreadyActed:
+			client.SendErrorCode(ErrCodeAlreadyActed, "The lovers are already picked")
+		default:
+			client.SendErrorCode(ErrCodeActionFailed, "Failed to pick the lovers")
+		}
+	}
+}
+
+func (r *Router) handlePassTurn(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	if err := r.gameService.PassSpeakingTurn(client.RoomCode, client.PlayerID); err != nil {
+		switch err {
+		case entity.ErrGameNotStarted:
+			client.SendErrorCode(ErrCodeGameNotFound, "Game not found")
+		case entity.ErrInvalidPhase:
+			client.SendErrorCode(ErrCodeInvalidPhase, "Nobody is taking turns to speak")
+		case entity.ErrNotYourTurn:
+			client.SendErrorCode(ErrCodeNotYourTurn, "It is not your turn to speak")
+		default:
+			client.SendErrorCode(ErrCodeActionFailed, "Failed to pass your turn")
+		}
+	}
+}
+
+func (r *Router) handleConfirmKill(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	if err := r.gameService.ConfirmKill(client.RoomCode, client.PlayerID); err != nil {
+		switch err {
+		case entity.ErrGameNotStarted:
+			client.SendErrorCode(ErrCodeGameNotFound, "Game not found")
+		case entity.ErrInvalidPhase:
+			client.SendErrorCode(ErrCodeInvalidPhase, "Kills can only be confirmed at night")
+		case entity.ErrPlayerDead:
+			client.SendErrorCode(ErrCodePlayerDead, "Dead players cannot act")
+		case entity.ErrNotGodfather:
+			client.SendErrorCode(ErrCodeNotGodfather, "Only the godfather can confirm the kill")
+		case entity.ErrConfirmNotRequired:
+			client.SendErrorCode(ErrCodeConfirmNotRequired, "This room does not use kill confirmation")
+		case entity.ErrInvalidTarget:
+			client.SendErrorCode(ErrCodeInvalidTarget, "The mafia has not chosen a target")
+		default:
+			client.SendErrorCode(ErrCodeActionFailed, "Failed to confirm kill: "+err.Error())
+		}
+	}
+}
+
+func (r *Router) handleDayVote(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload DayVotePayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid vote payload")
+		return
+	}
+
+	err := r.gameService.SubmitDayVote(client.RoomCode, client.PlayerID, payload.TargetID)
+	if err != nil {
+		switch err {
+		case entity.ErrInvalidPhase:
+			client.SendErrorCode(ErrCodeInvalidPhase, "Cannot vote now")
+		case entity.ErrVotingNotOpen:
+			client.SendErrorCode(ErrCodeVotingNotOpen, "Voting opens after discussion")
+		case entity.ErrPlayerDead:
+			client.SendErrorCode(ErrCodePlayerDead, "Dead players cannot vote")
+		case entity.ErrInvalidTarget:
+			client.SendErrorCode(ErrCodeInvalidTarget, "Invalid target")
+		case entity.ErrCannotTargetSelf:
+			client.SendErrorCode(ErrCodeInvalidTarget, "Cannot vote for yourself")
+		case entity.ErrVoteLocked:
+			client.SendErrorCode(ErrCodeVoteLocked, "Your vote is locked in")
+		default:
+			client.SendErrorCode(ErrCodeVoteFailed, "Failed to submit vote")
+		}
+		return
+	}
+}
+
+func (r *Router) handleConfirmLynch(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload ConfirmLynchPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid confirm lynch payload")
+		return
+	}
+
+	err := r.gameService.SubmitLynchConfirm(client.RoomCode, client.PlayerID, payload.Confirm)
+	if err != nil {
+		switch err {
+		case entity.ErrGameNotStarted:
+			client.SendErrorCode(ErrCodeGameNotFound, "No game in progress")
+		case entity.ErrInvalidPhase:
+			client.SendErrorCode(ErrCodeInvalidPhase, "No lynch is waiting for confirmation")
+		case entity.ErrPlayerDead:
+			client.SendErrorCode(ErrCodePlayerDead, "Dead players cannot vote")
+		default:
+			client.SendErrorCode(ErrCodeVoteFailed, "Failed to submit confirmation")
+		}
+	}
+}
+
+// handleLobbyChat relays a message to everyone waiting in the lobby. The
+// chat closes when the game starts; day and ghost chat take over from there.
+func (r *Router) handleLobbyChat(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload LobbyChatPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid lobby chat payload")
+		return
+	}
+
+	// Validate message
+	if payload.Message == "" || len(payload.Message) > 500 {
+		client.SendErrorCode(ErrCodeInvalidMessage, "Message must be 1-500 characters")
+		return
+	}
+
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil {
+		client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		return
+	}
+
+	if room.State != entity.RoomStateWaiting {
+		client.SendErrorCode(ErrCodeGameStarted, "Lobby chat is closed once the game starts")
+		return
+	}
+
+	player := room.GetPlayer(client.PlayerID)
+	if player == nil {
+		client.SendErrorCode(ErrCodePlayerNotFound, "Player not found")
+		return
+	}
+
+	if !client.allowLobbyChat(time.Now()) {
+		client.SendErrorCode(ErrCodeRateLimited, "Sending messages too quickly")
+		return
+	}
+
+	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeLobbyChat, LobbyChatBroadcastPayload{
+		FromID:       client.PlayerID,
+		FromNickname: player.Nickname,
+		Message:      payload.Message,
+		Timestamp:    time.Now().UnixMilli(),
+	}), nil)
+
+	client.Logger().Debug("lobby chat sent", "message_len", len(payload.Message))
+}
+
+func (r *Router) handleGhostChat(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
 	}
 
-	// Set up ICE candidate handler
-	if participant.PeerConn != nil {
-		participant.PeerConn.OnICECandidate(func(candidate *webrtc.ICECandidate) {
-			if candidate == nil {
-				return
-			}
-			candidateJSON := candidate.ToJSON()
-			usernameFrag := ""
-			if candidateJSON.UsernameFragment != nil {
-				usernameFrag = *candidateJSON.UsernameFragment
-			}
-			client.Send(MustMessage(EventTypeVoiceCandidate, VoiceCandidatePayload{
-				Candidate:        candidateJSON.Candidate,
-				SDPMid:           *candidateJSON.SDPMid,
-				SDPMLineIndex:    *candidateJSON.SDPMLineIndex,
-				UsernameFragment: usernameFrag,
-			}))
-		})
+	var payload GhostChatPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid ghost chat payload")
+		return
+	}
 
-		// Handle incoming audio tracks
-		participant.PeerConn.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
-			r.logger.Debug("received audio track",
-				"player", client.PlayerID,
-				"track", track.ID(),
-			)
-		})
+	// Validate message
+	if payload.Message == "" || len(payload.Message) > 500 {
+		client.SendErrorCode(ErrCodeInvalidMessage, "Message must be 1-500 characters")
+		return
+	}
+
+	// Get game and verify player is dead
+	game := r.gameService.GetGame(client.RoomCode)
+	if game == nil {
+		client.SendErrorCode(ErrCodeGameNotFound, "Game not found")
+		return
+	}
+
+	player := game.Room.GetPlayer(client.PlayerID)
+	if player == nil {
+		client.SendErrorCode(ErrCodePlayerNotFound, "Player not found")
+		return
+	}
+
+	if player.Status != entity.PlayerStatusDead {
+		client.SendErrorCode(ErrCodeNotDead, "Only dead players can use ghost chat")
+		return
+	}
+
+	// Get all dead player IDs
+	var deadPlayerIDs []string
+	for _, p := range game.Room.Players {
+		if p.Status == entity.PlayerStatusDead {
+			deadPlayerIDs = append(deadPlayerIDs, p.ID)
+		}
+	}
+
+	// Broadcast to all dead players
+	broadcastPayload := GhostChatBroadcastPayload{
+		FromID:       client.PlayerID,
+		FromNickname: player.Nickname,
+		Message:      payload.Message,
+		Timestamp:    time.Now().UnixMilli(),
+	}
+
+	r.hub.BroadcastToPlayers(client.RoomCode, deadPlayerIDs, MustMessage(EventTypeGhostChatBroadcast, broadcastPayload))
+
+	client.Logger().Debug("ghost chat sent", "message_len", len(payload.Message))
+}
+
+func (r *Router) handleDayChat(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload DayChatPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid day chat payload")
+		return
+	}
+
+	// Validate message
+	if payload.Message == "" || len(payload.Message) > 500 {
+		client.SendErrorCode(ErrCodeInvalidMessage, "Message must be 1-500 characters")
+		return
+	}
+
+	game := r.gameService.GetGame(client.RoomCode)
+	if game == nil {
+		client.SendErrorCode(ErrCodeGameNotFound, "Game not found")
+		return
+	}
+
+	if phase := game.GetPhase(); phase != entity.PhaseDayDiscussion && phase != entity.PhaseDay {
+		client.SendErrorCode(ErrCodeInvalidPhase, "Day chat is only open during the day")
+		return
+	}
+
+	player := game.Room.GetPlayer(client.PlayerID)
+	if player == nil {
+		client.SendErrorCode(ErrCodePlayerNotFound, "Player not found")
+		return
+	}
+
+	// Dead players never post into the living chat, even when they can read it
+	if player.Status != entity.PlayerStatusAlive {
+		client.SendErrorCode(ErrCodePlayerDead, "Dead players cannot post in day chat")
+		return
+	}
+
+	broadcastPayload := DayChatBroadcastPayload{
+		FromID:       client.PlayerID,
+		FromNickname: player.Nickname,
+		Message:      payload.Message,
+		Timestamp:    time.Now().UnixMilli(),
+	}
+
+	r.hub.BroadcastToPlayers(client.RoomCode, dayChatReaders(game), MustMessage(EventTypeDayChatBroadcast, broadcastPayload))
+
+	client.Logger().Debug("day chat sent", "message_len", len(payload.Message))
+}
+
+// dayChatReaders returns the players who read the day chat: the living, and
+// the dead too when the room allows it
+func dayChatReaders(game *entity.Game) []string {
+	deadCanSee := game.Room.Settings.DeadCanSeeLiveChat
+	var recipientIDs []string
+	for _, p := range game.Room.Players {
+		if p.Status == entity.PlayerStatusAlive || deadCanSee {
+			recipientIDs = append(recipientIDs, p.ID)
+		}
+	}
+	return recipientIDs
+}
+
+// handleClaimInvestigation relays a living player's claimed investigation
+// result to the day chat. The claim is never checked against real results;
+// bluffing is part of the game.
+func (r *Router) handleClaimInvestigation(client *Client, msg *Message) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	var payload ClaimInvestigationPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid investigation claim payload")
+		return
+	}
+
+	game := r.gameService.GetGame(client.RoomCode)
+	if game == nil {
+		client.SendErrorCode(ErrCodeGameNotFound, "Game not found")
+		return
+	}
+
+	if phase := game.GetPhase(); phase != entity.PhaseDayDiscussion && phase != entity.PhaseDay {
+		client.SendErrorCode(ErrCodeInvalidPhase, "Claims can only be made during the day")
+		return
+	}
+
+	player := game.Room.GetPlayer(client.PlayerID)
+	if player == nil {
+		client.SendErrorCode(ErrCodePlayerNotFound, "Player not found")
+		return
+	}
+	if player.Status != entity.PlayerStatusAlive {
+		client.SendErrorCode(ErrCodePlayerDead, "Dead players cannot make claims")
+		return
+	}
+
+	target := game.Room.GetPlayer(payload.TargetID)
+	if target == nil {
+		client.SendErrorCode(ErrCodeInvalidTarget, "Unknown claim target")
+		return
+	}
+
+	r.hub.BroadcastToPlayers(client.RoomCode, dayChatReaders(game), MustMessage(EventTypeInvestigationClaim, InvestigationClaimPayload{
+		FromID:         client.PlayerID,
+		FromNickname:   player.Nickname,
+		TargetID:       target.ID,
+		TargetNickname: target.Nickname,
+		IsMafia:        payload.IsMafia,
+		Timestamp:      time.Now().UnixMilli(),
+	}))
+
+	client.Logger().Debug("investigation claimed", "target", target.ID)
+}
+
+// --- Voice handlers ---
+
+func (r *Router) handleVoiceJoin(client *Client) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	if r.sfu == nil {
+		client.SendErrorCode(ErrCodeVoiceUnavailable, "Voice chat is not available")
+		return
+	}
+
+	if r.voiceDisabled(client) {
+		return
+	}
+
+	if _, err := r.joinVoice(client); err != nil {
+		client.SendErrorCode(ErrCodeVoiceJoinFailed, "Failed to join voice: "+err.Error())
+	}
+}
+
+// voiceDisabled tells the client and returns true when the host turned
+// voice off for their room
+func (r *Router) voiceDisabled(client *Client) bool {
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil || room.Settings.VoiceEnabled {
+		return false
+	}
+	client.SendErrorCode(ErrCodeVoiceDisabled, "Voice chat is turned off in this room")
+	return true
+}
+
+// joinVoice creates the player's SFU participant and announces them to the room
+func (r *Router) joinVoice(client *Client) (*sfu.Participant, error) {
+	participant, err := r.sfu.JoinVoice(client.RoomCode, client.PlayerID)
+	if err != nil {
+		return nil, err
 	}
 
+	r.attachPeerHandlers(client, participant)
+
+	// A fresh participant has no permissions yet; after a reconnect the old
+	// ones died with it. Route the whole room for the current phase.
+	r.refreshVoiceRouting(client.RoomCode, nil)
+
 	// Notify others in room
 	r.hub.BroadcastToRoom(client.RoomCode, MustMessage(EventTypeVoiceJoined, VoiceJoinedPayload{
 		PlayerID: client.PlayerID,
 	}), client)
 
-	r.logger.Info("player joined voice",
-		"room", client.RoomCode,
-		"player", client.PlayerID,
-	)
+	client.Logger().Info("player joined voice")
+
+	return participant, nil
+}
+
+// attachPeerHandlers wires ICE and track callbacks on a participant's peer connection
+func (r *Router) attachPeerHandlers(client *Client, participant *sfu.Participant) {
+	if participant.PeerConn == nil {
+		return
+	}
+
+	// Set up ICE candidate handler
+	participant.PeerConn.OnICECandidate(func(candidate *webrtc.ICECandidate) {
+		if candidate == nil {
+			return
+		}
+		candidateJSON := candidate.ToJSON()
+		usernameFrag := ""
+		if candidateJSON.UsernameFragment != nil {
+			usernameFrag = *candidateJSON.UsernameFragment
+		}
+		client.Send(MustMessage(EventTypeVoiceCandidate, VoiceCandidatePayload{
+			Candidate:        candidateJSON.Candidate,
+			SDPMid:           *candidateJSON.SDPMid,
+			SDPMLineIndex:    *candidateJSON.SDPMLineIndex,
+			UsernameFragment: usernameFrag,
+		}))
+	})
+
+	// Handle incoming audio tracks
+	participant.PeerConn.OnTrack(func(track *webrtc.TrackRemote, receiver *webrtc.RTPReceiver) {
+		client.Logger().Debug("received audio track", "track", track.ID())
+		r.sfu.ConsumeTrack(client.RoomCode, client.PlayerID, track)
+	})
+}
+
+// handlePersonalMute removes (or restores) one player in the sender's voice
+// routing. Only the sender is told; nobody else learns they were muted.
+func (r *Router) handlePersonalMute(client *Client, msg *Message, muted bool) {
+	if client.RoomCode == "" {
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
+		return
+	}
+
+	if r.sfu == nil {
+		client.SendErrorCode(ErrCodeVoiceUnavailable, "Voice chat is not available")
+		return
+	}
+
+	var payload MutePlayerPayload
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid mute payload")
+		return
+	}
+
+	room, err := r.roomService.GetRoom(client.RoomCode)
+	if err != nil {
+		client.SendErrorCode(ErrCodeRoomNotFound, "Room not found")
+		return
+	}
+	if payload.TargetID == client.PlayerID || room.GetPlayer(payload.TargetID) == nil {
+		client.SendErrorCode(ErrCodeInvalidTarget, "Invalid mute target")
+		return
+	}
+
+	if err := r.sfu.SetPersonalMute(client.RoomCode, client.PlayerID, payload.TargetID, muted); err != nil {
+		client.SendErrorCode(ErrCodeNotInVoice, "Join voice before muting players")
+		return
+	}
+
+	client.Send(MustMessage(EventTypePlayerMuted, PlayerMutedPayload{
+		TargetID: payload.TargetID,
+		Muted:    muted,
+	}))
+	r.refreshVoiceRouting(client.RoomCode, client)
 }
 
 func (r *Router) handleVoiceLeave(client *Client) {
@@ -690,26 +1809,28 @@ func (r *Router) handleVoiceLeave(client *Client) {
 		PlayerID: client.PlayerID,
 	}), client)
 
-	r.logger.Info("player left voice",
-		"room", client.RoomCode,
-		"player", client.PlayerID,
-	)
+	client.Logger().Info("player left voice")
 }
 
 func (r *Router) handleVoiceOffer(client *Client, msg *Message) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
 	if r.sfu == nil {
-		client.SendError("voice_unavailable", "Voice chat is not available")
+		client.SendErrorCode(ErrCodeVoiceUnavailable, "Voice chat is not available")
 		return
 	}
 
 	var payload VoiceOfferPayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid voice offer payload")
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid voice offer payload")
+		return
+	}
+
+	if err := sfu.ValidateOffer(payload.SDP); err != nil {
+		client.SendErrorCode(ErrCodeVoiceOfferFailed, err.Error())
 		return
 	}
 
@@ -718,9 +1839,30 @@ func (r *Router) handleVoiceOffer(client *Client, msg *Message) {
 		SDP:  payload.SDP,
 	}
 
+	// Offer arrived before voice_join - create the participant now
+	if r.sfu.GetParticipant(client.RoomCode, client.PlayerID) == nil {
+		if r.voiceDisabled(client) {
+			return
+		}
+		if _, err := r.joinVoice(client); err != nil {
+			client.SendErrorCode(ErrCodeVoiceJoinFailed, "Failed to join voice: "+err.Error())
+			return
+		}
+	}
+
 	answer, err := r.sfu.HandleOffer(client.RoomCode, client.PlayerID, offer)
 	if err != nil {
-		client.SendError("voice_offer_failed", "Failed to process offer: "+err.Error())
+		if errors.Is(err, sfu.ErrNegotiationFailed) {
+			// Peer connection was recreated - ask the client to start over
+			if participant := r.sfu.GetParticipant(client.RoomCode, client.PlayerID); participant != nil {
+				r.attachPeerHandlers(client, participant)
+			}
+			client.Send(MustMessage(EventTypeVoiceRenegotiate, VoiceRenegotiatePayload{
+				Reason: err.Error(),
+			}))
+			return
+		}
+		client.SendErrorCode(ErrCodeVoiceOfferFailed, "Failed to process offer: "+err.Error())
 		return
 	}
 
@@ -728,26 +1870,23 @@ func (r *Router) handleVoiceOffer(client *Client, msg *Message) {
 		SDP: answer.SDP,
 	}))
 
-	r.logger.Debug("voice offer/answer complete",
-		"room", client.RoomCode,
-		"player", client.PlayerID,
-	)
+	client.Logger().Debug("voice offer/answer complete")
 }
 
 func (r *Router) handleVoiceCandidate(client *Client, msg *Message) {
 	if client.RoomCode == "" {
-		client.SendError("not_in_room", "Not in a room")
+		client.SendErrorCode(ErrCodeNotInRoom, "Not in a room")
 		return
 	}
 
 	if r.sfu == nil {
-		client.SendError("voice_unavailable", "Voice chat is not available")
+		client.SendErrorCode(ErrCodeVoiceUnavailable, "Voice chat is not available")
 		return
 	}
 
 	var payload VoiceCandidatePayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
-		client.SendError("invalid_payload", "Invalid voice candidate payload")
+		client.SendErrorCode(ErrCodeInvalidPayload, "Invalid voice candidate payload")
 		return
 	}
 
@@ -760,10 +1899,7 @@ func (r *Router) handleVoiceCandidate(client *Client, msg *Message) {
 	}
 
 	if err := r.sfu.AddICECandidate(client.RoomCode, client.PlayerID, candidate); err != nil {
-		r.logger.Warn("failed to add ICE candidate",
-			"error", err,
-			"player", client.PlayerID,
-		)
+		client.Logger().Warn("failed to add ICE candidate", "error", err)
 	}
 }
 
@@ -772,6 +1908,11 @@ func (r *Router) handleSpeakingState(client *Client, msg *Message) {
 		return
 	}
 
+	// Client reports are ignored when the server detects speaking itself
+	if r.sfu != nil && r.sfu.ServerSpeakingDetection() {
+		return
+	}
+
 	var payload SpeakingStatePayload
 	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
 		return
@@ -789,11 +1930,28 @@ func (r *Router) handleSpeakingState(client *Client, msg *Message) {
 	}), nil)
 }
 
+// handleDetectedSpeaking broadcasts a speaking change detected by the SFU
+func (r *Router) handleDetectedSpeaking(roomCode, playerID string, speaking bool) {
+	r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypeSpeakingState, SpeakingStatePayload{
+		PlayerID: playerID,
+		Speaking: speaking,
+	}), nil)
+}
+
 // handleGameEvent processes events from the game service
 func (r *Router) handleGameEvent(event service.GameEvent) {
 	switch event.Type {
+	case service.EventForceReadied:
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeForceReadied, event.Data), nil)
+
 	case service.EventGameStarted:
-		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeGameStarting, nil), nil)
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeGameStarting, event.Data), nil)
+
+	case service.EventAutoStart:
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeAutoStart, event.Data), nil)
+
+	case service.EventGameQueued:
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeGameQueued, event.Data), nil)
 
 	case service.EventRoleAssigned:
 		// Send to specific player
@@ -803,7 +1961,7 @@ func (r *Router) handleGameEvent(event service.GameEvent) {
 		)
 		client := r.hub.GetClient(event.TargetPlayerID)
 		if client != nil {
-			r.logger.Info("found client for role assignment", "player_id", event.TargetPlayerID)
+			client.Logger().Info("found client for role assignment")
 			client.Send(MustMessage(EventTypeRoleAssigned, event.Data))
 		} else {
 			r.logger.Error("client not found for role assignment", "player_id", event.TargetPlayerID)
@@ -812,7 +1970,22 @@ func (r *Router) handleGameEvent(event service.GameEvent) {
 	case service.EventPhaseChanged:
 		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypePhaseChanged, event.Data), nil)
 		// Apply voice routing on phase change
-		r.applyVoiceRouting(event.RoomCode, event.Data)
+		r.applyVoiceRouting(event.RoomCode, event.Data, nil)
+
+	case service.EventActionPrompt:
+		if client := r.hub.GetClient(event.TargetPlayerID); client != nil {
+			client.Send(MustMessage(EventTypeActionPrompt, event.Data))
+		}
+
+	case service.EventLoversPaired:
+		if client := r.hub.GetClient(event.TargetPlayerID); client != nil {
+			client.Send(MustMessage(EventTypeLoversPaired, event.Data))
+		}
+
+	case service.EventSpeakingTurn:
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeSpeakingTurn, event.Data), nil)
+		// Hand the floor to the new speaker
+		r.refreshVoiceRouting(event.RoomCode, nil)
 
 	case service.EventTimerTick:
 		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeTimerTick, event.Data), nil)
@@ -832,6 +2005,12 @@ func (r *Router) handleGameEvent(event service.GameEvent) {
 	case service.EventVoteUpdate:
 		r.hub.BroadcastToRoom(event.RoomCode, MustMessage("vote_update", event.Data), nil)
 
+	case service.EventNightProgress:
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeNightProgress, event.Data), nil)
+
+	case service.EventLynchConfirm:
+		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeLynchConfirmUpdate, event.Data), nil)
+
 	case service.EventMafiaVote:
 		// Send mafia vote update to specific mafia teammate
 		client := r.hub.GetClient(event.TargetPlayerID)
@@ -845,7 +2024,17 @@ func (r *Router) handleGameEvent(event service.GameEvent) {
 	case service.EventGameOver:
 		r.hub.BroadcastToRoom(event.RoomCode, MustMessage(EventTypeGameOver, event.Data), nil)
 		// Apply game over voice routing (everyone can talk)
-		r.applyVoiceRouting(event.RoomCode, map[string]any{"phase": "game_over"})
+		r.applyVoiceRouting(event.RoomCode, map[string]any{"phase": "game_over"}, nil)
+
+	case service.EventMafiaSummary:
+		// Only ever targeted at a living mafia member
+		if client := r.hub.GetClient(event.TargetPlayerID); client != nil {
+			client.Send(MustMessage(EventTypeMafiaNightSummary, event.Data))
+		}
+
+	case service.EventPlayerDied:
+		// Mute the newly dead player from the living without waiting for the next phase
+		r.applyVoiceRouting(event.RoomCode, event.Data, nil)
 
 	case service.EventVoiceRouting:
 		// Broadcast voice routing to clients
@@ -853,8 +2042,9 @@ func (r *Router) handleGameEvent(event service.GameEvent) {
 	}
 }
 
-// applyVoiceRouting applies voice routing rules based on game phase
-func (r *Router) applyVoiceRouting(roomCode string, phaseData any) {
+// applyVoiceRouting applies voice routing rules based on game phase and
+// tells the room, or only client to when it is set
+func (r *Router) applyVoiceRouting(roomCode string, phaseData any, to *Client) {
 	if r.sfu == nil {
 		return
 	}
@@ -866,19 +2056,10 @@ func (r *Router) applyVoiceRouting(roomCode string, phaseData any) {
 	}
 
 	// Build voice routing state
-	var phase sfu.GamePhase
+	phase := string(game.GetPhase())
 	if data, ok := phaseData.(map[string]any); ok {
 		if p, ok := data["phase"].(string); ok {
-			switch p {
-			case "night":
-				phase = sfu.PhaseNight
-			case "day":
-				phase = sfu.PhaseDay
-			case "game_over":
-				phase = sfu.PhaseGameOver
-			default:
-				phase = sfu.PhaseLobby
-			}
+			phase = p
 		}
 	}
 
@@ -886,7 +2067,7 @@ func (r *Router) applyVoiceRouting(roomCode string, phaseData any) {
 	var players []sfu.PlayerVoiceState
 	for playerID, role := range game.Roles {
 		player := game.Room.GetPlayer(playerID)
-		if player == nil {
+		if player == nil || player.IsBot {
 			continue
 		}
 
@@ -902,15 +2083,76 @@ func (r *Router) applyVoiceRouting(roomCode string, phaseData any) {
 		})
 	}
 
+	r.routeVoice(roomCode, voicePhase(phase), players, sfu.RoutingOptions{
+		DeadDayWhisper: game.Room.Settings.DeadDayWhisper,
+		DeadNightChat:  game.Room.Settings.DeadNightChat,
+		Speaker:        game.CurrentSpeaker().PlayerID,
+	}, to)
+}
+
+// voicePhase maps a game phase to its voice routing. A running game never
+// routes as the open lobby: the reveal and result phases keep the rules of
+// the phase around them, so the dead stay muted to the living in between.
+func voicePhase(phase string) sfu.GamePhase {
+	switch entity.GamePhase(phase) {
+	case entity.PhaseCupid, entity.PhaseNight, entity.PhaseNightResult:
+		return sfu.PhaseNight
+	case entity.PhaseGameOver:
+		return sfu.PhaseGameOver
+	default:
+		// Role reveal, discussion, voting, the confirmation and the day
+		// result share day speaking permissions
+		return sfu.PhaseDay
+	}
+}
+
+// refreshVoiceRouting reapplies routing for whatever state the room is in:
+// the game's current phase, or the open lobby when no game is running. When
+// to is set only that client is told.
+func (r *Router) refreshVoiceRouting(roomCode string, to *Client) {
+	if game := r.gameService.GetGame(roomCode); game != nil {
+		r.applyVoiceRouting(roomCode, map[string]any{"phase": string(game.GetPhase())}, to)
+		return
+	}
+	if room, err := r.roomService.GetRoom(roomCode); err == nil {
+		r.applyLobbyVoiceRouting(room, to)
+	}
+}
+
+// applyLobbyVoiceRouting opens voice to everyone in a room with no game running
+func (r *Router) applyLobbyVoiceRouting(room *entity.Room, to *Client) {
+	if r.sfu == nil {
+		return
+	}
+
+	var players []sfu.PlayerVoiceState
+	for _, dto := range room.GetPlayersDTO() {
+		if dto.IsBot {
+			continue
+		}
+		players = append(players, sfu.PlayerVoiceState{
+			ID:      dto.ID,
+			Team:    sfu.TeamTown,
+			IsAlive: true,
+		})
+	}
+
+	r.routeVoice(room.Code, sfu.PhaseLobby, players, sfu.RoutingOptions{}, to)
+}
+
+// routeVoice applies routing in the SFU and tells clients who can speak and
+// hear: the whole room, or only to when it is set
+func (r *Router) routeVoice(roomCode string, phase sfu.GamePhase, players []sfu.PlayerVoiceState, opts sfu.RoutingOptions, to *Client) {
 	// Apply routing
 	state := sfu.VoiceRoutingState{
 		Phase:   phase,
 		Players: players,
+		Options: opts,
 	}
 	r.sfu.ApplyVoiceRouting(roomCode, state)
 
 	// Build and broadcast voice routing to clients
-	routing := sfu.CalculateRouting(phase, convertToPlayerInfo(players))
+	routing := sfu.CalculateRouting(phase, convertToPlayerInfo(players), opts)
 	var clientRouting []VoiceRoutingPlayerState
 	for _, ps := range routing {
 		clientRouting = append(clientRouting, VoiceRoutingPlayerState{
@@ -920,10 +2162,37 @@ func (r *Router) applyVoiceRouting(roomCode string, phaseData any) {
 		})
 	}
 
-	r.hub.BroadcastToRoom(roomCode, MustMessage(EventTypeVoiceRouting, VoiceRoutingPayload{
+	// Each client's own entry is narrowed by their personal mutes, which
+	// nobody else sees
+	payload := VoiceRoutingPayload{
 		Phase:   string(phase),
 		Players: clientRouting,
-	}), nil)
+	}
+	recipients := []*Client{to}
+	if to == nil {
+		recipients = r.hub.GetRoomClients(roomCode)
+	}
+	for _, client := range recipients {
+		client.Send(MustMessage(EventTypeVoiceRouting, r.personalRouting(roomCode, client.PlayerID, payload)))
+	}
+}
+
+// personalRouting returns payload with playerID's CanHear replaced by what
+// their voice participant actually hears once personal mutes are applied
+func (r *Router) personalRouting(roomCode, playerID string, payload VoiceRoutingPayload) VoiceRoutingPayload {
+	participant := r.sfu.GetParticipant(roomCode, playerID)
+	if participant == nil {
+		return payload
+	}
+
+	players := slices.Clone(payload.Players)
+	for i := range players {
+		if players[i].PlayerID == playerID {
+			players[i].CanHear = participant.GetCanHear()
+		}
+	}
+	payload.Players = players
+	return payload
 }
 
 func convertToPlayerInfo(players []sfu.PlayerVoiceState) []sfu.PlayerInfo {
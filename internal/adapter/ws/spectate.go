@@ -0,0 +1,83 @@
+package ws
+
+// roomHistorySize bounds how many recent room broadcasts are kept for
+// spectators who arrive mid-game
+const roomHistorySize = 50
+
+// transientEvents are room broadcasts that only matter the moment they are
+// sent, so replaying them to a late spectator would be noise
+var transientEvents = map[string]bool{
+	EventTypeTimerTick:     true,
+	EventTypePlayerLatency: true,
+	EventTypeSpeakingState: true,
+	EventTypeVoiceRouting:  true,
+}
+
+// spectatorMessages are the only messages a spectator may send
+var spectatorMessages = map[string]bool{
+	MsgTypeLeaveRoom:    true,
+	MsgTypeRequestState: true,
+	MsgTypeResync:       true,
+	MsgTypeGetTimeline:  true,
+}
+
+// eventRing keeps the last roomHistorySize timestamped messages of one room
+type eventRing struct {
+	events [roomHistorySize]*Message
+	next   int
+	full   bool
+}
+
+func (r *eventRing) add(msg *Message) {
+	r.events[r.next] = msg
+	r.next = (r.next + 1) % roomHistorySize
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// all returns the buffered messages, oldest first
+func (r *eventRing) all() []*Message {
+	if !r.full {
+		return append([]*Message{}, r.events[:r.next]...)
+	}
+	return append(append([]*Message{}, r.events[r.next:]...), r.events[:r.next]...)
+}
+
+// recordLocked buffers a room broadcast for later spectators. Only room-wide
+// broadcasts are recorded, so messages aimed at particular players never
+// are. Caller must hold mu.
+func (h *Hub) recordLocked(roomCode string, msg *Message) {
+	if transientEvents[msg.Type] {
+		return
+	}
+	ring, ok := h.history[roomCode]
+	if !ok {
+		ring = &eventRing{}
+		h.history[roomCode] = ring
+	}
+	ring.add(msg)
+}
+
+// Spectate adds client to a room as a watcher and replays the room's recent
+// public broadcasts to it, oldest first. Replayed messages keep their
+// original timestamps and take the spectator's next sequence numbers.
+func (h *Hub) Spectate(client *Client, roomCode string) {
+	// Joining and copying the history under one lock, then holding the
+	// spectator's send lock until the replay is queued, puts every live
+	// broadcast after the history it is not part of
+	h.mu.Lock()
+	h.joinLocked(client, roomCode)
+	client.spectating.Store(true)
+	var replay []*Message
+	if ring, ok := h.history[roomCode]; ok {
+		replay = ring.all()
+	}
+	client.sendMu.Lock()
+	h.mu.Unlock()
+	defer client.sendMu.Unlock()
+
+	for _, msg := range replay {
+		h.deliverLocked(client, msg)
+	}
+}
@@ -0,0 +1,100 @@
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSpectatorGetsBufferedPublicHistory(t *testing.T) {
+	r := newTestRouter(t, false)
+	room, clients := startTestGame(t, r, 5, nil)
+	go r.hub.Run()
+
+	// A private message and a transient one must not be replayed
+	r.hub.BroadcastToPlayers(room.Code, []string{"p0"}, MustMessage("private_note", nil))
+	r.hub.BroadcastToRoom(room.Code, MustMessage(EventTypeTimerTick, nil), nil)
+	r.hub.BroadcastToRoom(room.Code, MustMessage("public_note", map[string]any{}), nil)
+	waitFor(t, "public note", func() bool {
+		return lastOfType(t, clients["p1"], "public_note", new(any))
+	})
+
+	watcher := newTestClient(r.hub, "watcher", 256)
+	r.HandleMessage(watcher, MustMessage(MsgTypeSpectate, SpectatePayload{RoomCode: room.Code}))
+
+	msgs := drainMessages(t, watcher)
+	if len(msgs) == 0 {
+		t.Fatal("spectator got nothing")
+	}
+	seen := make(map[string]bool)
+	var lastSeq uint64
+	for _, msg := range msgs[:len(msgs)-1] {
+		seen[msg.Type] = true
+		if msg.Seq != lastSeq+1 {
+			t.Errorf("replayed %s with seq %d after %d", msg.Type, msg.Seq, lastSeq)
+		}
+		lastSeq = msg.Seq
+	}
+	for _, want := range []string{EventTypeGameStarting, "public_note"} {
+		if !seen[want] {
+			t.Errorf("history is missing %s; got %v", want, seen)
+		}
+	}
+	for _, private := range []string{"private_note", EventTypeTimerTick, EventTypeRoleAssigned} {
+		if seen[private] {
+			t.Errorf("history replayed %s", private)
+		}
+	}
+
+	// The current state comes last, as a spectator's
+	state := msgs[len(msgs)-1]
+	var payload map[string]any
+	if state.Type != EventTypeGameState || json.Unmarshal(state.Payload, &payload) != nil || payload["is_spectator"] != true {
+		t.Fatalf("last message %s %s, want a spectator game_state", state.Type, state.Payload)
+	}
+
+	// Spectators hear live room broadcasts but cannot act
+	r.hub.BroadcastToRoom(room.Code, MustMessage("live_note", map[string]any{}), nil)
+	waitFor(t, "live note", func() bool {
+		return lastOfType(t, watcher, "live_note", new(any))
+	})
+	r.HandleMessage(watcher, MustMessage(MsgTypeDayVote, DayVotePayload{TargetID: "p1"}))
+	if code := lastErrorCode(t, watcher); code != string(ErrCodeSpectating) {
+		t.Errorf("spectator vote error = %q, want %s", code, ErrCodeSpectating)
+	}
+	if room.GetPlayer("watcher") != nil {
+		t.Error("spectator took a seat")
+	}
+}
+
+func TestSpectateDuringBroadcastsSeesEachMessageOnce(t *testing.T) {
+	h := NewHub(discardLogger())
+	player := newTestClient(h, "p1", 256)
+	h.JoinRoom(player, "ROOM")
+
+	// Stay under roomHistorySize so nothing ages out of the replay
+	const sent = roomHistorySize - 10
+	watcher := newTestClient(h, "watcher", 256)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range sent {
+			h.broadcastToRoom(&RoomMessage{RoomCode: "ROOM", Message: MustMessage("note", map[string]int{"n": i})})
+		}
+	}()
+	h.Spectate(watcher, "ROOM")
+	<-done
+
+	msgs := drainMessages(t, watcher)
+	if len(msgs) != sent {
+		t.Fatalf("spectator got %d messages, want %d", len(msgs), sent)
+	}
+	for i, msg := range msgs {
+		var payload struct{ N int }
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil || payload.N != i {
+			t.Fatalf("message %d is %s, want n=%d in order", i, msg.Payload, i)
+		}
+		if msg.Seq != uint64(i+1) {
+			t.Errorf("message %d has seq %d, want %d", i, msg.Seq, i+1)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/adapter/sfu"
+	"github.com/V4T54L/mafia/internal/domain/entity"
+	"github.com/V4T54L/mafia/internal/domain/service"
+	"github.com/gorilla/websocket"
+)
+
+// discardLogger returns a logger that drops every record
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// newTestClient returns a client with no connection and a send buffer of
+// the given size, registered with the hub's client set
+func newTestClient(h *Hub, playerID string, buffer int) *Client {
+	c := NewClient(h, nil, playerID, h.logger, nil, nil)
+	c.send = make(chan []byte, buffer)
+	h.mu.Lock()
+	h.clients[c] = true
+	h.mu.Unlock()
+	return c
+}
+
+// drainMessages returns every message queued for c, oldest first
+func drainMessages(t *testing.T, c *Client) []*Message {
+	t.Helper()
+	var msgs []*Message
+	for {
+		select {
+		case data := <-c.send:
+			var msg Message
+			if err := json.Unmarshal(data, &msg); err != nil {
+				t.Fatalf("decode %s: %v", data, err)
+			}
+			msgs = append(msgs, &msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+// lastOfType returns the payload of the newest queued message of msgType,
+// decoded into payload, draining everything queued for c
+func lastOfType(t *testing.T, c *Client, msgType string, payload any) bool {
+	t.Helper()
+	var found *Message
+	for _, msg := range drainMessages(t, c) {
+		if msg.Type == msgType {
+			found = msg
+		}
+	}
+	if found == nil {
+		return false
+	}
+	if err := json.Unmarshal(found.Payload, payload); err != nil {
+		t.Fatalf("decode %s payload: %v", msgType, err)
+	}
+	return true
+}
+
+// newTestRouter returns a router over fresh services; voice is enabled
+// when withVoice is set
+func newTestRouter(t *testing.T, withVoice bool) *Router {
+	t.Helper()
+	logger := discardLogger()
+	rooms, err := service.NewRoomService(logger)
+	if err != nil {
+		t.Fatalf("room service: %v", err)
+	}
+	games := service.NewGameService(rooms, logger)
+
+	var voice *sfu.SFU
+	if withVoice {
+		var err error
+		voice, err = sfu.New(&sfu.Config{
+			UDPPortMin: 5000,
+			UDPPortMax: 5100,
+		}, logger)
+		if err != nil {
+			t.Fatalf("sfu: %v", err)
+		}
+		t.Cleanup(voice.Close)
+	}
+	return NewRouter(NewHub(logger), rooms, games, voice, logger)
+}
+
+// startTestGame seats n players (p0 is host), each with a hub client in the
+// room, and starts a game with the room's settings after tweak adjusts them
+func startTestGame(t *testing.T, r *Router, n int, tweak func(*entity.GameSettings)) (*entity.Room, map[string]*Client) {
+	t.Helper()
+
+	room, err := r.roomService.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	clients := make(map[string]*Client, n)
+	for i := range n {
+		id := fmt.Sprintf("p%d", i)
+		if _, err := r.roomService.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		if err := r.roomService.SetReady(room.Code, id, true); err != nil {
+			t.Fatalf("ready %s: %v", id, err)
+		}
+		clients[id] = newTestClient(r.hub, id, 256)
+		r.hub.JoinRoom(clients[id], room.Code)
+	}
+	if tweak != nil {
+		tweak(&room.Settings)
+	}
+
+	if err := r.gameService.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	return room, clients
+}
+
+// waitFor polls cond until it holds or three seconds pass
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(3 * time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// connPair returns both ends of a live WebSocket connection; they are
+// closed when the test ends
+func connPair(t *testing.T) (server, client *websocket.Conn) {
+	t.Helper()
+
+	conns := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(srv.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	server = <-conns
+	t.Cleanup(func() { server.Close() })
+	return server, client
+}
@@ -0,0 +1,52 @@
+package ws
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+	"github.com/V4T54L/mafia/internal/domain/service"
+)
+
+func TestMidDayDeathMutesThePlayerAtOnce(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, clients := startTestGame(t, r, 7, nil)
+	game := r.gameService.GetGame(room.Code)
+	game.StartDay(time.Minute)
+	r.applyVoiceRouting(room.Code, map[string]any{"phase": string(entity.PhaseDay)}, nil)
+	for _, c := range clients {
+		drainMessages(t, c)
+	}
+
+	// p2 dies while the day is still running
+	room.GetPlayer("p2").Status = entity.PlayerStatusDead
+	r.handleGameEvent(service.GameEvent{
+		Type:     service.EventPlayerDied,
+		RoomCode: room.Code,
+		Data:     map[string]any{"player_id": "p2", "phase": string(entity.PhaseDay)},
+	})
+
+	var routing VoiceRoutingPayload
+	if !lastOfType(t, clients["p1"], EventTypeVoiceRouting, &routing) {
+		t.Fatal("no voice routing after the death")
+	}
+	if routing.Phase != string(entity.PhaseDay) {
+		t.Errorf("routing phase %q, want %s", routing.Phase, entity.PhaseDay)
+	}
+	states := make(map[string]VoiceRoutingPlayerState)
+	for _, p := range routing.Players {
+		states[p.PlayerID] = p
+	}
+	if states["p2"].CanSpeak {
+		t.Error("dead p2 can still speak")
+	}
+	for _, id := range []string{"p0", "p1", "p3"} {
+		if !states[id].CanSpeak {
+			t.Errorf("living %s can no longer speak", id)
+		}
+		if slices.Contains(states[id].CanHear, "p2") {
+			t.Errorf("living %s still hears dead p2: %v", id, states[id].CanHear)
+		}
+	}
+}
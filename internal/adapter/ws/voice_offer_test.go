@@ -0,0 +1,91 @@
+package ws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+)
+
+// clientOffer returns an audio offer from a fresh browser-like peer
+func clientOffer(t *testing.T) string {
+	t.Helper()
+	pc, err := webrtc.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		t.Fatalf("client peer connection: %v", err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	if _, err := pc.AddTransceiverFromKind(webrtc.RTPCodecTypeAudio); err != nil {
+		t.Fatalf("add transceiver: %v", err)
+	}
+	offer, err := pc.CreateOffer(nil)
+	if err != nil {
+		t.Fatalf("create offer: %v", err)
+	}
+	return offer.SDP
+}
+
+func TestMalformedOfferRecovers(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, clients := startTestGame(t, r, 5, nil)
+	client := clients["p1"]
+	drainMessages(t, client)
+
+	// Passes validation but cannot be applied; the offer also arrives
+	// before voice_join, so the participant is created on the spot
+	malformed := "v=0\r\no=- 0 0 IN IP4 127.0.0.1\r\ns=-\r\nt=0 0\r\nm=audio 9 UDP/TLS/RTP/SAVPF not-a-codec\r\n"
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: malformed}))
+
+	var renegotiate VoiceRenegotiatePayload
+	if !lastOfType(t, client, EventTypeVoiceRenegotiate, &renegotiate) {
+		t.Fatal("malformed offer did not ask the client to renegotiate")
+	}
+	participant := r.sfu.GetParticipant(room.Code, "p1")
+	if participant == nil || participant.PeerConn == nil {
+		t.Fatal("participant was not kept with a fresh peer connection")
+	}
+	if state := participant.PeerConn.SignalingState(); state != webrtc.SignalingStateStable {
+		t.Fatalf("reset peer connection is in %s, want stable", state)
+	}
+
+	// A good offer on the recreated connection negotiates normally
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: clientOffer(t)}))
+	var answer VoiceAnswerPayload
+	if !lastOfType(t, client, EventTypeVoiceAnswer, &answer) || answer.SDP == "" {
+		t.Fatal("valid offer after a failed one got no answer")
+	}
+}
+
+func TestTruncatedOfferGetsCleanError(t *testing.T) {
+	r := newTestRouter(t, true)
+	_, clients := startTestGame(t, r, 5, nil)
+	client := clients["p1"]
+	offer := clientOffer(t)
+	media := strings.Index(offer, "\nm=")
+
+	// Cut before the media section: rejected before it reaches pion
+	for _, sdp := range []string{"", "   ", "v=", offer[:media]} {
+		drainMessages(t, client)
+		r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: sdp}))
+		var errPayload ErrorPayload
+		if !lastOfType(t, client, EventTypeError, &errPayload) || errPayload.Code != string(ErrCodeVoiceOfferFailed) {
+			t.Errorf("offer %q: error = %+v, want %s", sdp, errPayload, ErrCodeVoiceOfferFailed)
+		}
+	}
+
+	// Cut inside the media section: negotiation fails and the client is
+	// asked to start over
+	drainMessages(t, client)
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: offer[:media+12]}))
+	var renegotiate VoiceRenegotiatePayload
+	if !lastOfType(t, client, EventTypeVoiceRenegotiate, &renegotiate) {
+		t.Error("offer cut inside the media section did not ask to renegotiate")
+	}
+
+	// The server is still up and negotiates a complete offer
+	r.HandleMessage(client, MustMessage(MsgTypeVoiceOffer, VoiceOfferPayload{SDP: offer}))
+	var answer VoiceAnswerPayload
+	if !lastOfType(t, client, EventTypeVoiceAnswer, &answer) || answer.SDP == "" {
+		t.Fatal("complete offer after truncated ones got no answer")
+	}
+}
@@ -0,0 +1,36 @@
+package ws
+
+import (
+	"testing"
+)
+
+func TestVoiceJoinRejectedWhenDisabled(t *testing.T) {
+	r := newTestRouter(t, true)
+	room, clients := startTestGame(t, r, 7, nil)
+
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeVoiceJoin, nil))
+	if r.sfu.GetParticipant(room.Code, "p1") == nil {
+		t.Fatal("voice join failed with voice enabled")
+	}
+
+	// Turning voice off mid-game drops whoever is talking
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeUpdateSettings, map[string]any{"voice_enabled": false}))
+	if room.Settings.VoiceEnabled {
+		t.Fatal("voice still enabled after the host turned it off")
+	}
+	if r.sfu.GetParticipant(room.Code, "p1") != nil {
+		t.Error("participant survived voice being turned off")
+	}
+
+	for _, id := range []string{"p1", "p2"} {
+		drainMessages(t, clients[id])
+		r.HandleMessage(clients[id], MustMessage(MsgTypeVoiceJoin, nil))
+		var errPayload ErrorPayload
+		if !lastOfType(t, clients[id], EventTypeError, &errPayload) || errPayload.Code != string(ErrCodeVoiceDisabled) {
+			t.Errorf("%s: voice join error = %+v, want %s", id, errPayload, ErrCodeVoiceDisabled)
+		}
+		if r.sfu.GetParticipant(room.Code, id) != nil {
+			t.Errorf("%s joined voice while it was disabled", id)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package ws
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestConnectedReportsVoiceUnavailable(t *testing.T) {
+	h := NewHub(discardLogger())
+	go h.Run()
+	handler := NewHandler(h, discardLogger(), NewOriginPolicy(nil, true), nil, nil, nil)
+	handler.SetVoiceAvailable(false)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var msg Message
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read connected: %v", err)
+	}
+	var payload ConnectedPayload
+	if msg.Type != EventTypeConnected || json.Unmarshal(msg.Payload, &payload) != nil || !payload.VoiceUnavailable {
+		t.Errorf("connected = %s %s, want voice_unavailable", msg.Type, msg.Payload)
+	}
+}
+
+func TestGameRunsWithoutSFU(t *testing.T) {
+	r := newTestRouter(t, false)
+	go r.hub.Run()
+
+	room, err := r.roomService.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	clients := make(map[string]*Client)
+	for i := range 7 {
+		id := fmt.Sprintf("p%d", i)
+		if _, err := r.roomService.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		clients[id] = newTestClient(r.hub, id, 256)
+		r.hub.JoinRoom(clients[id], room.Code)
+	}
+
+	// The lobby learns there is no voice at all
+	for i := 1; i < 7; i++ {
+		r.HandleMessage(clients[fmt.Sprintf("p%d", i)], MustMessage(MsgTypeReady, ReadyPayload{Ready: true}))
+	}
+	var status LobbyStatusPayload
+	waitFor(t, "ready lobby status", func() bool {
+		return lastOfType(t, clients["p0"], EventTypeLobbyStatus, &status) && status.CanStart
+	})
+	if !status.VoiceUnavailable || status.VoiceEnabled {
+		t.Errorf("lobby status %+v, want voice unavailable", status)
+	}
+
+	// Voice requests fail cleanly and the game starts as usual
+	r.HandleMessage(clients["p1"], MustMessage(MsgTypeVoiceJoin, nil))
+	if code := lastErrorCode(t, clients["p1"]); code != string(ErrCodeVoiceUnavailable) {
+		t.Errorf("voice join error = %q, want %s", code, ErrCodeVoiceUnavailable)
+	}
+	r.HandleMessage(clients["p0"], MustMessage(MsgTypeStartGame, StartGamePayload{}))
+	if r.gameService.GetGame(room.Code) == nil {
+		t.Fatal("game did not start without an SFU")
+	}
+	waitFor(t, "game starting", func() bool {
+		for _, msg := range drainMessages(t, clients["p2"]) {
+			if msg.Type == EventTypeGameStarting {
+				return true
+			}
+		}
+		return false
+	})
+}
@@ -0,0 +1,86 @@
+package entity
+
+import "time"
+
+// ActionKind names what a player may do in the current phase
+type ActionKind string
+
+const (
+	ActionNone        ActionKind = "none"
+	ActionKill        ActionKind = "kill"
+	ActionProtect     ActionKind = "protect"
+	ActionInvestigate ActionKind = "investigate"
+	ActionVote        ActionKind = "vote"
+	ActionConfirm     ActionKind = "confirm_lynch" // yes/no on the single target
+	ActionPickLovers  ActionKind = "pick_lovers"   // two distinct targets
+)
+
+// ActionPrompt tells one player what they can do this phase. Targets are
+// checked against the same rules the action itself enforces, so clients
+// never have to reimplement them.
+type ActionPrompt struct {
+	Action   ActionKind
+	Targets  []string  // valid target IDs in seat order; empty for ActionNone
+	Deadline time.Time // when the phase ends
+}
+
+// nightActions maps the roles that act at night to their action
+var nightActions = map[Role]ActionKind{
+	RoleMafia:     ActionKill,
+	RoleGodfather: ActionKill,
+	RoleDoctor:    ActionProtect,
+	RoleDetective: ActionInvestigate,
+}
+
+// ActionPrompt returns what playerID can do in the current phase: their
+// role's night action or the day vote while voting is open, nothing otherwise
+func (g *Game) ActionPrompt(playerID string) ActionPrompt {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	prompt := ActionPrompt{Action: ActionNone, Deadline: g.PhaseEndTime}
+	player := g.Room.GetPlayer(playerID)
+	if player == nil || player.Status != PlayerStatusAlive {
+		return prompt
+	}
+
+	var valid func(targetID string) bool
+	switch g.Phase {
+	case PhaseNight:
+		role := g.Roles[playerID]
+		action, ok := nightActions[role]
+		if !ok {
+			return prompt
+		}
+		prompt.Action = action
+		valid = func(targetID string) bool {
+			return g.nightTargetErrLocked(playerID, role, targetID) == nil
+		}
+	case PhaseDay:
+		prompt.Action = ActionVote
+		valid = func(targetID string) bool {
+			return g.voteTargetErrLocked(playerID, targetID) == nil
+		}
+	case PhaseCupid:
+		if g.Roles[playerID] != RoleCupid || len(g.Lovers) > 0 {
+			return prompt
+		}
+		prompt.Action = ActionPickLovers
+		valid = g.isAliveLocked
+	case PhaseLynchConfirm:
+		prompt.Action = ActionConfirm
+		valid = func(targetID string) bool {
+			return targetID == g.PendingLynch
+		}
+	default:
+		return prompt
+	}
+
+	prompt.Targets = make([]string, 0, len(g.Room.PlayerOrder))
+	for _, targetID := range g.Room.PlayerOrder {
+		if valid(targetID) {
+			prompt.Targets = append(prompt.Targets, targetID)
+		}
+	}
+	return prompt
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// aliveExcept returns the living players in seat order, minus the excluded
+func aliveExcept(game *Game, excluded ...string) []string {
+	var ids []string
+	for _, id := range game.Room.PlayerOrder {
+		if game.Room.GetPlayer(id).Status == PlayerStatusAlive && !slices.Contains(excluded, id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+func TestActionPromptTargetsFollowRoleRules(t *testing.T) {
+	game := newTestGame(t, 7, nil)
+	byRole := playersByRole(game)
+	mafia, villager := byRole[RoleMafia], byRole[RoleVillager][0]
+	detective, doctor := byRole[RoleDetective][0], byRole[RoleDoctor][0]
+	dead := byRole[RoleVillager][1]
+	game.Room.GetPlayer(dead).Status = PlayerStatusDead
+
+	game.StartNight(time.Minute)
+	for _, tt := range []struct {
+		name     string
+		playerID string
+		action   ActionKind
+		targets  []string
+	}{
+		{"mafia", mafia[0], ActionKill, aliveExcept(game, mafia...)},
+		{"detective", detective, ActionInvestigate, aliveExcept(game, detective)},
+		{"doctor", doctor, ActionProtect, aliveExcept(game)},
+		{"villager", villager, ActionNone, nil},
+		{"dead", dead, ActionNone, nil},
+	} {
+		prompt := game.ActionPrompt(tt.playerID)
+		if prompt.Action != tt.action || !slices.Equal(prompt.Targets, tt.targets) {
+			t.Errorf("night %s: %s %v, want %s %v", tt.name, prompt.Action, prompt.Targets, tt.action, tt.targets)
+		}
+		if !prompt.Deadline.Equal(game.PhaseEndTime) {
+			t.Errorf("night %s: deadline %v, want %v", tt.name, prompt.Deadline, game.PhaseEndTime)
+		}
+		for _, target := range prompt.Targets {
+			if err := game.SubmitNightAction(tt.playerID, target); err != nil {
+				t.Errorf("night %s: prompted target %s rejected: %v", tt.name, target, err)
+			}
+		}
+	}
+
+	// By day every living player votes for anyone alive but themselves
+	game.StartDay(time.Minute)
+	for _, id := range []string{villager, mafia[0], detective} {
+		prompt := game.ActionPrompt(id)
+		if want := aliveExcept(game, id); prompt.Action != ActionVote || !slices.Equal(prompt.Targets, want) {
+			t.Errorf("day %s: %s %v, want vote %v", id, prompt.Action, prompt.Targets, want)
+		}
+	}
+	if prompt := game.ActionPrompt(dead); prompt.Action != ActionNone {
+		t.Errorf("day: dead player prompted to %s", prompt.Action)
+	}
+
+	// A runoff narrows the ballot to the tied players
+	game.StartRunoff(time.Minute, []string{villager, doctor})
+	if prompt := game.ActionPrompt(detective); !slices.Equal(prompt.Targets, []string{villager, doctor}) && !slices.Equal(prompt.Targets, []string{doctor, villager}) {
+		t.Errorf("runoff targets %v, want %s and %s", prompt.Targets, villager, doctor)
+	}
+	if prompt := game.ActionPrompt(villager); slices.Contains(prompt.Targets, villager) {
+		t.Errorf("runoff candidate may vote for themselves: %v", prompt.Targets)
+	}
+}
@@ -0,0 +1,43 @@
+package entity
+
+// Balanced returns these settings with role counts scaled to playerCount.
+// The mafia faction is about a quarter of the table, rounded to nearest, and
+// a godfather leads it once it has three members. The doctor joins at four
+// players and the detective at five; everyone else is a villager:
+//
+//	players  mafia  godfather  doctor  detective  villagers
+//	3        1      0          0       0          2
+//	4        1      0          1       0          2
+//	5        1      0          1       1          2
+//	6        2      0          1       1          2
+//	7        2      0          1       1          3
+//	8        2      0          1       1          4
+//	9        2      0          1       1          5
+//	10       2      1          1       1          5
+//	12       2      1          1       1          7
+//	14       3      1          1       1          8
+//	16       3      1          1       1          10
+//	20       4      1          1       1          13
+//
+// Counts below MinPlayers are raised to it. Timers and rule toggles are kept.
+func (s GameSettings) Balanced(playerCount int) GameSettings {
+	playerCount = max(playerCount, MinPlayers)
+
+	faction := max((playerCount+2)/4, 1)
+	s.Godfather = 0
+	if faction >= 3 {
+		s.Godfather = 1
+	}
+	s.Mafia = faction - s.Godfather
+
+	s.Doctor, s.Detective = 0, 0
+	if playerCount >= 4 {
+		s.Doctor = 1
+	}
+	if playerCount >= 5 {
+		s.Detective = 1
+	}
+
+	s.Villagers = playerCount - faction - s.Doctor - s.Detective
+	return s
+}
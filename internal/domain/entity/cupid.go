@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"errors"
+	"time"
+)
+
+// CupidSeconds is how long Cupid has to pick the lovers
+const CupidSeconds = 30
+
+// ErrNotCupid is returned when someone other than Cupid picks the lovers
+var ErrNotCupid = errors.New("only cupid can do this")
+
+// CupidID returns the player dealt Cupid, or empty if nobody was
+func (g *Game) CupidID() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	for _, id := range g.Room.PlayerOrder {
+		if g.Roles[id] == RoleCupid {
+			return id
+		}
+	}
+	return ""
+}
+
+// StartCupid opens the setup phase in which Cupid picks the lovers
+func (g *Game) StartCupid(duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Phase = PhaseCupid
+	g.PhaseEndTime = time.Now().Add(duration)
+}
+
+// SubmitCupidPick links two distinct living players as lovers. Cupid may
+// pick themselves; the pick is final.
+func (g *Game) SubmitCupidPick(playerID, firstID, secondID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Phase != PhaseCupid {
+		return ErrInvalidPhase
+	}
+	if g.Roles[playerID] != RoleCupid {
+		return ErrNotCupid
+	}
+	if len(g.Lovers) > 0 {
+		return ErrAlreadyActed
+	}
+	if firstID == secondID || !g.isAliveLocked(firstID) || !g.isAliveLocked(secondID) {
+		return ErrInvalidTarget
+	}
+
+	g.Lovers[firstID] = secondID
+	g.Lovers[secondID] = firstID
+	return nil
+}
+
+// ResolveCupid closes the setup phase, pairing two random players when Cupid
+// made no pick. Returns the lovers in seat order and whether Cupid chose them.
+func (g *Game) ResolveCupid() (first, second string, chosen bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	chosen = len(g.Lovers) > 0
+	if !chosen {
+		g.pairRandomLoversLocked()
+	}
+	for _, id := range g.Room.PlayerOrder {
+		if lover, ok := g.Lovers[id]; ok {
+			return id, lover, chosen
+		}
+	}
+	return "", "", chosen
+}
+
+// isAliveLocked reports whether playerID is seated and alive. Caller must hold g.mu.
+func (g *Game) isAliveLocked(playerID string) bool {
+	player := g.Room.GetPlayer(playerID)
+	return player != nil && player.Status == PlayerStatusAlive
+}
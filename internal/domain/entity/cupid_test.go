@@ -0,0 +1,95 @@
+package entity
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newCupidGame deals a 7-player game with Cupid and puts it in the Cupid phase
+func newCupidGame(t *testing.T) (game *Game, cupid string) {
+	t.Helper()
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	settings.Lovers = true
+	settings.Cupid = true
+	game = newTestGame(t, 7, &settings)
+
+	cupid = game.CupidID()
+	if cupid == "" {
+		t.Fatal("nobody was dealt cupid")
+	}
+	if len(game.Lovers) != 0 {
+		t.Fatalf("lovers paired before cupid picked: %v", game.Lovers)
+	}
+	game.StartCupid(time.Minute)
+	return game, cupid
+}
+
+func TestCupidPicksLovers(t *testing.T) {
+	game, cupid := newCupidGame(t)
+	villagers := playersByRole(game)[RoleVillager]
+	a, b := villagers[0], villagers[1]
+
+	if err := game.SubmitCupidPick(a, a, b); !errors.Is(err, ErrNotCupid) {
+		t.Errorf("non-cupid pick: %v, want %v", err, ErrNotCupid)
+	}
+	if err := game.SubmitCupidPick(cupid, a, a); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("same player twice: %v, want %v", err, ErrInvalidTarget)
+	}
+	if err := game.SubmitCupidPick(cupid, a, "nobody"); !errors.Is(err, ErrInvalidTarget) {
+		t.Errorf("unknown player: %v, want %v", err, ErrInvalidTarget)
+	}
+	if err := game.SubmitCupidPick(cupid, a, b); err != nil {
+		t.Fatalf("valid pick: %v", err)
+	}
+	if err := game.SubmitCupidPick(cupid, a, cupid); !errors.Is(err, ErrAlreadyActed) {
+		t.Errorf("second pick: %v, want %v", err, ErrAlreadyActed)
+	}
+
+	first, second, chosen := game.ResolveCupid()
+	if !chosen || first != a || second != b {
+		t.Fatalf("resolved %s+%s (chosen %v), want %s+%s chosen", first, second, chosen, a, b)
+	}
+	if game.Lovers[a] != b || game.Lovers[b] != a {
+		t.Errorf("lovers = %v, want %s and %s linked", game.Lovers, a, b)
+	}
+}
+
+func TestCupidTimeoutPairsAtRandom(t *testing.T) {
+	game, _ := newCupidGame(t)
+
+	first, second, chosen := game.ResolveCupid()
+	if chosen {
+		t.Error("timeout pairing reported as cupid's choice")
+	}
+	if first == "" || first == second {
+		t.Fatalf("resolved %q+%q, want two distinct players", first, second)
+	}
+	if game.Lovers[first] != second || game.Lovers[second] != first {
+		t.Errorf("lovers = %v, want %s and %s linked", game.Lovers, first, second)
+	}
+}
+
+func TestCupidPairingDrivesLinkedDeaths(t *testing.T) {
+	game, cupid := newCupidGame(t)
+	byRole := playersByRole(game)
+	a := byRole[RoleVillager][0]
+
+	// Cupid links themselves to a villager, then the mafia kill the villager
+	if err := game.SubmitCupidPick(cupid, cupid, a); err != nil {
+		t.Fatalf("pick: %v", err)
+	}
+	game.ResolveCupid()
+
+	result := killAtNight(t, game, byRole[RoleMafia], a)
+	if result.KilledID != a {
+		t.Fatalf("killed %q, want %s", result.KilledID, a)
+	}
+	if result.Heartbreak == nil || result.Heartbreak.PlayerID != cupid {
+		t.Fatalf("heartbreak = %+v, want cupid %s", result.Heartbreak, cupid)
+	}
+	if deathCause(game, cupid) != DeathCauseHeartbreak {
+		t.Errorf("cupid died of %q, want heartbreak", deathCause(game, cupid))
+	}
+}
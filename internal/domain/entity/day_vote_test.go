@@ -0,0 +1,266 @@
+package entity
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// tiedVote gives p1 and p2 three votes each out of seven (p2 skips)
+func tiedVote(t *testing.T, game *Game) *DayResult {
+	t.Helper()
+	game.StartDay(time.Minute)
+	for voter, target := range map[string]string{
+		"p0": "p1", "p3": "p1", "p4": "p1",
+		"p1": "p2", "p5": "p2", "p6": "p2",
+		"p2": "",
+	} {
+		if err := game.SubmitDayVote(voter, target); err != nil {
+			t.Fatalf("%s votes %q: %v", voter, target, err)
+		}
+	}
+	return game.ResolveDay()
+}
+
+func TestTieBreakModes(t *testing.T) {
+	// Only a plurality vote can tie at the threshold
+	newTied := func(mode TieBreak) (*Game, *DayResult) {
+		settings := DefaultSettings()
+		settings.VoteThreshold = VoteThresholdPlurality
+		settings.TieBreak = mode
+		game := newTestGame(t, 7, &settings)
+		return game, tiedVote(t, game)
+	}
+	tied := []string{"p1", "p2"}
+
+	t.Run("no_elimination", func(t *testing.T) {
+		game, result := newTied(TieBreakNoElimination)
+		if !slices.Equal(result.Tied, tied) || !result.NoMajority || result.Runoff || result.EliminatedID != "" {
+			t.Errorf("result = %+v", result)
+		}
+		if n := len(game.GetAlivePlayers()); n != 7 {
+			t.Errorf("%d alive, want 7", n)
+		}
+	})
+
+	t.Run("random", func(t *testing.T) {
+		_, result := newTied(TieBreakRandom)
+		if !slices.Equal(result.Tied, tied) || !slices.Contains(tied, result.EliminatedID) {
+			t.Fatalf("result = %+v", result)
+		}
+		// The same seed breaks the same tie the same way
+		for range 5 {
+			if _, again := newTied(TieBreakRandom); again.EliminatedID != result.EliminatedID {
+				t.Fatalf("seeded tie eliminated %s, then %s", result.EliminatedID, again.EliminatedID)
+			}
+		}
+	})
+
+	t.Run("revote", func(t *testing.T) {
+		game, result := newTied(TieBreakRevote)
+		if !slices.Equal(result.Tied, tied) || !result.Runoff || result.EliminatedID != "" {
+			t.Fatalf("result = %+v", result)
+		}
+
+		game.StartRunoff(time.Minute, result.Tied)
+		if err := game.SubmitDayVote("p0", "p3"); err != ErrInvalidTarget {
+			t.Errorf("runoff vote outside the tie: err = %v, want ErrInvalidTarget", err)
+		}
+		for _, voter := range []string{"p0", "p2", "p3", "p4", "p5", "p6"} {
+			if err := game.SubmitDayVote(voter, "p1"); err != nil {
+				t.Fatalf("runoff vote by %s: %v", voter, err)
+			}
+		}
+		if runoff := game.ResolveDay(); runoff.EliminatedID != "p1" {
+			t.Errorf("runoff eliminated %q, want p1", runoff.EliminatedID)
+		}
+	})
+}
+
+func TestRevotesStopAtMaxRevotes(t *testing.T) {
+	settings := DefaultSettings()
+	settings.VoteThreshold = VoteThresholdPlurality
+	settings.TieBreak = TieBreakRevote
+	settings.MaxRevotes = 2
+	game := newTestGame(t, 7, &settings)
+
+	result := tiedVote(t, game)
+	for revote := 1; revote <= 2; revote++ {
+		if !result.Runoff {
+			t.Fatalf("tie before revote %d: result = %+v, want a runoff", revote, result)
+		}
+		game.StartRunoff(time.Minute, result.Tied)
+		for voter, target := range map[string]string{
+			"p0": "p1", "p3": "p1", "p4": "p1",
+			"p1": "p2", "p5": "p2", "p6": "p2",
+		} {
+			if err := game.SubmitDayVote(voter, target); err != nil {
+				t.Fatalf("revote %d: %s votes %s: %v", revote, voter, target, err)
+			}
+		}
+		result = game.ResolveDay()
+	}
+
+	// Still tied after the last allowed revote: nobody dies, the day ends
+	if result.Runoff || !result.NoMajority || result.EliminatedID != "" {
+		t.Errorf("tie after %d revotes = %+v, want no elimination", game.GetRevotes(), result)
+	}
+	if n := len(game.GetAlivePlayers()); n != 7 {
+		t.Errorf("%d alive, want 7", n)
+	}
+
+	// The next day starts with its revotes back
+	if result := tiedVote(t, game); !result.Runoff || game.GetRevotes() != 0 {
+		t.Errorf("tie on a new day = %+v after %d revotes, want a runoff", result, game.GetRevotes())
+	}
+}
+
+func TestVoteLockAfterSubmit(t *testing.T) {
+	for _, locked := range []bool{false, true} {
+		settings := DefaultSettings()
+		settings.LockVoteAfterSubmit = locked
+		game := newTestGame(t, 7, &settings)
+		game.StartDay(time.Minute)
+
+		if err := game.SubmitDayVote("p0", "p1"); err != nil {
+			t.Fatalf("locked %v: first vote: %v", locked, err)
+		}
+		err := game.SubmitDayVote("p0", "p2")
+		if locked && err != ErrVoteLocked {
+			t.Errorf("locked %v: changed vote: err = %v, want ErrVoteLocked", locked, err)
+		}
+		if !locked && err != nil {
+			t.Errorf("locked %v: changed vote: %v", locked, err)
+		}
+
+		want := map[string]int{"p2": 1}
+		if locked {
+			want = map[string]int{"p1": 1}
+		}
+		if got := game.GetVoteCounts(); !maps.Equal(got, want) {
+			t.Errorf("locked %v: counts = %v, want %v", locked, got, want)
+		}
+
+		// A fresh day unlocks everyone again
+		game.StartDay(time.Minute)
+		if err := game.SubmitDayVote("p0", "p2"); err != nil {
+			t.Errorf("locked %v: vote on a new day: %v", locked, err)
+		}
+	}
+}
+
+func TestVoteThresholdModes(t *testing.T) {
+	// Seven alive: p1 leads with three votes, short of the four a majority needs
+	split := map[string]string{
+		"p0": "p1", "p3": "p1", "p4": "p1",
+		"p1": "p2", "p5": "p2",
+		"p2": "", "p6": "",
+	}
+	// p1 and p2 share the lead
+	tie := map[string]string{
+		"p0": "p1", "p3": "p1",
+		"p1": "p2", "p5": "p2",
+		"p2": "p3", "p4": "", "p6": "",
+	}
+	resolve := func(threshold VoteThreshold, votes map[string]string) *DayResult {
+		settings := DefaultSettings()
+		settings.VoteThreshold = threshold
+		game := newTestGame(t, 7, &settings)
+		game.StartDay(time.Minute)
+		for voter, target := range votes {
+			if err := game.SubmitDayVote(voter, target); err != nil {
+				t.Fatalf("%s votes %q: %v", voter, target, err)
+			}
+		}
+		return game.ResolveDay()
+	}
+
+	for _, tt := range []struct {
+		name       string
+		threshold  VoteThreshold
+		votes      map[string]string
+		eliminated string
+	}{
+		{"majority without a majority", VoteThresholdMajority, split, ""},
+		{"plurality leader", VoteThresholdPlurality, split, "p1"},
+		{"majority tie", VoteThresholdMajority, tie, ""},
+		{"plurality tie", VoteThresholdPlurality, tie, ""},
+		{"unset behaves as majority", "", split, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result := resolve(tt.threshold, tt.votes)
+			if result.EliminatedID != tt.eliminated || result.NoMajority != (tt.eliminated == "") {
+				t.Errorf("eliminated %q (no majority %v), want %q", result.EliminatedID, result.NoMajority, tt.eliminated)
+			}
+		})
+	}
+}
+
+func TestTieBreakAppliesOnlyAtTheThreshold(t *testing.T) {
+	// tiedVote's 3-3 split ties the lead, short of the four a majority needs
+	for _, threshold := range []VoteThreshold{VoteThresholdMajority, VoteThresholdPlurality} {
+		for _, mode := range []TieBreak{TieBreakNoElimination, TieBreakRandom, TieBreakRevote} {
+			settings := DefaultSettings()
+			settings.VoteThreshold = threshold
+			settings.TieBreak = mode
+			game := newTestGame(t, 7, &settings)
+			result := tiedVote(t, game)
+
+			reached := threshold == VoteThresholdPlurality
+			wantEliminated := reached && mode == TieBreakRandom
+			if (result.EliminatedID != "") != wantEliminated || result.NoMajority == wantEliminated {
+				t.Errorf("%s/%s: eliminated %q (no majority %v)", threshold, mode, result.EliminatedID, result.NoMajority)
+			}
+			if wantRunoff := reached && mode == TieBreakRevote; result.Runoff != wantRunoff {
+				t.Errorf("%s/%s: runoff = %v, want %v", threshold, mode, result.Runoff, wantRunoff)
+			}
+			if (len(result.Tied) != 0) != reached {
+				t.Errorf("%s/%s: tied = %v", threshold, mode, result.Tied)
+			}
+			if n := len(game.GetAlivePlayers()); wantEliminated != (n == 6) {
+				t.Errorf("%s/%s: %d alive", threshold, mode, n)
+			}
+		}
+	}
+}
+
+func TestRandomTieBreakAsksToConfirmAGameEndingLynch(t *testing.T) {
+	settings := DefaultSettings()
+	settings.VoteThreshold = VoteThresholdPlurality
+	settings.TieBreak = TieBreakRandom
+	settings.ConfirmGameEndingLynch = true
+	game := newTestGame(t, 7, &settings)
+
+	// Left with one mafioso and two townsfolk, either lynch ends the game
+	byRole := playersByRole(game)
+	mafioso := byRole[RoleMafia][0]
+	var town []string
+	for _, id := range game.Room.PlayerOrder {
+		if game.Roles[id].GetTeam() != TeamTown {
+			continue
+		}
+		if len(town) < 2 {
+			town = append(town, id)
+		} else {
+			game.Room.GetPlayer(id).Status = PlayerStatusDead
+		}
+	}
+	for _, id := range byRole[RoleMafia][1:] {
+		game.Room.GetPlayer(id).Status = PlayerStatusDead
+	}
+
+	game.StartDay(time.Minute)
+	for voter, target := range map[string]string{mafioso: town[0], town[0]: mafioso, town[1]: ""} {
+		if err := game.SubmitDayVote(voter, target); err != nil {
+			t.Fatalf("%s votes %q: %v", voter, target, err)
+		}
+	}
+	result := game.ResolveDay()
+	if len(result.Tied) != 2 || !slices.Contains(result.Tied, result.ConfirmTarget) {
+		t.Fatalf("result = %+v, want the random pick held for confirmation", result)
+	}
+	if result.EliminatedID != "" || len(game.GetAlivePlayers()) != 3 {
+		t.Errorf("random pick %s died before the confirmation vote", result.EliminatedID)
+	}
+}
@@ -0,0 +1,98 @@
+package entity
+
+import "errors"
+
+// Forced role errors
+var (
+	ErrUnknownRole             = errors.New("unknown role")
+	ErrForcedRolesExceedCounts = errors.New("assigned roles exceed the configured counts")
+)
+
+// ValidRole reports whether role is one that can be dealt
+func ValidRole(role Role) bool {
+	switch role {
+	case RoleVillager, RoleMafia, RoleGodfather, RoleDoctor, RoleDetective, RoleCupid:
+		return true
+	default:
+		return false
+	}
+}
+
+// AssignRole records that the host wants playerID dealt role at the next
+// start; an empty role returns the player to the random deal. The forced
+// roles must fit the configured counts for the players seated now.
+func (r *Room) AssignRole(playerID string, role Role) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.State != RoomStateWaiting {
+		return ErrGameAlreadyStarted
+	}
+	if _, ok := r.Players[playerID]; !ok {
+		return ErrPlayerNotFound
+	}
+
+	if role == "" {
+		delete(r.ForcedRoles, playerID)
+		return nil
+	}
+	if !ValidRole(role) {
+		return ErrUnknownRole
+	}
+
+	forced := make(map[string]Role, len(r.ForcedRoles)+1)
+	for id, assigned := range r.ForcedRoles {
+		forced[id] = assigned
+	}
+	forced[playerID] = role
+
+	pool, err := BuildRolePool(r.Settings, len(r.PlayerOrder))
+	if err != nil {
+		return err
+	}
+	if _, err := takeForced(pool, forced, r.PlayerOrder); err != nil {
+		return err
+	}
+	r.ForcedRoles = forced
+	return nil
+}
+
+// GetForcedRoles returns a copy of the host's forced assignments
+// (player ID -> role)
+func (r *Room) GetForcedRoles() map[string]Role {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	forced := make(map[string]Role, len(r.ForcedRoles))
+	for id, role := range r.ForcedRoles {
+		forced[id] = role
+	}
+	return forced
+}
+
+// takeForced removes the forced roles of the players in playerIDs from pool
+// and returns what is left for the random deal
+func takeForced(pool []Role, forced map[string]Role, playerIDs []string) ([]Role, error) {
+	remaining := append([]Role{}, pool...)
+	for _, id := range playerIDs {
+		role, ok := forced[id]
+		if !ok {
+			continue
+		}
+		i := indexOfRole(remaining, role)
+		if i < 0 {
+			return nil, ErrForcedRolesExceedCounts
+		}
+		remaining = append(remaining[:i], remaining[i+1:]...)
+	}
+	return remaining, nil
+}
+
+func indexOfRole(roles []Role, role Role) int {
+	for i, r := range roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestFullyForcedDeal(t *testing.T) {
+	room := newReadyRoom(t, 7, nil)
+	want := []Role{RoleDoctor, RoleMafia, RoleVillager, RoleDetective, RoleVillager, RoleMafia, RoleVillager}
+	for i, role := range want {
+		if err := room.AssignRole(room.PlayerOrder[i], role); err != nil {
+			t.Fatalf("assign %s to %s: %v", role, room.PlayerOrder[i], err)
+		}
+	}
+
+	// Every seat is already taken by the settings' counts
+	if err := room.AssignRole(room.PlayerOrder[2], RoleMafia); err != ErrForcedRolesExceedCounts {
+		t.Errorf("third mafia: err = %v, want ErrForcedRolesExceedCounts", err)
+	}
+	if err := room.AssignRole(room.PlayerOrder[2], "jester"); err != ErrUnknownRole {
+		t.Errorf("unknown role: err = %v, want ErrUnknownRole", err)
+	}
+
+	for seed := int64(1); seed <= 5; seed++ {
+		room.State = RoomStateWaiting
+		game, err := NewGameWithRand(room, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("seed %d: new game: %v", seed, err)
+		}
+		for i, id := range room.PlayerOrder {
+			if game.Roles[id] != want[i] {
+				t.Errorf("seed %d: %s dealt %s, want %s", seed, id, game.Roles[id], want[i])
+			}
+		}
+	}
+
+	// Returning to the lobby goes back to a random deal
+	room.State = RoomStateEnded
+	if err := room.ResetToLobby(); err != nil {
+		t.Fatalf("reset to lobby: %v", err)
+	}
+	if forced := room.GetForcedRoles(); len(forced) != 0 {
+		t.Errorf("forced roles after return to lobby: %v", forced)
+	}
+}
+
+func TestPartiallyForcedDealFillsTheRest(t *testing.T) {
+	room := newReadyRoom(t, 7, nil)
+	doctor, mafioso := room.PlayerOrder[3], room.PlayerOrder[5]
+	if err := room.AssignRole(doctor, RoleDoctor); err != nil {
+		t.Fatalf("assign doctor: %v", err)
+	}
+	if err := room.AssignRole(mafioso, RoleMafia); err != nil {
+		t.Fatalf("assign mafia: %v", err)
+	}
+
+	for seed := int64(1); seed <= 20; seed++ {
+		room.State = RoomStateWaiting
+		game, err := NewGameWithRand(room, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("seed %d: new game: %v", seed, err)
+		}
+		if game.Roles[doctor] != RoleDoctor || game.Roles[mafioso] != RoleMafia {
+			t.Fatalf("seed %d: forced players dealt %s and %s", seed, game.Roles[doctor], game.Roles[mafioso])
+		}
+
+		// The other five seats share what is left of the default pool
+		counts := make(map[Role]int)
+		for _, id := range room.PlayerOrder {
+			if id != doctor && id != mafioso {
+				counts[game.Roles[id]]++
+			}
+		}
+		want := map[Role]int{RoleMafia: 1, RoleDetective: 1, RoleVillager: 3}
+		if len(counts) != len(want) {
+			t.Fatalf("seed %d: remaining roles %v, want %v", seed, counts, want)
+		}
+		for role, n := range want {
+			if counts[role] != n {
+				t.Fatalf("seed %d: remaining roles %v, want %v", seed, counts, want)
+			}
+		}
+	}
+}
@@ -2,7 +2,10 @@ package entity
 
 import (
 	"errors"
+	"maps"
 	"math/rand"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 )
@@ -11,23 +14,34 @@ import (
 type GamePhase string
 
 const (
-	PhaseRoleReveal  GamePhase = "role_reveal"
-	PhaseNight       GamePhase = "night"
-	PhaseNightResult GamePhase = "night_result"
-	PhaseDay         GamePhase = "day"
-	PhaseDayResult   GamePhase = "day_result"
-	PhaseGameOver    GamePhase = "game_over"
+	PhaseRoleReveal    GamePhase = "role_reveal"
+	PhaseCupid         GamePhase = "cupid" // Cupid picks the lovers before the first night
+	PhaseNight         GamePhase = "night"
+	PhaseNightResult   GamePhase = "night_result"
+	PhaseDayDiscussion GamePhase = "day_discussion" // day before voting opens
+	PhaseDay           GamePhase = "day"
+	PhaseLynchConfirm  GamePhase = "lynch_confirm" // yes/no vote on a game-ending lynch
+	PhaseDayResult     GamePhase = "day_result"
+	PhaseGameOver      GamePhase = "game_over"
 )
 
 // Game errors
 var (
-	ErrGameNotStarted    = errors.New("game not started")
-	ErrInvalidPhase      = errors.New("invalid phase for this action")
-	ErrPlayerDead        = errors.New("player is dead")
-	ErrInvalidTarget     = errors.New("invalid target")
-	ErrAlreadyActed      = errors.New("player already acted this phase")
-	ErrCannotTargetSelf  = errors.New("cannot target self")
-	ErrMafiaTargetMafia  = errors.New("mafia cannot target mafia")
+	ErrGameNotStarted      = errors.New("game not started")
+	ErrInvalidPhase        = errors.New("invalid phase for this action")
+	ErrPlayerDead          = errors.New("player is dead")
+	ErrInvalidTarget       = errors.New("invalid target")
+	ErrAlreadyActed        = errors.New("player already acted this phase")
+	ErrCannotTargetSelf    = errors.New("cannot target self")
+	ErrMafiaTargetMafia    = errors.New("mafia cannot target mafia")
+	ErrTooManyRoles        = errors.New("more special roles than players")
+	ErrVotingNotOpen       = errors.New("voting is not open yet")
+	ErrSelfHealLimit       = errors.New("doctor self-heal limit reached")
+	ErrAlreadyInvestigated = errors.New("player already investigated")
+	ErrNotGodfather        = errors.New("only the godfather can do this")
+	ErrConfirmNotRequired  = errors.New("kill confirmation is not enabled")
+	ErrVoteLocked          = errors.New("vote already submitted")
+	ErrSameTargetTwice     = errors.New("cannot protect the same player two nights in a row")
 )
 
 // NightActions holds the actions taken during the night
@@ -35,7 +49,10 @@ type NightActions struct {
 	MafiaTarget     string            // player ID targeted by mafia
 	MafiaVotes      map[string]string // mafia player ID -> target ID
 	DoctorTarget    string            // player ID protected by doctor
+	DoctorID        string            // doctor who chose DoctorTarget
 	DetectiveTarget string            // player ID investigated by detective
+	DetectiveID     string            // detective who chose DetectiveTarget
+	KillConfirmed   bool              // godfather locked MafiaTarget (GodfatherDecides only)
 }
 
 // DayVotes holds the votes during the day phase
@@ -49,24 +66,62 @@ type DayVotes struct {
 type NightResult struct {
 	KilledID        string // empty if saved
 	KilledNickname  string
+	KilledRole      Role // empty unless roles are revealed on death
 	WasSaved        bool
+	AttemptedID     string      // mafia target when a kill was attempted (empty if none)
+	Heartbreak      *Heartbreak // the killed player's lover, who died with them
 	DetectiveResult *DetectiveResult
 }
 
+// Heartbreak is a lover who died because their partner did
+type Heartbreak struct {
+	PlayerID string
+	Nickname string
+	Role     Role // empty unless roles are revealed on death
+}
+
 // DetectiveResult contains investigation result (only sent to detective)
 type DetectiveResult struct {
-	TargetID       string
-	TargetNickname string
-	IsMafia        bool
+	DetectiveID       string
+	TargetID          string
+	TargetNickname    string
+	IsMafia           bool
+	PreviouslyChecked bool // the detective had already investigated this target
 }
 
 // DayResult contains the outcome of voting
 type DayResult struct {
 	EliminatedID       string
 	EliminatedNickname string
-	EliminatedRole     Role
+	EliminatedRole     Role           // empty unless roles are revealed on death
 	VoteCounts         map[string]int // target ID -> vote count
 	NoMajority         bool
+	Tied               []string    // targets sharing the most votes, sorted (nil without a tie)
+	Runoff             bool        // a runoff vote between the tied targets follows
+	Heartbreak         *Heartbreak // the eliminated player's lover, who died with them
+
+	// ConfirmTarget is set when the vote would end the game and a
+	// confirmation vote follows; nobody has been eliminated yet
+	ConfirmTarget string
+	// Confirmation is the tally when this result settles a confirmation vote
+	Confirmation *LynchConfirmation
+}
+
+// DeathCause records why a player died
+type DeathCause string
+
+const (
+	DeathCauseMafia      DeathCause = "mafia"      // killed at night
+	DeathCauseLynch      DeathCause = "lynch"      // voted out during the day
+	DeathCauseHeartbreak DeathCause = "heartbreak" // their lover died
+)
+
+// Death is one entry in the game's ordered death log
+type Death struct {
+	PlayerID string     `json:"player_id"`
+	Nickname string     `json:"nickname"`
+	Round    int        `json:"round"`
+	Cause    DeathCause `json:"cause"`
 }
 
 // Game represents an active game instance
@@ -85,6 +140,18 @@ type Game struct {
 	// Day phase
 	DayVotes *DayVotes
 
+	// Targets allowed in the current runoff vote (nil outside a runoff)
+	RunoffCandidates []string
+	// Runoff votes held so far today
+	Revotes int
+
+	// Who holds the floor while voting under TurnBasedVoting
+	Speaker SpeakingTurn
+
+	// Game-ending lynch awaiting confirmation (see ConfirmGameEndingLynch)
+	PendingLynch string
+	ConfirmVotes map[string]bool // voter ID -> confirms
+
 	// Results
 	LastNightResult *NightResult
 	LastDayResult   *DayResult
@@ -93,11 +160,59 @@ type Game struct {
 	// Godfather immunity - becomes false after first investigation
 	GodfatherImmunityUsed bool
 
+	// Nights each doctor protected themselves (doctor ID -> count)
+	DoctorSelfHeals map[string]int
+
+	// Who each doctor protected on the previous night (doctor ID -> target);
+	// a doctor who protected nobody has no entry
+	LastDoctorTargets map[string]string
+
+	// Targets each detective has investigated (detective ID -> target IDs)
+	Investigations map[string]map[string]bool
+
+	// Deaths in the order they happened
+	DeathLog []Death
+
+	// Per-player tallies for the end-of-game stats (see Stats)
+	VotesCast      map[string]int // player ID -> ballots naming a target
+	NightsSurvived map[string]int // player ID -> nights resolved while alive
+
+	// Public outcome of each round so far (see GetTimeline)
+	Timeline []TimelineEntry
+
+	// Lover links, both directions (player ID -> lover ID; empty without the Lovers setting)
+	Lovers map[string]string
+
+	// Seed the random source was created from (0 when injected directly);
+	// dealing with the same seed reproduces the role assignment
+	Seed int64
+
+	// Random source for role assignment (injectable for deterministic tests)
+	rng *rand.Rand
+
+	// Night resolvers in priority order (nightResolvers unless a test swaps them)
+	resolvers []nightResolver
+
 	mu sync.RWMutex
 }
 
 // NewGame creates a new game from a room
 func NewGame(room *Room) (*Game, error) {
+	return NewGameWithSeed(room, time.Now().UnixNano())
+}
+
+// NewGameWithSeed creates a new game whose roles are dealt from seed
+func NewGameWithSeed(room *Room, seed int64) (*Game, error) {
+	g, err := NewGameWithRand(room, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+	g.Seed = seed
+	return g, nil
+}
+
+// NewGameWithRand creates a new game using the given random source for role assignment
+func NewGameWithRand(room *Room, rng *rand.Rand) (*Game, error) {
 	if room.PlayerCount() < MinPlayers {
 		return nil, ErrNotEnoughPlayers
 	}
@@ -106,11 +221,27 @@ func NewGame(room *Room) (*Game, error) {
 		return nil, ErrNotAllReady
 	}
 
+	if err := room.Settings.Validate(room.PlayerCount()); err != nil {
+		return nil, err
+	}
+
 	g := &Game{
 		Room:  room,
 		Phase: PhaseRoleReveal,
 		Round: 1,
 		Roles: make(map[string]Role),
+		rng:   rng,
+
+		resolvers: nightResolvers,
+
+		DoctorSelfHeals: make(map[string]int),
+		Investigations:  make(map[string]map[string]bool),
+		Lovers:          make(map[string]string),
+
+		LastDoctorTargets: make(map[string]string),
+
+		VotesCast:      make(map[string]int),
+		NightsSurvived: make(map[string]int),
 	}
 
 	// Assign roles
@@ -136,8 +267,62 @@ func (g *Game) assignRoles() error {
 		}
 	}
 
-	// Build role pool
-	roles := make([]Role, 0)
+	roles, err := BuildRolePool(settings, len(playerIDs))
+	if err != nil {
+		return err
+	}
+
+	// Roles the host dealt by hand come out of the pool first
+	forced := g.Room.GetForcedRoles()
+	roles, err = takeForced(roles, forced, playerIDs)
+	if err != nil {
+		return err
+	}
+
+	// Shuffle roles
+	g.rng.Shuffle(len(roles), func(i, j int) {
+		roles[i], roles[j] = roles[j], roles[i]
+	})
+
+	// Assign to players
+	for _, playerID := range playerIDs {
+		role, ok := forced[playerID]
+		if !ok {
+			role, roles = roles[0], roles[1:]
+		}
+		g.Roles[playerID] = role
+		g.Room.Players[playerID].Role = role
+	}
+
+	// Pick the lovers independently of their roles; Cupid picks them later
+	if settings.Lovers && !settings.HasCupid() {
+		g.pairRandomLoversLocked()
+	}
+
+	return nil
+}
+
+// pairRandomLoversLocked links two random players as lovers. Caller must hold g.mu.
+func (g *Game) pairRandomLoversLocked() {
+	playerIDs := make([]string, 0, len(g.Room.Players))
+	for _, id := range g.Room.PlayerOrder {
+		if _, ok := g.Room.Players[id]; ok {
+			playerIDs = append(playerIDs, id)
+		}
+	}
+	if len(playerIDs) < 2 {
+		return
+	}
+	picks := g.rng.Perm(len(playerIDs))
+	a, b := playerIDs[picks[0]], playerIDs[picks[1]]
+	g.Lovers[a] = b
+	g.Lovers[b] = a
+}
+
+// BuildRolePool returns the unshuffled roles dealt for the given settings,
+// filling any seats left after special roles with villagers
+func BuildRolePool(settings GameSettings, playerCount int) ([]Role, error) {
+	roles := make([]Role, 0, playerCount)
 	for i := 0; i < settings.Mafia; i++ {
 		roles = append(roles, RoleMafia)
 	}
@@ -150,24 +335,20 @@ func (g *Game) assignRoles() error {
 	for i := 0; i < settings.Detective; i++ {
 		roles = append(roles, RoleDetective)
 	}
+	if settings.HasCupid() {
+		roles = append(roles, RoleCupid)
+	}
+	if len(roles) > playerCount {
+		return nil, ErrTooManyRoles
+	}
+
 	// Fill remaining with villagers
-	villagerCount := len(playerIDs) - len(roles)
+	villagerCount := playerCount - len(roles)
 	for i := 0; i < villagerCount; i++ {
 		roles = append(roles, RoleVillager)
 	}
 
-	// Shuffle roles
-	rand.Shuffle(len(roles), func(i, j int) {
-		roles[i], roles[j] = roles[j], roles[i]
-	})
-
-	// Assign to players
-	for i, playerID := range playerIDs {
-		g.Roles[playerID] = roles[i]
-		g.Room.Players[playerID].Role = roles[i]
-	}
-
-	return nil
+	return roles, nil
 }
 
 // StartNight transitions to night phase
@@ -203,57 +384,156 @@ func (g *Game) SubmitNightAction(playerID, targetID string) error {
 	if !role.CanActAtNight() {
 		return ErrInvalidPhase
 	}
+	if !g.Room.Settings.AllowActionChange {
+		if (role == RoleDoctor && g.NightActions.DoctorID == playerID) ||
+			(role == RoleDetective && g.NightActions.DetectiveID == playerID) {
+			return ErrAlreadyActed
+		}
+	}
 
 	// Validate target
 	if targetID != "" {
-		target := g.Room.GetPlayer(targetID)
-		if target == nil {
-			return ErrInvalidTarget
-		}
-		if target.Status != PlayerStatusAlive {
-			return ErrInvalidTarget
-		}
-
-		// Role-specific validation
-		switch role {
-		case RoleMafia, RoleGodfather:
-			// Can't target fellow mafia
-			if g.Roles[targetID].GetTeam() == TeamMafia {
-				return ErrMafiaTargetMafia
-			}
-		case RoleDoctor:
-			// Doctor can protect anyone including self
-		case RoleDetective:
-			// Can't investigate self
-			if targetID == playerID {
-				return ErrCannotTargetSelf
-			}
+		if err := g.nightTargetErrLocked(playerID, role, targetID); err != nil {
+			return err
 		}
 	}
 
 	// Record action
 	switch role {
 	case RoleMafia, RoleGodfather:
+		// A confirmed kill is locked for the rest of the night
+		if g.NightActions.KillConfirmed {
+			return ErrAlreadyActed
+		}
 		g.NightActions.MafiaVotes[playerID] = targetID
 		// Resolve mafia target (majority or godfather decides)
 		g.resolveMafiaTarget()
+		// A godfather without living teammates confirms by voting
+		if role == RoleGodfather && g.killConfirmRequiredLocked() && targetID != "" && g.aliveMafiaCountLocked() == 1 {
+			g.NightActions.KillConfirmed = true
+		}
 	case RoleDoctor:
 		g.NightActions.DoctorTarget = targetID
+		g.NightActions.DoctorID = playerID
 	case RoleDetective:
 		g.NightActions.DetectiveTarget = targetID
+		g.NightActions.DetectiveID = playerID
+	}
+
+	return nil
+}
+
+// nightTargetErrLocked checks a non-empty night target against the rules
+// of the acting player's role. Caller must hold g.mu.
+func (g *Game) nightTargetErrLocked(playerID string, role Role, targetID string) error {
+	target := g.Room.GetPlayer(targetID)
+	if target == nil {
+		return ErrInvalidTarget
+	}
+	if target.Status != PlayerStatusAlive {
+		return ErrInvalidTarget
+	}
+
+	// Role-specific validation
+	switch role {
+	case RoleMafia, RoleGodfather:
+		// Can't target fellow mafia
+		if g.Roles[targetID].GetTeam() == TeamMafia {
+			return ErrMafiaTargetMafia
+		}
+	case RoleDoctor:
+		// Doctor can protect anyone, self only while under the limit
+		settings := g.Room.Settings
+		if targetID == playerID && settings.LimitDoctorSelfHeals && g.DoctorSelfHeals[playerID] >= settings.DoctorSelfHealLimit {
+			return ErrSelfHealLimit
+		}
+		if g.Room.Settings.DoctorNoConsecutiveSameTarget && g.LastDoctorTargets[playerID] == targetID {
+			return ErrSameTargetTwice
+		}
+	case RoleDetective:
+		// Can't investigate self
+		if targetID == playerID {
+			return ErrCannotTargetSelf
+		}
+		if g.Room.Settings.RejectRepeatInvestigations && g.Investigations[playerID][targetID] {
+			return ErrAlreadyInvestigated
+		}
+	}
+	return nil
+}
+
+// ConfirmKill locks the current mafia target (GodfatherDecides only)
+func (g *Game) ConfirmKill(playerID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Phase != PhaseNight {
+		return ErrInvalidPhase
+	}
+	if !g.Room.Settings.GodfatherDecides {
+		return ErrConfirmNotRequired
 	}
 
+	player := g.Room.GetPlayer(playerID)
+	if player == nil {
+		return ErrPlayerNotFound
+	}
+	if player.Status != PlayerStatusAlive {
+		return ErrPlayerDead
+	}
+	if g.Roles[playerID] != RoleGodfather {
+		return ErrNotGodfather
+	}
+	if g.NightActions.MafiaTarget == "" {
+		return ErrInvalidTarget
+	}
+
+	g.NightActions.KillConfirmed = true
 	return nil
 }
 
-// resolveMafiaTarget determines the final mafia target
+// killConfirmRequiredLocked reports whether tonight's kill waits on a living godfather
+func (g *Game) killConfirmRequiredLocked() bool {
+	if !g.Room.Settings.GodfatherDecides {
+		return false
+	}
+	for playerID, role := range g.Roles {
+		if role != RoleGodfather {
+			continue
+		}
+		if player := g.Room.GetPlayer(playerID); player != nil && player.Status == PlayerStatusAlive {
+			return true
+		}
+	}
+	return false
+}
+
+// aliveMafiaCountLocked returns the number of living mafia (godfather included)
+func (g *Game) aliveMafiaCountLocked() int {
+	count := 0
+	for playerID, role := range g.Roles {
+		if role.GetTeam() != TeamMafia {
+			continue
+		}
+		if player := g.Room.GetPlayer(playerID); player != nil && player.Status == PlayerStatusAlive {
+			count++
+		}
+	}
+	return count
+}
+
+// resolveMafiaTarget determines the final mafia target from the current
+// votes, ignoring any vote that has gone stale (dead or mafia target).
+// Caller must hold g.mu.
 func (g *Game) resolveMafiaTarget() {
+	g.NightActions.MafiaTarget = ""
+
 	// Count votes for each target
 	voteCounts := make(map[string]int)
 	var godfatherVote string
 
 	for mafiaID, targetID := range g.NightActions.MafiaVotes {
-		if targetID == "" {
+		if !g.validMafiaTargetLocked(targetID) {
 			continue
 		}
 		voteCounts[targetID]++
@@ -268,76 +548,134 @@ func (g *Game) resolveMafiaTarget() {
 		return
 	}
 
-	// Otherwise, pick majority (or first if tie)
+	// Otherwise, pick majority (ties go to the lowest ID so the result is stable)
+	targets := make([]string, 0, len(voteCounts))
+	for target := range voteCounts {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
 	maxVotes := 0
-	for target, count := range voteCounts {
-		if count > maxVotes {
-			maxVotes = count
+	for _, target := range targets {
+		if voteCounts[target] > maxVotes {
+			maxVotes = voteCounts[target]
 			g.NightActions.MafiaTarget = target
 		}
 	}
 }
 
+// validMafiaTargetLocked reports whether the mafia may still kill targetID:
+// a living player outside the mafia. Caller must hold g.mu.
+func (g *Game) validMafiaTargetLocked(targetID string) bool {
+	if targetID == "" || g.Roles[targetID].GetTeam() == TeamMafia {
+		return false
+	}
+	target := g.Room.GetPlayer(targetID)
+	return target != nil && target.Status == PlayerStatusAlive
+}
+
+// TryTransition moves the game from the expected phase to next and reports
+// whether it did. Only one of several racing callers wins, so a phase that a
+// timer and a final action both try to close is resolved once.
+func (g *Game) TryTransition(expected, next GamePhase) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Phase != expected {
+		return false
+	}
+	g.Phase = next
+	return true
+}
+
 // ResolveNight processes night actions and returns the result
 func (g *Game) ResolveNight() *NightResult {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	g.Phase = PhaseNightResult
-	result := &NightResult{}
 
-	// Night 1 has no kills - Mafia only identifies each other
+	// By default Night 1 has no kills - Mafia only identifies each other
 	// Check if this is Night 1 by seeing if no day phase has occurred yet
 	isFirstNight := g.LastDayResult == nil
+	ctx := newNightContext(!isFirstNight || g.Room.Settings.AllowFirstNightKill)
 
-	// Check if mafia target was saved
-	mafiaTarget := g.NightActions.MafiaTarget
-	doctorTarget := g.NightActions.DoctorTarget
+	for _, resolve := range g.resolvers {
+		resolve(g, ctx)
+	}
+	g.applyCasualtiesLocked(ctx)
+	g.recordNightLocked(ctx.Result)
+	g.recordSurvivorsLocked()
 
-	// Only process kill if not first night
-	if mafiaTarget != "" && !isFirstNight {
-		if mafiaTarget == doctorTarget {
-			result.WasSaved = true
-		} else {
-			// Player dies
-			if player := g.Room.GetPlayer(mafiaTarget); player != nil {
-				player.Status = PlayerStatusDead
-				result.KilledID = mafiaTarget
-				result.KilledNickname = player.Nickname
-			}
-		}
+	g.LastNightResult = ctx.Result
+	return ctx.Result
+}
+
+// GetPhase returns the current phase
+func (g *Game) GetPhase() GamePhase {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Phase
+}
+
+// AdvanceRound moves the game on to its next round and returns it
+func (g *Game) AdvanceRound() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.Round++
+	return g.Round
+}
+
+// GameSnapshot is a copy of the game's progress taken under the game lock
+type GameSnapshot struct {
+	Phase GamePhase
+	Round int
+	Roles map[string]Role // player ID -> role
+
+	RunoffCandidates []string // nil outside a runoff
+	PendingLynch     string   // empty outside a lynch confirmation
+}
+
+// Snapshot copies the phase, round, roles and open vote state together, so a
+// state resync never mixes fields from two phases
+func (g *Game) Snapshot() GameSnapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return GameSnapshot{
+		Phase:            g.Phase,
+		Round:            g.Round,
+		Roles:            maps.Clone(g.Roles),
+		RunoffCandidates: slices.Clone(g.RunoffCandidates),
+		PendingLynch:     g.PendingLynch,
 	}
+}
 
-	// Detective investigation
-	if g.NightActions.DetectiveTarget != "" {
-		targetID := g.NightActions.DetectiveTarget
-		if target := g.Room.GetPlayer(targetID); target != nil {
-			targetRole := g.Roles[targetID]
-			var isMafia bool
-			if targetRole == RoleGodfather {
-				// Godfather has one-time immunity
-				if g.GodfatherImmunityUsed {
-					// Immunity already used, appears as mafia
-					isMafia = true
-				} else {
-					// First investigation, use immunity, appears as town
-					isMafia = false
-					g.GodfatherImmunityUsed = true
-				}
-			} else {
-				// Regular mafia check
-				isMafia = targetRole == RoleMafia
-			}
-			result.DetectiveResult = &DetectiveResult{
-				TargetID:       targetID,
-				TargetNickname: target.Nickname,
-				IsMafia:        isMafia,
-			}
-		}
+// PhaseRemaining returns the time left in the current phase as of now.
+// Returns false for phases without a timer (role reveal, results, game over).
+func (g *Game) PhaseRemaining(now time.Time) (time.Duration, bool) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	switch g.Phase {
+	case PhaseCupid, PhaseNight, PhaseDayDiscussion, PhaseDay, PhaseLynchConfirm:
+	default:
+		return 0, false
 	}
 
-	g.LastNightResult = result
-	return result
+	remaining := g.PhaseEndTime.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// StartDayDiscussion transitions to the discussion part of the day (no voting)
+func (g *Game) StartDayDiscussion(duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Phase = PhaseDayDiscussion
+	g.PhaseEndTime = time.Now().Add(duration)
 }
 
 // StartDay transitions to day phase
@@ -345,8 +683,30 @@ func (g *Game) StartDay(duration time.Duration) {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	g.Revotes = 0
+	g.startDayLocked(duration, nil)
+}
+
+// StartRunoff reopens day voting limited to the given tied targets
+func (g *Game) StartRunoff(duration time.Duration, candidates []string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Revotes++
+	g.startDayLocked(duration, candidates)
+}
+
+// GetRevotes returns how many runoff votes have been held today
+func (g *Game) GetRevotes() int {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Revotes
+}
+
+func (g *Game) startDayLocked(duration time.Duration, runoffCandidates []string) {
 	g.Phase = PhaseDay
 	g.PhaseEndTime = time.Now().Add(duration)
+	g.RunoffCandidates = runoffCandidates
 	g.DayVotes = &DayVotes{
 		Votes:     make(map[string]string),
 		VotedTime: make(map[string]time.Time),
@@ -359,6 +719,9 @@ func (g *Game) SubmitDayVote(voterID, targetID string) error {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if g.Phase == PhaseDayDiscussion {
+		return ErrVotingNotOpen
+	}
 	if g.Phase != PhaseDay {
 		return ErrInvalidPhase
 	}
@@ -370,18 +733,14 @@ func (g *Game) SubmitDayVote(voterID, targetID string) error {
 	if voter.Status != PlayerStatusAlive {
 		return ErrPlayerDead
 	}
+	if g.Room.Settings.LockVoteAfterSubmit && g.DayVotes.Submitted[voterID] {
+		return ErrVoteLocked
+	}
 
 	// Validate target (empty = skip vote)
 	if targetID != "" {
-		target := g.Room.GetPlayer(targetID)
-		if target == nil {
-			return ErrInvalidTarget
-		}
-		if target.Status != PlayerStatusAlive {
-			return ErrInvalidTarget
-		}
-		if targetID == voterID {
-			return ErrCannotTargetSelf
+		if err := g.voteTargetErrLocked(voterID, targetID); err != nil {
+			return err
 		}
 	}
 
@@ -392,12 +751,31 @@ func (g *Game) SubmitDayVote(voterID, targetID string) error {
 	return nil
 }
 
+// voteTargetErrLocked checks a non-empty day vote target. Caller must hold g.mu.
+func (g *Game) voteTargetErrLocked(voterID, targetID string) error {
+	target := g.Room.GetPlayer(targetID)
+	if target == nil {
+		return ErrInvalidTarget
+	}
+	if target.Status != PlayerStatusAlive {
+		return ErrInvalidTarget
+	}
+	if targetID == voterID {
+		return ErrCannotTargetSelf
+	}
+	if g.RunoffCandidates != nil && !slices.Contains(g.RunoffCandidates, targetID) {
+		return ErrInvalidTarget
+	}
+	return nil
+}
+
 // ResolveDay processes votes and returns the result
 func (g *Game) ResolveDay() *DayResult {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
 	g.Phase = PhaseDayResult
+	g.Speaker.PlayerID = "" // the floor opens again once voting closes
 	result := &DayResult{
 		VoteCounts: make(map[string]int),
 	}
@@ -408,49 +786,145 @@ func (g *Game) ResolveDay() *DayResult {
 			result.VoteCounts[targetID]++
 		}
 	}
+	g.recordBallotsLocked()
 
-	// Find majority
-	alivePlayers := g.getAlivePlayerCount()
-	majorityNeeded := (alivePlayers / 2) + 1
+	// Find majority, or just the leader under plurality voting
+	votesNeeded := (g.getAlivePlayerCount() / 2) + 1
+	if g.Room.Settings.VoteThreshold == VoteThresholdPlurality {
+		votesNeeded = 1
+	}
 
+	// Collect every target sharing the top count, sorted so ties resolve
+	// the same way regardless of map iteration order
 	var maxVotes int
-	var topTarget string
+	var topTargets []string
 	for targetID, votes := range result.VoteCounts {
-		if votes > maxVotes {
+		switch {
+		case votes > maxVotes:
 			maxVotes = votes
-			topTarget = targetID
+			topTargets = []string{targetID}
+		case votes == maxVotes:
+			topTargets = append(topTargets, targetID)
 		}
 	}
+	sort.Strings(topTargets)
 
-	if maxVotes >= majorityNeeded {
-		// Elimination
-		if player := g.Room.GetPlayer(topTarget); player != nil {
-			player.Status = PlayerStatusDead
-			result.EliminatedID = topTarget
-			result.EliminatedNickname = player.Nickname
-			result.EliminatedRole = g.Roles[topTarget]
-		}
-	} else {
+	g.RunoffCandidates = nil
+
+	// The threshold applies before any tie-break: a tie short of it is no
+	// majority, whatever the tie-break policy
+	switch {
+	case maxVotes < votesNeeded:
 		result.NoMajority = true
+	case len(topTargets) > 1:
+		result.Tied = topTargets
+		switch g.Room.Settings.TieBreak {
+		case TieBreakRandom:
+			g.lynchLocked(result, topTargets[g.rng.Intn(len(topTargets))])
+		case TieBreakRevote:
+			// A tie still standing after the last allowed runoff eliminates nobody
+			result.Runoff = g.Revotes < g.Room.Settings.RevoteLimit()
+			result.NoMajority = true
+		default:
+			result.NoMajority = true
+		}
+	default:
+		g.lynchLocked(result, topTargets[0])
 	}
 
+	g.recordVoteLocked(result)
 	g.LastDayResult = result
 	return result
 }
 
+// lynchLocked eliminates the day vote's target, or holds them for the
+// confirmation vote when their death would end the game. Caller must hold g.mu.
+func (g *Game) lynchLocked(result *DayResult, targetID string) {
+	if g.needsLynchConfirmLocked(targetID) {
+		// Nobody dies until the confirmation vote (see ResolveLynchConfirm)
+		result.ConfirmTarget = targetID
+		g.PendingLynch = targetID
+		return
+	}
+	g.eliminateLocked(result, targetID)
+}
+
+// eliminateLocked kills the voted-out player and records them in the result
+func (g *Game) eliminateLocked(result *DayResult, targetID string) {
+	player := g.Room.GetPlayer(targetID)
+	if player == nil {
+		return
+	}
+	player.Status = PlayerStatusDead
+	g.recordDeathLocked(player, DeathCauseLynch)
+	result.EliminatedID = targetID
+	result.EliminatedNickname = player.Nickname
+	result.EliminatedRole = g.Room.Settings.Visibility().DeathRole(g.Roles[targetID])
+	result.Heartbreak = g.killLoverLocked(targetID)
+}
+
+// killLoverLocked kills the lover of a player who just died. Returns nil when
+// the player has no lover or the lover is already dead. Caller must hold g.mu.
+func (g *Game) killLoverLocked(playerID string) *Heartbreak {
+	loverID, ok := g.Lovers[playerID]
+	if !ok {
+		return nil
+	}
+	lover := g.Room.GetPlayer(loverID)
+	if lover == nil || lover.Status != PlayerStatusAlive {
+		return nil
+	}
+
+	lover.Status = PlayerStatusDead
+	g.recordDeathLocked(lover, DeathCauseHeartbreak)
+	return &Heartbreak{
+		PlayerID: loverID,
+		Nickname: lover.Nickname,
+		Role:     g.Room.Settings.Visibility().DeathRole(g.Roles[loverID]),
+	}
+}
+
+// GetLover returns the player's lover, or empty if they have none
+func (g *Game) GetLover(playerID string) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Lovers[playerID]
+}
+
+// recordDeathLocked appends a death to the log. Caller must hold g.mu.
+func (g *Game) recordDeathLocked(player *Player, cause DeathCause) {
+	g.DeathLog = append(g.DeathLog, Death{
+		PlayerID: player.ID,
+		Nickname: player.Nickname,
+		Round:    g.Round,
+		Cause:    cause,
+	})
+}
+
+// GetDeathLog returns a copy of the deaths so far, in order
+func (g *Game) GetDeathLog() []Death {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]Death{}, g.DeathLog...)
+}
+
 // CheckWinCondition checks if the game has ended
 func (g *Game) CheckWinCondition() (bool, Team) {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
+	return g.winConditionLocked(nil)
+}
 
+// winConditionLocked checks the win condition as if the players in dying
+// were already dead. Caller must hold g.mu.
+func (g *Game) winConditionLocked(dying map[string]bool) (bool, Team) {
 	var townAlive, mafiaAlive int
 
 	for playerID, player := range g.Room.Players {
-		if player.Status != PlayerStatusAlive {
+		if player.Status != PlayerStatusAlive || dying[playerID] {
 			continue
 		}
-		role := g.Roles[playerID]
-		if role.GetTeam() == TeamMafia {
+		if g.Roles[playerID].GetTeam() == TeamMafia {
 			mafiaAlive++
 		} else {
 			townAlive++
@@ -470,14 +944,50 @@ func (g *Game) CheckWinCondition() (bool, Team) {
 	return false, ""
 }
 
-// EndGame marks the game as over
-func (g *Game) EndGame(winner Team) {
+// EndGame marks the game as over. It reports false if the game had already
+// ended, so of several callers racing to end it only one goes on to announce it.
+func (g *Game) EndGame(winner Team) bool {
 	g.mu.Lock()
 	defer g.mu.Unlock()
 
+	if g.Phase == PhaseGameOver {
+		return false
+	}
+	// A day cut short never reaches ResolveDay, so count its ballots here
+	if g.Phase == PhaseDay && g.DayVotes != nil {
+		g.recordBallotsLocked()
+	}
 	g.Phase = PhaseGameOver
 	g.Winner = winner
 	g.Room.State = RoomStateEnded
+	return true
+}
+
+// WinnerIDs returns the players credited with the win, in seat order
+func (g *Game) WinnerIDs() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	winners := make([]string, 0)
+	if g.Winner == "" {
+		return winners
+	}
+	for _, id := range g.Room.PlayerOrder {
+		if role, ok := g.Roles[id]; ok && role.GetTeam() == g.Winner {
+			winners = append(winners, id)
+		}
+	}
+	return winners
+}
+
+// WinnerLabel describes the result for the game over screen
+func (g *Game) WinnerLabel() string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	if g.Winner == "" {
+		return ""
+	}
+	return g.Winner.Label() + " wins"
 }
 
 // GetAlivePlayerCount returns the number of alive players
@@ -505,6 +1015,94 @@ func (g *Game) GetAlivePlayers() []string {
 	return alive
 }
 
+// BotTargets picks a move for every living bot that acts in the current phase.
+// At night mafia bots share one target (following any target their team has
+// already chosen), doctors protect someone else and detectives prefer players
+// they have not checked. In the day bots vote for another living player (only
+// runoff candidates in a runoff), mafia bots preferring town.
+func (g *Game) BotTargets() map[string]string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var alive []string
+	for _, id := range g.Room.PlayerOrder {
+		if p, ok := g.Room.Players[id]; ok && p.Status == PlayerStatusAlive {
+			alive = append(alive, id)
+		}
+	}
+	isTown := func(id string) bool { return g.Roles[id].GetTeam() != TeamMafia }
+
+	var mafiaTarget string
+	if g.Phase == PhaseNight {
+		mafiaTarget = g.NightActions.MafiaTarget
+	}
+
+	targets := make(map[string]string)
+	for _, botID := range alive {
+		if !g.Room.Players[botID].IsBot {
+			continue
+		}
+		notSelf := func(id string) bool { return id != botID }
+		role := g.Roles[botID]
+
+		var candidates []string
+		switch {
+		case g.Phase == PhaseNight && role.GetTeam() == TeamMafia:
+			if mafiaTarget == "" {
+				mafiaTarget = g.pickLocked(filterIDs(alive, isTown))
+			}
+			candidates = []string{mafiaTarget}
+		case g.Phase == PhaseNight && role == RoleDoctor:
+			candidates = filterIDs(alive, func(id string) bool {
+				return id != botID && !(g.Room.Settings.DoctorNoConsecutiveSameTarget && g.LastDoctorTargets[botID] == id)
+			})
+		case g.Phase == PhaseNight && role == RoleDetective:
+			candidates = filterIDs(alive, func(id string) bool {
+				return id != botID && !g.Investigations[botID][id]
+			})
+			if len(candidates) == 0 && !g.Room.Settings.RejectRepeatInvestigations {
+				candidates = filterIDs(alive, notSelf)
+			}
+		case g.Phase == PhaseLynchConfirm:
+			candidates = []string{g.PendingLynch}
+		case g.Phase == PhaseDay:
+			pool := alive
+			if g.RunoffCandidates != nil {
+				pool = g.RunoffCandidates
+			}
+			candidates = filterIDs(pool, notSelf)
+			if role.GetTeam() == TeamMafia {
+				if town := filterIDs(candidates, isTown); len(town) > 0 {
+					candidates = town
+				}
+			}
+		}
+
+		if target := g.pickLocked(candidates); target != "" {
+			targets[botID] = target
+		}
+	}
+	return targets
+}
+
+// pickLocked returns a random ID from ids, or empty if there are none. Caller must hold g.mu.
+func (g *Game) pickLocked(ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[g.rng.Intn(len(ids))]
+}
+
+func filterIDs(ids []string, keep func(string) bool) []string {
+	var kept []string
+	for _, id := range ids {
+		if id != "" && keep(id) {
+			kept = append(kept, id)
+		}
+	}
+	return kept
+}
+
 // GetMafiaTeammates returns the IDs of other mafia members (for a mafia player)
 func (g *Game) GetMafiaTeammates(playerID string) []string {
 	g.mu.RLock()
@@ -519,34 +1117,69 @@ func (g *Game) GetMafiaTeammates(playerID string) []string {
 	return teammates
 }
 
+// waitingOn reports whether phase completion waits for this player
+func (g *Game) waitingOn(player *Player) bool {
+	if player.Status != PlayerStatusAlive {
+		return false
+	}
+	return player.IsConnected || !g.Room.Settings.SkipDisconnected
+}
+
 // AllNightActionsComplete checks if all night actors have submitted
 func (g *Game) AllNightActionsComplete() bool {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
 	for playerID, player := range g.Room.Players {
-		if player.Status != PlayerStatusAlive {
+		if !g.waitingOn(player) {
+			continue
+		}
+		role := g.Roles[playerID]
+		if role.CanActAtNight() && !g.hasActedLocked(playerID, role) {
+			return false
+		}
+	}
+	return true
+}
+
+// NightProgress returns how many night actors have acted out of how many the
+// night is waiting on, without revealing who they are
+func (g *Game) NightProgress() (acted, total int) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	for playerID, player := range g.Room.Players {
+		if !g.waitingOn(player) {
 			continue
 		}
 		role := g.Roles[playerID]
 		if !role.CanActAtNight() {
 			continue
 		}
+		total++
+		if g.hasActedLocked(playerID, role) {
+			acted++
+		}
+	}
+	return acted, total
+}
 
-		switch role {
-		case RoleMafia, RoleGodfather:
-			if _, ok := g.NightActions.MafiaVotes[playerID]; !ok {
-				return false
-			}
-		case RoleDoctor:
-			if g.NightActions.DoctorTarget == "" {
-				return false
-			}
-		case RoleDetective:
-			if g.NightActions.DetectiveTarget == "" {
-				return false
-			}
+// hasActedLocked reports whether a night actor has submitted their action
+func (g *Game) hasActedLocked(playerID string, role Role) bool {
+	switch role {
+	case RoleMafia:
+		_, ok := g.NightActions.MafiaVotes[playerID]
+		return ok
+	case RoleGodfather:
+		if g.Room.Settings.GodfatherDecides {
+			return g.NightActions.KillConfirmed
 		}
+		_, ok := g.NightActions.MafiaVotes[playerID]
+		return ok
+	case RoleDoctor:
+		return g.NightActions.DoctorTarget != ""
+	case RoleDetective:
+		return g.NightActions.DetectiveTarget != ""
 	}
 	return true
 }
@@ -557,7 +1190,7 @@ func (g *Game) AllDayVotesComplete() bool {
 	defer g.mu.RUnlock()
 
 	for _, player := range g.Room.Players {
-		if player.Status != PlayerStatusAlive {
+		if !g.waitingOn(player) {
 			continue
 		}
 		if _, ok := g.DayVotes.Votes[player.ID]; !ok {
@@ -639,6 +1272,16 @@ func (g *Game) GetRoleRevealData(playerID string) map[string]any {
 		data["teammates"] = teammates
 	}
 
+	// Lovers know each other
+	if loverID, ok := g.Lovers[playerID]; ok {
+		if p := g.Room.GetPlayer(loverID); p != nil {
+			data["lover"] = map[string]string{
+				"id":       loverID,
+				"nickname": p.Nickname,
+			}
+		}
+	}
+
 	return data
 }
 
@@ -662,3 +1305,39 @@ func (g *Game) GetMafiaVotes() map[string]string {
 	}
 	return votes
 }
+
+// MafiaVoteStatus summarizes the mafia's night vote for its members
+type MafiaVoteStatus struct {
+	Voted     int    // living mafia with a vote on a valid target
+	Alive     int    // living mafia, godfather included
+	Consensus bool   // every living member votes for the same target
+	Target    string // the agreed target when Consensus is set
+}
+
+// MafiaVoteStatus returns how far the mafia are from agreeing on a target.
+// Votes on targets that are no longer valid do not count.
+func (g *Game) MafiaVoteStatus() MafiaVoteStatus {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	status := MafiaVoteStatus{Alive: g.aliveMafiaCountLocked()}
+	if g.NightActions == nil {
+		return status
+	}
+
+	targets := make(map[string]bool)
+	for mafiaID, targetID := range g.NightActions.MafiaVotes {
+		voter := g.Room.GetPlayer(mafiaID)
+		if voter == nil || voter.Status != PlayerStatusAlive || !g.validMafiaTargetLocked(targetID) {
+			continue
+		}
+		status.Voted++
+		targets[targetID] = true
+		status.Target = targetID
+	}
+	status.Consensus = status.Alive > 0 && status.Voted == status.Alive && len(targets) == 1
+	if !status.Consensus {
+		status.Target = ""
+	}
+	return status
+}
@@ -0,0 +1,51 @@
+package entity
+
+// PlayerStats is one player's end-of-game scoreboard line
+type PlayerStats struct {
+	VotesCast      int  `json:"votes_cast"`      // day and runoff ballots naming a target
+	NightsSurvived int  `json:"nights_survived"` // nights resolved with the player still alive
+	Investigated   bool `json:"investigated"`    // checked by a detective at least once
+}
+
+// recordBallotsLocked counts the day vote's ballots towards each voter's
+// stats. Caller must hold g.mu.
+func (g *Game) recordBallotsLocked() {
+	for voterID, targetID := range g.DayVotes.Votes {
+		if targetID != "" {
+			g.VotesCast[voterID]++
+		}
+	}
+}
+
+// recordSurvivorsLocked credits a resolved night to everyone still alive.
+// Caller must hold g.mu.
+func (g *Game) recordSurvivorsLocked() {
+	for _, player := range g.Room.Players {
+		if player.Status == PlayerStatusAlive {
+			g.NightsSurvived[player.ID]++
+		}
+	}
+}
+
+// Stats returns every seated player's stats (player ID -> stats)
+func (g *Game) Stats() map[string]PlayerStats {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	investigated := make(map[string]bool)
+	for _, checked := range g.Investigations {
+		for targetID := range checked {
+			investigated[targetID] = true
+		}
+	}
+
+	stats := make(map[string]PlayerStats, len(g.Room.PlayerOrder))
+	for _, playerID := range g.Room.PlayerOrder {
+		stats[playerID] = PlayerStats{
+			VotesCast:      g.VotesCast[playerID],
+			NightsSurvived: g.NightsSurvived[playerID],
+			Investigated:   investigated[playerID],
+		}
+	}
+	return stats
+}
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatsFollowScriptedGame(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, villagers, detective := byRole[RoleMafia], byRole[RoleVillager], byRole[RoleDetective][0]
+
+	// Night 1: a villager dies and the detective checks a mafioso
+	game.StartNight(time.Minute)
+	for _, id := range mafia {
+		if err := game.SubmitNightAction(id, villagers[0]); err != nil {
+			t.Fatalf("%s targets %s: %v", id, villagers[0], err)
+		}
+	}
+	if err := game.SubmitNightAction(detective, mafia[0]); err != nil {
+		t.Fatalf("detective investigates: %v", err)
+	}
+	game.ResolveNight()
+
+	// Day 1: everyone but the mafioso votes them out
+	lynch(t, game, mafia[0])
+
+	// Night 2: the last mafioso kills another villager
+	game.Round++
+	killAtNight(t, game, mafia[1:], villagers[1])
+
+	want := map[string]PlayerStats{
+		villagers[0]: {},
+		mafia[0]:     {NightsSurvived: 1, Investigated: true},
+		villagers[1]: {VotesCast: 1, NightsSurvived: 1},
+	}
+	for _, id := range game.Room.PlayerOrder {
+		if _, ok := want[id]; !ok {
+			want[id] = PlayerStats{VotesCast: 1, NightsSurvived: 2}
+		}
+	}
+
+	stats := game.Stats()
+	if len(stats) != len(want) {
+		t.Fatalf("stats for %d players, want %d", len(stats), len(want))
+	}
+	for id, w := range want {
+		if got := stats[id]; got != w {
+			t.Errorf("%s (%s) stats = %+v, want %+v", id, game.Roles[id], got, w)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package entity
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestInjectedRandDealsDeterministically(t *testing.T) {
+	deal := func(seed int64) map[string]Role {
+		game, err := NewGameWithRand(newReadyRoom(t, 8, nil), rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("new game: %v", err)
+		}
+		return game.Roles
+	}
+
+	first := deal(42)
+	if !maps.Equal(first, deal(42)) {
+		t.Fatalf("same seed dealt differently: %v", first)
+	}
+
+	counts := make(map[Role]int)
+	for _, role := range first {
+		counts[role]++
+	}
+	if len(first) != 8 || counts[RoleMafia]+counts[RoleGodfather] == 0 {
+		t.Errorf("dealt %v", counts)
+	}
+
+	// Some other seed shuffles the same roles into another order
+	for seed := int64(1); seed <= 20; seed++ {
+		if !maps.Equal(first, deal(seed)) {
+			return
+		}
+	}
+	t.Error("20 different seeds all dealt the same assignment")
+}
+
+func TestPhaseRemaining(t *testing.T) {
+	game := newTestGame(t, 7, nil)
+	if _, ok := game.PhaseRemaining(time.Now()); ok {
+		t.Error("role reveal reported a timer")
+	}
+
+	// Day voting has no ticker but still records when it ends
+	for _, start := range []func(time.Duration){game.StartNight, game.StartDayDiscussion, game.StartDay} {
+		start(2 * time.Minute)
+		end := game.PhaseEndTime
+		for _, tt := range []struct {
+			now  time.Time
+			want time.Duration
+		}{
+			{end.Add(-2 * time.Minute), 2 * time.Minute},
+			{end.Add(-45 * time.Second), 45 * time.Second},
+			{end.Add(time.Second), 0},
+		} {
+			got, ok := game.PhaseRemaining(tt.now)
+			if !ok || got != tt.want {
+				t.Errorf("%s at end%+v: %v, %v; want %v", game.Phase, tt.now.Sub(end), got, ok, tt.want)
+			}
+		}
+	}
+}
+
+func TestPhasesCompleteWithoutDisconnectedPlayers(t *testing.T) {
+	for _, skip := range []bool{false, true} {
+		settings := DefaultSettings()
+		settings.SkipDisconnected = skip
+		game := newTestGame(t, 7, &settings)
+		byRole := playersByRole(game)
+		detective, villager := byRole[RoleDetective][0], byRole[RoleVillager][0]
+		game.Room.GetPlayer(detective).IsConnected = false
+		game.Room.GetPlayer(villager).IsConnected = false
+
+		// Every connected night actor acts; the detective never does
+		game.StartNight(time.Minute)
+		for _, id := range byRole[RoleMafia] {
+			if err := game.SubmitNightAction(id, villager); err != nil {
+				t.Fatalf("skip %v: %s acts: %v", skip, id, err)
+			}
+		}
+		doctor := byRole[RoleDoctor][0]
+		if err := game.SubmitNightAction(doctor, doctor); err != nil {
+			t.Fatalf("skip %v: doctor acts: %v", skip, err)
+		}
+		if got := game.AllNightActionsComplete(); got != skip {
+			t.Errorf("skip %v: night complete = %v", skip, got)
+		}
+		game.ResolveNight()
+
+		// Every connected player votes; the two disconnected never do
+		game.StartDay(time.Minute)
+		for _, id := range game.GetAlivePlayers() {
+			if id == detective || id == villager {
+				continue
+			}
+			if err := game.SubmitDayVote(id, ""); err != nil {
+				t.Fatalf("skip %v: %s votes: %v", skip, id, err)
+			}
+		}
+		if got := game.AllDayVotesComplete(); got != skip {
+			t.Errorf("skip %v: day complete = %v", skip, got)
+		}
+	}
+}
+
+func TestDeathLogFollowsScriptedGame(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, villagers := byRole[RoleMafia], byRole[RoleVillager]
+
+	// Night 1: the mafia kill a villager
+	killAtNight(t, game, mafia, villagers[0])
+	// Day 1: a mafia member is lynched
+	lynch(t, game, mafia[0])
+	// Night 2: the other mafia member kills again
+	game.Round = 2
+	killAtNight(t, game, mafia[1:], villagers[1])
+
+	want := []Death{
+		{villagers[0], "Player " + villagers[0][1:], 1, DeathCauseMafia},
+		{mafia[0], "Player " + mafia[0][1:], 1, DeathCauseLynch},
+		{villagers[1], "Player " + villagers[1][1:], 2, DeathCauseMafia},
+	}
+	if got := game.GetDeathLog(); !slices.Equal(got, want) {
+		t.Errorf("death log = %+v, want %+v", got, want)
+	}
+}
+
+func TestDeathLogRecordsHeartbreaks(t *testing.T) {
+	game, a, b, mafia := pairLovers(t)
+
+	// Night 1: the mafia kill a lover, whose partner dies of heartbreak
+	game.Round = 1
+	killAtNight(t, game, mafia, a)
+	// Day 1: a mafia member is lynched
+	lynch(t, game, mafia[0])
+	// Night 2: the other mafia member kills again
+	game.Round = 2
+	var victim string
+	for _, id := range game.GetAlivePlayers() {
+		if game.Roles[id].GetTeam() == TeamTown {
+			victim = id
+			break
+		}
+	}
+	killAtNight(t, game, mafia[1:], victim)
+
+	want := []Death{
+		{a, "Player " + a[1:], 1, DeathCauseMafia},
+		{b, "Player " + b[1:], 1, DeathCauseHeartbreak},
+		{mafia[0], "Player " + mafia[0][1:], 1, DeathCauseLynch},
+		{victim, "Player " + victim[1:], 2, DeathCauseMafia},
+	}
+	if got := game.GetDeathLog(); !slices.Equal(got, want) {
+		t.Errorf("death log = %+v, want %+v", got, want)
+	}
+}
+
+func TestWinnerLabels(t *testing.T) {
+	for _, tt := range []struct {
+		winner Team
+		want   string
+	}{
+		{TeamTown, "Town wins"},
+		{TeamMafia, "Mafia wins"},
+		{TeamNeutral, "Neutral wins"},
+	} {
+		game := newTestGame(t, 7, nil)
+		game.EndGame(tt.winner)
+		if got := game.WinnerLabel(); got != tt.want {
+			t.Errorf("%s: label = %q, want %q", tt.winner, got, tt.want)
+		}
+		for _, id := range game.WinnerIDs() {
+			if team := game.GetPlayerRole(id).GetTeam(); team != tt.winner {
+				t.Errorf("%s: winner %s is on %s", tt.winner, id, team)
+			}
+		}
+	}
+}
+
+func TestSnapshotIsACopy(t *testing.T) {
+	game := newTestGame(t, 7, nil)
+	game.StartNight(time.Minute)
+	round := game.AdvanceRound()
+
+	snapshot := game.Snapshot()
+	if snapshot.Phase != PhaseNight || snapshot.Round != round || !maps.Equal(snapshot.Roles, game.Roles) {
+		t.Fatalf("snapshot = %+v, want night of round %d", snapshot, round)
+	}
+	dealt := game.GetPlayerRole("p0")
+	delete(snapshot.Roles, "p0")
+	if game.GetPlayerRole("p0") != dealt {
+		t.Error("changing the snapshot changed the game's roles")
+	}
+}
@@ -0,0 +1,104 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+)
+
+// pairLovers makes two villagers lovers and returns them with the mafia
+func pairLovers(t *testing.T) (game *Game, a, b string, mafia []string) {
+	t.Helper()
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	game = newTestGame(t, 7, &settings)
+
+	var villagers []string
+	for _, id := range game.Room.PlayerOrder {
+		switch {
+		case game.Roles[id] == RoleVillager:
+			villagers = append(villagers, id)
+		case game.Roles[id].GetTeam() == TeamMafia:
+			mafia = append(mafia, id)
+		}
+	}
+	if len(villagers) < 2 {
+		t.Fatalf("dealt %d villagers, need 2", len(villagers))
+	}
+	a, b = villagers[0], villagers[1]
+	game.Lovers = map[string]string{a: b, b: a}
+	return game, a, b, mafia
+}
+
+func deathCause(game *Game, playerID string) DeathCause {
+	for _, death := range game.GetDeathLog() {
+		if death.PlayerID == playerID {
+			return death.Cause
+		}
+	}
+	return ""
+}
+
+func TestLoverDiesWithNightVictim(t *testing.T) {
+	game, a, b, mafia := pairLovers(t)
+
+	result := killAtNight(t, game, mafia, a)
+	if result.KilledID != a {
+		t.Fatalf("killed %q, want %s", result.KilledID, a)
+	}
+	if result.Heartbreak == nil || result.Heartbreak.PlayerID != b {
+		t.Fatalf("heartbreak = %+v, want %s", result.Heartbreak, b)
+	}
+	if game.Room.GetPlayer(b).Status != PlayerStatusDead || deathCause(game, b) != DeathCauseHeartbreak {
+		t.Errorf("lover %s: status %s, cause %q", b, game.Room.GetPlayer(b).Status, deathCause(game, b))
+	}
+	if slices.Contains(game.GetAlivePlayers(), b) {
+		t.Errorf("lover %s still counted alive", b)
+	}
+}
+
+func TestLoverDiesWithLynchVictim(t *testing.T) {
+	game, a, b, _ := pairLovers(t)
+
+	result := lynch(t, game, a)
+	if result.EliminatedID != a {
+		t.Fatalf("eliminated %q, want %s", result.EliminatedID, a)
+	}
+	if result.Heartbreak == nil || result.Heartbreak.PlayerID != b {
+		t.Fatalf("heartbreak = %+v, want %s", result.Heartbreak, b)
+	}
+	if deathCause(game, b) != DeathCauseHeartbreak {
+		t.Errorf("lover %s died of %q, want heartbreak", b, deathCause(game, b))
+	}
+}
+
+func TestLoverAlreadyDeadIsNotKilledAgain(t *testing.T) {
+	game, a, b, mafia := pairLovers(t)
+	game.Room.GetPlayer(b).Status = PlayerStatusDead
+	result := killAtNight(t, game, mafia, a)
+	if result.KilledID != a {
+		t.Fatalf("killed %q, want %s", result.KilledID, a)
+	}
+	if result.Heartbreak != nil {
+		t.Errorf("heartbreak for an already dead lover: %+v", result.Heartbreak)
+	}
+	if n := len(game.GetDeathLog()); n != 1 {
+		t.Errorf("death log has %d entries, want 1", n)
+	}
+}
+
+func TestLoverDiesWithVictimOfAnyNightAttack(t *testing.T) {
+	game, a, b, _ := pairLovers(t)
+	ctx := newNightContext(true)
+	ctx.Attacks = append(ctx.Attacks, NightAttack{TargetID: a, Cause: "vigilante"})
+
+	game.mu.Lock()
+	game.applyCasualtiesLocked(ctx)
+	game.mu.Unlock()
+
+	if ctx.Result.Heartbreak == nil || ctx.Result.Heartbreak.PlayerID != b {
+		t.Fatalf("heartbreak = %+v, want %s", ctx.Result.Heartbreak, b)
+	}
+	if deathCause(game, a) != "vigilante" || deathCause(game, b) != DeathCauseHeartbreak {
+		t.Errorf("causes %q and %q, want vigilante and heartbreak", deathCause(game, a), deathCause(game, b))
+	}
+}
@@ -0,0 +1,128 @@
+package entity
+
+import "time"
+
+// LynchConfirmSeconds is the length of the vote confirming a game-ending lynch
+const LynchConfirmSeconds = 20
+
+// LynchConfirmation is the tally of a confirmation vote
+type LynchConfirmation struct {
+	TargetID string
+	Yes      int
+	No       int
+}
+
+// Confirmed reports whether more players confirmed the lynch than rejected it
+func (c LynchConfirmation) Confirmed() bool {
+	return c.Yes > c.No
+}
+
+// needsLynchConfirmLocked reports whether lynching targetID must be
+// confirmed first: the setting is on and the death (with any lover's) would
+// end the game. Caller must hold g.mu.
+func (g *Game) needsLynchConfirmLocked(targetID string) bool {
+	if !g.Room.Settings.ConfirmGameEndingLynch {
+		return false
+	}
+	dying := map[string]bool{targetID: true}
+	if loverID, ok := g.Lovers[targetID]; ok {
+		dying[loverID] = true
+	}
+	ended, _ := g.winConditionLocked(dying)
+	return ended
+}
+
+// StartLynchConfirm opens the yes/no vote on the pending lynch
+func (g *Game) StartLynchConfirm(duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Phase = PhaseLynchConfirm
+	g.PhaseEndTime = time.Now().Add(duration)
+	g.ConfirmVotes = make(map[string]bool)
+}
+
+// SubmitLynchConfirm records whether a living player confirms the pending
+// lynch; players may change their answer until the vote closes
+func (g *Game) SubmitLynchConfirm(voterID string, confirm bool) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Phase != PhaseLynchConfirm {
+		return ErrInvalidPhase
+	}
+	voter := g.Room.GetPlayer(voterID)
+	if voter == nil {
+		return ErrPlayerNotFound
+	}
+	if voter.Status != PlayerStatusAlive {
+		return ErrPlayerDead
+	}
+
+	g.ConfirmVotes[voterID] = confirm
+	return nil
+}
+
+// LynchConfirmSubmitted returns who has answered the confirmation vote, in
+// seat order
+func (g *Game) LynchConfirmSubmitted() []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	submitted := make([]string, 0, len(g.ConfirmVotes))
+	for _, id := range g.Room.PlayerOrder {
+		if _, ok := g.ConfirmVotes[id]; ok {
+			submitted = append(submitted, id)
+		}
+	}
+	return submitted
+}
+
+// AllLynchConfirmVotesIn reports whether everyone the vote waits on has answered
+func (g *Game) AllLynchConfirmVotesIn() bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	for _, player := range g.Room.Players {
+		if !g.waitingOn(player) {
+			continue
+		}
+		if _, ok := g.ConfirmVotes[player.ID]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ResolveLynchConfirm settles the confirmation vote: the pending target is
+// eliminated if confirmed, otherwise the day ends with no elimination
+func (g *Game) ResolveLynchConfirm() *DayResult {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Phase = PhaseDayResult
+	tally := &LynchConfirmation{TargetID: g.PendingLynch}
+	for _, confirm := range g.ConfirmVotes {
+		if confirm {
+			tally.Yes++
+		} else {
+			tally.No++
+		}
+	}
+
+	result := &DayResult{
+		VoteCounts:   map[string]int{tally.TargetID: tally.Yes},
+		Confirmation: tally,
+	}
+	if tally.Confirmed() {
+		g.eliminateLocked(result, tally.TargetID)
+	} else {
+		result.NoMajority = true
+	}
+
+	g.PendingLynch = ""
+	g.ConfirmVotes = nil
+	g.recordVoteLocked(result)
+	g.LastDayResult = result
+	return result
+}
@@ -0,0 +1,80 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetPlayerRole(t *testing.T) {
+	game := newTestGame(t, 7, nil)
+
+	for _, id := range game.Room.PlayerOrder {
+		if role := game.GetPlayerRole(id); role == "" || role != game.Roles[id] {
+			t.Errorf("%s: role %q, want dealt role %q", id, role, game.Roles[id])
+		}
+	}
+	if role := game.GetPlayerRole("nobody"); role != "" {
+		t.Errorf("unknown player has role %q, want none", role)
+	}
+}
+
+func TestGetMafiaVotes(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, town := byRole[RoleMafia], byRole[RoleVillager]
+
+	if votes := game.GetMafiaVotes(); votes != nil {
+		t.Errorf("votes before any night = %v, want nil", votes)
+	}
+
+	game.StartNight(time.Minute)
+	if votes := game.GetMafiaVotes(); votes == nil || len(votes) != 0 {
+		t.Errorf("votes at the start of the night = %v, want empty", votes)
+	}
+
+	if err := game.SubmitNightAction(mafia[0], town[0]); err != nil {
+		t.Fatalf("mafia vote: %v", err)
+	}
+	votes := game.GetMafiaVotes()
+	if len(votes) != 1 || votes[mafia[0]] != town[0] {
+		t.Fatalf("votes = %v, want %s -> %s", votes, mafia[0], town[0])
+	}
+
+	// The map is a copy
+	votes[mafia[1]] = town[1]
+	if len(game.GetMafiaVotes()) != 1 {
+		t.Error("changing the returned votes changed the game")
+	}
+}
+
+func TestMafiaVoteStatus(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, town := byRole[RoleMafia], byRole[RoleVillager]
+
+	if status := game.MafiaVoteStatus(); status != (MafiaVoteStatus{Alive: 2}) {
+		t.Errorf("status before any night = %+v, want nobody voted", status)
+	}
+
+	game.StartNight(time.Minute)
+	steps := []struct {
+		voter, target string
+		want          MafiaVoteStatus
+	}{
+		{mafia[0], town[0], MafiaVoteStatus{Voted: 1, Alive: 2}},
+		{mafia[1], town[1], MafiaVoteStatus{Voted: 2, Alive: 2}},
+		{mafia[1], town[0], MafiaVoteStatus{Voted: 2, Alive: 2, Consensus: true, Target: town[0]}},
+	}
+	for _, step := range steps {
+		if err := game.SubmitNightAction(step.voter, step.target); err != nil {
+			t.Fatalf("%s votes %s: %v", step.voter, step.target, err)
+		}
+		if status := game.MafiaVoteStatus(); status != step.want {
+			t.Errorf("after %s votes %s: status %+v, want %+v", step.voter, step.target, status, step.want)
+		}
+	}
+}
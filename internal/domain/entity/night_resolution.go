@@ -0,0 +1,147 @@
+package entity
+
+// NightContext accumulates the night's protections and attacks while the
+// resolvers run. Casualties are only applied once every resolver has had its
+// turn, so a protection always covers an attack regardless of who acted first.
+type NightContext struct {
+	KillAllowed bool            // false on a no-kill first night
+	Protected   map[string]bool // player IDs protected tonight
+	Attacks     []NightAttack   // attacks in resolution order
+	Result      *NightResult
+}
+
+// NightAttack is an attempt on a player's life during the night
+type NightAttack struct {
+	TargetID string
+	Cause    DeathCause
+}
+
+func newNightContext(killAllowed bool) *NightContext {
+	return &NightContext{
+		KillAllowed: killAllowed,
+		Protected:   make(map[string]bool),
+		Result:      &NightResult{},
+	}
+}
+
+// nightResolver applies one kind of night action to the context.
+// Resolvers run with g.mu held.
+type nightResolver func(g *Game, ctx *NightContext)
+
+// nightResolvers run in priority order: protections are laid down before any
+// attack is made, and investigations see roles as they stood at nightfall.
+// New roles slot in here at the priority their interactions need. Every game
+// starts with this list.
+var nightResolvers = []nightResolver{
+	resolveProtection,
+	resolveInvestigation,
+	resolveMafiaKill,
+}
+
+// resolveProtection records the doctor's protection and counts self-heals.
+// It also replaces last night's protections, so a night without one frees
+// the doctor to pick anyone the next night.
+func resolveProtection(g *Game, ctx *NightContext) {
+	doctorTarget := g.NightActions.DoctorTarget
+	g.LastDoctorTargets = make(map[string]string)
+	if doctorTarget == "" {
+		return
+	}
+	g.LastDoctorTargets[g.NightActions.DoctorID] = doctorTarget
+	ctx.Protected[doctorTarget] = true
+
+	// Count self-protection against the doctor's limit
+	if doctorTarget == g.NightActions.DoctorID {
+		g.DoctorSelfHeals[doctorTarget]++
+	}
+}
+
+// resolveInvestigation answers the detective's check
+func resolveInvestigation(g *Game, ctx *NightContext) {
+	targetID := g.NightActions.DetectiveTarget
+	if targetID == "" {
+		return
+	}
+	target := g.Room.GetPlayer(targetID)
+	if target == nil {
+		return
+	}
+
+	// The godfather reads as town to the first investigation
+	isMafia, usesImmunity := g.Room.Settings.Visibility().InvestigationResult(g.Roles[targetID], g.GodfatherImmunityUsed)
+	if usesImmunity {
+		g.GodfatherImmunityUsed = true
+	}
+
+	detectiveID := g.NightActions.DetectiveID
+	checked := g.Investigations[detectiveID]
+	if checked == nil {
+		checked = make(map[string]bool)
+		g.Investigations[detectiveID] = checked
+	}
+	ctx.Result.DetectiveResult = &DetectiveResult{
+		DetectiveID:       detectiveID,
+		TargetID:          targetID,
+		TargetNickname:    target.Nickname,
+		IsMafia:           isMafia,
+		PreviouslyChecked: checked[targetID],
+	}
+	checked[targetID] = true
+}
+
+// resolveMafiaKill settles the mafia's target and queues the attack
+func resolveMafiaKill(g *Game, ctx *NightContext) {
+	// Votes may have gone stale since they were cast; re-derive the target,
+	// and drop a confirmed kill whose target is no longer valid
+	if g.NightActions.KillConfirmed {
+		if !g.validMafiaTargetLocked(g.NightActions.MafiaTarget) {
+			g.NightActions.MafiaTarget = ""
+		}
+	} else {
+		g.resolveMafiaTarget()
+	}
+
+	mafiaTarget := g.NightActions.MafiaTarget
+
+	// An unconfirmed kill does not happen when the godfather decides
+	if g.killConfirmRequiredLocked() && !g.NightActions.KillConfirmed {
+		mafiaTarget = ""
+	}
+
+	if mafiaTarget == "" || !ctx.KillAllowed {
+		return
+	}
+	ctx.Result.AttemptedID = mafiaTarget
+	ctx.Attacks = append(ctx.Attacks, NightAttack{TargetID: mafiaTarget, Cause: DeathCauseMafia})
+}
+
+// applyCasualtiesLocked turns the night's attacks into deaths, skipping
+// protected targets and anyone already dead. Caller must hold g.mu.
+func (g *Game) applyCasualtiesLocked(ctx *NightContext) {
+	result := ctx.Result
+	for _, attack := range ctx.Attacks {
+		if ctx.Protected[attack.TargetID] {
+			if attack.Cause == DeathCauseMafia {
+				result.WasSaved = true
+			}
+			continue
+		}
+
+		player := g.Room.GetPlayer(attack.TargetID)
+		if player == nil || player.Status != PlayerStatusAlive {
+			continue
+		}
+		player.Status = PlayerStatusDead
+		g.recordDeathLocked(player, attack.Cause)
+		// A lover dies of heartbreak whatever killed their partner
+		if heartbreak := g.killLoverLocked(attack.TargetID); heartbreak != nil {
+			result.Heartbreak = heartbreak
+		}
+
+		if attack.Cause == DeathCauseMafia {
+			result.KilledID = attack.TargetID
+			result.KilledNickname = player.Nickname
+			result.KilledRole = g.Room.Settings.Visibility().DeathRole(g.Roles[attack.TargetID])
+		}
+	}
+}
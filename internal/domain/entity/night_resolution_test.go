@@ -0,0 +1,359 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestFirstNightKillSetting(t *testing.T) {
+	for _, allow := range []bool{false, true} {
+		settings := DefaultSettings()
+		settings.AllowFirstNightKill = allow
+		game := newTestGame(t, 7, &settings)
+		byRole := playersByRole(game)
+		target, detective := byRole[RoleVillager][0], byRole[RoleDetective][0]
+
+		game.StartNight(time.Minute)
+		for _, id := range byRole[RoleMafia] {
+			if err := game.SubmitNightAction(id, target); err != nil {
+				t.Fatalf("allow %v: %s targets %s: %v", allow, id, target, err)
+			}
+		}
+		if err := game.SubmitNightAction(detective, byRole[RoleMafia][0]); err != nil {
+			t.Fatalf("allow %v: detective investigates: %v", allow, err)
+		}
+		result := game.ResolveNight()
+
+		alive := game.Room.GetPlayer(target).Status == PlayerStatusAlive
+		if allow && (result.KilledID != target || alive) {
+			t.Errorf("allow %v: killed %q, target alive %v", allow, result.KilledID, alive)
+		}
+		if !allow && (result.KilledID != "" || !alive) {
+			t.Errorf("allow %v: killed %q, target alive %v", allow, result.KilledID, alive)
+		}
+
+		// Investigations work on night one either way
+		check := result.DetectiveResult
+		if check == nil || check.TargetID != byRole[RoleMafia][0] || !check.IsMafia {
+			t.Errorf("allow %v: detective result %+v", allow, check)
+		}
+	}
+}
+
+func TestDoctorSelfHealLimit(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		limited bool
+		limit   int
+		heals   int // nights the doctor may protect themselves
+	}{
+		{"never", true, 0, 0},
+		{"once", true, 1, 1},
+		{"unlimited", false, 0, 4},
+	} {
+		settings := DefaultSettings()
+		settings.LimitDoctorSelfHeals = tt.limited
+		settings.DoctorSelfHealLimit = tt.limit
+		game := newTestGame(t, 7, &settings)
+		byRole := playersByRole(game)
+		doctor, other := byRole[RoleDoctor][0], byRole[RoleVillager][0]
+
+		for night := range 4 {
+			game.StartNight(time.Minute)
+			err := game.SubmitNightAction(doctor, doctor)
+			if night < tt.heals && err != nil {
+				t.Errorf("%s: self-heal on night %d: %v", tt.name, night+1, err)
+			}
+			if night >= tt.heals && err != ErrSelfHealLimit {
+				t.Errorf("%s: self-heal on night %d: err = %v, want ErrSelfHealLimit", tt.name, night+1, err)
+			}
+
+			// Protecting someone else is never limited
+			if err != nil {
+				if err := game.SubmitNightAction(doctor, other); err != nil {
+					t.Errorf("%s: protect other on night %d: %v", tt.name, night+1, err)
+				}
+			}
+			game.ResolveNight()
+		}
+	}
+}
+
+func TestRepeatInvestigations(t *testing.T) {
+	investigate := func(game *Game, detective, target string) (*DetectiveResult, error) {
+		game.StartNight(time.Minute)
+		if err := game.SubmitNightAction(detective, target); err != nil {
+			game.ResolveNight()
+			return nil, err
+		}
+		return game.ResolveNight().DetectiveResult, nil
+	}
+
+	for _, reject := range []bool{false, true} {
+		settings := DefaultSettings()
+		settings.RejectRepeatInvestigations = reject
+		game := newTestGame(t, 7, &settings)
+		byRole := playersByRole(game)
+		detective, target, other := byRole[RoleDetective][0], byRole[RoleMafia][0], byRole[RoleVillager][0]
+
+		first, err := investigate(game, detective, target)
+		if err != nil || first.PreviouslyChecked {
+			t.Fatalf("reject %v: first check = %+v, %v", reject, first, err)
+		}
+
+		second, err := investigate(game, detective, target)
+		switch {
+		case reject && err != ErrAlreadyInvestigated:
+			t.Errorf("reject %v: second check err = %v, want ErrAlreadyInvestigated", reject, err)
+		case !reject && (err != nil || !second.PreviouslyChecked || !second.IsMafia):
+			t.Errorf("reject %v: second check = %+v, %v; want flagged as previously checked", reject, second, err)
+		}
+
+		// A new target is never flagged
+		if fresh, err := investigate(game, detective, other); err != nil || fresh.PreviouslyChecked {
+			t.Errorf("reject %v: new target = %+v, %v", reject, fresh, err)
+		}
+	}
+}
+
+// godfatherGame deals n players with the godfather deciding the kill
+func godfatherGame(t *testing.T, n, mafia int) (*Game, map[Role][]string) {
+	t.Helper()
+	settings := DefaultSettings()
+	settings.Mafia, settings.Godfather = mafia, 1
+	settings.GodfatherDecides = true
+	settings.AllowFirstNightKill = true
+	game := newTestGame(t, n, &settings)
+	game.StartNight(time.Minute)
+
+	// Town actors act first so only the mafia is outstanding
+	byRole := playersByRole(game)
+	doctor := byRole[RoleDoctor][0]
+	if err := game.SubmitNightAction(doctor, doctor); err != nil {
+		t.Fatalf("doctor acts: %v", err)
+	}
+	if err := game.SubmitNightAction(byRole[RoleDetective][0], doctor); err != nil {
+		t.Fatalf("detective acts: %v", err)
+	}
+	return game, byRole
+}
+
+func TestGodfatherConfirmsKill(t *testing.T) {
+	game, byRole := godfatherGame(t, 7, 1)
+	mafia, godfather, target := byRole[RoleMafia][0], byRole[RoleGodfather][0], byRole[RoleVillager][0]
+
+	if err := game.ConfirmKill(godfather); err != ErrInvalidTarget {
+		t.Errorf("confirm before any vote: err = %v, want ErrInvalidTarget", err)
+	}
+	if err := game.SubmitNightAction(mafia, target); err != nil {
+		t.Fatalf("mafia votes: %v", err)
+	}
+	if game.AllNightActionsComplete() {
+		t.Fatal("night complete before the godfather confirmed")
+	}
+	if err := game.ConfirmKill(mafia); err != ErrNotGodfather {
+		t.Errorf("confirm by mafia: err = %v, want ErrNotGodfather", err)
+	}
+
+	if err := game.ConfirmKill(godfather); err != nil {
+		t.Fatalf("godfather confirms: %v", err)
+	}
+	if !game.AllNightActionsComplete() {
+		t.Fatal("night incomplete after the godfather confirmed")
+	}
+	if err := game.SubmitNightAction(mafia, byRole[RoleVillager][1]); err != ErrAlreadyActed {
+		t.Errorf("vote after confirmation: err = %v, want ErrAlreadyActed", err)
+	}
+	if result := game.ResolveNight(); result.KilledID != target {
+		t.Errorf("killed %q, want confirmed target %s", result.KilledID, target)
+	}
+}
+
+func TestLoneGodfatherConfirmsByVoting(t *testing.T) {
+	game, byRole := godfatherGame(t, 6, 0)
+	godfather, target := byRole[RoleGodfather][0], byRole[RoleVillager][0]
+
+	if game.AllNightActionsComplete() {
+		t.Fatal("night complete before the godfather voted")
+	}
+	if err := game.SubmitNightAction(godfather, target); err != nil {
+		t.Fatalf("godfather votes: %v", err)
+	}
+	if !game.AllNightActionsComplete() {
+		t.Fatal("lone godfather's vote did not confirm the kill")
+	}
+	if result := game.ResolveNight(); result.KilledID != target {
+		t.Errorf("killed %q, want %s", result.KilledID, target)
+	}
+}
+
+func TestActionChangeSetting(t *testing.T) {
+	for _, allow := range []bool{true, false} {
+		settings := DefaultSettings()
+		settings.AllowActionChange = allow
+		game := newTestGame(t, 7, &settings)
+		byRole := playersByRole(game)
+		doctor, detective := byRole[RoleDoctor][0], byRole[RoleDetective][0]
+		first, second := byRole[RoleVillager][0], byRole[RoleVillager][1]
+
+		game.StartNight(time.Minute)
+		for _, id := range []string{doctor, detective, byRole[RoleMafia][0]} {
+			if err := game.SubmitNightAction(id, first); err != nil {
+				t.Fatalf("allow %v: %s first action: %v", allow, game.Roles[id], err)
+			}
+		}
+
+		var wantErr error
+		want := second
+		if !allow {
+			wantErr, want = ErrAlreadyActed, first
+		}
+		for _, id := range []string{doctor, detective} {
+			if err := game.SubmitNightAction(id, second); err != wantErr {
+				t.Errorf("allow %v: %s changes target: err = %v, want %v", allow, game.Roles[id], err, wantErr)
+			}
+		}
+		if got := game.NightActions.DoctorTarget; got != want {
+			t.Errorf("allow %v: doctor target %s, want %s", allow, got, want)
+		}
+		if got := game.NightActions.DetectiveTarget; got != want {
+			t.Errorf("allow %v: detective target %s, want %s", allow, got, want)
+		}
+
+		// Mafia keep negotiating their kill either way
+		if err := game.SubmitNightAction(byRole[RoleMafia][0], second); err != nil {
+			t.Errorf("allow %v: mafia changes vote: %v", allow, err)
+		}
+	}
+}
+
+func TestMafiaTargetSkipsStaleVotes(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+
+	// The leading target dies before dawn; the kill falls to the other vote
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, villagers := byRole[RoleMafia], byRole[RoleVillager]
+	game.StartNight(time.Minute)
+	for i, id := range mafia {
+		if err := game.SubmitNightAction(id, villagers[i]); err != nil {
+			t.Fatalf("%s votes %s: %v", id, villagers[i], err)
+		}
+	}
+	if game.NightActions.MafiaTarget != villagers[0] {
+		t.Fatalf("tied vote picked %s, want %s", game.NightActions.MafiaTarget, villagers[0])
+	}
+	game.Room.GetPlayer(villagers[0]).Status = PlayerStatusDead
+	if result := game.ResolveNight(); result.KilledID != villagers[1] {
+		t.Errorf("killed %q after the leading target died, want %s", result.KilledID, villagers[1])
+	}
+
+	// A vote that somehow lands on a teammate is never carried out
+	game = newTestGame(t, 7, &settings)
+	byRole = playersByRole(game)
+	mafia = byRole[RoleMafia]
+	game.StartNight(time.Minute)
+	if err := game.SubmitNightAction(mafia[0], mafia[1]); err != ErrMafiaTargetMafia {
+		t.Errorf("vote for teammate: err = %v, want ErrMafiaTargetMafia", err)
+	}
+	game.NightActions.MafiaVotes[mafia[0]] = mafia[1]
+	if result := game.ResolveNight(); result.KilledID != "" || result.AttemptedID != "" {
+		t.Errorf("stale teammate vote: attempted %q, killed %q", result.AttemptedID, result.KilledID)
+	}
+	if game.Room.GetPlayer(mafia[1]).Status != PlayerStatusAlive {
+		t.Errorf("mafia %s killed by a teammate's vote", mafia[1])
+	}
+}
+
+func TestNightResolversLayerProtectionsAndAttacks(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, villagers, doctor := byRole[RoleMafia], byRole[RoleVillager], byRole[RoleDoctor][0]
+
+	// Stand-ins for a vigilante, who shoots a guarded villager and a
+	// mafioso, and a bodyguard, who guards only after every attack is made
+	const vigilanteShot DeathCause = "vigilante"
+	vigilante := func(g *Game, ctx *NightContext) {
+		for _, id := range []string{villagers[1], mafia[1]} {
+			ctx.Attacks = append(ctx.Attacks, NightAttack{TargetID: id, Cause: vigilanteShot})
+		}
+	}
+	bodyguard := func(g *Game, ctx *NightContext) {
+		ctx.Protected[villagers[1]] = true
+	}
+	game.resolvers = append([]nightResolver{vigilante}, append(slices.Clone(nightResolvers), bodyguard)...)
+
+	// The mafia go for the doctor's patient
+	game.StartNight(time.Minute)
+	for _, id := range mafia {
+		if err := game.SubmitNightAction(id, villagers[0]); err != nil {
+			t.Fatalf("%s votes: %v", id, err)
+		}
+	}
+	if err := game.SubmitNightAction(doctor, villagers[0]); err != nil {
+		t.Fatalf("doctor protects: %v", err)
+	}
+	result := game.ResolveNight()
+
+	if !result.WasSaved || result.KilledID != "" || result.AttemptedID != villagers[0] {
+		t.Errorf("mafia attack on %s: attempted %q, killed %q, saved %v",
+			villagers[0], result.AttemptedID, result.KilledID, result.WasSaved)
+	}
+	for _, id := range villagers[:2] {
+		if game.Room.GetPlayer(id).Status != PlayerStatusAlive {
+			t.Errorf("protected %s died", id)
+		}
+	}
+	if game.Room.GetPlayer(mafia[1]).Status != PlayerStatusDead || deathCause(game, mafia[1]) != vigilanteShot {
+		t.Errorf("unprotected %s: status %s, cause %q", mafia[1], game.Room.GetPlayer(mafia[1]).Status, deathCause(game, mafia[1]))
+	}
+	if deaths := game.GetDeathLog(); len(deaths) != 1 {
+		t.Errorf("death log %+v, want one death", deaths)
+	}
+}
+
+func TestDoctorConsecutiveSameTarget(t *testing.T) {
+	for _, forbid := range []bool{false, true} {
+		settings := DefaultSettings()
+		settings.DoctorNoConsecutiveSameTarget = forbid
+		game := newTestGame(t, 7, &settings)
+		byRole := playersByRole(game)
+		doctor, a, b := byRole[RoleDoctor][0], byRole[RoleVillager][0], byRole[RoleVillager][1]
+
+		var wantRepeat error
+		if forbid {
+			wantRepeat = ErrSameTargetTwice
+		}
+		protect := func(night int, target string, want error) {
+			t.Helper()
+			if err := game.SubmitNightAction(doctor, target); err != want {
+				t.Errorf("forbid %v: night %d protect %s: err = %v, want %v", forbid, night, target, err, want)
+			}
+		}
+
+		game.StartNight(time.Minute)
+		protect(1, a, nil)
+		game.ResolveNight()
+
+		// Only last night's patient is off limits
+		game.StartNight(time.Minute)
+		protect(2, a, wantRepeat)
+		protect(2, b, nil)
+		game.ResolveNight()
+
+		game.StartNight(time.Minute)
+		protect(3, a, nil)
+		protect(3, b, wantRepeat)
+		game.ResolveNight()
+
+		// A night off clears the memory
+		game.StartNight(time.Minute)
+		game.ResolveNight()
+		game.StartNight(time.Minute)
+		protect(5, a, nil)
+	}
+}
@@ -1,5 +1,87 @@
 package entity
 
+import (
+	"errors"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxNicknameLength is the longest nickname allowed, in characters
+const MaxNicknameLength = 20
+
+// ErrInvalidNickname is returned for empty, overlong or unprintable nicknames
+var ErrInvalidNickname = errors.New("invalid nickname")
+
+// NormalizeNickname cleans a nickname for display: zero-width and control
+// characters are dropped, runs of whitespace collapse to one space, and the
+// result must be 1-MaxNicknameLength printable characters
+func NormalizeNickname(nickname string) (string, error) {
+	nickname = strings.Join(strings.Fields(strings.Map(dropInvisible, nickname)), " ")
+	if nickname == "" || utf8.RuneCountInString(nickname) > MaxNicknameLength {
+		return "", ErrInvalidNickname
+	}
+	for _, r := range nickname {
+		if !unicode.IsPrint(r) {
+			return "", ErrInvalidNickname
+		}
+	}
+	return nickname, nil
+}
+
+// NicknameKey returns the form nicknames are compared in for uniqueness, so
+// lookalikes such as "Alice", "ALICE", "Al\u0131ce" and "Ali\u200bce" collide.
+// It applies NFKC, drops invisible characters, case-folds, maps common
+// cross-script homoglyphs to Latin and collapses whitespace.
+func NicknameKey(nickname string) string {
+	nickname = strings.Map(dropInvisible, norm.NFKC.String(nickname))
+	nickname = strings.Map(unconfuse, strings.ToLower(nickname))
+	return strings.Join(strings.Fields(nickname), " ")
+}
+
+// dropInvisible removes format (zero-width, bidi) and control characters
+func dropInvisible(r rune) rune {
+	if unicode.Is(unicode.Cf, r) || (unicode.IsControl(r) && !unicode.IsSpace(r)) {
+		return -1
+	}
+	return r
+}
+
+// homoglyphs maps lowercase letters that render like a Latin letter to it
+var homoglyphs = map[rune]rune{
+	'\u0131': 'i', // dotless i
+	'\u0430': 'a', // Cyrillic a
+	'\u0435': 'e', // Cyrillic ie
+	'\u043e': 'o', // Cyrillic o
+	'\u0440': 'p', // Cyrillic er
+	'\u0441': 'c', // Cyrillic es
+	'\u0443': 'y', // Cyrillic u
+	'\u0445': 'x', // Cyrillic ha
+	'\u0455': 's', // Cyrillic dze
+	'\u0456': 'i', // Cyrillic byelorussian-ukrainian i
+	'\u0458': 'j', // Cyrillic je
+	'\u04bb': 'h', // Cyrillic shha
+	'\u0501': 'd', // Cyrillic komi de
+	'\u03b1': 'a', // Greek alpha
+	'\u03b9': 'i', // Greek iota
+	'\u03ba': 'k', // Greek kappa
+	'\u03bd': 'v', // Greek nu
+	'\u03bf': 'o', // Greek omicron
+	'\u03c1': 'p', // Greek rho
+	'\u03c4': 't', // Greek tau
+	'\u03c5': 'u', // Greek upsilon
+	'\u03c7': 'x', // Greek chi
+}
+
+func unconfuse(r rune) rune {
+	if latin, ok := homoglyphs[r]; ok {
+		return latin
+	}
+	return r
+}
+
 // PlayerStatus represents the player's alive/dead state
 type PlayerStatus string
 
@@ -16,7 +98,9 @@ type Player struct {
 	IsReady     bool
 	IsConnected bool
 	Status      PlayerStatus
-	Role        Role // assigned when game starts
+	Role        Role   // assigned when game starts
+	HistoryKey  string // client-supplied key for game history (empty = not recorded)
+	IsBot       bool   // server-controlled seat filler with no connection or voice
 }
 
 // NewPlayer creates a new player
@@ -31,6 +115,14 @@ func NewPlayer(id, nickname string, isHost bool) *Player {
 	}
 }
 
+// NewBotPlayer creates a bot, always ready and counted as connected
+func NewBotPlayer(id, nickname string) *Player {
+	player := NewPlayer(id, nickname, false)
+	player.IsBot = true
+	player.IsReady = true
+	return player
+}
+
 // ToDTO converts player to a DTO for sending to clients
 func (p *Player) ToDTO() PlayerDTO {
 	return PlayerDTO{
@@ -40,6 +132,7 @@ func (p *Player) ToDTO() PlayerDTO {
 		IsReady:     p.IsReady,
 		IsConnected: p.IsConnected,
 		Status:      string(p.Status),
+		IsBot:       p.IsBot,
 	}
 }
 
@@ -51,4 +144,5 @@ type PlayerDTO struct {
 	IsReady     bool   `json:"is_ready"`
 	IsConnected bool   `json:"is_connected"`
 	Status      string `json:"status"`
+	IsBot       bool   `json:"is_bot"`
 }
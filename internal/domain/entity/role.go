@@ -9,16 +9,32 @@ const (
 	RoleGodfather Role = "godfather"
 	RoleDoctor    Role = "doctor"
 	RoleDetective Role = "detective"
+	RoleCupid     Role = "cupid" // picks the lovers before the first night
 )
 
 // Team represents which team a role belongs to
 type Team string
 
 const (
-	TeamTown  Team = "town"
-	TeamMafia Team = "mafia"
+	TeamTown    Team = "town"
+	TeamMafia   Team = "mafia"
+	TeamNeutral Team = "neutral" // roles playing for themselves (none dealt yet)
 )
 
+// Label returns the human-readable name of the team
+func (t Team) Label() string {
+	switch t {
+	case TeamTown:
+		return "Town"
+	case TeamMafia:
+		return "Mafia"
+	case TeamNeutral:
+		return "Neutral"
+	default:
+		return string(t)
+	}
+}
+
 // GetTeam returns the team for a role
 func (r Role) GetTeam() Team {
 	switch r {
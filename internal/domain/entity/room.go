@@ -2,7 +2,12 @@ package entity
 
 import (
 	"errors"
+	"fmt"
+	"sort"
 	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // RoomState represents the current state of the room
@@ -19,17 +24,81 @@ var (
 	ErrRoomFull          = errors.New("room is full")
 	ErrRoomNotFound      = errors.New("room not found")
 	ErrWrongPassword     = errors.New("wrong password")
+	ErrInvalidPassword   = errors.New("invalid password")
 	ErrPlayerNotFound    = errors.New("player not found")
 	ErrGameAlreadyStarted = errors.New("game already started")
 	ErrNotEnoughPlayers  = errors.New("not enough players")
 	ErrNotAllReady       = errors.New("not all players are ready")
 	ErrNotHost           = errors.New("only host can do this")
 	ErrNicknameInUse     = errors.New("nickname already in use")
+	ErrPresetNotFound    = errors.New("preset not found")
+	ErrPresetDoesNotFit  = errors.New("preset does not fit player count")
+	ErrInvalidMaxPlayers = errors.New("max players out of range")
+	ErrGameNotEnded      = errors.New("game has not ended")
+	ErrUnbalancedTeams   = errors.New("mafia share of players out of range")
+	ErrInvalidDuration   = errors.New("phase duration out of range")
+	ErrInvalidMaxRevotes = errors.New("max revotes out of range")
+	ErrBotNotAllowed     = errors.New("bots cannot do this")
 )
 
 const (
 	MinPlayers = 3
-	MaxPlayers = 12
+	MaxPlayers = 12 // default per-room cap
+
+	// MaxPlayersCeiling is the largest cap a room may be created with
+	MaxPlayersCeiling = 20
+
+	// MinVotingSeconds is the shortest voting window left after discussion
+	MinVotingSeconds = 10
+
+	// RunoffSeconds is the length of a runoff vote between tied targets
+	RunoffSeconds = 30
+
+	// Runoffs a tied day may go to when MaxRevotes is 0, and the most allowed
+	DefaultMaxRevotes = 1
+	MaxRevotesCeiling = 5
+
+	// Default bounds on the mafia share of players (Godfather counts as mafia).
+	// Auto-balance lands in 20-40% at every player count; the floor is lowered
+	// to 15 so the small-6 preset's one mafioso in six still fits.
+	DefaultMinMafiaPercent = 15
+	DefaultMaxMafiaPercent = 40
+
+	// Phase lengths used when the matching setting is 0
+	DefaultRoleRevealSeconds = 5
+	DefaultNightSeconds      = 60
+	DefaultResultSeconds     = 3
+)
+
+// Allowed ranges for explicit phase durations, in seconds
+const (
+	MinRoleRevealSeconds = 1
+	MaxRoleRevealSeconds = 30
+	MinNightSeconds      = 10
+	MaxNightSeconds      = 300
+	MinDaySeconds        = 20
+	MaxDaySeconds        = 600
+	MinResultSeconds     = 1
+	MaxResultSeconds     = 30
+	MinAutoStartSeconds  = 1
+	MaxAutoStartSeconds  = 60
+)
+
+// TieBreak decides what happens when the top day vote targets are tied
+type TieBreak string
+
+const (
+	TieBreakNoElimination TieBreak = "no_elimination" // nobody is eliminated
+	TieBreakRandom        TieBreak = "random"         // one tied target is eliminated at random
+	TieBreakRevote        TieBreak = "revote"         // a runoff vote between the tied targets
+)
+
+// VoteThreshold decides how many votes a day vote needs to eliminate
+type VoteThreshold string
+
+const (
+	VoteThresholdMajority  VoteThreshold = "majority"  // more than half the living players
+	VoteThresholdPlurality VoteThreshold = "plurality" // the most votes, whatever the turnout
 )
 
 // GameSettings contains the game configuration
@@ -39,7 +108,119 @@ type GameSettings struct {
 	Godfather  int `json:"godfather"`
 	Doctor     int `json:"doctor"`
 	Detective  int `json:"detective"`
-	NightTimer int `json:"night_timer"`
+	NightTimer int `json:"night_timer"` // night length in seconds (0 = DefaultNightSeconds)
+
+	// DiscussionSeconds opens the day with a discussion period before voting (0 = vote immediately)
+	DiscussionSeconds int `json:"discussion_seconds"`
+
+	// Explicit phase lengths in seconds. 0 keeps the older behaviour: role reveal
+	// DefaultRoleRevealSeconds, day (discussion + voting) twice the night,
+	// results DefaultResultSeconds.
+	RoleRevealSeconds int `json:"role_reveal_seconds"`
+	DaySeconds        int `json:"day_seconds"`
+	ResultSeconds     int `json:"result_seconds"`
+
+	// LimitDoctorSelfHeals caps how many nights the doctor may protect
+	// themselves at DoctorSelfHealLimit (0 = never); off, there is no cap
+	LimitDoctorSelfHeals bool `json:"limit_doctor_self_heals"`
+	DoctorSelfHealLimit  int  `json:"doctor_self_heal_limit"`
+
+	// AllowFirstNightKill lets the mafia kill on night 1 (default: night 1 is for identifying teammates)
+	AllowFirstNightKill bool `json:"allow_first_night_kill"`
+
+	// RevealRolesOnDeath shows a player's role as soon as they die;
+	// when false, roles are only revealed at game over
+	RevealRolesOnDeath bool `json:"reveal_roles_on_death"`
+
+	// TieBreak resolves a tie at the top of a day vote that meets VoteThreshold
+	TieBreak TieBreak `json:"tie_break"`
+
+	// MaxRevotes is how many runoff votes a tie may go to under
+	// TieBreakRevote before the day ends with nobody eliminated
+	// (0 = DefaultMaxRevotes, at most MaxRevotesCeiling)
+	MaxRevotes int `json:"max_revotes"`
+
+	// VoteThreshold is how many votes an elimination needs, checked before
+	// TieBreak: a tie short of it eliminates nobody. Two targets can never
+	// both hold a majority, so TieBreak only matters under plurality.
+	VoteThreshold VoteThreshold `json:"vote_threshold"`
+
+	// SkipDisconnected lets night and day phases end early once every
+	// connected player has acted; disconnected players count as not acting
+	SkipDisconnected bool `json:"skip_disconnected"`
+
+	// RejectRepeatInvestigations stops a detective from checking the same
+	// player twice; when false, repeats are allowed and flagged in the result
+	RejectRepeatInvestigations bool `json:"reject_repeat_investigations"`
+
+	// DeadCanSeeLiveChat lets dead players read (but not post in) the day chat
+	DeadCanSeeLiveChat bool `json:"dead_can_see_live_chat"`
+
+	// Bounds on the mafia share of players, in percent
+	MinMafiaPercent int `json:"min_mafia_percent"`
+	MaxMafiaPercent int `json:"max_mafia_percent"`
+
+	// AllowUnbalanced skips the mafia share check for custom games
+	AllowUnbalanced bool `json:"allow_unbalanced"`
+
+	// GodfatherDecides makes a living godfather confirm the mafia target;
+	// the night waits for the confirmation and an unconfirmed kill does not happen
+	GodfatherDecides bool `json:"godfather_decides"`
+
+	// LockVoteAfterSubmit makes a day vote final once submitted
+	LockVoteAfterSubmit bool `json:"lock_vote_after_submit"`
+
+	// AllowActionChange lets the doctor and detective change their night
+	// target; when false their first submission is final (mafia can always re-vote)
+	AllowActionChange bool `json:"allow_action_change"`
+
+	// HiddenVoting keeps day vote targets secret while voting is open; only
+	// who has submitted is broadcast, and the ballots are revealed with the result
+	HiddenVoting bool `json:"hidden_voting"`
+
+	// AnonymousVoting never attributes day votes to voters: live updates and
+	// the day result carry per-target counts only
+	AnonymousVoting bool `json:"anonymous_voting"`
+
+	// Lovers links two random players at the start; when one dies the
+	// other dies of heartbreak straight away
+	Lovers bool `json:"lovers"`
+
+	// Cupid deals one villager seat as Cupid, who picks the lovers in a
+	// setup phase before the first night instead of them being paired at
+	// random; with Lovers off it does nothing
+	Cupid bool `json:"cupid"`
+
+	// DoctorNoConsecutiveSameTarget stops the doctor protecting the same
+	// player two nights running
+	DoctorNoConsecutiveSameTarget bool `json:"doctor_no_consecutive_same_target"`
+
+	// AutoStartSeconds starts the game this long after every player is
+	// ready, unless someone un-readies or leaves first (0 = host starts it)
+	AutoStartSeconds int `json:"auto_start_seconds"`
+
+	// VoiceEnabled offers voice chat in this room when the server has it
+	VoiceEnabled bool `json:"voice_enabled"`
+
+	// DeadDayWhisper lets dead players talk among themselves over voice
+	// during the day; they still hear the living, who never hear them
+	DeadDayWhisper bool `json:"dead_day_whisper"`
+
+	// DeadNightChat lets dead players talk among themselves over voice at
+	// night; the living never hear them. Off, the dead are muted at night.
+	DeadNightChat bool `json:"dead_night_chat"`
+
+	// TurnBasedVoting gives each living player the floor in turn for
+	// SpeakingTurnSeconds while voting is open; only they may speak
+	TurnBasedVoting bool `json:"turn_based_voting"`
+
+	// ConfirmGameEndingLynch holds a lynch that would end the game for a
+	// yes/no confirmation vote before anyone dies
+	ConfirmGameEndingLynch bool `json:"confirm_game_ending_lynch"`
+
+	// GameStats adds per-player stats (votes cast, nights survived, whether
+	// they were investigated) to the game over payload
+	GameStats bool `json:"game_stats"`
 }
 
 // DefaultSettings returns the default game settings
@@ -51,7 +232,164 @@ func DefaultSettings() GameSettings {
 		Doctor:     1,
 		Detective:  1,
 		NightTimer: 60,
+
+		RevealRolesOnDeath: true,
+		TieBreak:           TieBreakNoElimination,
+		VoteThreshold:      VoteThresholdMajority,
+		MinMafiaPercent:    DefaultMinMafiaPercent,
+		MaxMafiaPercent:    DefaultMaxMafiaPercent,
+		AllowActionChange:   true,
+		VoiceEnabled:        true,
+	}
+}
+
+// presets are canned role setups keyed by name
+var presets = map[string]GameSettings{
+	"small-6": {
+		Villagers:  3,
+		Mafia:      1,
+		Doctor:     1,
+		Detective:  1,
+		NightTimer: 45,
+	},
+	"classic-8": {
+		Villagers:  4,
+		Mafia:      2,
+		Doctor:     1,
+		Detective:  1,
+		NightTimer: 60,
+	},
+	"godfather-10": {
+		Villagers:  5,
+		Mafia:      2,
+		Godfather:  1,
+		Doctor:     1,
+		Detective:  1,
+		NightTimer: 60,
+	},
+	"chaos-12": {
+		Villagers:  6,
+		Mafia:      3,
+		Godfather:  1,
+		Doctor:     1,
+		Detective:  1,
+		NightTimer: 90,
+	},
+}
+
+// PresetNames returns the names of all available presets
+func PresetNames() []string {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Preset returns these settings with the role counts and timer of the named preset.
+// Rule toggles are kept as they are.
+func (s GameSettings) Preset(name string) (GameSettings, bool) {
+	preset, ok := presets[name]
+	if !ok {
+		return s, false
+	}
+
+	s = s.WithRoles(preset)
+	s.NightTimer = preset.NightTimer
+	return s, true
+}
+
+// WithRoles returns these settings with the role counts of roles
+func (s GameSettings) WithRoles(roles GameSettings) GameSettings {
+	s.Villagers = roles.Villagers
+	s.Mafia = roles.Mafia
+	s.Godfather = roles.Godfather
+	s.Doctor = roles.Doctor
+	s.Detective = roles.Detective
+	return s
+}
+
+// RevoteLimit returns how many runoff votes a tied day may go to
+func (s GameSettings) RevoteLimit() int {
+	if s.MaxRevotes <= 0 {
+		return DefaultMaxRevotes
+	}
+	return s.MaxRevotes
+}
+
+// RoleRevealDuration returns how long roles are shown before the first night
+func (s GameSettings) RoleRevealDuration() time.Duration {
+	return secondsOr(s.RoleRevealSeconds, DefaultRoleRevealSeconds)
+}
+
+// NightDuration returns the length of the night phase
+func (s GameSettings) NightDuration() time.Duration {
+	return secondsOr(s.NightTimer, DefaultNightSeconds)
+}
+
+// DayDuration returns the length of the whole day (discussion + voting)
+func (s GameSettings) DayDuration() time.Duration {
+	if s.DaySeconds > 0 {
+		return time.Duration(s.DaySeconds) * time.Second
 	}
+	return 2 * s.NightDuration()
+}
+
+// ResultDuration returns how long night and day results are shown
+func (s GameSettings) ResultDuration() time.Duration {
+	return secondsOr(s.ResultSeconds, DefaultResultSeconds)
+}
+
+func secondsOr(seconds, fallback int) time.Duration {
+	if seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	return time.Duration(fallback) * time.Second
+}
+
+// HasCupid reports whether a Cupid is dealt and picks the lovers
+func (s GameSettings) HasCupid() bool {
+	return s.Lovers && s.Cupid
+}
+
+// ValidateDurations checks that every explicit phase duration and the
+// auto-start delay is 0 (default) or within its range
+func (s GameSettings) ValidateDurations() error {
+	checks := []struct{ value, min, max int }{
+		{s.RoleRevealSeconds, MinRoleRevealSeconds, MaxRoleRevealSeconds},
+		{s.NightTimer, MinNightSeconds, MaxNightSeconds},
+		{s.DaySeconds, MinDaySeconds, MaxDaySeconds},
+		{s.ResultSeconds, MinResultSeconds, MaxResultSeconds},
+		{s.AutoStartSeconds, MinAutoStartSeconds, MaxAutoStartSeconds},
+	}
+	for _, c := range checks {
+		if c.value != 0 && (c.value < c.min || c.value > c.max) {
+			return ErrInvalidDuration
+		}
+	}
+	return nil
+}
+
+// Validate checks the phase durations, the revote limit and that the mafia
+// faction (Godfather included) is within the configured share of a game with
+// playerCount players. A playerCount of 0 skips the mafia share check.
+func (s GameSettings) Validate(playerCount int) error {
+	if err := s.ValidateDurations(); err != nil {
+		return err
+	}
+	if s.MaxRevotes < 0 || s.MaxRevotes > MaxRevotesCeiling {
+		return ErrInvalidMaxRevotes
+	}
+	if s.AllowUnbalanced || playerCount <= 0 {
+		return nil
+	}
+
+	mafia := s.Mafia + s.Godfather
+	if mafia*100 < s.MinMafiaPercent*playerCount || mafia*100 > s.MaxMafiaPercent*playerCount {
+		return ErrUnbalancedTeams
+	}
+	return nil
 }
 
 // TotalPlayers returns the total number of players needed
@@ -68,10 +406,20 @@ type Room struct {
 	Players      map[string]*Player // keyed by player ID
 	PlayerOrder  []string           // ordered list of player IDs
 
+	// AbsentHostID is the original host while a temporary host stands in
+	AbsentHostID string
+
+	// MaxPlayers is this room's player cap (MinPlayers..MaxPlayersCeiling)
+	MaxPlayers int
+
+	// ForcedRoles are roles the host dealt by hand (player ID -> role),
+	// honoured by the next start and cleared on return to the lobby
+	ForcedRoles map[string]Role
+
 	mu sync.RWMutex
 }
 
-// NewRoom creates a new room
+// NewRoom creates a new room with the default player cap
 func NewRoom(code, passwordHash string) *Room {
 	return &Room{
 		Code:         code,
@@ -80,15 +428,26 @@ func NewRoom(code, passwordHash string) *Room {
 		Settings:     DefaultSettings(),
 		Players:      make(map[string]*Player),
 		PlayerOrder:  make([]string, 0),
+		MaxPlayers:   MaxPlayers,
 	}
 }
 
-// AddPlayer adds a player to the room
+// ValidMaxPlayers returns true if a room may be created with this cap
+func ValidMaxPlayers(maxPlayers int) bool {
+	return maxPlayers >= MinPlayers && maxPlayers <= MaxPlayersCeiling
+}
+
+// AddPlayer adds a player to the room. Adding a player ID that is already
+// seated is a no-op, so a repeated join never takes a second seat.
 func (r *Room) AddPlayer(player *Player) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	if len(r.Players) >= MaxPlayers {
+	if _, ok := r.Players[player.ID]; ok {
+		return nil
+	}
+
+	if len(r.Players) >= r.MaxPlayers {
 		return ErrRoomFull
 	}
 
@@ -96,15 +455,16 @@ func (r *Room) AddPlayer(player *Player) error {
 		return ErrGameAlreadyStarted
 	}
 
-	// Check nickname uniqueness
+	// Check nickname uniqueness, ignoring lookalike spellings
+	key := NicknameKey(player.Nickname)
 	for _, p := range r.Players {
-		if p.Nickname == player.Nickname {
+		if NicknameKey(p.Nickname) == key {
 			return ErrNicknameInUse
 		}
 	}
 
-	// First player becomes host and is ready by default
-	if len(r.Players) == 0 {
+	// First player becomes host and is ready by default (bots never host)
+	if !player.IsBot && r.humanCountLocked() == 0 {
 		player.IsHost = true
 		player.IsReady = true
 	}
@@ -119,12 +479,34 @@ func (r *Room) RemovePlayer(playerID string) (*Player, string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	return r.removePlayerLocked(playerID)
+}
+
+// RemoveLobbyPlayer removes a player like RemovePlayer, but only while the
+// room is still waiting; the state is checked under the same lock as the removal
+func (r *Room) RemoveLobbyPlayer(playerID string) (*Player, string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.State != RoomStateWaiting {
+		return nil, "", ErrGameAlreadyStarted
+	}
+	player, newHostID := r.removePlayerLocked(playerID)
+	if player == nil {
+		return nil, "", ErrPlayerNotFound
+	}
+	return player, newHostID, nil
+}
+
+// removePlayerLocked removes a player. Caller must hold r.mu.
+func (r *Room) removePlayerLocked(playerID string) (*Player, string) {
 	player, ok := r.Players[playerID]
 	if !ok {
 		return nil, ""
 	}
 
 	delete(r.Players, playerID)
+	delete(r.ForcedRoles, playerID)
 
 	// Remove from order
 	for i, id := range r.PlayerOrder {
@@ -136,15 +518,163 @@ func (r *Room) RemovePlayer(playerID string) (*Player, string) {
 
 	// Transfer host if needed
 	var newHostID string
-	if player.IsHost && len(r.Players) > 0 {
-		// Assign host to first remaining player
-		newHostID = r.PlayerOrder[0]
-		r.Players[newHostID].IsHost = true
+	if player.IsHost {
+		// Assign host to first remaining player (bots never host)
+		for _, id := range r.PlayerOrder {
+			if !r.Players[id].IsBot {
+				newHostID = id
+				r.Players[id].IsHost = true
+				break
+			}
+		}
+	}
+
+	// Original host is gone for good - the temporary host becomes permanent
+	if r.AbsentHostID == playerID {
+		r.AbsentHostID = ""
+		for _, p := range r.Players {
+			if p.IsHost {
+				newHostID = p.ID
+				break
+			}
+		}
 	}
 
 	return player, newHostID
 }
 
+// AssignTemporaryHost hands host to the first connected player while the
+// disconnected host is away. Returns the new host ID, or empty if unchanged.
+func (r *Room) AssignTemporaryHost(disconnectedID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	player, ok := r.Players[disconnectedID]
+	if !ok || !player.IsHost {
+		return ""
+	}
+
+	for _, id := range r.PlayerOrder {
+		candidate, ok := r.Players[id]
+		if !ok || id == disconnectedID || !candidate.IsConnected || candidate.IsBot {
+			continue
+		}
+
+		player.IsHost = false
+		candidate.IsHost = true
+		// Keep the original host if a temporary host disconnects in turn
+		if r.AbsentHostID == "" {
+			r.AbsentHostID = disconnectedID
+		}
+		return id
+	}
+	return ""
+}
+
+// RestoreHost returns host to the original host when they reconnect.
+// Returns the ID of the temporary host that was replaced, or empty if unchanged.
+func (r *Room) RestoreHost(playerID string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.AbsentHostID != playerID {
+		return ""
+	}
+	original, ok := r.Players[playerID]
+	if !ok {
+		return ""
+	}
+
+	var temporaryID string
+	for _, p := range r.Players {
+		if p.IsHost {
+			p.IsHost = false
+			temporaryID = p.ID
+		}
+	}
+	original.IsHost = true
+	r.AbsentHostID = ""
+	return temporaryID
+}
+
+// ChangeNickname renames a player in the lobby, keeping nicknames unique
+func (r *Room) ChangeNickname(playerID, nickname string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.State != RoomStateWaiting {
+		return ErrGameAlreadyStarted
+	}
+
+	player, ok := r.Players[playerID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+
+	key := NicknameKey(nickname)
+	for _, p := range r.Players {
+		if p.ID != playerID && NicknameKey(p.Nickname) == key {
+			return ErrNicknameInUse
+		}
+	}
+
+	player.Nickname = nickname
+	return nil
+}
+
+// TransferHost hands host from the current host to another player in the
+// lobby. Exactly one player is host afterwards.
+func (r *Room) TransferHost(fromID, toID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.State != RoomStateWaiting {
+		return ErrGameAlreadyStarted
+	}
+
+	from, ok := r.Players[fromID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+	if !from.IsHost {
+		return ErrNotHost
+	}
+	to, ok := r.Players[toID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+	if to.IsBot {
+		return ErrBotNotAllowed
+	}
+
+	for _, p := range r.Players {
+		p.IsHost = false
+	}
+	to.IsHost = true
+	r.AbsentHostID = ""
+	return nil
+}
+
+// ResetToLobby puts a finished room back in the lobby so the same group can
+// play again. Everyone is alive with no role; only the host stays ready.
+func (r *Room) ResetToLobby() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.State != RoomStateEnded {
+		return ErrGameNotEnded
+	}
+
+	for _, p := range r.Players {
+		p.Status = PlayerStatusAlive
+		p.Role = ""
+		p.IsReady = p.IsHost
+	}
+	r.ForcedRoles = nil
+	r.State = RoomStateWaiting
+	return nil
+}
+
 // GetPlayer returns a player by ID
 func (r *Room) GetPlayer(playerID string) *Player {
 	r.mu.RLock()
@@ -178,6 +708,45 @@ func (r *Room) SetReady(playerID string, ready bool) error {
 	return nil
 }
 
+// SetHistoryKey opts a player into game history under key
+func (r *Room) SetHistoryKey(playerID, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	player, ok := r.Players[playerID]
+	if !ok {
+		return ErrPlayerNotFound
+	}
+	player.HistoryKey = key
+	return nil
+}
+
+// HistoryKey returns a player's game history key (empty = not recorded)
+func (r *Room) HistoryKey(playerID string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if player, ok := r.Players[playerID]; ok {
+		return player.HistoryKey
+	}
+	return ""
+}
+
+// ReadyAll marks every player ready and returns the IDs that were not ready before
+func (r *Room) ReadyAll() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	readied := make([]string, 0)
+	for _, id := range r.PlayerOrder {
+		if p, ok := r.Players[id]; ok && !p.IsReady {
+			p.IsReady = true
+			readied = append(readied, id)
+		}
+	}
+	return readied
+}
+
 // AllReady returns true if all players are ready
 func (r *Room) AllReady() bool {
 	r.mu.RLock()
@@ -195,11 +764,87 @@ func (r *Room) AllReady() bool {
 	return true
 }
 
-// UpdateSettings updates the game settings
-func (r *Room) UpdateSettings(settings GameSettings) {
+// LobbyStatus reports whether the game can be started and, if not, why
+type LobbyStatus struct {
+	CanStart    bool
+	Reason      string // empty when CanStart is true
+	ReadyCount  int
+	PlayerCount int
+}
+
+// LobbyStatus computes start eligibility from player count, readiness and settings
+func (r *Room) LobbyStatus() LobbyStatus {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	status := LobbyStatus{PlayerCount: len(r.Players)}
+	for _, p := range r.Players {
+		if p.IsReady {
+			status.ReadyCount++
+		}
+	}
+
+	switch {
+	case r.State != RoomStateWaiting:
+		status.Reason = "game already started"
+	case status.PlayerCount < MinPlayers:
+		status.Reason = fmt.Sprintf("need %s", pluralize(MinPlayers-status.PlayerCount, "more player"))
+	case !rolesFit(r.Settings, status.PlayerCount):
+		status.Reason = fmt.Sprintf("too many special roles for %s", pluralize(status.PlayerCount, "player"))
+	case r.Settings.Validate(status.PlayerCount) != nil:
+		status.Reason = fmt.Sprintf("mafia must be %d-%d%% of players", r.Settings.MinMafiaPercent, r.Settings.MaxMafiaPercent)
+	case status.ReadyCount < status.PlayerCount:
+		status.Reason = fmt.Sprintf("waiting for %s to ready up", pluralize(status.PlayerCount-status.ReadyCount, "player"))
+	default:
+		status.CanStart = true
+	}
+	return status
+}
+
+func rolesFit(settings GameSettings, playerCount int) bool {
+	_, err := BuildRolePool(settings, playerCount)
+	return err == nil
+}
+
+// pluralize formats a count with a noun, adding "s" when needed
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// UpdateSettings updates the game settings and returns the ones it replaced.
+// Outside the lobby only voice can be switched; anything else is
+// ErrGameAlreadyStarted.
+func (r *Room) UpdateSettings(settings GameSettings) (GameSettings, error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
+
+	if !r.canChangeSettingsLocked(settings) {
+		return GameSettings{}, ErrGameAlreadyStarted
+	}
+
+	prev := r.Settings
 	r.Settings = settings
+	return prev, nil
+}
+
+// CanChangeSettings reports whether settings may replace the current ones:
+// anything goes in the lobby, but once a game has started the host can only
+// turn voice on or off
+func (r *Room) CanChangeSettings(settings GameSettings) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.canChangeSettingsLocked(settings)
+}
+
+func (r *Room) canChangeSettingsLocked(settings GameSettings) bool {
+	if r.State == RoomStateWaiting {
+		return true
+	}
+	settings.VoiceEnabled = r.Settings.VoiceEnabled
+	return settings == r.Settings
 }
 
 // PlayerCount returns the number of players
@@ -209,9 +854,42 @@ func (r *Room) PlayerCount() int {
 	return len(r.Players)
 }
 
-// IsEmpty returns true if the room has no players
+// IsEmpty returns true if no human players remain (bots do not keep a room alive)
 func (r *Room) IsEmpty() bool {
-	return r.PlayerCount() == 0
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.humanCountLocked() == 0
+}
+
+func (r *Room) humanCountLocked() int {
+	count := 0
+	for _, p := range r.Players {
+		if !p.IsBot {
+			count++
+		}
+	}
+	return count
+}
+
+// HasBots returns true if any seat is filled by a bot
+func (r *Room) HasBots() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.humanCountLocked() < len(r.Players)
+}
+
+// ConnectedCount returns the number of human players with a live connection
+func (r *Room) ConnectedCount() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, p := range r.Players {
+		if p.IsConnected && !p.IsBot {
+			count++
+		}
+	}
+	return count
 }
 
 // GetPlayersDTO returns all players as DTOs
@@ -228,6 +906,23 @@ func (r *Room) GetPlayersDTO() []PlayerDTO {
 	return players
 }
 
+// MaxPasswordLength is the longest room password allowed, in characters
+const MaxPasswordLength = 64
+
+// ValidatePassword checks a room password is at most MaxPasswordLength
+// printable characters. An empty password means no password and is valid.
+func ValidatePassword(password string) error {
+	if utf8.RuneCountInString(password) > MaxPasswordLength {
+		return ErrInvalidPassword
+	}
+	for _, r := range password {
+		if !unicode.IsPrint(r) {
+			return ErrInvalidPassword
+		}
+	}
+	return nil
+}
+
 // HasPassword returns true if the room has a password
 func (r *Room) HasPassword() bool {
 	return r.PasswordHash != ""
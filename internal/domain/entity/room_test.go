@@ -0,0 +1,250 @@
+package entity
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// hosts returns the IDs of every player flagged as host
+func hosts(room *Room) []string {
+	var ids []string
+	for _, p := range room.GetPlayersDTO() {
+		if p.IsHost {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+func TestTransferHostLeavesOneHost(t *testing.T) {
+	room := newReadyRoom(t, 4, nil)
+
+	if err := room.TransferHost("p1", "p2"); err != ErrNotHost {
+		t.Errorf("transfer by non-host: err = %v, want ErrNotHost", err)
+	}
+	if err := room.TransferHost("p0", "nobody"); err != ErrPlayerNotFound {
+		t.Errorf("transfer to a stranger: err = %v, want ErrPlayerNotFound", err)
+	}
+	if got := hosts(room); len(got) != 1 || got[0] != "p0" {
+		t.Fatalf("hosts after failed transfers = %v, want [p0]", got)
+	}
+
+	// Racing transfers from the same host: exactly one wins
+	var wg sync.WaitGroup
+	var won atomic.Int32
+	for _, to := range []string{"p1", "p2", "p3"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if room.TransferHost("p0", to) == nil {
+				won.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := hosts(room); won.Load() != 1 || len(got) != 1 || got[0] == "p0" {
+		t.Fatalf("%d transfers won, hosts = %v; want one new host", won.Load(), got)
+	}
+
+	room.State = RoomStatePlaying
+	if err := room.TransferHost(hosts(room)[0], "p0"); err != ErrGameAlreadyStarted {
+		t.Errorf("transfer mid-game: err = %v, want ErrGameAlreadyStarted", err)
+	}
+}
+
+func TestLobbyStatusReasons(t *testing.T) {
+	tests := []struct {
+		name    string
+		players int
+		tweak   func(*Room)
+		reason  string
+		ready   int
+	}{
+		{"too few players", 2, nil, "need 1 more player", 2},
+		{"too many special roles", 7, func(r *Room) { r.Settings.Doctor, r.Settings.Detective = 3, 3 }, "too many special roles for 7 players", 7},
+		{"mafia share", 7, func(r *Room) { r.Settings.Mafia = 5 }, "mafia must be 15-40% of players", 7},
+		{"not ready", 7, func(r *Room) { r.SetReady("p5", false); r.SetReady("p6", false) }, "waiting for 2 players to ready up", 5},
+		{"already started", 7, func(r *Room) { r.State = RoomStatePlaying }, "game already started", 7},
+		{"can start", 7, nil, "", 7},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := DefaultSettings()
+			room := newReadyRoom(t, tt.players, &settings)
+			if tt.tweak != nil {
+				tt.tweak(room)
+			}
+			status := room.LobbyStatus()
+			if status.Reason != tt.reason || status.CanStart != (tt.reason == "") {
+				t.Errorf("status = %+v, want reason %q", status, tt.reason)
+			}
+			if status.ReadyCount != tt.ready || status.PlayerCount != tt.players {
+				t.Errorf("counts = %d/%d, want %d/%d", status.ReadyCount, status.PlayerCount, tt.ready, tt.players)
+			}
+		})
+	}
+}
+
+func TestMafiaShareBoundaries(t *testing.T) {
+	tests := []struct {
+		name        string
+		mafia       int
+		godfather   int
+		players     int
+		unbalanced  bool
+		wantBalance bool
+	}{
+		{name: "at the minimum", mafia: 2, players: 10, wantBalance: true},
+		{name: "below the minimum", mafia: 1, players: 10},
+		{name: "at the maximum", mafia: 4, players: 10, wantBalance: true},
+		{name: "above the maximum", mafia: 5, players: 10},
+		{name: "godfather counts toward mafia", mafia: 3, godfather: 2, players: 10},
+		{name: "godfather fills the minimum", mafia: 1, godfather: 1, players: 10, wantBalance: true},
+		{name: "host override", mafia: 5, players: 10, unbalanced: true, wantBalance: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := DefaultSettings()
+			s.MinMafiaPercent = 20
+			s.MaxMafiaPercent = 40
+			s.Mafia = tt.mafia
+			s.Godfather = tt.godfather
+			s.AllowUnbalanced = tt.unbalanced
+
+			err := s.Validate(tt.players)
+			if tt.wantBalance && err != nil {
+				t.Errorf("Validate(%d) = %v, want nil", tt.players, err)
+			}
+			if !tt.wantBalance && err != ErrUnbalancedTeams {
+				t.Errorf("Validate(%d) = %v, want ErrUnbalancedTeams", tt.players, err)
+			}
+		})
+	}
+}
+
+func TestPhaseDurations(t *testing.T) {
+	settings := DefaultSettings()
+	for name, tt := range map[string]struct{ got, want time.Duration }{
+		"default reveal": {settings.RoleRevealDuration(), DefaultRoleRevealSeconds * time.Second},
+		"default night":  {settings.NightDuration(), time.Duration(settings.NightTimer) * time.Second},
+		"default day":    {settings.DayDuration(), 2 * time.Duration(settings.NightTimer) * time.Second},
+		"default result": {settings.ResultDuration(), DefaultResultSeconds * time.Second},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", name, tt.got, tt.want)
+		}
+	}
+
+	settings.RoleRevealSeconds, settings.NightTimer, settings.DaySeconds, settings.ResultSeconds = 8, 45, 200, 6
+	if err := settings.ValidateDurations(); err != nil {
+		t.Fatalf("validate in-range durations: %v", err)
+	}
+	for name, tt := range map[string]struct{ got, want time.Duration }{
+		"reveal": {settings.RoleRevealDuration(), 8 * time.Second},
+		"night":  {settings.NightDuration(), 45 * time.Second},
+		"day":    {settings.DayDuration(), 200 * time.Second},
+		"result": {settings.ResultDuration(), 6 * time.Second},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("%s = %v, want %v", name, tt.got, tt.want)
+		}
+	}
+
+	for name, tweak := range map[string]func(*GameSettings){
+		"reveal too long":     func(s *GameSettings) { s.RoleRevealSeconds = MaxRoleRevealSeconds + 1 },
+		"night too short":     func(s *GameSettings) { s.NightTimer = MinNightSeconds - 1 },
+		"day too long":        func(s *GameSettings) { s.DaySeconds = MaxDaySeconds + 1 },
+		"negative result":     func(s *GameSettings) { s.ResultSeconds = -1 },
+		"auto-start too long": func(s *GameSettings) { s.AutoStartSeconds = MaxAutoStartSeconds + 1 },
+	} {
+		bad := DefaultSettings()
+		tweak(&bad)
+		if err := bad.ValidateDurations(); err != ErrInvalidDuration {
+			t.Errorf("%s: err = %v, want ErrInvalidDuration", name, err)
+		}
+		if err := bad.Validate(7); err != ErrInvalidDuration {
+			t.Errorf("%s: Validate = %v, want ErrInvalidDuration", name, err)
+		}
+	}
+}
+
+func TestMaxRevotesValidated(t *testing.T) {
+	settings := DefaultSettings()
+	for revotes, want := range map[int]int{0: DefaultMaxRevotes, 1: 1, MaxRevotesCeiling: MaxRevotesCeiling} {
+		settings.MaxRevotes = revotes
+		if err := settings.Validate(7); err != nil {
+			t.Errorf("max revotes %d: Validate = %v", revotes, err)
+		}
+		if got := settings.RevoteLimit(); got != want {
+			t.Errorf("max revotes %d: RevoteLimit = %d, want %d", revotes, got, want)
+		}
+	}
+
+	for _, revotes := range []int{-1, MaxRevotesCeiling + 1} {
+		settings.MaxRevotes = revotes
+		if err := settings.Validate(0); err != ErrInvalidMaxRevotes {
+			t.Errorf("max revotes %d: Validate = %v, want ErrInvalidMaxRevotes", revotes, err)
+		}
+	}
+}
+
+func TestLookalikeNicknamesCollide(t *testing.T) {
+	room := NewRoom("ABCD", "")
+	if err := room.AddPlayer(NewPlayer("alice", "Alice", false)); err != nil {
+		t.Fatalf("add Alice: %v", err)
+	}
+
+	for _, nickname := range []string{
+		"alice",                          // case only
+		"Al\u0131ce",                     // dotless i
+		"\u0410lice",                     // Cyrillic capital A
+		"Ali\u200bce",                    // zero-width space
+		"\u200dAlice\ufeff",              // zero-width joiner and BOM
+		"\uff21\uff4c\uff49\uff43\uff45", // fullwidth, folded by NFKC
+	} {
+		display, err := NormalizeNickname(nickname)
+		if err != nil {
+			t.Errorf("%q: normalize: %v", nickname, err)
+			continue
+		}
+		if err := room.AddPlayer(NewPlayer("imposter", display, false)); err != ErrNicknameInUse {
+			t.Errorf("%q joined as %q: err = %v, want ErrNicknameInUse", nickname, display, err)
+		}
+	}
+
+	// Display names keep their spelling, minus invisible characters
+	display, _ := NormalizeNickname("  Bob\u200b   the\tBuilder ")
+	if display != "Bob the Builder" {
+		t.Errorf("display = %q, want %q", display, "Bob the Builder")
+	}
+	if err := room.AddPlayer(NewPlayer("bob", display, false)); err != nil {
+		t.Fatalf("add Bob: %v", err)
+	}
+	if err := room.ChangeNickname("alice", "BOB THE BUILDER"); err != ErrNicknameInUse {
+		t.Errorf("rename to Bob's lookalike: err = %v, want ErrNicknameInUse", err)
+	}
+	if err := room.ChangeNickname("alice", "Alicia"); err != nil {
+		t.Errorf("rename to a distinct name: %v", err)
+	}
+}
+
+func TestDefaultMafiaShareFitsBalancedTables(t *testing.T) {
+	s := DefaultSettings()
+	if err := s.Validate(s.TotalPlayers()); err != nil {
+		t.Errorf("default roles: %v", err)
+	}
+	for players := MinPlayers; players <= MaxPlayersCeiling; players++ {
+		if err := s.Balanced(players).Validate(players); err != nil {
+			t.Errorf("balanced for %d players: %v", players, err)
+		}
+	}
+	for _, name := range PresetNames() {
+		preset, _ := s.Preset(name)
+		if err := preset.Validate(preset.TotalPlayers()); err != nil {
+			t.Errorf("preset %s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package entity
+
+import (
+	"reflect"
+	"strings"
+)
+
+// SettingChange is one setting's value before and after an update
+type SettingChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// SettingsDiff maps the JSON name of each changed setting to its change
+type SettingsDiff map[string]SettingChange
+
+// Diff returns the settings that differ from prev, keyed by their JSON
+// names, so clients can say e.g. "host changed night_timer from 60 to 90"
+func (s GameSettings) Diff(prev GameSettings) SettingsDiff {
+	diff := make(SettingsDiff)
+	oldVal, newVal := reflect.ValueOf(prev), reflect.ValueOf(s)
+	for i := range newVal.NumField() {
+		field := newVal.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		before, after := oldVal.Field(i).Interface(), newVal.Field(i).Interface()
+		if !reflect.DeepEqual(before, after) {
+			diff[name] = SettingChange{Old: before, New: after}
+		}
+	}
+	return diff
+}
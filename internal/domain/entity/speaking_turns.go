@@ -0,0 +1,83 @@
+package entity
+
+import (
+	"errors"
+	"slices"
+)
+
+// SpeakingTurnSeconds is how long each player holds the floor when
+// TurnBasedVoting is on
+const SpeakingTurnSeconds = 10
+
+// ErrNotYourTurn is returned when someone other than the current speaker passes
+var ErrNotYourTurn = errors.New("not your turn to speak")
+
+// SpeakingTurn is who holds the floor during turn-based voting. Turn counts
+// up with every hand-off, so a turn timer can tell it has been overtaken.
+type SpeakingTurn struct {
+	PlayerID string // empty while the floor is open
+	Turn     int
+}
+
+// CurrentSpeaker returns who holds the floor; the PlayerID is empty outside
+// turn-based voting
+func (g *Game) CurrentSpeaker() SpeakingTurn {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.Speaker
+}
+
+// StartSpeakingTurns gives the floor to the first living player in seat
+// order. Bots never speak, so they get no turn.
+func (g *Game) StartSpeakingTurns() SpeakingTurn {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.Speaker.PlayerID = ""
+	g.nextSpeakerLocked()
+	return g.Speaker
+}
+
+// AdvanceSpeaker hands the floor on when turn runs out, wrapping round to
+// the first speaker after the last. Returns false if turn was already
+// overtaken or voting has closed.
+func (g *Game) AdvanceSpeaker(turn int) (SpeakingTurn, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Phase != PhaseDay || g.Speaker.PlayerID == "" || g.Speaker.Turn != turn {
+		return g.Speaker, false
+	}
+	g.nextSpeakerLocked()
+	return g.Speaker, true
+}
+
+// PassSpeakingTurn ends playerID's turn early and hands the floor on
+func (g *Game) PassSpeakingTurn(playerID string) (SpeakingTurn, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.Phase != PhaseDay || g.Speaker.PlayerID == "" {
+		return g.Speaker, ErrInvalidPhase
+	}
+	if g.Speaker.PlayerID != playerID {
+		return g.Speaker, ErrNotYourTurn
+	}
+	g.nextSpeakerLocked()
+	return g.Speaker, nil
+}
+
+// nextSpeakerLocked moves the floor to the next living human after the
+// current speaker in seat order. Caller must hold g.mu.
+func (g *Game) nextSpeakerLocked() {
+	g.Speaker.Turn++
+	start := slices.Index(g.Room.PlayerOrder, g.Speaker.PlayerID)
+	for i := 1; i <= len(g.Room.PlayerOrder); i++ {
+		id := g.Room.PlayerOrder[(start+i+len(g.Room.PlayerOrder))%len(g.Room.PlayerOrder)]
+		if player := g.Room.GetPlayer(id); player != nil && !player.IsBot && player.Status == PlayerStatusAlive {
+			g.Speaker.PlayerID = id
+			return
+		}
+	}
+	g.Speaker.PlayerID = ""
+}
@@ -0,0 +1,74 @@
+package entity
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// newReadyRoom seats n ready players p0..p(n-1) with the given settings
+// (nil = defaults)
+func newReadyRoom(t *testing.T, n int, settings *GameSettings) *Room {
+	t.Helper()
+	room := NewRoom("TEST", "")
+	if settings != nil {
+		room.Settings = *settings
+	}
+	for i := range n {
+		player := NewPlayer(fmt.Sprintf("p%d", i), fmt.Sprintf("Player %d", i), i == 0)
+		if err := room.AddPlayer(player); err != nil {
+			t.Fatalf("add player: %v", err)
+		}
+		room.SetReady(player.ID, true)
+	}
+	return room
+}
+
+// newTestGame deals a game to n ready players p0..p(n-1) with the given
+// settings (nil = defaults)
+func newTestGame(t *testing.T, n int, settings *GameSettings) *Game {
+	t.Helper()
+	game, err := NewGameWithRand(newReadyRoom(t, n, settings), rand.New(rand.NewSource(1)))
+	if err != nil {
+		t.Fatalf("new game: %v", err)
+	}
+	return game
+}
+
+// playersByRole groups the seated players by their dealt role, in seat order
+func playersByRole(game *Game) map[Role][]string {
+	byRole := make(map[Role][]string)
+	for _, id := range game.Room.PlayerOrder {
+		byRole[game.Roles[id]] = append(byRole[game.Roles[id]], id)
+	}
+	return byRole
+}
+
+// killAtNight runs a night in which the given mafia all target target
+func killAtNight(t *testing.T, game *Game, mafia []string, target string) *NightResult {
+	t.Helper()
+	game.StartNight(time.Minute)
+	for _, id := range mafia {
+		if err := game.SubmitNightAction(id, target); err != nil {
+			t.Fatalf("%s targets %s: %v", id, target, err)
+		}
+	}
+	return game.ResolveNight()
+}
+
+// lynch runs a day in which every living player but target votes for target
+func lynch(t *testing.T, game *Game, target string) *DayResult {
+	t.Helper()
+	game.StartDay(time.Minute)
+	for _, id := range game.GetAlivePlayers() {
+		vote := target
+		if id == target {
+			vote = ""
+		}
+		if err := game.SubmitDayVote(id, vote); err != nil {
+			t.Fatalf("%s votes %q: %v", id, vote, err)
+		}
+	}
+	return game.ResolveDay()
+}
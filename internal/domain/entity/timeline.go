@@ -0,0 +1,94 @@
+package entity
+
+// TimelineEntry is the public record of one round, built from the night and
+// day results as they resolve. It only holds what was announced to everyone.
+type TimelineEntry struct {
+	Round int           `json:"round"`
+	Night *NightSummary `json:"night,omitempty"`
+	Votes []VoteSummary `json:"votes,omitempty"` // day vote, then any runoff
+}
+
+// TimelineDeath is a publicly announced death
+type TimelineDeath struct {
+	PlayerID string `json:"player_id"`
+	Nickname string `json:"nickname"`
+	Role     Role   `json:"role,omitempty"` // empty unless roles are revealed on death
+}
+
+// NightSummary is the public outcome of a night
+type NightSummary struct {
+	Killed     *TimelineDeath `json:"killed,omitempty"`
+	WasSaved   bool           `json:"was_saved"`
+	Heartbreak *TimelineDeath `json:"heartbreak,omitempty"`
+}
+
+// VoteSummary is the public outcome of a day vote
+type VoteSummary struct {
+	Eliminated *TimelineDeath `json:"eliminated,omitempty"`
+	NoMajority bool           `json:"no_majority"`
+	Runoff     bool           `json:"runoff,omitempty"`  // a runoff vote followed
+	Confirm    bool           `json:"confirm,omitempty"` // a game-ending lynch went to a confirmation vote
+	Heartbreak *TimelineDeath `json:"heartbreak,omitempty"`
+}
+
+// recordNightLocked adds a resolved night to the timeline. Caller must hold g.mu.
+func (g *Game) recordNightLocked(result *NightResult) {
+	summary := &NightSummary{WasSaved: result.WasSaved}
+	if result.KilledID != "" {
+		summary.Killed = &TimelineDeath{
+			PlayerID: result.KilledID,
+			Nickname: result.KilledNickname,
+			Role:     result.KilledRole,
+		}
+	}
+	summary.Heartbreak = heartbreakDeath(result.Heartbreak)
+	g.timelineEntryLocked().Night = summary
+}
+
+// recordVoteLocked adds a resolved day vote to the timeline. Caller must hold g.mu.
+func (g *Game) recordVoteLocked(result *DayResult) {
+	summary := VoteSummary{
+		NoMajority: result.NoMajority,
+		Runoff:     result.Runoff,
+		Confirm:    result.ConfirmTarget != "",
+	}
+	if result.EliminatedID != "" {
+		summary.Eliminated = &TimelineDeath{
+			PlayerID: result.EliminatedID,
+			Nickname: result.EliminatedNickname,
+			Role:     result.EliminatedRole,
+		}
+	}
+	summary.Heartbreak = heartbreakDeath(result.Heartbreak)
+	entry := g.timelineEntryLocked()
+	entry.Votes = append(entry.Votes, summary)
+}
+
+// timelineEntryLocked returns the current round's entry, starting it if needed
+func (g *Game) timelineEntryLocked() *TimelineEntry {
+	if n := len(g.Timeline); n > 0 && g.Timeline[n-1].Round == g.Round {
+		return &g.Timeline[n-1]
+	}
+	g.Timeline = append(g.Timeline, TimelineEntry{Round: g.Round})
+	return &g.Timeline[len(g.Timeline)-1]
+}
+
+func heartbreakDeath(h *Heartbreak) *TimelineDeath {
+	if h == nil {
+		return nil
+	}
+	return &TimelineDeath{PlayerID: h.PlayerID, Nickname: h.Nickname, Role: h.Role}
+}
+
+// GetTimeline returns a copy of the rounds so far, oldest first
+func (g *Game) GetTimeline() []TimelineEntry {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	timeline := make([]TimelineEntry, len(g.Timeline))
+	for i, entry := range g.Timeline {
+		entry.Votes = append([]VoteSummary(nil), entry.Votes...)
+		timeline[i] = entry
+	}
+	return timeline
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimelineAccumulatesRounds(t *testing.T) {
+	settings := DefaultSettings()
+	settings.AllowFirstNightKill = true
+	settings.RevealRolesOnDeath = false
+	game := newTestGame(t, 7, &settings)
+	byRole := playersByRole(game)
+	mafia, villagers, doctor := byRole[RoleMafia], byRole[RoleVillager], byRole[RoleDoctor][0]
+
+	// Round 1: a villager dies and the town cannot agree
+	killAtNight(t, game, mafia, villagers[0])
+	game.StartDay(time.Minute)
+	for _, id := range game.GetAlivePlayers() {
+		if err := game.SubmitDayVote(id, ""); err != nil {
+			t.Fatalf("%s abstains: %v", id, err)
+		}
+	}
+	game.ResolveDay()
+
+	// Round 2: the doctor saves the next victim and a mafioso is lynched
+	game.Round++
+	game.StartNight(time.Minute)
+	for _, id := range mafia {
+		if err := game.SubmitNightAction(id, villagers[1]); err != nil {
+			t.Fatalf("%s targets %s: %v", id, villagers[1], err)
+		}
+	}
+	if err := game.SubmitNightAction(doctor, villagers[1]); err != nil {
+		t.Fatalf("doctor protects: %v", err)
+	}
+	game.ResolveNight()
+	lynch(t, game, mafia[0])
+
+	timeline := game.GetTimeline()
+	if len(timeline) != 2 {
+		t.Fatalf("timeline has %d rounds, want 2: %+v", len(timeline), timeline)
+	}
+	for i, entry := range timeline {
+		if entry.Round != i+1 || entry.Night == nil || len(entry.Votes) != 1 {
+			t.Fatalf("round %d entry %+v", i+1, entry)
+		}
+	}
+
+	first, second := timeline[0], timeline[1]
+	if killed := first.Night.Killed; killed == nil || killed.PlayerID != villagers[0] || first.Night.WasSaved {
+		t.Errorf("round 1 night %+v, want %s killed", first.Night, villagers[0])
+	}
+	if vote := first.Votes[0]; !vote.NoMajority || vote.Eliminated != nil {
+		t.Errorf("round 1 vote %+v, want no majority", vote)
+	}
+	if second.Night.Killed != nil || !second.Night.WasSaved {
+		t.Errorf("round 2 night %+v, want a save", second.Night)
+	}
+	eliminated := second.Votes[0].Eliminated
+	if eliminated == nil || eliminated.PlayerID != mafia[0] {
+		t.Fatalf("round 2 vote %+v, want %s eliminated", second.Votes[0], mafia[0])
+	}
+
+	// Roles stay private while they are hidden on death
+	if first.Night.Killed.Role != "" || eliminated.Role != "" {
+		t.Errorf("timeline reveals roles %q and %q", first.Night.Killed.Role, eliminated.Role)
+	}
+
+	// Callers get a copy
+	timeline[1].Votes[0].NoMajority = true
+	if game.GetTimeline()[1].Votes[0].NoMajority {
+		t.Error("editing the returned timeline changed the game's")
+	}
+}
@@ -0,0 +1,71 @@
+package entity
+
+// VisibilityPolicy is the single place that decides what results reveal.
+// Result builders and event emitters consult it instead of reading the
+// individual settings, so every rule variant redacts the same way.
+type VisibilityPolicy struct {
+	// RevealRolesOnDeath shows a dead player's role in night and day results
+	RevealRolesOnDeath bool
+
+	// HiddenTally withholds day votes (targets and counts) until the day resolves
+	HiddenTally bool
+
+	// AnonymousVotes never attributes a day vote to its voter
+	AnonymousVotes bool
+
+	// GodfatherImmunity makes the godfather read as town to the first
+	// investigation. There is no setting for it yet; it is always on.
+	GodfatherImmunity bool
+}
+
+// Visibility derives the visibility policy from the settings
+func (s GameSettings) Visibility() VisibilityPolicy {
+	return VisibilityPolicy{
+		RevealRolesOnDeath: s.RevealRolesOnDeath,
+		HiddenTally:        s.HiddenVoting,
+		AnonymousVotes:     s.AnonymousVoting,
+		GodfatherImmunity:  true,
+	}
+}
+
+// DeathRole returns the role to publish for a player who just died
+// (empty while roles stay hidden until game over)
+func (v VisibilityPolicy) DeathRole(role Role) Role {
+	if !v.RevealRolesOnDeath {
+		return ""
+	}
+	return role
+}
+
+// LiveVotes redacts the vote state broadcast while voting is open. It returns
+// the voter -> target attributions and the target -> count tally that may be
+// shown; nil means that part is withheld.
+func (v VisibilityPolicy) LiveVotes(votes map[string]string, counts map[string]int) (map[string]string, map[string]int) {
+	switch {
+	case v.HiddenTally:
+		return nil, nil
+	case v.AnonymousVotes:
+		return nil, counts
+	default:
+		return votes, counts
+	}
+}
+
+// RevealBallots reports whether the day result lists who voted for whom.
+// Ballots withheld during a hidden vote are revealed at resolution unless
+// voting is anonymous; open votes were already public.
+func (v VisibilityPolicy) RevealBallots() bool {
+	return v.HiddenTally && !v.AnonymousVotes
+}
+
+// InvestigationResult returns whether an investigated role reads as mafia,
+// and whether the check used up the godfather's immunity
+func (v VisibilityPolicy) InvestigationResult(role Role, immunityUsed bool) (isMafia, usesImmunity bool) {
+	if role == RoleGodfather {
+		if v.GodfatherImmunity && !immunityUsed {
+			return false, true
+		}
+		return true, false
+	}
+	return role == RoleMafia, false
+}
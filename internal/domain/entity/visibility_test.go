@@ -0,0 +1,91 @@
+package entity
+
+import "testing"
+
+func TestDeathRolesFollowRevealSetting(t *testing.T) {
+	for _, reveal := range []bool{false, true} {
+		game := newTestGame(t, 7, nil)
+		game.Room.Settings.RevealRolesOnDeath = reveal
+		byRole := playersByRole(game)
+		mafia := byRole[RoleMafia]
+		victim := byRole[RoleVillager][0]
+		want := func(role Role) Role {
+			if reveal {
+				return role
+			}
+			return ""
+		}
+
+		// The first night never kills, so the day comes first
+		day := lynch(t, game, mafia[0])
+		if day.EliminatedID != mafia[0] {
+			t.Fatalf("reveal %v: lynched %q, want %s", reveal, day.EliminatedID, mafia[0])
+		}
+		if day.EliminatedRole != want(RoleMafia) {
+			t.Errorf("reveal %v: eliminated role %q", reveal, day.EliminatedRole)
+		}
+
+		night := killAtNight(t, game, mafia[1:], victim)
+		if night.KilledID != victim {
+			t.Fatalf("reveal %v: night killed %q, want %s", reveal, night.KilledID, victim)
+		}
+		if night.KilledRole != want(RoleVillager) {
+			t.Errorf("reveal %v: killed role %q", reveal, night.KilledRole)
+		}
+	}
+}
+
+func TestVisibilityPolicyRedaction(t *testing.T) {
+	votes := map[string]string{"a": "c", "b": "c"}
+	counts := map[string]int{"c": 2}
+
+	for _, tt := range []struct {
+		name                       string
+		settings                   GameSettings
+		wantVotes, wantCounts      bool
+		wantBallots, wantDeathRole bool
+	}{
+		{"open", GameSettings{RevealRolesOnDeath: true}, true, true, false, true},
+		{"roles hidden", GameSettings{}, true, true, false, false},
+		{"anonymous", GameSettings{AnonymousVoting: true, RevealRolesOnDeath: true}, false, true, false, true},
+		{"hidden tally", GameSettings{HiddenVoting: true}, false, false, true, false},
+		{"hidden and anonymous", GameSettings{HiddenVoting: true, AnonymousVoting: true}, false, false, false, false},
+	} {
+		policy := tt.settings.Visibility()
+
+		gotVotes, gotCounts := policy.LiveVotes(votes, counts)
+		if (gotVotes != nil) != tt.wantVotes || (gotCounts != nil) != tt.wantCounts {
+			t.Errorf("%s: live votes %v, counts %v", tt.name, gotVotes, gotCounts)
+		}
+		if got := policy.RevealBallots(); got != tt.wantBallots {
+			t.Errorf("%s: reveal ballots = %v", tt.name, got)
+		}
+		for _, role := range []Role{RoleVillager, RoleMafia, RoleGodfather} {
+			var want Role
+			if tt.wantDeathRole {
+				want = role
+			}
+			if got := policy.DeathRole(role); got != want {
+				t.Errorf("%s: death role for %s = %q, want %q", tt.name, role, got, want)
+			}
+		}
+
+		// Investigations read the same under every variant
+		for _, check := range []struct {
+			role                  Role
+			immunityUsed          bool
+			wantMafia, wantSpends bool
+		}{
+			{RoleVillager, false, false, false},
+			{RoleMafia, false, true, false},
+			{RoleGodfather, false, false, true},
+			{RoleGodfather, true, true, false},
+		} {
+			isMafia, spends := policy.InvestigationResult(check.role, check.immunityUsed)
+			if isMafia != check.wantMafia || spends != check.wantSpends {
+				t.Errorf("%s: investigate %s (immunity used %v) = mafia %v, spends %v",
+					tt.name, check.role, check.immunityUsed, isMafia, spends)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestAutoBalanceFitsEveryPlayerCount(t *testing.T) {
+	_, games, _ := newTestServices()
+
+	for n := entity.MinPlayers; n <= entity.MaxPlayersCeiling; n++ {
+		s := games.AutoBalance(n)
+		if s.TotalPlayers() != n {
+			t.Errorf("%d players: settings seat %d", n, s.TotalPlayers())
+		}
+		if err := s.Validate(n); err != nil {
+			t.Errorf("%d players: %v (mafia %d, godfather %d)", n, err, s.Mafia, s.Godfather)
+		}
+		if _, err := entity.BuildRolePool(s, n); err != nil {
+			t.Errorf("%d players: role pool: %v", n, err)
+		}
+		mafia := s.Mafia + s.Godfather
+		if mafia < 1 || 2*mafia >= n {
+			t.Errorf("%d players: %d mafia is not a minority", n, mafia)
+		}
+		if s.Doctor > 1 || s.Detective > 1 || s.Godfather > 1 {
+			t.Errorf("%d players: more than one of a unique role: %+v", n, s)
+		}
+	}
+}
+
+func TestAutoBalanceDistributions(t *testing.T) {
+	_, games, _ := newTestServices()
+
+	tests := []struct {
+		players                                        int
+		villagers, mafia, godfather, doctor, detective int
+	}{
+		{3, 2, 1, 0, 0, 0},
+		{5, 2, 1, 0, 1, 1},
+		{7, 3, 2, 0, 1, 1},
+		{10, 5, 2, 1, 1, 1},
+		{12, 7, 2, 1, 1, 1},
+		{20, 13, 4, 1, 1, 1},
+	}
+	for _, tt := range tests {
+		s := games.AutoBalance(tt.players)
+		got := [5]int{s.Villagers, s.Mafia, s.Godfather, s.Doctor, s.Detective}
+		want := [5]int{tt.villagers, tt.mafia, tt.godfather, tt.doctor, tt.detective}
+		if got != want {
+			t.Errorf("%d players: got %v, want %v", tt.players, got, want)
+		}
+	}
+}
+
+func TestApplyRolesKeepsRulesAndNeedsHost(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	room := seatPlayers(t, rooms, 10)
+	room.Settings.Lovers = true
+
+	balanced := games.AutoBalance(room.PlayerCount())
+	if _, _, err := rooms.ApplyRoles(room.Code, "p1", balanced); !errors.Is(err, entity.ErrNotHost) {
+		t.Fatalf("non-host apply: %v, want %v", err, entity.ErrNotHost)
+	}
+
+	settings, _, err := rooms.ApplyRoles(room.Code, "p0", balanced)
+	if err != nil {
+		t.Fatalf("apply: %v", err)
+	}
+	if settings.TotalPlayers() != 10 || settings.Godfather != 1 {
+		t.Errorf("applied %+v, want a balanced 10-player setup", settings)
+	}
+	if !room.Settings.Lovers || room.Settings != settings {
+		t.Error("applying roles changed the room's rule toggles")
+	}
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// UpdateAutoStart arms the room's auto-start countdown once the lobby could
+// start (enough players, all ready, a dealable setup), and cancels a pending
+// one when that stops being true. Call it whenever readiness, the player list
+// or the settings change.
+func (s *GameService) UpdateAutoStart(roomCode string) {
+	room, err := s.roomService.GetRoom(roomCode)
+	if err != nil {
+		return
+	}
+	seconds := room.Settings.AutoStartSeconds
+	armed := seconds > 0 && room.LobbyStatus().CanStart
+
+	s.timerMu.Lock()
+	pending, ok := s.autoStarts[roomCode]
+	switch {
+	case armed && !ok:
+		var timer *time.Timer
+		timer = time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+			s.expireAutoStart(roomCode, timer)
+		})
+		s.autoStarts[roomCode] = timer
+	case !armed && ok:
+		pending.Stop()
+		delete(s.autoStarts, roomCode)
+	default:
+		s.timerMu.Unlock()
+		return
+	}
+	s.timerMu.Unlock()
+
+	s.logger.Info("auto-start countdown changed", "room", roomCode, "armed", armed, "seconds", seconds)
+	data := map[string]any{"cancelled": !armed}
+	if armed {
+		data["seconds"] = seconds
+	}
+	s.emitEvent(GameEvent{
+		Type:     EventAutoStart,
+		RoomCode: roomCode,
+		Data:     data,
+	})
+}
+
+// expireAutoStart runs when timer's countdown ends. A countdown that was
+// cancelled or replaced since it was armed does nothing.
+func (s *GameService) expireAutoStart(roomCode string, timer *time.Timer) {
+	s.timerMu.Lock()
+	current := s.autoStarts[roomCode] == timer
+	if current {
+		delete(s.autoStarts, roomCode)
+	}
+	s.timerMu.Unlock()
+	if current {
+		s.autoStart(roomCode)
+	}
+}
+
+// autoStart begins the game on the host's behalf when the countdown ends
+func (s *GameService) autoStart(roomCode string) {
+	room, err := s.roomService.GetRoom(roomCode)
+	if err != nil {
+		return
+	}
+	host := room.GetHost()
+	if host == nil {
+		return
+	}
+	if err := s.StartGame(roomCode, host.ID); err != nil && !errors.Is(err, ErrStartQueued) {
+		s.logger.Warn("auto-start failed", "room", roomCode, "error", err)
+	}
+}
+
+// stopAutoStart drops a pending countdown without announcing it
+func (s *GameService) stopAutoStart(roomCode string) {
+	s.timerMu.Lock()
+	defer s.timerMu.Unlock()
+	if timer, ok := s.autoStarts[roomCode]; ok {
+		timer.Stop()
+		delete(s.autoStarts, roomCode)
+	}
+}
+
+// RoomDeleted drops what the service still holds for a deleted room
+func (s *GameService) RoomDeleted(roomCode string) {
+	s.stopAutoStart(roomCode)
+
+	s.mu.Lock()
+	delete(s.dealSeeds, roomCode)
+	s.mu.Unlock()
+}
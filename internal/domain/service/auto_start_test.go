@@ -0,0 +1,113 @@
+package service
+
+import "testing"
+
+// fireAutoStart expires the room's pending countdown as if its timer ran out
+func fireAutoStart(t *testing.T, games *GameService, roomCode string) {
+	t.Helper()
+	games.timerMu.Lock()
+	timer, ok := games.autoStarts[roomCode]
+	games.timerMu.Unlock()
+	if !ok {
+		t.Fatal("no countdown armed")
+	}
+	timer.Stop()
+	games.expireAutoStart(roomCode, timer)
+}
+
+func autoStartArmed(games *GameService, roomCode string) bool {
+	games.timerMu.Lock()
+	defer games.timerMu.Unlock()
+	_, ok := games.autoStarts[roomCode]
+	return ok
+}
+
+func TestAutoStartFiresWhenAllReady(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	room := seatPlayers(t, rooms, 6)
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+
+	// Disabled by default
+	games.UpdateAutoStart(room.Code)
+	if n := len(recorder.ofType(EventAutoStart)); n != 0 {
+		t.Fatalf("%d countdowns with auto-start off", n)
+	}
+
+	room.Settings.AutoStartSeconds = 60
+	games.UpdateAutoStart(room.Code)
+	games.UpdateAutoStart(room.Code) // a repeat does not restart the countdown
+	countdowns := recorder.ofType(EventAutoStart)
+	if len(countdowns) != 1 {
+		t.Fatalf("%d countdown events, want 1", len(countdowns))
+	}
+	if data := countdowns[0].Data.(map[string]any); data["seconds"] != 60 || data["cancelled"] != false {
+		t.Errorf("countdown = %v, want 60 seconds, not cancelled", data)
+	}
+
+	fireAutoStart(t, games, room.Code)
+	if games.GetGame(room.Code) == nil {
+		t.Fatal("game did not auto-start")
+	}
+	if n := len(recorder.ofType(EventGameStarted)); n != 1 {
+		t.Errorf("%d game_started events, want 1", n)
+	}
+}
+
+func TestAutoStartCancelledByUnready(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	room := seatPlayers(t, rooms, 6)
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+	room.Settings.AutoStartSeconds = 60
+
+	games.UpdateAutoStart(room.Code)
+	games.timerMu.Lock()
+	timer := games.autoStarts[room.Code]
+	games.timerMu.Unlock()
+	if err := rooms.SetReady(room.Code, "p3", false); err != nil {
+		t.Fatalf("unready: %v", err)
+	}
+	games.UpdateAutoStart(room.Code)
+
+	countdowns := recorder.ofType(EventAutoStart)
+	if len(countdowns) != 2 {
+		t.Fatalf("%d countdown events, want armed then cancelled", len(countdowns))
+	}
+	if data := countdowns[1].Data.(map[string]any); data["cancelled"] != true {
+		t.Errorf("second countdown = %v, want cancelled", data)
+	}
+
+	// A timer that fires after being cancelled does nothing
+	games.expireAutoStart(room.Code, timer)
+	if games.GetGame(room.Code) != nil {
+		t.Error("game started after the countdown was cancelled")
+	}
+}
+
+func TestAutoStartWaitsForMinPlayers(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	room := seatPlayers(t, rooms, 2)
+	room.Settings.AutoStartSeconds = 60
+
+	games.UpdateAutoStart(room.Code)
+	if autoStartArmed(games, room.Code) {
+		t.Fatal("countdown armed below the minimum player count")
+	}
+	if n := len(recorder.ofType(EventAutoStart)); n != 0 {
+		t.Errorf("%d countdown events below the minimum player count", n)
+	}
+}
+
+func TestAutoStartStoppedWhenRoomDeleted(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	room := seatPlayers(t, rooms, 6)
+	room.Settings.AutoStartSeconds = 60
+
+	games.UpdateAutoStart(room.Code)
+	if !autoStartArmed(games, room.Code) {
+		t.Fatal("countdown not armed")
+	}
+	rooms.DeleteRoom(room.Code)
+	if autoStartArmed(games, room.Code) {
+		t.Error("countdown still pending after the room was deleted")
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// botActionDelay is how long bots wait into a phase before acting, so their
+// moves don't land before players have seen the phase change
+const botActionDelay = 2 * time.Second
+
+// scheduleBots lets the room's bots act once the phase has settled
+func (s *GameService) scheduleBots(roomCode string, phase entity.GamePhase) {
+	game := s.GetGame(roomCode)
+	if game == nil || !game.Room.HasBots() {
+		return
+	}
+	timer := time.AfterFunc(botActionDelay, func() {
+		s.runBots(roomCode, phase)
+	})
+
+	s.timerMu.Lock()
+	if pending, ok := s.botTimers[roomCode]; ok {
+		pending.Stop()
+	}
+	s.botTimers[roomCode] = timer
+	s.timerMu.Unlock()
+}
+
+// runBots submits a move for every living bot. Moves go through the same
+// entry points as players so progress events and early resolution still fire.
+func (s *GameService) runBots(roomCode string, phase entity.GamePhase) {
+	game := s.GetGame(roomCode)
+	if game == nil || game.GetPhase() != phase {
+		return
+	}
+
+	if phase == entity.PhaseCupid {
+		// A bot Cupid leaves the pairing to chance rather than hold up the game
+		if cupid := game.Room.GetPlayer(game.CupidID()); cupid != nil && cupid.IsBot {
+			s.resolveCupid(roomCode)
+		}
+		return
+	}
+
+	for botID, targetID := range game.BotTargets() {
+		var err error
+		switch phase {
+		case entity.PhaseNight:
+			err = s.SubmitNightAction(roomCode, botID, targetID)
+			if err == nil && game.Room.Settings.GodfatherDecides && game.GetPlayerRole(botID) == entity.RoleGodfather {
+				err = s.ConfirmKill(roomCode, botID)
+			}
+		case entity.PhaseDay:
+			err = s.SubmitDayVote(roomCode, botID, targetID)
+		case entity.PhaseLynchConfirm:
+			// The target is the pending lynch; bots spare only themselves
+			err = s.SubmitLynchConfirm(roomCode, botID, botID != targetID)
+		}
+		if err != nil {
+			// Usually the phase resolved early after an earlier move
+			s.logger.Debug("bot move rejected",
+				"room", roomCode,
+				"bot", botID,
+				"error", err,
+			)
+		}
+	}
+}
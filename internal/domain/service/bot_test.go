@@ -0,0 +1,96 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestBotsPlayGameToCompletion(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	room, err := rooms.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	if _, err := rooms.JoinRoom(room.Code, "", "p0", "Host"); err != nil {
+		t.Fatalf("join host: %v", err)
+	}
+	for range 6 {
+		if _, err := rooms.AddBot(room.Code); err != nil {
+			t.Fatalf("add bot: %v", err)
+		}
+	}
+	for _, dto := range room.GetPlayersDTO() {
+		if dto.IsBot == (dto.ID == "p0") {
+			t.Errorf("%s (%s): is_bot = %v", dto.ID, dto.Nickname, dto.IsBot)
+		}
+	}
+
+	room.Settings.RoleRevealSeconds = entity.MinRoleRevealSeconds
+	room.Settings.ResultSeconds = entity.MinResultSeconds
+	room.Settings.DiscussionSeconds = 0
+	room.Settings.SkipDisconnected = true
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+
+	// The host walks away so only bots are left to move
+	if !rooms.MarkPlayerDisconnected(room.Code, "p0", false) {
+		t.Fatal("host not marked disconnected")
+	}
+
+	// Drive the bots as soon as each phase opens rather than after
+	// botActionDelay; repeat moves are rejected like any other
+	game := games.GetGame(room.Code)
+	deadline := time.Now().Add(30 * time.Second)
+	for len(recorder.ofType(EventGameOver)) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("game stuck in %s, round %d", game.GetPhase(), game.Round)
+		}
+		if phase := game.GetPhase(); phase == entity.PhaseNight || phase == entity.PhaseDay {
+			games.runBots(room.Code, phase)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	over := recorder.ofType(EventGameOver)[0].Data.(map[string]any)
+	if over["aborted"] == true {
+		t.Fatalf("game aborted: %v", over["reason"])
+	}
+	if over["winner"] == "" || game.GetPhase() != entity.PhaseGameOver {
+		t.Errorf("game over in %s with winner %q after %d rounds", game.GetPhase(), over["winner"], game.Round)
+	}
+}
+
+func TestBotMovesStopWithThePhaseTimer(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	room := seatPlayers(t, rooms, 6)
+	if _, err := rooms.AddBot(room.Code); err != nil {
+		t.Fatalf("add bot: %v", err)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+
+	games.transitionToNight(room.Code)
+	games.timerMu.Lock()
+	timer, ok := games.botTimers[room.Code]
+	games.timerMu.Unlock()
+	if !ok {
+		t.Fatal("no bot moves scheduled for the night")
+	}
+
+	games.cancelPhaseTimer(room.Code)
+	if timer.Stop() {
+		t.Error("bot moves still pending after the phase timer stopped")
+	}
+	games.timerMu.Lock()
+	_, ok = games.botTimers[room.Code]
+	games.timerMu.Unlock()
+	if ok {
+		t.Error("stopped bot moves still tracked")
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// startCupid opens the setup phase in which Cupid picks the lovers
+func (s *GameService) startCupid(roomCode string) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return
+	}
+
+	duration := time.Duration(entity.CupidSeconds) * time.Second
+	game.StartCupid(duration)
+
+	s.logger.Info("cupid phase started", "room", roomCode, "cupid", game.CupidID())
+
+	s.emitEvent(GameEvent{
+		Type:     EventPhaseChanged,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"phase": string(entity.PhaseCupid),
+			"round": game.Round,
+			"timer": entity.CupidSeconds,
+		},
+	})
+	s.emitActionPrompts(roomCode, game)
+
+	s.startPhaseTimer(roomCode, duration, func() {
+		s.resolveCupid(roomCode)
+	})
+	s.scheduleBots(roomCode, entity.PhaseCupid)
+}
+
+// SubmitCupidPick records Cupid's choice of lovers and moves on to the first night
+func (s *GameService) SubmitCupidPick(roomCode, playerID, firstID, secondID string) error {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return entity.ErrGameNotStarted
+	}
+
+	if err := game.SubmitCupidPick(playerID, firstID, secondID); err != nil {
+		return err
+	}
+
+	s.resolveCupid(roomCode)
+	return nil
+}
+
+// resolveCupid closes the setup phase, pairing the lovers at random if Cupid
+// never chose, tells the lovers and Cupid, and starts the first night
+func (s *GameService) resolveCupid(roomCode string) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return
+	}
+
+	// The timer and Cupid's pick can race to get here; only one resolves
+	if !game.TryTransition(entity.PhaseCupid, entity.PhaseNight) {
+		return
+	}
+	// Only the winner stops the timer; a loser would cancel the next phase's
+	s.cancelPhaseTimer(roomCode)
+	first, second, chosen := game.ResolveCupid()
+
+	s.logger.Info("lovers paired",
+		"room", roomCode,
+		"lovers", []string{first, second},
+		"chosen_by_cupid", chosen,
+	)
+
+	for _, pair := range [][2]string{{first, second}, {second, first}} {
+		lover := game.Room.GetPlayer(pair[1])
+		if player := game.Room.GetPlayer(pair[0]); player == nil || player.IsBot || lover == nil {
+			continue
+		}
+		s.emitEvent(GameEvent{
+			Type:           EventLoversPaired,
+			RoomCode:       roomCode,
+			TargetPlayerID: pair[0],
+			Data: map[string]any{
+				"lover": map[string]string{
+					"id":       lover.ID,
+					"nickname": lover.Nickname,
+				},
+				"chosen_by_cupid": chosen,
+			},
+		})
+	}
+	if cupidID := game.CupidID(); cupidID != "" && cupidID != first && cupidID != second {
+		if cupid := game.Room.GetPlayer(cupidID); cupid != nil && !cupid.IsBot {
+			s.emitEvent(GameEvent{
+				Type:           EventLoversPaired,
+				RoomCode:       roomCode,
+				TargetPlayerID: cupidID,
+				Data: map[string]any{
+					"lovers":          []string{first, second},
+					"chosen_by_cupid": chosen,
+				},
+			})
+		}
+	}
+
+	s.transitionToNight(roomCode)
+}
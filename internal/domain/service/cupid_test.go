@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func startCupidGame(t *testing.T) (*GameService, *entity.Game, *eventRecorder, string) {
+	t.Helper()
+	games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+		s.Lovers = true
+		s.Cupid = true
+	})
+	games.cancelPhaseTimer(game.Room.Code)
+	games.startCupid(game.Room.Code)
+	if game.GetPhase() != entity.PhaseCupid {
+		t.Fatalf("phase = %s, want %s", game.GetPhase(), entity.PhaseCupid)
+	}
+	return games, game, recorder, game.CupidID()
+}
+
+func TestCupidSelectionStartsFirstNight(t *testing.T) {
+	games, game, recorder, cupid := startCupidGame(t)
+	villagers := make([]string, 0, 2)
+	for _, id := range game.Room.PlayerOrder {
+		if game.Roles[id] == entity.RoleVillager {
+			villagers = append(villagers, id)
+		}
+	}
+	a, b := villagers[0], villagers[1]
+
+	if err := games.SubmitCupidPick(game.Room.Code, a, a, b); !errors.Is(err, entity.ErrNotCupid) {
+		t.Fatalf("non-cupid pick: %v, want %v", err, entity.ErrNotCupid)
+	}
+	if err := games.SubmitCupidPick(game.Room.Code, cupid, a, b); err != nil {
+		t.Fatalf("pick: %v", err)
+	}
+	if game.GetPhase() != entity.PhaseNight {
+		t.Errorf("phase after pick = %s, want %s", game.GetPhase(), entity.PhaseNight)
+	}
+
+	told := make(map[string]any)
+	for _, event := range recorder.ofType(EventLoversPaired) {
+		data := event.Data.(map[string]any)
+		if data["chosen_by_cupid"] != true {
+			t.Errorf("event for %s not marked as cupid's choice", event.TargetPlayerID)
+		}
+		told[event.TargetPlayerID] = data["lover"]
+	}
+	if len(told) != 3 {
+		t.Fatalf("lovers_paired sent to %v, want both lovers and cupid", told)
+	}
+	if lover := told[a].(map[string]string); lover["id"] != b {
+		t.Errorf("%s told their lover is %s, want %s", a, lover["id"], b)
+	}
+}
+
+func TestCupidTimeoutFallsBackToRandomPair(t *testing.T) {
+	games, game, recorder, _ := startCupidGame(t)
+
+	// What the phase timer runs when Cupid never picks
+	games.resolveCupid(game.Room.Code)
+	games.resolveCupid(game.Room.Code)
+
+	if game.GetPhase() != entity.PhaseNight {
+		t.Errorf("phase after timeout = %s, want %s", game.GetPhase(), entity.PhaseNight)
+	}
+	if len(game.Lovers) != 2 {
+		t.Fatalf("lovers = %v, want one random pair", game.Lovers)
+	}
+	for _, event := range recorder.ofType(EventLoversPaired) {
+		if event.Data.(map[string]any)["chosen_by_cupid"] != false {
+			t.Errorf("random pairing reported as cupid's choice to %s", event.TargetPlayerID)
+		}
+	}
+	if nights := recorder.ofType(EventPhaseChanged); nights[len(nights)-1].Data.(map[string]any)["phase"] != string(entity.PhaseNight) {
+		t.Error("first night did not start after the cupid timeout")
+	}
+}
+
+func TestLosingCupidResolveLeavesNightTimer(t *testing.T) {
+	games, game, _, _ := startCupidGame(t)
+	roomCode := game.Room.Code
+
+	// The timer wins and arms the night; Cupid's late pick then loses the race
+	games.resolveCupid(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Fatal("no night timer after cupid resolved")
+	}
+	games.resolveCupid(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Error("the losing resolve cancelled the night timer")
+	}
+}
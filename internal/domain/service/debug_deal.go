@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// ErrDebugDisabled is returned by debug commands outside development mode
+var ErrDebugDisabled = errors.New("debug commands are disabled")
+
+// SetDebugDeals enables deal seeds for reproducing role assignments: hosts
+// may pin the next deal's seed, and game_started carries the seed used.
+// Only enable this in development; a known seed reveals every role.
+func (s *GameService) SetDebugDeals(enabled bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.debugDeals = enabled
+}
+
+// RedealRoles pins the seed the room's next game deals roles with, so a
+// previous deal can be reproduced. A nil seed picks a fresh one. Returns the
+// pinned seed.
+func (s *GameService) RedealRoles(roomCode, hostPlayerID string, seed *int64) (int64, error) {
+	room, err := s.roomService.GetRoom(roomCode)
+	if err != nil {
+		return 0, err
+	}
+
+	host := room.GetHost()
+	if host == nil || host.ID != hostPlayerID {
+		return 0, entity.ErrNotHost
+	}
+	if room.State == entity.RoomStatePlaying {
+		return 0, entity.ErrGameAlreadyStarted
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.debugDeals {
+		return 0, ErrDebugDisabled
+	}
+
+	pinned := time.Now().UnixNano()
+	if seed != nil {
+		pinned = *seed
+	}
+	s.dealSeeds[roomCode] = pinned
+
+	s.logger.Info("deal seed pinned", "room", roomCode, "seed", pinned)
+	return pinned, nil
+}
+
+// nextDealSeed returns the pinned seed for the room's next game, or a fresh one
+func (s *GameService) nextDealSeed(roomCode string) int64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if seed, ok := s.dealSeeds[roomCode]; ok {
+		return seed
+	}
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestRedealRolesReproducesDeal(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	seed := int64(20240601)
+
+	room := seatPlayers(t, rooms, 7)
+	if _, err := games.RedealRoles(room.Code, "p0", &seed); err != ErrDebugDisabled {
+		t.Fatalf("redeal without debug deals: err = %v, want ErrDebugDisabled", err)
+	}
+	games.SetDebugDeals(true)
+	if _, err := games.RedealRoles(room.Code, "p1", &seed); err != entity.ErrNotHost {
+		t.Fatalf("redeal by non-host: err = %v, want ErrNotHost", err)
+	}
+
+	// Two tables of the same players pinned to one seed deal identically
+	var deals []map[string]entity.Role
+	for _, room := range []*entity.Room{room, seatPlayers(t, rooms, 7)} {
+		if got, err := games.RedealRoles(room.Code, "p0", &seed); err != nil || got != seed {
+			t.Fatalf("redeal: seed %d, err %v", got, err)
+		}
+		if err := games.StartGame(room.Code, "p0"); err != nil {
+			t.Fatalf("start game: %v", err)
+		}
+		t.Cleanup(func() { games.DiscardGame(room.Code) })
+		game := games.GetGame(room.Code)
+		if game.Seed != seed {
+			t.Errorf("game seed %d, want %d", game.Seed, seed)
+		}
+		deals = append(deals, maps.Clone(game.Roles))
+
+		if _, err := games.RedealRoles(room.Code, "p0", &seed); err != entity.ErrGameAlreadyStarted {
+			t.Errorf("redeal mid-game: err = %v, want ErrGameAlreadyStarted", err)
+		}
+	}
+	if !maps.Equal(deals[0], deals[1]) {
+		t.Errorf("same seed dealt %v and %v", deals[0], deals[1])
+	}
+
+	// The seed is handed back so the deal can be fed in again
+	started := recorder.ofType(EventGameStarted)
+	if len(started) != 2 {
+		t.Fatalf("game_started emitted %d times, want 2", len(started))
+	}
+	for _, event := range started {
+		if got := event.Data.(map[string]any)["seed"]; got != seed {
+			t.Errorf("game_started seed = %v, want %d", got, seed)
+		}
+	}
+}
+
+func TestPinnedSeedDroppedWithRoom(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	games.SetDebugDeals(true)
+	room := seatPlayers(t, rooms, 7)
+	seed := int64(42)
+	if _, err := games.RedealRoles(room.Code, "p0", &seed); err != nil {
+		t.Fatalf("redeal: %v", err)
+	}
+
+	rooms.DeleteRoom(room.Code)
+	games.mu.RLock()
+	_, ok := games.dealSeeds[room.Code]
+	games.mu.RUnlock()
+	if ok {
+		t.Error("pinned seed kept after the room was deleted")
+	}
+}
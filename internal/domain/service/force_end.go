@@ -0,0 +1,47 @@
+package service
+
+import "errors"
+
+// ErrNoActiveGame is returned when a room has no game in progress
+var ErrNoActiveGame = errors.New("no active game in room")
+
+// ForceEndGame aborts a room's game without a winner, for operators clearing
+// a game that has hung. Timers are cancelled and game_over is emitted with
+// "aborted" set, so clients leave the game screen and voice opens up as it
+// would after a normal ending. Aborted games are not recorded in history.
+func (s *GameService) ForceEndGame(roomCode, reason string) error {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return ErrNoActiveGame
+	}
+
+	// Stop timers first so no phase resolves, bot moves or queued start
+	// lands while the game is torn down
+	s.cancelPhaseTimer(roomCode)
+	s.stopAutoStart(roomCode)
+	phase := game.GetPhase()
+	if !game.EndGame("") {
+		return ErrNoActiveGame
+	}
+
+	s.logger.Warn("game force-ended by operator",
+		"room", roomCode,
+		"phase", phase,
+		"round", game.Round,
+		"reason", reason,
+	)
+
+	data := gameOverData(game, true)
+	data["winner"] = ""
+	data["winner_label"] = "Game aborted"
+	data["winners"] = []string{}
+	data["reason"] = reason
+	s.emitEvent(GameEvent{
+		Type:     EventGameOver,
+		RoomCode: roomCode,
+		Data:     data,
+	})
+	s.finishReplay(roomCode, "")
+	s.closeGame(roomCode)
+	return nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestForceEndGameAbortsAndCleansUp(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, nil)
+	roomCode := game.Room.Code
+	games.transitionToNight(roomCode)
+
+	if err := games.ForceEndGame(roomCode, "stuck"); err != nil {
+		t.Fatalf("force end: %v", err)
+	}
+
+	games.timerMu.Lock()
+	_, timer := games.phaseTimers[roomCode]
+	_, ticker := games.timerCancels[roomCode]
+	games.timerMu.Unlock()
+	if timer || ticker {
+		t.Errorf("timers left running: phase timer %v, ticker %v", timer, ticker)
+	}
+	if games.GetGame(roomCode) != nil {
+		t.Error("game still registered after force end")
+	}
+	if game.Room.State != entity.RoomStateEnded || game.GetPhase() != entity.PhaseGameOver {
+		t.Errorf("room %s in phase %s, want ended game_over", game.Room.State, game.GetPhase())
+	}
+
+	over := recorder.ofType(EventGameOver)
+	if len(over) != 1 {
+		t.Fatalf("got %d game_over events, want 1", len(over))
+	}
+	data := over[0].Data.(map[string]any)
+	if data["aborted"] != true || data["reason"] != "stuck" || data["winner"] != "" {
+		t.Errorf("game_over = %v, want aborted with no winner", data)
+	}
+	if players := data["players"].([]map[string]any); len(players) != 7 {
+		t.Errorf("game_over revealed %d players, want 7", len(players))
+	}
+
+	if err := games.ForceEndGame(roomCode, "again"); !errors.Is(err, ErrNoActiveGame) {
+		t.Errorf("second force end = %v, want ErrNoActiveGame", err)
+	}
+}
+
+func TestForceEndGameReportsStatsWithOpenBallots(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+		s.GameStats = true
+		s.DiscussionSeconds = 0
+	})
+	roomCode := game.Room.Code
+	games.transitionToDay(roomCode)
+	if err := games.SubmitDayVote(roomCode, "p0", "p1"); err != nil {
+		t.Fatalf("vote: %v", err)
+	}
+
+	if err := games.ForceEndGame(roomCode, "stuck"); err != nil {
+		t.Fatalf("force end: %v", err)
+	}
+
+	over := recorder.ofType(EventGameOver)
+	if len(over) != 1 {
+		t.Fatalf("got %d game_over events, want 1", len(over))
+	}
+	stats, ok := over[0].Data.(map[string]any)["stats"].(map[string]entity.PlayerStats)
+	if !ok {
+		t.Fatal("aborted game_over carries no stats")
+	}
+	if got := stats["p0"].VotesCast; got != 1 {
+		t.Errorf("p0 cast %d votes, want the ballot from the day cut short", got)
+	}
+}
+
+func TestForceEndGameStopsBotAndAutoStartTimers(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	room := seatPlayers(t, rooms, 6)
+	if _, err := rooms.AddBot(room.Code); err != nil {
+		t.Fatalf("add bot: %v", err)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+
+	games.transitionToNight(room.Code)
+	games.timerMu.Lock()
+	_, botsPending := games.botTimers[room.Code]
+	games.autoStarts[room.Code] = time.AfterFunc(time.Hour, func() {})
+	games.timerMu.Unlock()
+	if !botsPending {
+		t.Fatal("no bot moves scheduled for the night")
+	}
+
+	if err := games.ForceEndGame(room.Code, "stuck"); err != nil {
+		t.Fatalf("force end: %v", err)
+	}
+
+	games.timerMu.Lock()
+	_, bots := games.botTimers[room.Code]
+	_, autoStart := games.autoStarts[room.Code]
+	games.timerMu.Unlock()
+	if bots || autoStart {
+		t.Errorf("timers left pending: bot moves %v, auto-start %v", bots, autoStart)
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+var (
+	// ErrTooManyActiveGames is returned when the server is already running
+	// as many games as its limit allows
+	ErrTooManyActiveGames = errors.New("too many active games")
+
+	// ErrStartQueued is returned when a start over the limit was queued; the
+	// game begins once another game ends
+	ErrStartQueued = errors.New("game start queued")
+)
+
+// SetActiveGameLimit caps how many games may be in progress at once
+// (0 = no cap). With queue set, starts over the cap wait for a free slot
+// instead of failing. Call before any game starts.
+func (s *GameService) SetActiveGameLimit(limit int, queue bool) {
+	s.maxActiveGames = limit
+	s.queueStarts = queue
+}
+
+// ActiveGameLimit returns the cap on games in progress (0 = none)
+func (s *GameService) ActiveGameLimit() int {
+	return s.maxActiveGames
+}
+
+// QueuedStarts returns how many rooms are waiting for a free game slot
+func (s *GameService) QueuedStarts() int {
+	s.startMu.Lock()
+	defer s.startMu.Unlock()
+	return len(s.startQueue)
+}
+
+// admitStartLocked checks the active game limit for a room about to start.
+// Over the limit the room is queued (when queueing is on and the room could
+// otherwise start) and ErrStartQueued returned, or ErrTooManyActiveGames.
+// Must hold startMu.
+func (s *GameService) admitStartLocked(room *entity.Room) error {
+	if s.maxActiveGames <= 0 || s.GameCount() < s.maxActiveGames {
+		return nil
+	}
+	if !s.queueStarts || !room.LobbyStatus().CanStart {
+		return ErrTooManyActiveGames
+	}
+
+	position := slices.Index(s.startQueue, room.Code) + 1
+	if position == 0 {
+		s.startQueue = append(s.startQueue, room.Code)
+		position = len(s.startQueue)
+	}
+	s.logger.Info("game start queued", "room", room.Code, "position", position)
+	s.emitEvent(GameEvent{
+		Type:     EventGameQueued,
+		RoomCode: room.Code,
+		Data: map[string]any{
+			"position": position,
+		},
+	})
+	return ErrStartQueued
+}
+
+// startQueued starts queued rooms while there are free game slots. Rooms
+// that can no longer start (a player left or un-readied) are dropped from
+// the queue and told so.
+func (s *GameService) startQueued() {
+	for {
+		s.startMu.Lock()
+		if len(s.startQueue) == 0 || (s.maxActiveGames > 0 && s.GameCount() >= s.maxActiveGames) {
+			s.startMu.Unlock()
+			return
+		}
+		roomCode := s.startQueue[0]
+		s.startQueue = s.startQueue[1:]
+		s.announceQueueLocked()
+		s.startMu.Unlock()
+
+		room, err := s.roomService.GetRoom(roomCode)
+		if err != nil {
+			continue
+		}
+		host := room.GetHost()
+		if host == nil {
+			continue
+		}
+		if err := s.StartGame(roomCode, host.ID); err != nil && !errors.Is(err, ErrStartQueued) {
+			s.logger.Warn("queued game failed to start", "room", roomCode, "error", err)
+			s.emitEvent(GameEvent{
+				Type:     EventGameQueued,
+				RoomCode: roomCode,
+				Data: map[string]any{
+					"cancelled": true,
+				},
+			})
+		}
+	}
+}
+
+// unqueueStartLocked removes a room that has started from the start queue.
+// Must hold startMu.
+func (s *GameService) unqueueStartLocked(roomCode string) {
+	queued := len(s.startQueue)
+	s.startQueue = slices.DeleteFunc(s.startQueue, func(code string) bool {
+		return code == roomCode
+	})
+	if len(s.startQueue) != queued {
+		s.announceQueueLocked()
+	}
+}
+
+// announceQueueLocked tells every queued room its position, after the queue
+// moved up. Must hold startMu.
+func (s *GameService) announceQueueLocked() {
+	for i, roomCode := range s.startQueue {
+		s.emitEvent(GameEvent{
+			Type:     EventGameQueued,
+			RoomCode: roomCode,
+			Data: map[string]any{
+				"position": i + 1,
+			},
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestActiveGameLimitRejectsUntilAGameEnds(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	games.SetActiveGameLimit(1, false)
+	first, second := seatPlayers(t, rooms, 5), seatPlayers(t, rooms, 5)
+	t.Cleanup(func() {
+		games.cancelPhaseTimer(first.Code)
+		games.cancelPhaseTimer(second.Code)
+	})
+
+	if err := games.StartGame(first.Code, "p0"); err != nil {
+		t.Fatalf("first start: %v", err)
+	}
+	if err := games.StartGame(second.Code, "p0"); !errors.Is(err, ErrTooManyActiveGames) {
+		t.Fatalf("start over the limit: %v, want %v", err, ErrTooManyActiveGames)
+	}
+	if games.HasActiveGame(second.Code) || games.QueuedStarts() != 0 {
+		t.Fatal("rejected start left a game or a queue entry behind")
+	}
+
+	if err := games.ForceEndGame(first.Code, "test"); err != nil {
+		t.Fatalf("end first game: %v", err)
+	}
+	if err := games.StartGame(second.Code, "p0"); err != nil {
+		t.Fatalf("start after a game ended: %v", err)
+	}
+}
+
+func TestQueuedStartBeginsWhenASlotFrees(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	games.SetActiveGameLimit(1, true)
+	first, second := seatPlayers(t, rooms, 5), seatPlayers(t, rooms, 5)
+	t.Cleanup(func() {
+		games.cancelPhaseTimer(first.Code)
+		games.cancelPhaseTimer(second.Code)
+	})
+
+	if err := games.StartGame(first.Code, "p0"); err != nil {
+		t.Fatalf("first start: %v", err)
+	}
+	if err := games.StartGame(second.Code, "p0"); !errors.Is(err, ErrStartQueued) {
+		t.Fatalf("start over the limit: %v, want %v", err, ErrStartQueued)
+	}
+	queued := recorder.ofType(EventGameQueued)
+	if len(queued) != 1 || queued[0].RoomCode != second.Code || queued[0].Data.(map[string]any)["position"] != 1 {
+		t.Fatalf("game_queued events = %+v, want position 1 for the second room", queued)
+	}
+
+	if err := games.ForceEndGame(first.Code, "test"); err != nil {
+		t.Fatalf("end first game: %v", err)
+	}
+	if !games.HasActiveGame(second.Code) || games.QueuedStarts() != 0 {
+		t.Error("queued game did not start once the first game ended")
+	}
+}
+
+func TestQueuePositionsMoveUpOnEachDequeue(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	games.SetActiveGameLimit(1, true)
+	running := seatPlayers(t, rooms, 5)
+	queued := []*entity.Room{seatPlayers(t, rooms, 5), seatPlayers(t, rooms, 5), seatPlayers(t, rooms, 5)}
+	t.Cleanup(func() {
+		games.cancelPhaseTimer(running.Code)
+		for _, room := range queued {
+			games.cancelPhaseTimer(room.Code)
+		}
+	})
+
+	if err := games.StartGame(running.Code, "p0"); err != nil {
+		t.Fatalf("first start: %v", err)
+	}
+	for _, room := range queued {
+		if err := games.StartGame(room.Code, "p0"); !errors.Is(err, ErrStartQueued) {
+			t.Fatalf("start over the limit: %v, want %v", err, ErrStartQueued)
+		}
+	}
+
+	if err := games.ForceEndGame(running.Code, "test"); err != nil {
+		t.Fatalf("end running game: %v", err)
+	}
+	if !games.HasActiveGame(queued[0].Code) {
+		t.Fatal("head of the queue did not start")
+	}
+
+	positions := make(map[string]any)
+	for _, event := range recorder.ofType(EventGameQueued) {
+		positions[event.RoomCode] = event.Data.(map[string]any)["position"]
+	}
+	for i, room := range queued[1:] {
+		if positions[room.Code] != i+1 {
+			t.Errorf("queued room %d last told position %v, want %d", i+2, positions[room.Code], i+1)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
@@ -13,6 +14,7 @@ type GameEventType string
 
 const (
 	EventGameStarted    GameEventType = "game_started"
+	EventForceReadied   GameEventType = "force_readied"
 	EventRoleAssigned   GameEventType = "role_assigned"
 	EventPhaseChanged   GameEventType = "phase_changed"
 	EventTimerTick      GameEventType = "timer_tick"
@@ -20,19 +22,34 @@ const (
 	EventDayResult      GameEventType = "day_result"
 	EventVoteUpdate     GameEventType = "vote_update"
 	EventMafiaVote      GameEventType = "mafia_vote"
+	EventNightProgress  GameEventType = "night_progress"
 	EventGameOver       GameEventType = "game_over"
 	EventVoiceRouting   GameEventType = "voice_routing"
+	EventPlayerDied     GameEventType = "player_died"
+	EventMafiaSummary   GameEventType = "mafia_night_summary"
+	EventAutoStart      GameEventType = "auto_start_countdown"
+	EventActionPrompt   GameEventType = "action_prompt"
+	EventLynchConfirm   GameEventType = "lynch_confirm_update"
+	EventGameQueued     GameEventType = "game_queued"
+	EventLoversPaired   GameEventType = "lovers_paired"
+	EventSpeakingTurn   GameEventType = "speaking_turn"
 )
 
 // GameEvent is emitted when game state changes
 type GameEvent struct {
-	Type     GameEventType
-	RoomCode string
-	Data     any
+	Type      GameEventType
+	RoomCode  string
+	Data      any
+	Timestamp time.Time // set when the event is emitted
 	// For targeted events (e.g., role reveal to specific player)
 	TargetPlayerID string
 }
 
+// IsPrivate returns true if the event was only sent to one player
+func (e GameEvent) IsPrivate() bool {
+	return e.TargetPlayerID != ""
+}
+
 // GameEventHandler handles game events
 type GameEventHandler func(event GameEvent)
 
@@ -41,6 +58,8 @@ type GameService struct {
 	games        map[string]*entity.Game // room code -> game
 	roomService  *RoomService
 	eventHandler GameEventHandler
+	auditLogger  GameEventHandler // optional sink recording every event for replay
+	store        GameStore // optional game history persistence
 	logger       *slog.Logger
 	mu           sync.RWMutex
 
@@ -48,6 +67,24 @@ type GameService struct {
 	phaseTimers   map[string]*time.Timer
 	timerCancels  map[string]chan struct{} // cancel channels for ticker goroutines
 	timerMu       sync.Mutex
+	autoStarts    map[string]*time.Timer // pending automatic starts, keyed by room code
+	botTimers     map[string]*time.Timer // pending bot moves, keyed by room code
+
+	// Pinned deal seeds for reproducing role assignments (see RedealRoles)
+	debugDeals bool
+	dealSeeds  map[string]int64 // room code -> seed for the next game
+
+	// Active game limit (see SetActiveGameLimit). startMu serializes starts
+	// and guards startQueue, room codes waiting for a free slot, oldest first.
+	maxActiveGames int
+	queueStarts    bool
+	startQueue     []string
+	startMu        sync.Mutex
+
+	// Replays of the latest game per room (see ExportReplay)
+	replays     map[string]*Replay
+	replayOrder []string // room codes, oldest first, for eviction
+	replayMu    sync.Mutex
 }
 
 // NewGameService creates a new game service
@@ -58,6 +95,10 @@ func NewGameService(roomService *RoomService, logger *slog.Logger) *GameService
 		logger:       logger,
 		phaseTimers:  make(map[string]*time.Timer),
 		timerCancels: make(map[string]chan struct{}),
+		autoStarts:   make(map[string]*time.Timer),
+		botTimers:    make(map[string]*time.Timer),
+		replays:      make(map[string]*Replay),
+		dealSeeds:    make(map[string]int64),
 	}
 }
 
@@ -66,8 +107,23 @@ func (s *GameService) SetEventHandler(handler GameEventHandler) {
 	s.eventHandler = handler
 }
 
+// SetGameStore sets where finished games are recorded for player history
+func (s *GameService) SetGameStore(store GameStore) {
+	s.store = store
+}
+
+// SetAuditLogger sets a sink that receives every event, independent of the event handler
+func (s *GameService) SetAuditLogger(logger GameEventHandler) {
+	s.auditLogger = logger
+}
+
 // emitEvent sends an event to the handler
 func (s *GameService) emitEvent(event GameEvent) {
+	event.Timestamp = time.Now()
+	s.recordReplayEvent(event)
+	if s.auditLogger != nil {
+		s.auditLogger(event)
+	}
 	if s.eventHandler != nil {
 		s.eventHandler(event)
 	}
@@ -86,29 +142,53 @@ func (s *GameService) StartGame(roomCode, hostPlayerID string) error {
 		return entity.ErrNotHost
 	}
 
+	// Admit and register the game under startMu so concurrent starts
+	// cannot overshoot the active game limit
+	s.startMu.Lock()
+	if err := s.admitStartLocked(room); err != nil {
+		s.startMu.Unlock()
+		return err
+	}
+
 	// Create game
-	game, err := entity.NewGame(room)
+	game, err := entity.NewGameWithSeed(room, s.nextDealSeed(roomCode))
 	if err != nil {
+		s.startMu.Unlock()
 		return err
 	}
 
 	s.mu.Lock()
 	s.games[roomCode] = game
+	delete(s.dealSeeds, roomCode)
+	debugDeals := s.debugDeals
 	s.mu.Unlock()
+	s.unqueueStartLocked(roomCode)
+	s.startMu.Unlock()
+	s.stopAutoStart(roomCode)
+	s.beginReplay(roomCode, game)
 
 	s.logger.Info("game started",
 		"room", roomCode,
 		"players", room.PlayerCount(),
+		"seed", game.Seed,
 	)
 
-	// Emit game started event
+	// Emit game started event (with the deal seed when debugging deals)
+	var startedData any
+	if debugDeals {
+		startedData = map[string]any{"seed": game.Seed}
+	}
 	s.emitEvent(GameEvent{
 		Type:     EventGameStarted,
 		RoomCode: roomCode,
+		Data:     startedData,
 	})
 
 	// Send role assignments to each player
 	for _, playerID := range room.PlayerOrder {
+		if room.GetPlayer(playerID).IsBot {
+			continue
+		}
 		roleData := game.GetRoleRevealData(playerID)
 		s.emitEvent(GameEvent{
 			Type:           EventRoleAssigned,
@@ -118,14 +198,117 @@ func (s *GameService) StartGame(roomCode, hostPlayerID string) error {
 		})
 	}
 
-	// Start role reveal phase timer (5 seconds)
-	s.schedulePhaseTransition(roomCode, 5*time.Second, func() {
-		s.transitionToNight(roomCode)
+	// Start role reveal phase timer; with Cupid the lovers are picked first
+	next := s.transitionToNight
+	if room.Settings.HasCupid() {
+		next = s.startCupid
+	}
+	s.schedulePhaseTransition(roomCode, room.Settings.RoleRevealDuration(), func() {
+		next(roomCode)
 	})
 
 	return nil
 }
 
+// ForceStartGame starts the game after readying any players who are not ready.
+// The minimum player count still applies. If the game cannot start the
+// players are left as they were and nobody is told they were readied.
+func (s *GameService) ForceStartGame(roomCode, hostPlayerID string) error {
+	room, err := s.roomService.GetRoom(roomCode)
+	if err != nil {
+		return err
+	}
+
+	// Verify host before touching ready states
+	host := room.GetHost()
+	if host == nil || host.ID != hostPlayerID {
+		return entity.ErrNotHost
+	}
+	if room.PlayerCount() < entity.MinPlayers {
+		return entity.ErrNotEnoughPlayers
+	}
+
+	readied := room.ReadyAll()
+	err = s.StartGame(roomCode, hostPlayerID)
+	if err != nil && !errors.Is(err, ErrStartQueued) {
+		for _, id := range readied {
+			room.SetReady(id, false)
+		}
+		return err
+	}
+
+	// A queued start keeps the players readied so it can go ahead later
+	if len(readied) > 0 {
+		s.logger.Info("players force-readied",
+			"room", roomCode,
+			"players", readied,
+		)
+		s.emitEvent(GameEvent{
+			Type:     EventForceReadied,
+			RoomCode: roomCode,
+			Data: map[string]any{
+				"player_ids": readied,
+			},
+		})
+	}
+	return err
+}
+
+// PreviewRoles returns the role counts the room's current settings would deal
+func (s *GameService) PreviewRoles(roomCode string) (map[entity.Role]int, error) {
+	room, err := s.roomService.GetRoom(roomCode)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := entity.BuildRolePool(room.Settings, room.PlayerCount())
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[entity.Role]int)
+	for _, role := range roles {
+		counts[role]++
+	}
+	return counts, nil
+}
+
+// AutoBalance returns the default settings with role counts balanced for
+// playerCount (see entity.GameSettings.Balanced)
+func (s *GameService) AutoBalance(playerCount int) entity.GameSettings {
+	return entity.DefaultSettings().Balanced(playerCount)
+}
+
+// HasActiveGame returns true if the room has a game that has not ended
+func (s *GameService) HasActiveGame(roomCode string) bool {
+	game := s.GetGame(roomCode)
+	return game != nil && game.GetPhase() != entity.PhaseGameOver
+}
+
+// GameCount returns the number of games that have not ended
+func (s *GameService) GameCount() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, game := range s.games {
+		if game.GetPhase() != entity.PhaseGameOver {
+			count++
+		}
+	}
+	return count
+}
+
+// DiscardGame drops a room's game and its timers (e.g., when the room returns to the lobby)
+func (s *GameService) DiscardGame(roomCode string) {
+	s.cancelPhaseTimer(roomCode)
+	s.dropUnfinishedReplay(roomCode)
+	s.mu.Lock()
+	delete(s.games, roomCode)
+	s.mu.Unlock()
+	s.startQueued()
+}
+
 // GetGame returns a game by room code
 func (s *GameService) GetGame(roomCode string) *entity.Game {
 	s.mu.RLock()
@@ -140,13 +323,13 @@ func (s *GameService) transitionToNight(roomCode string) {
 		return
 	}
 
-	duration := time.Duration(game.Room.Settings.NightTimer) * time.Second
+	duration := game.Room.Settings.NightDuration()
 	game.StartNight(duration)
-	game.Round++
+	round := game.AdvanceRound()
 
 	s.logger.Info("night phase started",
 		"room", roomCode,
-		"round", game.Round,
+		"round", round,
 	)
 
 	s.emitEvent(GameEvent{
@@ -154,15 +337,40 @@ func (s *GameService) transitionToNight(roomCode string) {
 		RoomCode: roomCode,
 		Data: map[string]any{
 			"phase": "night",
-			"round": game.Round,
-			"timer": game.Room.Settings.NightTimer,
+			"round": round,
+			"timer": int(duration.Seconds()),
 		},
 	})
 
+	s.emitActionPrompts(roomCode, game)
+
 	// Start night timer
 	s.startPhaseTimer(roomCode, duration, func() {
 		s.resolveNight(roomCode)
 	})
+	s.scheduleBots(roomCode, entity.PhaseNight)
+}
+
+// emitActionPrompts tells each human player what they can do this phase
+func (s *GameService) emitActionPrompts(roomCode string, game *entity.Game) {
+	for _, playerID := range game.Room.PlayerOrder {
+		player := game.Room.GetPlayer(playerID)
+		if player == nil || player.IsBot {
+			continue
+		}
+		prompt := game.ActionPrompt(playerID)
+		s.emitEvent(GameEvent{
+			Type:           EventActionPrompt,
+			RoomCode:       roomCode,
+			TargetPlayerID: playerID,
+			Data: map[string]any{
+				"phase":    string(game.GetPhase()),
+				"action":   string(prompt.Action),
+				"targets":  prompt.Targets,
+				"deadline": prompt.Deadline.UnixMilli(),
+			},
+		})
+	}
 }
 
 // SubmitNightAction handles a player's night action
@@ -213,18 +421,117 @@ func (s *GameService) SubmitNightAction(roomCode, playerID, targetID string) err
 					"target_id":       targetID,
 					"target_nickname": targetNickname,
 					"votes":           game.GetMafiaVotes(),
+					"progress":        mafiaVoteProgress(game),
 				},
 			})
 		}
 	}
 
+	s.afterNightAction(roomCode, game)
+	return nil
+}
+
+// ConfirmKill lets the godfather lock the mafia target
+func (s *GameService) ConfirmKill(roomCode, playerID string) error {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return entity.ErrGameNotStarted
+	}
+
+	if err := game.ConfirmKill(playerID); err != nil {
+		return err
+	}
+
+	s.logger.Debug("kill confirmed", "room", roomCode, "godfather", playerID)
+
+	// Let the whole mafia know the target is locked
+	mafia := append(game.GetMafiaTeammates(playerID), playerID)
+	for _, mafiaID := range mafia {
+		s.emitEvent(GameEvent{
+			Type:           EventMafiaVote,
+			RoomCode:       roomCode,
+			TargetPlayerID: mafiaID,
+			Data: map[string]any{
+				"voter_id":  playerID,
+				"votes":     game.GetMafiaVotes(),
+				"progress":  mafiaVoteProgress(game),
+				"confirmed": true,
+			},
+		})
+	}
+
+	s.afterNightAction(roomCode, game)
+	return nil
+}
+
+// mafiaVoteProgress tells the mafia how many of them have voted and
+// whether they agree, so they can coordinate before the night ends
+func mafiaVoteProgress(game *entity.Game) map[string]any {
+	status := game.MafiaVoteStatus()
+	progress := map[string]any{
+		"voted":     status.Voted,
+		"total":     status.Alive,
+		"consensus": status.Consensus,
+	}
+	if status.Consensus {
+		progress["target_id"] = status.Target
+	}
+	return progress
+}
+
+// afterNightAction reports night progress and resolves the night once everyone has acted
+func (s *GameService) afterNightAction(roomCode string, game *entity.Game) {
+	// Broadcast how many actors are done (counts only - no roles or targets)
+	acted, total := game.NightProgress()
+	s.emitEvent(GameEvent{
+		Type:     EventNightProgress,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"acted": acted,
+			"total": total,
+		},
+	})
+
 	// Check if all actions are complete
 	if game.AllNightActionsComplete() {
-		s.cancelPhaseTimer(roomCode)
 		s.resolveNight(roomCode)
 	}
+}
 
-	return nil
+// PlayerDisconnected ends the night or voting early if the disconnected
+// player was the last one it was waiting on (only with SkipDisconnected)
+func (s *GameService) PlayerDisconnected(roomCode string) {
+	game := s.GetGame(roomCode)
+	if game == nil || !game.Room.Settings.SkipDisconnected {
+		return
+	}
+	s.resolveIfComplete(roomCode, game)
+}
+
+// PlayerLeft settles a game after a player gave up their seat: the departure
+// may decide it, or leave the phase with nobody left to wait on
+func (s *GameService) PlayerLeft(roomCode string) {
+	if s.EndIfDecided(roomCode) {
+		return
+	}
+	if game := s.GetGame(roomCode); game != nil {
+		s.resolveIfComplete(roomCode, game)
+	}
+}
+
+// resolveIfComplete ends the night or voting early once everyone it waits on
+// has acted, as the last action or vote itself would
+func (s *GameService) resolveIfComplete(roomCode string, game *entity.Game) {
+	switch game.GetPhase() {
+	case entity.PhaseNight:
+		if game.AllNightActionsComplete() {
+			s.resolveNight(roomCode)
+		}
+	case entity.PhaseDay:
+		if game.AllDayVotesComplete() {
+			s.resolveDay(roomCode)
+		}
+	}
 }
 
 // resolveNight processes night actions and moves to day (or game over)
@@ -234,6 +541,12 @@ func (s *GameService) resolveNight(roomCode string) {
 		return
 	}
 
+	// The timer and the last action can race to get here; only one resolves
+	if !game.TryTransition(entity.PhaseNight, entity.PhaseNightResult) {
+		return
+	}
+	// Only the winner stops the timer; a loser would cancel the next phase's
+	s.cancelPhaseTimer(roomCode)
 	result := game.ResolveNight()
 
 	s.logger.Info("night resolved",
@@ -243,45 +556,73 @@ func (s *GameService) resolveNight(roomCode string) {
 	)
 
 	// Send night result to all players
+	nightData := map[string]any{
+		"killed":          result.KilledID,
+		"killed_nickname": result.KilledNickname,
+		"was_saved":       result.WasSaved,
+	}
+	if result.KilledRole != "" {
+		nightData["killed_role"] = string(result.KilledRole)
+	}
+	if result.Heartbreak != nil {
+		nightData["heartbreak"] = heartbreakData(result.Heartbreak)
+	}
 	s.emitEvent(GameEvent{
 		Type:     EventNightResult,
 		RoomCode: roomCode,
-		Data: map[string]any{
-			"killed":          result.KilledID,
-			"killed_nickname": result.KilledNickname,
-			"was_saved":       result.WasSaved,
-		},
+		Data:     nightData,
 	})
 
-	// Send detective result only to detective
+	// Send detective result only to the detective who investigated
 	if result.DetectiveResult != nil {
-		for playerID, role := range game.Roles {
-			if role == entity.RoleDetective {
-				s.emitEvent(GameEvent{
-					Type:           EventNightResult,
-					RoomCode:       roomCode,
-					TargetPlayerID: playerID,
-					Data: map[string]any{
-						"investigation": map[string]any{
-							"target_id":       result.DetectiveResult.TargetID,
-							"target_nickname": result.DetectiveResult.TargetNickname,
-							"is_mafia":        result.DetectiveResult.IsMafia,
-						},
-					},
-				})
-				break
+		s.emitEvent(GameEvent{
+			Type:           EventNightResult,
+			RoomCode:       roomCode,
+			TargetPlayerID: result.DetectiveResult.DetectiveID,
+			Data: map[string]any{
+				"investigation": map[string]any{
+					"target_id":          result.DetectiveResult.TargetID,
+					"target_nickname":    result.DetectiveResult.TargetNickname,
+					"is_mafia":           result.DetectiveResult.IsMafia,
+					"previously_checked": result.DetectiveResult.PreviouslyChecked,
+				},
+			},
+		})
+	}
+
+	// Tell the mafia how their kill went, without revealing who saved the target
+	if result.AttemptedID != "" {
+		for _, playerID := range game.GetAlivePlayers() {
+			if game.GetPlayerRole(playerID).GetTeam() != entity.TeamMafia {
+				continue
 			}
+			s.emitEvent(GameEvent{
+				Type:           EventMafiaSummary,
+				RoomCode:       roomCode,
+				TargetPlayerID: playerID,
+				Data: map[string]any{
+					"attempted_target": result.AttemptedID,
+					"succeeded":        result.KilledID != "",
+					"saved":            result.WasSaved,
+				},
+			})
 		}
 	}
 
 	// Check win condition
-	if ended, winner := game.CheckWinCondition(); ended {
-		s.endGame(roomCode, winner)
+	if s.EndIfDecided(roomCode) {
 		return
 	}
 
-	// Transition to day after showing result (3 seconds)
-	s.schedulePhaseTransition(roomCode, 3*time.Second, func() {
+	if result.KilledID != "" {
+		s.playerDied(roomCode, result.KilledID, entity.PhaseNight)
+	}
+	if result.Heartbreak != nil {
+		s.playerDied(roomCode, result.Heartbreak.PlayerID, entity.PhaseNight)
+	}
+
+	// Transition to day after showing result
+	s.schedulePhaseTransition(roomCode, game.Room.Settings.ResultDuration(), func() {
 		s.transitionToDay(roomCode)
 	})
 }
@@ -293,8 +634,50 @@ func (s *GameService) transitionToDay(roomCode string) {
 		return
 	}
 
-	// Day phase is 2x night timer for discussion + voting
-	duration := time.Duration(game.Room.Settings.NightTimer*2) * time.Second
+	// Day phase covers discussion + voting
+	daySeconds := int(game.Room.Settings.DayDuration().Seconds())
+	discussionSeconds := game.Room.Settings.DiscussionSeconds
+	if discussionSeconds <= 0 {
+		s.startVoting(roomCode, daySeconds)
+		return
+	}
+
+	// Leave a minimum voting window after discussion
+	votingSeconds := daySeconds - discussionSeconds
+	if votingSeconds < entity.MinVotingSeconds {
+		votingSeconds = entity.MinVotingSeconds
+	}
+
+	game.StartDayDiscussion(time.Duration(discussionSeconds) * time.Second)
+
+	s.logger.Info("day discussion started",
+		"room", roomCode,
+		"round", game.Round,
+	)
+
+	s.emitEvent(GameEvent{
+		Type:     EventPhaseChanged,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"phase": string(entity.PhaseDayDiscussion),
+			"round": game.Round,
+			"timer": discussionSeconds,
+		},
+	})
+
+	s.startDayTimer(roomCode, time.Duration(discussionSeconds)*time.Second, func() {
+		s.startVoting(roomCode, votingSeconds)
+	})
+}
+
+// startVoting opens day voting for the given number of seconds
+func (s *GameService) startVoting(roomCode string, votingSeconds int) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return
+	}
+
+	duration := time.Duration(votingSeconds) * time.Second
 	game.StartDay(duration)
 
 	s.logger.Info("day phase started",
@@ -308,14 +691,56 @@ func (s *GameService) transitionToDay(roomCode string) {
 		Data: map[string]any{
 			"phase": "day",
 			"round": game.Round,
-			"timer": game.Room.Settings.NightTimer * 2,
+			"timer": votingSeconds,
 		},
 	})
+	s.emitActionPrompts(roomCode, game)
+	s.startSpeakingTurns(roomCode, game)
 
 	// Start day timer (no ticker - voting doesn't need countdown display)
 	s.startDayTimer(roomCode, duration, func() {
 		s.resolveDay(roomCode)
 	})
+	s.scheduleBots(roomCode, entity.PhaseDay)
+}
+
+// startRunoff reopens voting between the targets tied in the day vote
+func (s *GameService) startRunoff(roomCode string, candidates []string) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return
+	}
+
+	duration := time.Duration(entity.RunoffSeconds) * time.Second
+	game.StartRunoff(duration, candidates)
+	round, revote := game.Snapshot().Round, game.GetRevotes()
+
+	s.logger.Info("runoff vote started",
+		"room", roomCode,
+		"round", round,
+		"candidates", candidates,
+		"revote", revote,
+	)
+
+	s.emitEvent(GameEvent{
+		Type:     EventPhaseChanged,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"phase":             string(entity.PhaseDay),
+			"round":             round,
+			"timer":             entity.RunoffSeconds,
+			"runoff_candidates": candidates,
+			"revote":            revote,
+			"max_revotes":       game.Room.Settings.RevoteLimit(),
+		},
+	})
+	s.emitActionPrompts(roomCode, game)
+	s.startSpeakingTurns(roomCode, game)
+
+	s.startDayTimer(roomCode, duration, func() {
+		s.resolveDay(roomCode)
+	})
+	s.scheduleBots(roomCode, entity.PhaseDay)
 }
 
 // SubmitDayVote handles a player's vote
@@ -336,20 +761,28 @@ func (s *GameService) SubmitDayVote(roomCode, voterID, targetID string) error {
 		"target", targetID,
 	)
 
-	// Broadcast vote update with detailed vote information
+	// Broadcast vote update, redacted by the room's visibility policy; the
+	// submitted list is always public
 	votes, submitted := game.GetVoteDetails()
+	votes, counts := game.Room.Settings.Visibility().LiveVotes(votes, game.GetVoteCounts())
+	if votes == nil {
+		votes = map[string]string{}
+	}
+	voteData := map[string]any{
+		"votes":     votes,     // voter ID -> target ID
+		"submitted": submitted, // list of voter IDs who have finalized
+	}
+	if counts != nil {
+		voteData["counts"] = counts // target ID -> vote count
+	}
 	s.emitEvent(GameEvent{
 		Type:     EventVoteUpdate,
 		RoomCode: roomCode,
-		Data: map[string]any{
-			"votes":     votes,     // voter ID -> target ID
-			"submitted": submitted, // list of voter IDs who have finalized
-		},
+		Data:     voteData,
 	})
 
 	// Check if all votes are in
 	if game.AllDayVotesComplete() {
-		s.cancelPhaseTimer(roomCode)
 		s.resolveDay(roomCode)
 	}
 
@@ -363,44 +796,141 @@ func (s *GameService) resolveDay(roomCode string) {
 		return
 	}
 
+	// The timer and the last vote can race to get here; only one resolves
+	if !game.TryTransition(entity.PhaseDay, entity.PhaseDayResult) {
+		return
+	}
+	// Only the winner stops the timer; a loser would cancel the next phase's
+	s.cancelPhaseTimer(roomCode)
+	ballots, _ := game.GetVoteDetails()
 	result := game.ResolveDay()
 
 	s.logger.Info("day resolved",
 		"room", roomCode,
 		"eliminated", result.EliminatedNickname,
 		"no_majority", result.NoMajority,
+		"confirm_target", result.ConfirmTarget,
 	)
+	s.finishDay(roomCode, game, result, ballots)
+}
 
-	// Send day result
-	var eliminatedRole string
+// finishDay announces a day result, then moves on to a runoff, a lynch
+// confirmation, the night or game over
+func (s *GameService) finishDay(roomCode string, game *entity.Game, result *entity.DayResult, ballots map[string]string) {
+	// Send day result (role omitted when roles are hidden until game over)
+	dayData := map[string]any{
+		"eliminated":          result.EliminatedID,
+		"eliminated_nickname": result.EliminatedNickname,
+		"votes":               result.VoteCounts,
+		"no_majority":         result.NoMajority,
+	}
 	if result.EliminatedRole != "" {
-		eliminatedRole = string(result.EliminatedRole)
+		dayData["eliminated_role"] = string(result.EliminatedRole)
+	}
+	if len(result.Tied) > 0 {
+		dayData["tied"] = result.Tied
+		dayData["runoff"] = result.Runoff
+	}
+	if result.ConfirmTarget != "" {
+		dayData["confirm_target"] = result.ConfirmTarget
+	}
+	if c := result.Confirmation; c != nil {
+		dayData["confirmation"] = map[string]any{
+			"target":    c.TargetID,
+			"yes":       c.Yes,
+			"no":        c.No,
+			"confirmed": c.Confirmed(),
+		}
+	}
+	if result.Heartbreak != nil {
+		dayData["heartbreak"] = heartbreakData(result.Heartbreak)
+	}
+	if ballots != nil && game.Room.Settings.Visibility().RevealBallots() {
+		dayData["ballots"] = ballots // voter ID -> target ID, withheld during the vote
 	}
 
 	s.emitEvent(GameEvent{
 		Type:     EventDayResult,
 		RoomCode: roomCode,
-		Data: map[string]any{
-			"eliminated":          result.EliminatedID,
-			"eliminated_nickname": result.EliminatedNickname,
-			"eliminated_role":     eliminatedRole,
-			"votes":               result.VoteCounts,
-			"no_majority":         result.NoMajority,
-		},
+		Data:     dayData,
 	})
 
 	// Check win condition
-	if ended, winner := game.CheckWinCondition(); ended {
-		s.endGame(roomCode, winner)
+	if s.EndIfDecided(roomCode) {
 		return
 	}
 
-	// Transition to night after showing result (3 seconds)
-	s.schedulePhaseTransition(roomCode, 3*time.Second, func() {
+	if result.EliminatedID != "" {
+		s.playerDied(roomCode, result.EliminatedID, entity.PhaseDay)
+	}
+	if result.Heartbreak != nil {
+		s.playerDied(roomCode, result.Heartbreak.PlayerID, entity.PhaseDay)
+	}
+
+	if result.Runoff {
+		s.schedulePhaseTransition(roomCode, game.Room.Settings.ResultDuration(), func() {
+			s.startRunoff(roomCode, result.Tied)
+		})
+		return
+	}
+	if result.ConfirmTarget != "" {
+		s.schedulePhaseTransition(roomCode, game.Room.Settings.ResultDuration(), func() {
+			s.startLynchConfirm(roomCode, result.ConfirmTarget)
+		})
+		return
+	}
+
+	// Transition to night after showing result
+	s.schedulePhaseTransition(roomCode, game.Room.Settings.ResultDuration(), func() {
 		s.transitionToNight(roomCode)
 	})
 }
 
+// heartbreakData describes a lover's death for night and day results
+func heartbreakData(h *entity.Heartbreak) map[string]any {
+	data := map[string]any{
+		"player_id": h.PlayerID,
+		"nickname":  h.Nickname,
+	}
+	if h.Role != "" {
+		data["role"] = string(h.Role)
+	}
+	return data
+}
+
+// playerDied reports a death that happened inside a phase so voice routing
+// can be re-applied right away instead of waiting for the next phase change.
+// phase is the night or day the death belongs to, whose routing still holds
+// while its result is shown.
+func (s *GameService) playerDied(roomCode, playerID string, phase entity.GamePhase) {
+	s.emitEvent(GameEvent{
+		Type:     EventPlayerDied,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"player_id": playerID,
+			"phase":     string(phase),
+		},
+	})
+}
+
+// EndIfDecided ends the game right away if a team has won. Call it after any
+// death or departure, including ones outside phase resolution, so the game
+// never runs on into a phase that cannot change the outcome. It reports
+// whether the game is over, including when another caller ended it first.
+func (s *GameService) EndIfDecided(roomCode string) bool {
+	game := s.GetGame(roomCode)
+	if game == nil || game.GetPhase() == entity.PhaseGameOver {
+		return true
+	}
+
+	ended, winner := game.CheckWinCondition()
+	if !ended {
+		return false
+	}
+	s.endGame(roomCode, winner)
+	return true
+}
+
 // endGame finishes the game and announces winner
 func (s *GameService) endGame(roomCode string, winner entity.Team) {
 	game := s.GetGame(roomCode)
@@ -408,14 +938,47 @@ func (s *GameService) endGame(roomCode string, winner entity.Team) {
 		return
 	}
 
-	game.EndGame(winner)
+	// A departure and a phase resolution can both find the game decided
+	if !game.EndGame(winner) {
+		return
+	}
 
 	s.logger.Info("game ended",
 		"room", roomCode,
 		"winner", winner,
 	)
 
-	// Build player list with roles revealed
+	data := gameOverData(game, false)
+	data["winner"] = string(winner)
+	data["winner_label"] = game.WinnerLabel()
+	data["winners"] = game.WinnerIDs()
+	s.emitEvent(GameEvent{
+		Type:     EventGameOver,
+		RoomCode: roomCode,
+		Data:     data,
+	})
+	s.finishReplay(roomCode, winner)
+
+	s.recordHistory(game, winner)
+	s.closeGame(roomCode)
+}
+
+// gameOverData builds the game_over fields shared by a won and an aborted game
+func gameOverData(game *entity.Game, aborted bool) map[string]any {
+	data := map[string]any{
+		"players":   revealedPlayers(game),
+		"death_log": game.GetDeathLog(),
+		"aborted":   aborted,
+	}
+	// Opt-in, as it grows the payload with every seat
+	if game.Room.Settings.GameStats {
+		data["stats"] = game.Stats()
+	}
+	return data
+}
+
+// revealedPlayers lists every seat with its role for the game over screen
+func revealedPlayers(game *entity.Game) []map[string]any {
 	players := make([]map[string]any, 0)
 	for _, playerID := range game.Room.PlayerOrder {
 		if player := game.Room.GetPlayer(playerID); player != nil {
@@ -427,21 +990,50 @@ func (s *GameService) endGame(roomCode string, winner entity.Team) {
 			})
 		}
 	}
+	return players
+}
 
-	s.emitEvent(GameEvent{
-		Type:     EventGameOver,
-		RoomCode: roomCode,
-		Data: map[string]any{
-			"winner":  string(winner),
-			"players": players,
-		},
-	})
-
-	// Cleanup
+// closeGame stops a finished game's timers and forgets it
+func (s *GameService) closeGame(roomCode string) {
 	s.cancelPhaseTimer(roomCode)
 	s.mu.Lock()
 	delete(s.games, roomCode)
 	s.mu.Unlock()
+	s.startQueued()
+}
+
+// recordHistory saves the finished game for players who supplied a history key
+func (s *GameService) recordHistory(game *entity.Game, winner entity.Team) {
+	if s.store == nil {
+		return
+	}
+
+	endedAt := time.Now()
+	for _, playerID := range game.Room.PlayerOrder {
+		player := game.Room.GetPlayer(playerID)
+		historyKey := game.Room.HistoryKey(playerID)
+		if player == nil || historyKey == "" {
+			continue
+		}
+
+		role := game.Roles[playerID]
+		record := GameRecord{
+			RoomCode: game.Room.Code,
+			EndedAt:  endedAt,
+			Rounds:   game.Round,
+			Role:     string(role),
+			Winner:   string(winner),
+			Won:      role.GetTeam() == winner,
+			Survived: player.Status == entity.PlayerStatusAlive,
+		}
+		if err := s.store.SaveRecord(historyKey, record); err != nil {
+			s.logger.Warn("failed to record game history",
+				"room", game.Room.Code,
+				"player", playerID,
+				"error", err,
+			)
+		}
+	}
 }
 
 // Timer management
@@ -525,6 +1117,12 @@ func (s *GameService) cancelPhaseTimer(roomCode string) {
 		close(cancel)
 		delete(s.timerCancels, roomCode)
 	}
+
+	// Bot moves belong to the phase whose timer this was
+	if timer, ok := s.botTimers[roomCode]; ok {
+		timer.Stop()
+		delete(s.botTimers, roomCode)
+	}
 }
 
 // startDayTimer creates a simple timeout for day phase (no ticker)
@@ -546,6 +1144,17 @@ func (s *GameService) startDayTimer(roomCode string, duration time.Duration, onE
 	s.phaseTimers[roomCode] = time.AfterFunc(duration, onExpire)
 }
 
+// RemainingPhaseTime returns how long the current phase has left, so clients
+// that missed timer ticks can resync. Returns false if there is no game or the
+// phase is untimed.
+func (s *GameService) RemainingPhaseTime(roomCode string) (time.Duration, bool) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return 0, false
+	}
+	return game.PhaseRemaining(time.Now())
+}
+
 // GetGameState returns the current game state for a player
 func (s *GameService) GetGameState(roomCode, playerID string) map[string]any {
 	game := s.GetGame(roomCode)
@@ -553,19 +1162,38 @@ func (s *GameService) GetGameState(roomCode, playerID string) map[string]any {
 		return nil
 	}
 
+	remaining, timed := s.RemainingPhaseTime(roomCode)
+	snapshot := game.Snapshot()
+
 	state := map[string]any{
-		"phase": string(game.Phase),
-		"round": game.Round,
+		"phase": string(snapshot.Phase),
+		"round": snapshot.Round,
+		"timer": int(remaining.Seconds()),
+	}
+	if timed {
+		state["timer_remaining_ms"] = remaining.Milliseconds()
 	}
 
-	// Add role info
-	if role, ok := game.Roles[playerID]; ok {
+	// Add role info (players without a role joined as spectators)
+	role, ok := snapshot.Roles[playerID]
+	state["is_spectator"] = !ok
+	if ok {
 		state["my_role"] = string(role)
 		state["my_team"] = string(role.GetTeam())
 
+		isAlive := false
+		if player := game.Room.GetPlayer(playerID); player != nil {
+			isAlive = player.Status == entity.PlayerStatusAlive
+		}
+		state["is_alive"] = isAlive
+		// Dead players may use ghost chat
+		state["can_ghost_chat"] = !isAlive
+
 		// Mafia sees teammates
 		if role.GetTeam() == entity.TeamMafia {
-			state["teammates"] = game.GetMafiaTeammates(playerID)
+			if teammates, ok := game.GetRoleRevealData(playerID)["teammates"]; ok {
+				state["teammates"] = teammates
+			}
 		}
 	}
 
@@ -573,9 +1201,20 @@ func (s *GameService) GetGameState(roomCode, playerID string) map[string]any {
 	state["alive_players"] = game.GetAlivePlayers()
 
 	// Phase-specific data
-	switch game.Phase {
+	switch snapshot.Phase {
 	case entity.PhaseDay:
-		state["votes"] = game.GetVoteCounts()
+		if _, counts := game.Room.Settings.Visibility().LiveVotes(nil, game.GetVoteCounts()); counts != nil {
+			state["votes"] = counts
+		}
+		if snapshot.RunoffCandidates != nil {
+			state["runoff_candidates"] = snapshot.RunoffCandidates
+		}
+		if speaker := game.CurrentSpeaker(); speaker.PlayerID != "" {
+			state["speaking_turn"] = speaker.PlayerID
+		}
+	case entity.PhaseLynchConfirm:
+		state["confirm_target"] = snapshot.PendingLynch
+		state["confirm_submitted"] = game.LynchConfirmSubmitted()
 	}
 
 	return state
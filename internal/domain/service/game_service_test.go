@@ -0,0 +1,562 @@
+package service
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestGameOverRevealsRolesWhenHiddenOnDeath(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+		s.RevealRolesOnDeath = false
+	})
+
+	games.endGame(game.Room.Code, entity.TeamTown)
+	over := recorder.ofType(EventGameOver)
+	if len(over) != 1 {
+		t.Fatalf("game_over emitted %d times, want 1", len(over))
+	}
+	players := over[0].Data.(map[string]any)["players"].([]map[string]any)
+	if len(players) != 7 {
+		t.Fatalf("game_over lists %d players, want 7", len(players))
+	}
+	for _, player := range players {
+		if id := player["id"].(string); player["role"] != string(game.Roles[id]) {
+			t.Errorf("%s: role %v, want %s", id, player["role"], game.Roles[id])
+		}
+	}
+}
+
+// phases lists the phases announced so far, oldest first
+func (r *eventRecorder) phases() []any {
+	var phases []any
+	for _, event := range r.ofType(EventPhaseChanged) {
+		phases = append(phases, event.Data.(map[string]any)["phase"])
+	}
+	return phases
+}
+
+func TestVotingOpensAfterDiscussion(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+		s.DiscussionSeconds = 1
+	})
+	roomCode := game.Room.Code
+
+	games.transitionToDay(roomCode)
+	if phases := recorder.phases(); len(phases) == 0 || phases[len(phases)-1] != string(entity.PhaseDayDiscussion) {
+		t.Fatalf("day opened with phases %v, want %s last", phases, entity.PhaseDayDiscussion)
+	}
+	if err := games.SubmitDayVote(roomCode, "p0", "p1"); err != entity.ErrVotingNotOpen {
+		t.Fatalf("vote during discussion: %v, want %v", err, entity.ErrVotingNotOpen)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for phases := recorder.phases(); phases[len(phases)-1] != "day"; phases = recorder.phases() {
+		if time.Now().After(deadline) {
+			t.Fatalf("still in %v after discussion ended", phases[len(phases)-1])
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if err := games.SubmitDayVote(roomCode, "p0", "p1"); err != nil {
+		t.Fatalf("vote after discussion: %v", err)
+	}
+
+	if phases := recorder.phases(); len(phases) < 2 || !slices.Equal(phases[len(phases)-2:], []any{string(entity.PhaseDayDiscussion), "day"}) {
+		t.Errorf("phase changes = %v, want discussion then day", phases)
+	}
+}
+
+func TestGameStateIncludesDayTimer(t *testing.T) {
+	games, game, _ := startTestGame(t, 7, nil)
+	roomCode := game.Room.Code
+
+	if _, ok := games.GetGameState(roomCode, "p0")["timer_remaining_ms"]; ok {
+		t.Error("role reveal reported a timer")
+	}
+
+	games.transitionToDay(roomCode)
+	remaining, ok := games.GetGameState(roomCode, "p0")["timer_remaining_ms"].(int64)
+	if day := int64(game.Room.Settings.NightTimer*2) * 1000; !ok || remaining <= 0 || remaining > day {
+		t.Errorf("day timer_remaining_ms = %v, want within (0, %d]", remaining, day)
+	}
+}
+
+func TestNightProgressCountsActions(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, nil)
+	roomCode := game.Room.Code
+	games.transitionToNight(roomCode)
+
+	byRole := make(map[entity.Role][]string)
+	for _, id := range game.Room.PlayerOrder {
+		role := game.GetPlayerRole(id)
+		byRole[role] = append(byRole[role], id)
+	}
+	villager, doctor, detective := byRole[entity.RoleVillager][0], byRole[entity.RoleDoctor][0], byRole[entity.RoleDetective][0]
+	actions := [][2]string{{doctor, doctor}, {detective, byRole[entity.RoleMafia][0]}}
+	for _, id := range byRole[entity.RoleMafia] {
+		actions = append(actions, [2]string{id, villager})
+	}
+
+	for i, action := range actions {
+		if len(recorder.ofType(EventNightResult)) != 0 {
+			t.Fatalf("night resolved after %d of %d actions", i, len(actions))
+		}
+		if err := games.SubmitNightAction(roomCode, action[0], action[1]); err != nil {
+			t.Fatalf("%s targets %s: %v", action[0], action[1], err)
+		}
+
+		progress := recorder.ofType(EventNightProgress)
+		if len(progress) != i+1 {
+			t.Fatalf("night_progress emitted %d times after %d actions", len(progress), i+1)
+		}
+		want := map[string]any{"acted": i + 1, "total": len(actions)}
+		if data := progress[i].Data.(map[string]any); !maps.Equal(data, want) {
+			t.Errorf("night_progress = %v, want %v", data, want)
+		}
+	}
+	if len(recorder.ofType(EventNightResult)) == 0 {
+		t.Error("night did not resolve once every actor acted")
+	}
+}
+
+func TestMidPhaseDeathsReapplyVoiceRouting(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, nil)
+	roomCode := game.Room.Code
+	games.startVoting(roomCode, 60)
+	games.cancelPhaseTimer(roomCode)
+
+	victim := ""
+	for _, id := range game.Room.PlayerOrder {
+		if game.GetPlayerRole(id) == entity.RoleVillager {
+			victim = id
+			break
+		}
+	}
+	for _, id := range game.GetAlivePlayers() {
+		if id == victim {
+			continue
+		}
+		if err := games.SubmitDayVote(roomCode, id, victim); err != nil {
+			t.Fatalf("%s votes %s: %v", id, victim, err)
+		}
+	}
+	games.resolveDay(roomCode)
+	games.cancelPhaseTimer(roomCode)
+
+	// The lynch is reported under the day's routing, not the result phase
+	died := recorder.ofType(EventPlayerDied)
+	if len(died) != 1 {
+		t.Fatalf("player_died emitted %d times, want 1", len(died))
+	}
+	data := died[0].Data.(map[string]any)
+	if data["player_id"] != victim || data["phase"] != string(entity.PhaseDay) {
+		t.Errorf("player_died data = %v, want %s in phase %s", data, victim, entity.PhaseDay)
+	}
+}
+
+func TestPhaseTimersUseConfiguredDurations(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+		s.RoleRevealSeconds, s.NightTimer, s.DaySeconds, s.ResultSeconds = 1, 15, 40, 1
+	})
+	roomCode := game.Room.Code
+
+	// lastTimer returns the timer announced with the latest phase change
+	lastTimer := func() any {
+		changes := recorder.ofType(EventPhaseChanged)
+		return changes[len(changes)-1].Data.(map[string]any)["timer"]
+	}
+
+	// Open each phase the way its timer would rather than waiting for it
+	games.cancelPhaseTimer(roomCode)
+	games.transitionToNight(roomCode)
+	if timer := lastTimer(); timer != 15 {
+		t.Errorf("night timer = %v, want 15", timer)
+	}
+	if remaining, _ := games.RemainingPhaseTime(roomCode); remaining <= 14*time.Second || remaining > 15*time.Second {
+		t.Errorf("night remaining = %v, want just under 15s", remaining)
+	}
+
+	games.cancelPhaseTimer(roomCode)
+	games.transitionToDay(roomCode)
+	if timer := lastTimer(); timer != 40 {
+		t.Errorf("day timer = %v, want 40", timer)
+	}
+	if remaining, _ := games.RemainingPhaseTime(roomCode); remaining <= 39*time.Second || remaining > 40*time.Second {
+		t.Errorf("day remaining = %v, want just under 40s", remaining)
+	}
+}
+
+func TestHiddenVotingRevealsTallyAtResolution(t *testing.T) {
+	for _, hidden := range []bool{false, true} {
+		games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+			s.HiddenVoting = hidden
+		})
+		roomCode := game.Room.Code
+		games.startVoting(roomCode, 60)
+
+		// Everyone but the target votes them out; the target abstains last
+		target := "p6"
+		for _, id := range game.GetAlivePlayers() {
+			if id == target {
+				continue
+			}
+			if err := games.SubmitDayVote(roomCode, id, target); err != nil {
+				t.Fatalf("hidden %v: %s votes: %v", hidden, id, err)
+			}
+		}
+		updates := recorder.ofType(EventVoteUpdate)
+		if len(updates) == 0 {
+			t.Fatalf("hidden %v: no vote updates", hidden)
+		}
+		live := updates[len(updates)-1].Data.(map[string]any)
+		votes := live["votes"].(map[string]string)
+		if hidden && len(votes) != 0 {
+			t.Errorf("hidden %v: live update leaks votes %v", hidden, votes)
+		}
+		if !hidden && votes["p0"] != target {
+			t.Errorf("hidden %v: live update votes %v", hidden, votes)
+		}
+		if submitted := live["submitted"].([]string); len(submitted) != 6 {
+			t.Errorf("hidden %v: submitted %v, want 6 voters", hidden, submitted)
+		}
+
+		// A resync mid-vote must not leak the tally either
+		_, hasTally := games.GetGameState(roomCode, "p0")["votes"]
+		if hasTally == hidden {
+			t.Errorf("hidden %v: game state includes the tally = %v", hidden, hasTally)
+		}
+
+		if err := games.SubmitDayVote(roomCode, target, ""); err != nil {
+			t.Fatalf("hidden %v: target abstains: %v", hidden, err)
+		}
+		results := recorder.ofType(EventDayResult)
+		if len(results) != 1 {
+			t.Fatalf("hidden %v: %d day results, want 1", hidden, len(results))
+		}
+		result := results[0].Data.(map[string]any)
+		if counts := result["votes"].(map[string]int); counts[target] != 6 {
+			t.Errorf("hidden %v: day result tally %v, want 6 for %s", hidden, counts, target)
+		}
+		ballots, hasBallots := result["ballots"].(map[string]string)
+		if hasBallots != hidden || hidden && ballots["p0"] != target {
+			t.Errorf("hidden %v: day result ballots %v", hidden, result["ballots"])
+		}
+	}
+}
+
+func TestAnonymousVotingWithholdsAttributions(t *testing.T) {
+	for _, hidden := range []bool{false, true} {
+		games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+			s.AnonymousVoting = true
+			s.HiddenVoting = hidden
+		})
+		roomCode := game.Room.Code
+		games.startVoting(roomCode, 60)
+
+		target := "p6"
+		for _, id := range game.GetAlivePlayers() {
+			vote := target
+			if id == target {
+				vote = ""
+			}
+			if err := games.SubmitDayVote(roomCode, id, vote); err != nil {
+				t.Fatalf("hidden %v: %s votes: %v", hidden, id, err)
+			}
+		}
+
+		for _, event := range recorder.ofType(EventVoteUpdate) {
+			live := event.Data.(map[string]any)
+			if votes := live["votes"].(map[string]string); len(votes) != 0 {
+				t.Errorf("hidden %v: live update attributes votes %v", hidden, votes)
+			}
+			if _, hasCounts := live["counts"]; hasCounts == hidden {
+				t.Errorf("hidden %v: live update counts %v", hidden, live["counts"])
+			}
+		}
+
+		results := recorder.ofType(EventDayResult)
+		if len(results) != 1 {
+			t.Fatalf("hidden %v: %d day results, want 1", hidden, len(results))
+		}
+		result := results[0].Data.(map[string]any)
+		if counts := result["votes"].(map[string]int); counts[target] != 6 {
+			t.Errorf("hidden %v: day result tally %v, want 6 for %s", hidden, counts, target)
+		}
+		if ballots, ok := result["ballots"]; ok {
+			t.Errorf("hidden %v: day result attributes ballots %v", hidden, ballots)
+		}
+	}
+}
+
+func TestVisibilityPolicyAppliesToEveryResult(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		settings func(*entity.GameSettings)
+	}{
+		{"open", func(s *entity.GameSettings) { s.RevealRolesOnDeath = true }},
+		{"roles hidden", func(s *entity.GameSettings) { s.RevealRolesOnDeath = false }},
+		{"anonymous", func(s *entity.GameSettings) { s.AnonymousVoting = true }},
+		{"hidden tally", func(s *entity.GameSettings) { s.HiddenVoting = true }},
+	} {
+		games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+			s.AllowFirstNightKill = true
+			tt.settings(s)
+		})
+		roomCode := game.Room.Code
+		policy := game.Room.Settings.Visibility()
+		byRole := make(map[entity.Role][]string)
+		for _, id := range game.Room.PlayerOrder {
+			role := game.GetPlayerRole(id)
+			byRole[role] = append(byRole[role], id)
+		}
+		mafia, victim := byRole[entity.RoleMafia], byRole[entity.RoleVillager][0]
+
+		// Night: the mafia kill a villager nobody protects
+		games.transitionToNight(roomCode)
+		for _, id := range mafia {
+			if err := games.SubmitNightAction(roomCode, id, victim); err != nil {
+				t.Fatalf("%s: %s targets %s: %v", tt.name, id, victim, err)
+			}
+		}
+		games.resolveNight(roomCode)
+		night := recorder.ofType(EventNightResult)[0].Data.(map[string]any)
+		if night["killed"] != victim {
+			t.Fatalf("%s: night killed %v, want %s", tt.name, night["killed"], victim)
+		}
+		if role, _ := night["killed_role"].(string); entity.Role(role) != policy.DeathRole(entity.RoleVillager) {
+			t.Errorf("%s: night result role %q", tt.name, role)
+		}
+
+		// Day: everyone else lynches a mafioso, who abstains last
+		games.cancelPhaseTimer(roomCode)
+		games.startVoting(roomCode, 60)
+		for _, id := range game.GetAlivePlayers() {
+			vote := mafia[0]
+			if id == mafia[0] {
+				vote = ""
+			}
+			if err := games.SubmitDayVote(roomCode, id, vote); err != nil {
+				t.Fatalf("%s: %s votes: %v", tt.name, id, err)
+			}
+		}
+		updates := recorder.ofType(EventVoteUpdate)
+		live := updates[len(updates)-1].Data.(map[string]any)
+		wantVotes, wantCounts := policy.LiveVotes(map[string]string{}, map[string]int{})
+		if votes := live["votes"].(map[string]string); (len(votes) != 0) != (wantVotes != nil) {
+			t.Errorf("%s: live update votes %v", tt.name, votes)
+		}
+		if _, hasCounts := live["counts"]; hasCounts != (wantCounts != nil) {
+			t.Errorf("%s: live update counts %v", tt.name, live["counts"])
+		}
+
+		day := recorder.ofType(EventDayResult)[0].Data.(map[string]any)
+		if day["eliminated"] != mafia[0] {
+			t.Fatalf("%s: day eliminated %v, want %s", tt.name, day["eliminated"], mafia[0])
+		}
+		if role, _ := day["eliminated_role"].(string); entity.Role(role) != policy.DeathRole(entity.RoleMafia) {
+			t.Errorf("%s: day result role %q", tt.name, role)
+		}
+		if _, hasBallots := day["ballots"]; hasBallots != policy.RevealBallots() {
+			t.Errorf("%s: day result ballots %v", tt.name, day["ballots"])
+		}
+
+		// Game over: every role is revealed whatever the policy withheld
+		games.endGame(roomCode, entity.TeamTown)
+		players := recorder.ofType(EventGameOver)[0].Data.(map[string]any)["players"].([]map[string]any)
+		for _, player := range players {
+			if id := player["id"].(string); player["role"] != string(game.Roles[id]) {
+				t.Errorf("%s: game over role for %s = %v, want %s", tt.name, id, player["role"], game.Roles[id])
+			}
+		}
+	}
+}
+
+// publicResults counts the results of one type sent to the whole room
+func (r *eventRecorder) publicResults(eventType GameEventType) int {
+	n := 0
+	for _, event := range r.ofType(eventType) {
+		if !event.IsPrivate() {
+			n++
+		}
+	}
+	return n
+}
+
+func TestPhaseResolvesOnceWhenDeadlineMeetsLastAction(t *testing.T) {
+	for round := range 20 {
+		games, game, recorder := startTestGame(t, 7, func(s *entity.GameSettings) {
+			s.AllowFirstNightKill = true
+		})
+		roomCode := game.Room.Code
+
+		// The last night actions land just as the timer fires, several times over
+		games.transitionToNight(roomCode)
+		var wg sync.WaitGroup
+		for _, id := range game.Room.PlayerOrder {
+			if role := game.GetPlayerRole(id); role.CanActAtNight() {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					target := "p0"
+					if id == target || role.GetTeam() == entity.TeamMafia {
+						target = game.Room.PlayerOrder[len(game.Room.PlayerOrder)-1]
+					}
+					games.SubmitNightAction(roomCode, id, target)
+				}()
+			}
+		}
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				games.resolveNight(roomCode)
+			}()
+		}
+		wg.Wait()
+		if n := recorder.publicResults(EventNightResult); n != 1 {
+			t.Fatalf("round %d: night resolved %d times, want 1", round, n)
+		}
+		if game.GetPhase() == entity.PhaseGameOver {
+			continue
+		}
+
+		// Likewise for the final day votes and the day timer
+		games.cancelPhaseTimer(roomCode)
+		games.transitionToDay(roomCode)
+		for _, id := range game.GetAlivePlayers() {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				games.SubmitDayVote(roomCode, id, "")
+			}()
+		}
+		for range 3 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				games.resolveDay(roomCode)
+			}()
+		}
+		wg.Wait()
+		if n := recorder.publicResults(EventDayResult); n != 1 {
+			t.Fatalf("round %d: day resolved %d times, want 1", round, n)
+		}
+	}
+}
+
+func TestSpecialRoleDyingMidNightEndsGameOnce(t *testing.T) {
+	games, game, recorder := startTestGame(t, 3, func(s *entity.GameSettings) {
+		s.Villagers, s.Mafia, s.Doctor, s.Detective = 1, 1, 1, 0
+	})
+	roomCode := game.Room.Code
+	games.cancelPhaseTimer(roomCode)
+	games.transitionToNight(roomCode)
+
+	// The doctor drops out while the night resolves, leaving mafia at parity,
+	// and a second caller that already found the game decided ends it too
+	var doctor string
+	for _, id := range game.Room.PlayerOrder {
+		if game.Roles[id] == entity.RoleDoctor {
+			doctor = id
+		}
+	}
+	games.SetEventHandler(func(event GameEvent) {
+		recorder.record(event)
+		switch event.Type {
+		case EventNightResult:
+			game.Room.GetPlayer(doctor).Status = entity.PlayerStatusDead
+			games.EndIfDecided(roomCode)
+		case EventGameOver:
+			games.endGame(roomCode, entity.TeamMafia)
+		}
+	})
+	games.resolveNight(roomCode)
+
+	if n := len(recorder.ofType(EventGameOver)); n != 1 {
+		t.Fatalf("game_over emitted %d times, want 1", n)
+	}
+	if game.Winner != entity.TeamMafia {
+		t.Errorf("winner %q, want %q", game.Winner, entity.TeamMafia)
+	}
+	if events := recorder.all(); events[len(events)-1].Type != EventGameOver {
+		t.Errorf("night went on to emit %s after game over", events[len(events)-1].Type)
+	}
+	if games.GetGame(roomCode) != nil {
+		t.Error("finished game still running")
+	}
+	games.timerMu.Lock()
+	_, scheduled := games.phaseTimers[roomCode]
+	games.timerMu.Unlock()
+	if scheduled {
+		t.Error("the night scheduled another phase after game over")
+	}
+}
+
+func TestLosingResolveLeavesNextPhaseTimer(t *testing.T) {
+	games, game, _ := startTestGame(t, 7, nil)
+	roomCode := game.Room.Code
+
+	// The timer wins each race and schedules what follows; the last action
+	// arriving a moment later must not cancel that
+	games.cancelPhaseTimer(roomCode)
+	games.transitionToNight(roomCode)
+	games.resolveNight(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Fatal("nothing scheduled after the night resolved")
+	}
+	games.resolveNight(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Error("the losing night resolve cancelled the day transition")
+	}
+
+	games.cancelPhaseTimer(roomCode)
+	games.startVoting(roomCode, 60)
+	games.resolveDay(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Fatal("nothing scheduled after the day resolved")
+	}
+	games.resolveDay(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Error("the losing day resolve cancelled the night transition")
+	}
+}
+
+func TestForceStartReadiesOnlyWhenTheGameStarts(t *testing.T) {
+	rooms, games, recorder := newTestServices()
+	room := seatPlayers(t, rooms, 7)
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+	if err := rooms.SetReady(room.Code, "p3", false); err != nil {
+		t.Fatalf("unready: %v", err)
+	}
+
+	// A start that fails leaves p3 unready and unannounced
+	room.Settings.Mafia = 5
+	if err := games.ForceStartGame(room.Code, "p0"); err == nil {
+		t.Fatal("force start with unbalanced teams succeeded")
+	}
+	if room.GetPlayer("p3").IsReady {
+		t.Error("p3 left ready after the start failed")
+	}
+	if events := recorder.all(); len(events) != 0 {
+		t.Errorf("failed force start emitted %v", events)
+	}
+
+	room.Settings.Mafia = 2
+	if err := games.ForceStartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("force start: %v", err)
+	}
+	if games.GetGame(room.Code) == nil {
+		t.Fatal("no game after force start")
+	}
+	readied := recorder.ofType(EventForceReadied)
+	if len(readied) != 1 || !slices.Equal(readied[0].Data.(map[string]any)["player_ids"].([]string), []string{"p3"}) {
+		t.Errorf("force_readied = %v, want p3 once", readied)
+	}
+	if events := recorder.all(); events[0].Type != EventGameStarted {
+		t.Errorf("first event %s, want the game to start before readied players are announced", events[0].Type)
+	}
+}
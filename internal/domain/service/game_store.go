@@ -0,0 +1,28 @@
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNoHistory is returned when a player has no recorded games
+var ErrNoHistory = errors.New("no game history")
+
+// GameRecord is a finished game from one player's point of view
+type GameRecord struct {
+	RoomCode string    `json:"room_code"`
+	EndedAt  time.Time `json:"ended_at"`
+	Rounds   int       `json:"rounds"`
+	Role     string    `json:"role"`
+	Winner   string    `json:"winner"`
+	Won      bool      `json:"won"`
+	Survived bool      `json:"survived"`
+}
+
+// GameStore persists finished games for players who opted in with a history key
+type GameStore interface {
+	// SaveRecord stores a finished game for a player
+	SaveRecord(historyKey string, record GameRecord) error
+	// GetPlayerHistory returns a player's games, newest first
+	GetPlayerHistory(historyKey string) ([]GameRecord, error)
+}
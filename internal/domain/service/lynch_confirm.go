@@ -0,0 +1,87 @@
+package service
+
+import (
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// startLynchConfirm opens the yes/no vote on a lynch that would end the game
+func (s *GameService) startLynchConfirm(roomCode, targetID string) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return
+	}
+
+	duration := time.Duration(entity.LynchConfirmSeconds) * time.Second
+	game.StartLynchConfirm(duration)
+	round := game.Snapshot().Round
+
+	s.logger.Info("lynch confirmation started", "room", roomCode, "round", round, "target", targetID)
+
+	s.emitEvent(GameEvent{
+		Type:     EventPhaseChanged,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"phase":  string(entity.PhaseLynchConfirm),
+			"round":  round,
+			"timer":  entity.LynchConfirmSeconds,
+			"target": targetID,
+		},
+	})
+	s.emitActionPrompts(roomCode, game)
+
+	s.startDayTimer(roomCode, duration, func() {
+		s.resolveLynchConfirm(roomCode)
+	})
+	s.scheduleBots(roomCode, entity.PhaseLynchConfirm)
+}
+
+// SubmitLynchConfirm records a player's yes/no on the pending lynch. Only who
+// has answered is broadcast; the tally comes with the result.
+func (s *GameService) SubmitLynchConfirm(roomCode, voterID string, confirm bool) error {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return entity.ErrGameNotStarted
+	}
+
+	if err := game.SubmitLynchConfirm(voterID, confirm); err != nil {
+		return err
+	}
+
+	s.emitEvent(GameEvent{
+		Type:     EventLynchConfirm,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"submitted": game.LynchConfirmSubmitted(),
+		},
+	})
+
+	if game.AllLynchConfirmVotesIn() {
+		s.resolveLynchConfirm(roomCode)
+	}
+	return nil
+}
+
+// resolveLynchConfirm settles the confirmation vote and finishes the day
+func (s *GameService) resolveLynchConfirm(roomCode string) {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return
+	}
+
+	// The timer and the last answer can race to get here; only one resolves
+	if !game.TryTransition(entity.PhaseLynchConfirm, entity.PhaseDayResult) {
+		return
+	}
+	// Only the winner stops the timer; a loser would cancel the next phase's
+	s.cancelPhaseTimer(roomCode)
+	result := game.ResolveLynchConfirm()
+
+	s.logger.Info("lynch confirmation resolved",
+		"room", roomCode,
+		"target", result.Confirmation.TargetID,
+		"confirmed", result.Confirmation.Confirmed(),
+	)
+	s.finishDay(roomCode, game, result, nil)
+}
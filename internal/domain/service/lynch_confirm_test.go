@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// lynchConfirmGame starts a four-player game with one mafia and confirmation
+// on, then has the town vote the mafia out so the lynch would end the game
+func lynchConfirmGame(t *testing.T) (*GameService, *entity.Game, *eventRecorder, string) {
+	t.Helper()
+	games, game, recorder := startTestGame(t, 4, func(s *entity.GameSettings) {
+		s.Villagers, s.Mafia, s.Godfather, s.Doctor, s.Detective = 2, 1, 0, 1, 0
+		s.ConfirmGameEndingLynch = true
+	})
+	roomCode := game.Room.Code
+
+	var mafia, other string
+	for _, id := range game.Room.PlayerOrder {
+		if game.GetPlayerRole(id) == entity.RoleMafia {
+			mafia = id
+		} else if other == "" {
+			other = id
+		}
+	}
+
+	games.startVoting(roomCode, 60)
+	for _, id := range game.Room.PlayerOrder {
+		target := mafia
+		if id == mafia {
+			target = other
+		}
+		if err := games.SubmitDayVote(roomCode, id, target); err != nil {
+			t.Fatalf("vote %s: %v", id, err)
+		}
+	}
+
+	results := recorder.ofType(EventDayResult)
+	if len(results) != 1 {
+		t.Fatalf("got %d day results, want 1", len(results))
+	}
+	data := results[0].Data.(map[string]any)
+	if data["confirm_target"] != mafia || data["eliminated"] != "" {
+		t.Fatalf("day result = %v, want confirmation on %s and nobody out", data, mafia)
+	}
+	if p := game.Room.GetPlayer(mafia); p.Status != entity.PlayerStatusAlive {
+		t.Fatal("target eliminated before the confirmation vote")
+	}
+	if len(recorder.ofType(EventGameOver)) != 0 {
+		t.Fatal("game ended before the confirmation vote")
+	}
+
+	games.startLynchConfirm(roomCode, mafia)
+	if game.GetPhase() != entity.PhaseLynchConfirm {
+		t.Fatalf("phase = %s, want %s", game.GetPhase(), entity.PhaseLynchConfirm)
+	}
+	if target := games.GetGameState(roomCode, other)["confirm_target"]; target != mafia {
+		t.Fatalf("resync confirm target = %v, want %s", target, mafia)
+	}
+	return games, game, recorder, mafia
+}
+
+func TestGameEndingLynchConfirmed(t *testing.T) {
+	games, game, recorder, mafia := lynchConfirmGame(t)
+	roomCode := game.Room.Code
+
+	for _, id := range game.Room.PlayerOrder {
+		if err := games.SubmitLynchConfirm(roomCode, id, id != mafia); err != nil {
+			t.Fatalf("confirm %s: %v", id, err)
+		}
+	}
+
+	if p := game.Room.GetPlayer(mafia); p.Status == entity.PlayerStatusAlive {
+		t.Error("confirmed target is still alive")
+	}
+	over := recorder.ofType(EventGameOver)
+	if len(over) != 1 {
+		t.Fatalf("got %d game_over events, want 1", len(over))
+	}
+	if winner := over[0].Data.(map[string]any)["winner"]; winner != string(entity.TeamTown) {
+		t.Errorf("winner = %v, want town", winner)
+	}
+}
+
+func TestGameEndingLynchRejected(t *testing.T) {
+	games, game, recorder, mafia := lynchConfirmGame(t)
+	roomCode := game.Room.Code
+
+	for i, id := range game.Room.PlayerOrder {
+		// Only one town player still wants the lynch: 1 yes, 3 no
+		if err := games.SubmitLynchConfirm(roomCode, id, i == 0 && id != mafia); err != nil {
+			t.Fatalf("confirm %s: %v", id, err)
+		}
+	}
+
+	if p := game.Room.GetPlayer(mafia); p.Status != entity.PlayerStatusAlive {
+		t.Error("rejected target was eliminated")
+	}
+	if len(recorder.ofType(EventGameOver)) != 0 {
+		t.Error("game ended after the lynch was rejected")
+	}
+	results := recorder.ofType(EventDayResult)
+	if len(results) != 2 {
+		t.Fatalf("got %d day results, want 2", len(results))
+	}
+	data := results[1].Data.(map[string]any)
+	confirmation, _ := data["confirmation"].(map[string]any)
+	if confirmation == nil || confirmation["confirmed"] != false || data["no_majority"] != true {
+		t.Errorf("day result = %v, want a rejected confirmation with no majority", data)
+	}
+	if game.GetPhase() != entity.PhaseDayResult {
+		t.Errorf("phase = %s, want %s", game.GetPhase(), entity.PhaseDayResult)
+	}
+}
+
+func TestLosingLynchConfirmResolveLeavesNextTimer(t *testing.T) {
+	games, game, _, _ := lynchConfirmGame(t)
+	roomCode := game.Room.Code
+
+	// The timer wins with nobody answering; the last answer then loses the race
+	games.resolveLynchConfirm(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Fatal("nothing scheduled after the confirmation resolved")
+	}
+	games.resolveLynchConfirm(roomCode)
+	if !games.phaseTimerArmed(roomCode) {
+		t.Error("the losing resolve cancelled the next phase's timer")
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReconnectTokenTTL is how long a reconnect token stays valid after it is issued
+const ReconnectTokenTTL = 12 * time.Hour
+
+// Reconnect errors
+var (
+	ErrReconnectUnauthorized = errors.New("reconnect token invalid or expired")
+	ErrNoReconnectSession    = errors.New("no session to reconnect to")
+)
+
+// signReconnectToken builds "playerID.roomCode.expiry.signature", where the
+// signature is an HMAC-SHA256 over the first three fields. Player IDs and
+// room codes never contain dots.
+func signReconnectToken(key []byte, playerID, roomCode string, expires time.Time) string {
+	claims := playerID + "." + roomCode + "." + strconv.FormatInt(expires.Unix(), 10)
+	return claims + "." + reconnectSignature(key, claims)
+}
+
+// parseReconnectToken verifies a token and returns the player and room it was issued for
+func parseReconnectToken(key []byte, token string, now time.Time) (playerID, roomCode string, err error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return "", "", ErrReconnectUnauthorized
+	}
+
+	claims := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(parts[3]), []byte(reconnectSignature(key, claims))) {
+		return "", "", ErrReconnectUnauthorized
+	}
+
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || now.After(time.Unix(expiry, 0)) {
+		return "", "", ErrReconnectUnauthorized
+	}
+
+	return parts[0], parts[1], nil
+}
+
+func reconnectSignature(key []byte, claims string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(claims))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
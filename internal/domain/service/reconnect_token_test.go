@@ -0,0 +1,66 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReconnectTokens(t *testing.T) {
+	key := []byte("test-signing-key")
+	now := time.Now()
+	token := signReconnectToken(key, "p1", "ROOM", now.Add(time.Hour))
+
+	playerID, roomCode, err := parseReconnectToken(key, token, now)
+	if err != nil || playerID != "p1" || roomCode != "ROOM" {
+		t.Fatalf("valid token = %q, %q, %v", playerID, roomCode, err)
+	}
+
+	parts := strings.Split(token, ".")
+	for name, bad := range map[string]string{
+		"other player":  strings.Replace(token, "p1.", "p2.", 1),
+		"other room":    strings.Replace(token, ".ROOM.", ".EVIL.", 1),
+		"later expiry":  strings.Join([]string{parts[0], parts[1], "9999999999", parts[3]}, "."),
+		"bad signature": token[:len(token)-2] + "xx",
+		"no signature":  strings.Join(parts[:3], "."),
+		"extra field":   token + ".x",
+		"other key":     signReconnectToken([]byte("other-key"), "p1", "ROOM", now.Add(time.Hour)),
+		"expired":       signReconnectToken(key, "p1", "ROOM", now.Add(-time.Second)),
+		"empty":         "",
+	} {
+		if _, _, err := parseReconnectToken(key, bad, now); err != ErrReconnectUnauthorized {
+			t.Errorf("%s: err = %v, want ErrReconnectUnauthorized", name, err)
+		}
+	}
+}
+
+func TestCanReconnectChecksToken(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	room := seatPlayers(t, rooms, 5)
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+	if !rooms.MarkPlayerDisconnected(room.Code, "p1", false) {
+		t.Fatal("p1 was not kept for reconnection")
+	}
+	t.Cleanup(func() { rooms.ReconnectPlayer("p1") })
+
+	dp, err := rooms.CanReconnect(rooms.IssueReconnectToken("p1", room.Code))
+	if err != nil || dp.PlayerID != "p1" || dp.RoomCode != room.Code {
+		t.Fatalf("valid token: %+v, %v", dp, err)
+	}
+	if _, err := rooms.CanReconnect(rooms.IssueReconnectToken("p1", "OTHER")); err != ErrReconnectUnauthorized {
+		t.Errorf("token for another room: err = %v, want ErrReconnectUnauthorized", err)
+	}
+	if _, err := rooms.CanReconnect(rooms.IssueReconnectToken("p2", room.Code)); err != ErrNoReconnectSession {
+		t.Errorf("token for a connected player: err = %v, want ErrNoReconnectSession", err)
+	}
+
+	// A token signed with another server's key is rejected
+	other, _, _ := newTestServices()
+	other.SetReconnectKey([]byte("another key"))
+	if _, err := rooms.CanReconnect(other.IssueReconnectToken("p1", room.Code)); err != ErrReconnectUnauthorized {
+		t.Errorf("foreign token: err = %v, want ErrReconnectUnauthorized", err)
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// maxStoredReplays bounds how many finished games keep a replay in memory
+const maxStoredReplays = 100
+
+// Replay errors
+var (
+	ErrNoReplay          = errors.New("no replay for room")
+	ErrReplayNotFinished = errors.New("game has not ended")
+)
+
+// Replay is a self-contained record of one game: the settings and roles it
+// started with plus every event in emission order, enough to rebuild each phase
+type Replay struct {
+	RoomCode  string              `json:"room_code"`
+	StartedAt time.Time           `json:"started_at"`
+	EndedAt   time.Time           `json:"ended_at"`
+	Winner    string              `json:"winner"`
+	Settings  entity.GameSettings `json:"settings"`
+	Players   []ReplayPlayer      `json:"players"`
+	Events    []ReplayEvent       `json:"events"`
+	Private   bool                `json:"private"` // true when private events are included
+
+	finished bool
+}
+
+// ReplayPlayer is a seat and the role dealt to it
+type ReplayPlayer struct {
+	ID       string `json:"id"`
+	Nickname string `json:"nickname"`
+	Role     string `json:"role"`
+	IsBot    bool   `json:"is_bot,omitempty"`
+}
+
+// ReplayEvent is one emitted game event
+type ReplayEvent struct {
+	Seq       int       `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	Recipient string    `json:"recipient,omitempty"` // set for private events: the only player who received it
+	Data      any       `json:"data,omitempty"`
+}
+
+// beginReplay starts recording a new game, replacing the room's previous replay
+func (s *GameService) beginReplay(roomCode string, game *entity.Game) {
+	replay := &Replay{
+		RoomCode:  roomCode,
+		StartedAt: time.Now(),
+		Settings:  game.Room.Settings,
+		Events:    make([]ReplayEvent, 0),
+	}
+	for _, playerID := range game.Room.PlayerOrder {
+		player := game.Room.GetPlayer(playerID)
+		if player == nil {
+			continue
+		}
+		replay.Players = append(replay.Players, ReplayPlayer{
+			ID:       player.ID,
+			Nickname: player.Nickname,
+			Role:     string(game.GetPlayerRole(playerID)),
+			IsBot:    player.IsBot,
+		})
+	}
+
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	if _, ok := s.replays[roomCode]; !ok {
+		s.replayOrder = append(s.replayOrder, roomCode)
+	}
+	s.replays[roomCode] = replay
+
+	// Evict the oldest rooms' replays
+	for len(s.replayOrder) > maxStoredReplays {
+		delete(s.replays, s.replayOrder[0])
+		s.replayOrder = s.replayOrder[1:]
+	}
+}
+
+// recordReplayEvent appends an event to the room's replay while its game runs
+func (s *GameService) recordReplayEvent(event GameEvent) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	replay, ok := s.replays[event.RoomCode]
+	if !ok || replay.finished {
+		return
+	}
+	replay.Events = append(replay.Events, ReplayEvent{
+		Seq:       len(replay.Events) + 1,
+		Timestamp: event.Timestamp,
+		Type:      string(event.Type),
+		Recipient: event.TargetPlayerID,
+		Data:      event.Data,
+	})
+}
+
+// finishReplay closes the room's replay once the game is over
+func (s *GameService) finishReplay(roomCode string, winner entity.Team) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	if replay, ok := s.replays[roomCode]; ok {
+		replay.EndedAt = time.Now()
+		replay.Winner = string(winner)
+		replay.finished = true
+	}
+}
+
+// dropUnfinishedReplay forgets a replay whose game was discarded before ending
+func (s *GameService) dropUnfinishedReplay(roomCode string) {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	if replay, ok := s.replays[roomCode]; ok && !replay.finished {
+		delete(s.replays, roomCode)
+	}
+}
+
+// ExportReplay serializes the room's last finished game as JSON. Private
+// events (role reveals, investigations, mafia votes) are only included when
+// includePrivate is set.
+func (s *GameService) ExportReplay(roomCode string, includePrivate bool) ([]byte, error) {
+	s.replayMu.Lock()
+	replay, ok := s.replays[roomCode]
+	if !ok {
+		s.replayMu.Unlock()
+		return nil, ErrNoReplay
+	}
+	if !replay.finished {
+		s.replayMu.Unlock()
+		return nil, ErrReplayNotFinished
+	}
+
+	export := *replay
+	export.Private = includePrivate
+	export.Events = make([]ReplayEvent, 0, len(replay.Events))
+	for _, event := range replay.Events {
+		if event.Recipient != "" && !includePrivate {
+			continue
+		}
+		export.Events = append(export.Events, event)
+	}
+	s.replayMu.Unlock()
+
+	return json.Marshal(export)
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestExportReplayRoundTrip(t *testing.T) {
+	games, game, recorder := startTestGame(t, 7, nil)
+	roomCode := game.Room.Code
+
+	if _, err := games.ExportReplay(roomCode, true); err != ErrReplayNotFinished {
+		t.Fatalf("export mid-game: err = %v, want ErrReplayNotFinished", err)
+	}
+	if _, err := games.ExportReplay("NOPE", true); err != ErrNoReplay {
+		t.Fatalf("export unknown room: err = %v, want ErrNoReplay", err)
+	}
+
+	// One scripted night, then the game is ended
+	byRole := map[entity.Role][]string{}
+	for id, role := range game.Roles {
+		byRole[role] = append(byRole[role], id)
+	}
+	victim, mafioso := byRole[entity.RoleVillager][0], byRole[entity.RoleMafia][0]
+	games.transitionToNight(roomCode)
+	for _, id := range byRole[entity.RoleMafia] {
+		if err := games.SubmitNightAction(roomCode, id, victim); err != nil {
+			t.Fatalf("mafia %s acts: %v", id, err)
+		}
+	}
+	doctor, detective := byRole[entity.RoleDoctor][0], byRole[entity.RoleDetective][0]
+	if err := games.SubmitNightAction(roomCode, doctor, doctor); err != nil {
+		t.Fatalf("doctor acts: %v", err)
+	}
+	if err := games.SubmitNightAction(roomCode, detective, mafioso); err != nil {
+		t.Fatalf("detective acts: %v", err)
+	}
+	games.endGame(roomCode, entity.TeamTown)
+
+	for _, private := range []bool{false, true} {
+		data, err := games.ExportReplay(roomCode, private)
+		if err != nil {
+			t.Fatalf("private %v: export: %v", private, err)
+		}
+		var replay Replay
+		if err := json.Unmarshal(data, &replay); err != nil {
+			t.Fatalf("private %v: decode: %v", private, err)
+		}
+
+		if replay.RoomCode != roomCode || replay.Private != private {
+			t.Errorf("private %v: header room %q private %v", private, replay.RoomCode, replay.Private)
+		}
+		if replay.Settings != game.Room.Settings {
+			t.Errorf("private %v: settings %+v, want %+v", private, replay.Settings, game.Room.Settings)
+		}
+		if !replay.EndedAt.After(replay.StartedAt) {
+			t.Errorf("private %v: ended %v, started %v", private, replay.EndedAt, replay.StartedAt)
+		}
+		if len(replay.Players) != len(game.Roles) {
+			t.Fatalf("private %v: %d players, want %d", private, len(replay.Players), len(game.Roles))
+		}
+		for _, player := range replay.Players {
+			if player.Role != string(game.Roles[player.ID]) {
+				t.Errorf("private %v: %s dealt %q, want %q", private, player.ID, player.Role, game.Roles[player.ID])
+			}
+		}
+
+		// The export holds every emitted event in order, private ones
+		// labeled with their recipient and dropped from public exports
+		var want []GameEvent
+		for _, event := range recorder.all() {
+			if private || event.TargetPlayerID == "" {
+				want = append(want, event)
+			}
+		}
+		if len(replay.Events) != len(want) {
+			t.Fatalf("private %v: %d events, want %d", private, len(replay.Events), len(want))
+		}
+		sawInvestigation := false
+		for i, event := range replay.Events {
+			if event.Type != string(want[i].Type) || event.Recipient != want[i].TargetPlayerID {
+				t.Errorf("private %v: event %d is %s to %q, want %s to %q",
+					private, i, event.Type, event.Recipient, want[i].Type, want[i].TargetPlayerID)
+			}
+			if i > 0 && event.Seq <= replay.Events[i-1].Seq {
+				t.Errorf("private %v: event %d seq %d after %d", private, i, event.Seq, replay.Events[i-1].Seq)
+			}
+			if event.Type == string(EventNightResult) && event.Recipient == detective {
+				sawInvestigation = true
+			}
+		}
+		if sawInvestigation != private {
+			t.Errorf("private %v: investigation result exported = %v", private, sawInvestigation)
+		}
+	}
+}
@@ -1,9 +1,13 @@
 package service
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"log/slog"
+	"strconv"
 	"sync"
 	"time"
 
@@ -16,6 +20,8 @@ const (
 	ReconnectTimeout = 60 * time.Second
 	// RoomTTL is how long an empty room persists before deletion
 	RoomTTL = 5 * time.Minute
+	// ReapGracePeriod is how long a room must stay stuck before the reaper deletes it
+	ReapGracePeriod = 2 * time.Minute
 )
 
 // DisconnectedPlayer tracks a disconnected player awaiting reconnection
@@ -24,6 +30,7 @@ type DisconnectedPlayer struct {
 	RoomCode  string
 	Timer     *time.Timer
 	ExpiresAt time.Time
+	InVoice   bool // was in voice chat when the connection dropped
 }
 
 // RoomService manages game rooms
@@ -36,16 +43,44 @@ type RoomService struct {
 
 	// Callback when a disconnected player times out
 	onReconnectTimeout func(roomCode, playerID string)
+
+	// Reaper hooks: whether a room still has a running game, and cleanup after deletion
+	hasActiveGame func(roomCode string) bool
+	onRoomReaped  func(roomCode string)
+
+	// Callback after a room is deleted, however that happens
+	onRoomDeleted func(roomCode string)
+
+	// HMAC key for reconnect tokens (random per process unless configured)
+	reconnectKey []byte
 }
 
-// NewRoomService creates a new room service
-func NewRoomService(logger *slog.Logger) *RoomService {
+// NewRoomService creates a new room service. It fails only if no random
+// key for reconnect tokens can be generated.
+func NewRoomService(logger *slog.Logger) (*RoomService, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("generate reconnect key: %w", err)
+	}
+
 	return &RoomService{
 		rooms:        make(map[string]*entity.Room),
 		disconnected: make(map[string]*DisconnectedPlayer),
 		roomTTL:      make(map[string]*time.Timer),
 		logger:       logger,
-	}
+		reconnectKey: key,
+	}, nil
+}
+
+// SetReconnectKey sets the key reconnect tokens are signed with. Call before serving.
+func (s *RoomService) SetReconnectKey(key []byte) {
+	s.reconnectKey = key
+}
+
+// IssueReconnectToken returns a signed token that lets the player reclaim
+// their seat in the room after a dropped connection
+func (s *RoomService) IssueReconnectToken(playerID, roomCode string) string {
+	return signReconnectToken(s.reconnectKey, playerID, roomCode, time.Now().Add(ReconnectTokenTTL))
 }
 
 // SetReconnectTimeoutHandler sets the callback for when a disconnected player times out
@@ -53,8 +88,35 @@ func (s *RoomService) SetReconnectTimeoutHandler(handler func(roomCode, playerID
 	s.onReconnectTimeout = handler
 }
 
-// CreateRoom creates a new room and returns the room code
-func (s *RoomService) CreateRoom(password string) (*entity.Room, error) {
+// SetActiveGameChecker sets how the reaper tells whether a room still has a running game
+func (s *RoomService) SetActiveGameChecker(check func(roomCode string) bool) {
+	s.hasActiveGame = check
+}
+
+// SetRoomReapedHandler sets the callback for when the reaper deletes a stuck room
+func (s *RoomService) SetRoomReapedHandler(handler func(roomCode string)) {
+	s.onRoomReaped = handler
+}
+
+// SetRoomDeletedHandler sets the callback for when any room is deleted,
+// whether explicitly, by the reaper or when its TTL expires
+func (s *RoomService) SetRoomDeletedHandler(handler func(roomCode string)) {
+	s.onRoomDeleted = handler
+}
+
+// CreateRoom creates a new room and returns the room code.
+// A maxPlayers of 0 uses the default cap.
+func (s *RoomService) CreateRoom(password string, maxPlayers int) (*entity.Room, error) {
+	if maxPlayers == 0 {
+		maxPlayers = entity.MaxPlayers
+	}
+	if !entity.ValidMaxPlayers(maxPlayers) {
+		return nil, entity.ErrInvalidMaxPlayers
+	}
+	if err := entity.ValidatePassword(password); err != nil {
+		return nil, err
+	}
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -74,9 +136,10 @@ func (s *RoomService) CreateRoom(password string) (*entity.Room, error) {
 	}
 
 	room := entity.NewRoom(code, passwordHash)
+	room.MaxPlayers = maxPlayers
 	s.rooms[code] = room
 
-	s.logger.Info("room created", "code", code, "has_password", password != "")
+	s.logger.Info("room created", "code", code, "has_password", password != "", "max_players", maxPlayers)
 	return room, nil
 }
 
@@ -92,20 +155,28 @@ func (s *RoomService) GetRoom(code string) (*entity.Room, error) {
 	return room, nil
 }
 
-// JoinRoom adds a player to a room
+// JoinRoom adds a player to a room. Joining a room the player is already
+// seated in returns the room unchanged.
 func (s *RoomService) JoinRoom(code, password, playerID, nickname string) (*entity.Room, error) {
 	room, err := s.GetRoom(code)
 	if err != nil {
 		return nil, err
 	}
 
-	// Verify password
+	// Verify password; reject junk before spending time hashing it
 	if room.HasPassword() {
-		if hashPassword(password) != room.PasswordHash {
+		if err := entity.ValidatePassword(password); err != nil {
+			return nil, err
+		}
+		if !checkPassword(password, room.PasswordHash) {
 			return nil, entity.ErrWrongPassword
 		}
 	}
 
+	if room.GetPlayer(playerID) != nil {
+		return room, nil
+	}
+
 	// Cancel any pending TTL timer
 	s.cancelRoomTTL(code)
 
@@ -125,6 +196,25 @@ func (s *RoomService) JoinRoom(code, password, playerID, nickname string) (*enti
 	return room, nil
 }
 
+// Spectate checks that a room can be watched with the given password.
+// Spectators take no seat, so full and running rooms are fine.
+func (s *RoomService) Spectate(code, password string) (*entity.Room, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if room.HasPassword() {
+		if err := entity.ValidatePassword(password); err != nil {
+			return nil, err
+		}
+		if !checkPassword(password, room.PasswordHash) {
+			return nil, entity.ErrWrongPassword
+		}
+	}
+	return room, nil
+}
+
 // LeaveRoom removes a player from a room
 func (s *RoomService) LeaveRoom(code, playerID string) (*entity.Player, string, error) {
 	room, err := s.GetRoom(code)
@@ -136,10 +226,31 @@ func (s *RoomService) LeaveRoom(code, playerID string) (*entity.Player, string,
 	if player == nil {
 		return nil, "", entity.ErrPlayerNotFound
 	}
+	s.playerLeft(room, player, newHostID)
+	return player, newHostID, nil
+}
 
+// LeaveLobby removes a player like LeaveRoom, but only while the room is
+// still in the lobby; once a game starts it returns ErrGameAlreadyStarted
+func (s *RoomService) LeaveLobby(code, playerID string) (*entity.Player, string, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return nil, "", err
+	}
+
+	player, newHostID, err := room.RemoveLobbyPlayer(playerID)
+	if err != nil {
+		return nil, "", err
+	}
+	s.playerLeft(room, player, newHostID)
+	return player, newHostID, nil
+}
+
+// playerLeft logs a departure and starts the TTL once the room is empty
+func (s *RoomService) playerLeft(room *entity.Room, player *entity.Player, newHostID string) {
 	s.logger.Info("player left room",
-		"room", code,
-		"player_id", playerID,
+		"room", room.Code,
+		"player_id", player.ID,
 		"nickname", player.Nickname,
 		"new_host", newHostID,
 		"player_count", room.PlayerCount(),
@@ -147,10 +258,18 @@ func (s *RoomService) LeaveRoom(code, playerID string) (*entity.Player, string,
 
 	// Start TTL timer for empty rooms
 	if room.IsEmpty() {
-		s.startRoomTTL(code)
+		s.startRoomTTL(room.Code)
 	}
+}
 
-	return player, newHostID, nil
+// SetHistoryKey opts a player into game history under the given key
+func (s *RoomService) SetHistoryKey(code, playerID, historyKey string) error {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return err
+	}
+
+	return room.SetHistoryKey(playerID, historyKey)
 }
 
 // SetReady sets a player's ready state
@@ -163,8 +282,107 @@ func (s *RoomService) SetReady(code, playerID string, ready bool) error {
 	return room.SetReady(playerID, ready)
 }
 
-// UpdateSettings updates game settings (host only)
-func (s *RoomService) UpdateSettings(code, playerID string, settings entity.GameSettings) error {
+// UpdateSettings updates game settings (host only) and returns what changed
+func (s *RoomService) UpdateSettings(code, playerID string, settings entity.GameSettings) (entity.SettingsDiff, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return nil, err
+	}
+
+	player := room.GetPlayer(playerID)
+	if player == nil {
+		return nil, entity.ErrPlayerNotFound
+	}
+
+	if !player.IsHost {
+		return nil, entity.ErrNotHost
+	}
+	if !room.CanChangeSettings(settings) {
+		return nil, entity.ErrGameAlreadyStarted
+	}
+
+	// The mafia share is checked against the players seated once there are
+	// enough to start; start checks it again as the table may have changed
+	playerCount := room.PlayerCount()
+	if playerCount < entity.MinPlayers {
+		playerCount = 0
+	}
+	if err := settings.Validate(playerCount); err != nil {
+		return nil, err
+	}
+
+	prev, err := room.UpdateSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	diff := settings.Diff(prev)
+	s.logger.Debug("settings updated", "room", code, "by", playerID, "changed", len(diff))
+	return diff, nil
+}
+
+// ApplyPreset replaces the room's role setup with a named preset and returns
+// what changed (host only)
+func (s *RoomService) ApplyPreset(code, playerID, name string) (entity.GameSettings, entity.SettingsDiff, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return entity.GameSettings{}, nil, err
+	}
+
+	settings, ok := room.Settings.Preset(name)
+	if !ok {
+		return entity.GameSettings{}, nil, entity.ErrPresetNotFound
+	}
+	if !room.CanChangeSettings(settings) {
+		return entity.GameSettings{}, nil, entity.ErrGameAlreadyStarted
+	}
+
+	// Preset must seat everyone and leave room for its special roles
+	playerCount := room.PlayerCount()
+	if playerCount > settings.TotalPlayers() {
+		return entity.GameSettings{}, nil, entity.ErrPresetDoesNotFit
+	}
+	if _, err := entity.BuildRolePool(settings, playerCount); err != nil {
+		return entity.GameSettings{}, nil, entity.ErrPresetDoesNotFit
+	}
+
+	changes, err := s.UpdateSettings(code, playerID, settings)
+	if err != nil {
+		return entity.GameSettings{}, nil, err
+	}
+
+	s.logger.Debug("preset applied", "room", code, "preset", name, "by", playerID)
+	return settings, changes, nil
+}
+
+// ApplyRoles replaces the room's role counts with those of roles, keeping
+// its timers and rule toggles, and returns what changed (host only)
+func (s *RoomService) ApplyRoles(code, playerID string, roles entity.GameSettings) (entity.GameSettings, entity.SettingsDiff, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return entity.GameSettings{}, nil, err
+	}
+
+	settings := room.Settings.WithRoles(roles)
+	changes, err := s.UpdateSettings(code, playerID, settings)
+	if err != nil {
+		return entity.GameSettings{}, nil, err
+	}
+
+	s.logger.Debug("roles applied", "room", code, "by", playerID, "players", settings.TotalPlayers())
+	return settings, changes, nil
+}
+
+// LobbyStatus returns whether the room's game can be started and why not
+func (s *RoomService) LobbyStatus(code string) (entity.LobbyStatus, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return entity.LobbyStatus{}, err
+	}
+	return room.LobbyStatus(), nil
+}
+
+// ReturnToLobby resets a finished room to the lobby for another game (host only)
+func (s *RoomService) ReturnToLobby(code, playerID string) error {
 	room, err := s.GetRoom(code)
 	if err != nil {
 		return err
@@ -174,21 +392,21 @@ func (s *RoomService) UpdateSettings(code, playerID string, settings entity.Game
 	if player == nil {
 		return entity.ErrPlayerNotFound
 	}
-
 	if !player.IsHost {
 		return entity.ErrNotHost
 	}
 
-	room.UpdateSettings(settings)
-	s.logger.Debug("settings updated", "room", code, "by", playerID)
+	if err := room.ResetToLobby(); err != nil {
+		return err
+	}
+
+	s.logger.Info("room returned to lobby", "room", code, "by", playerID)
 	return nil
 }
 
 // DeleteRoom removes a room
 func (s *RoomService) DeleteRoom(code string) {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
 	// Cancel TTL timer if exists
 	if timer, ok := s.roomTTL[code]; ok {
 		timer.Stop()
@@ -196,7 +414,84 @@ func (s *RoomService) DeleteRoom(code string) {
 	}
 
 	delete(s.rooms, code)
+	s.mu.Unlock()
 	s.logger.Info("room deleted", "code", code)
+	s.roomDeleted(code)
+}
+
+// roomDeleted runs the room deleted callback. Call it without s.mu held.
+func (s *RoomService) roomDeleted(code string) {
+	if s.onRoomDeleted != nil {
+		s.onRoomDeleted(code)
+	}
+}
+
+// StartReaper sweeps for stuck rooms every interval until stop is closed.
+// Empty rooms are handled by the room TTL; the reaper catches rooms whose game
+// started but that no connected player or running game is keeping alive.
+func (s *RoomService) StartReaper(interval time.Duration, stop <-chan struct{}) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		stuckSince := make(map[string]time.Time)
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-ticker.C:
+				s.reapStuckRooms(now, stuckSince)
+			}
+		}
+	}()
+}
+
+// reapStuckRooms deletes rooms that have been stuck for at least ReapGracePeriod
+func (s *RoomService) reapStuckRooms(now time.Time, stuckSince map[string]time.Time) {
+	s.mu.RLock()
+	rooms := make(map[string]*entity.Room, len(s.rooms))
+	for code, room := range s.rooms {
+		rooms[code] = room
+	}
+	s.mu.RUnlock()
+
+	// Forget rooms deleted since the last sweep
+	for code := range stuckSince {
+		if _, ok := rooms[code]; !ok {
+			delete(stuckSince, code)
+		}
+	}
+
+	for code, room := range rooms {
+		if !s.isStuck(code, room) {
+			delete(stuckSince, code)
+			continue
+		}
+
+		since, ok := stuckSince[code]
+		if !ok {
+			stuckSince[code] = now
+			continue
+		}
+		if now.Sub(since) < ReapGracePeriod {
+			continue
+		}
+
+		delete(stuckSince, code)
+		s.DeleteRoom(code)
+		s.logger.Info("stuck room reaped", "code", code, "state", room.State, "stuck_for", now.Sub(since))
+		if s.onRoomReaped != nil {
+			s.onRoomReaped(code)
+		}
+	}
+}
+
+// isStuck returns true for a started room with nobody connected and no running game
+func (s *RoomService) isStuck(code string, room *entity.Room) bool {
+	if room.State == entity.RoomStateWaiting || room.ConnectedCount() > 0 {
+		return false
+	}
+	return s.hasActiveGame == nil || !s.hasActiveGame(code)
 }
 
 // startRoomTTL starts a cleanup timer for an empty room
@@ -214,7 +509,8 @@ func (s *RoomService) startRoomTTL(code string) {
 	s.roomTTL[code] = time.AfterFunc(RoomTTL, func() {
 		s.mu.Lock()
 		room, exists := s.rooms[code]
-		if exists && room.IsEmpty() {
+		expired := exists && room.IsEmpty()
+		if expired {
 			delete(s.rooms, code)
 			delete(s.roomTTL, code)
 			s.logger.Info("room expired and deleted", "code", code)
@@ -223,6 +519,9 @@ func (s *RoomService) startRoomTTL(code string) {
 			delete(s.roomTTL, code)
 		}
 		s.mu.Unlock()
+		if expired {
+			s.roomDeleted(code)
+		}
 	})
 }
 
@@ -248,7 +547,7 @@ func (s *RoomService) RoomCount() int {
 // MarkPlayerDisconnected marks a player as disconnected and starts the reconnection timer
 // Returns true if the player was marked as disconnected (game in progress)
 // Returns false if the player should be removed immediately (lobby phase)
-func (s *RoomService) MarkPlayerDisconnected(code, playerID string) bool {
+func (s *RoomService) MarkPlayerDisconnected(code, playerID string, inVoice bool) bool {
 	room, err := s.GetRoom(code)
 	if err != nil {
 		return false
@@ -280,6 +579,7 @@ func (s *RoomService) MarkPlayerDisconnected(code, playerID string) bool {
 		RoomCode:  code,
 		Timer:     timer,
 		ExpiresAt: time.Now().Add(ReconnectTimeout),
+		InVoice:   inVoice,
 	}
 
 	s.logger.Info("player disconnected, awaiting reconnect",
@@ -291,6 +591,142 @@ func (s *RoomService) MarkPlayerDisconnected(code, playerID string) bool {
 	return true
 }
 
+// AssignTemporaryHost stands in a connected player for a disconnected host.
+// Returns the temporary host ID, or empty if the host did not change.
+func (s *RoomService) AssignTemporaryHost(code, playerID string) string {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return ""
+	}
+
+	newHostID := room.AssignTemporaryHost(playerID)
+	if newHostID != "" {
+		s.logger.Info("temporary host assigned",
+			"room", code,
+			"absent_host", playerID,
+			"temporary_host", newHostID,
+		)
+	}
+	return newHostID
+}
+
+// RestoreHost gives host back to a reconnecting original host.
+// Returns the ID of the replaced temporary host, or empty if unchanged.
+func (s *RoomService) RestoreHost(code, playerID string) string {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return ""
+	}
+
+	temporaryID := room.RestoreHost(playerID)
+	if temporaryID != "" {
+		s.logger.Info("original host restored",
+			"room", code,
+			"host", playerID,
+			"temporary_host", temporaryID,
+		)
+	}
+	return temporaryID
+}
+
+// AddBot fills a seat in the lobby with a bot named "Bot N"
+func (s *RoomService) AddBot(code string) (*entity.Player, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Take the first free bot name; AddPlayer enforces uniqueness
+	for n := 1; n <= entity.MaxPlayersCeiling; n++ {
+		bot := entity.NewBotPlayer(id.Generate(), "Bot "+strconv.Itoa(n))
+		err := room.AddPlayer(bot)
+		if err == entity.ErrNicknameInUse {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		s.logger.Info("bot added",
+			"room", code,
+			"player_id", bot.ID,
+			"nickname", bot.Nickname,
+			"player_count", room.PlayerCount(),
+		)
+		return bot, nil
+	}
+	return nil, entity.ErrNicknameInUse
+}
+
+// ChangeNickname validates and applies a lobby nickname change, returning the
+// normalized nickname
+func (s *RoomService) ChangeNickname(code, playerID, nickname string) (string, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return "", err
+	}
+
+	nickname, err = entity.NormalizeNickname(nickname)
+	if err != nil {
+		return "", err
+	}
+
+	if err := room.ChangeNickname(playerID, nickname); err != nil {
+		return "", err
+	}
+
+	s.logger.Info("nickname changed",
+		"room", code,
+		"player_id", playerID,
+		"nickname", nickname,
+	)
+	return nickname, nil
+}
+
+// TransferHost hands host to another player in the room (host only, lobby only)
+func (s *RoomService) TransferHost(code, fromID, toID string) error {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return err
+	}
+
+	if err := room.TransferHost(fromID, toID); err != nil {
+		return err
+	}
+
+	s.logger.Info("host transferred",
+		"room", code,
+		"from", fromID,
+		"to", toID,
+	)
+	return nil
+}
+
+// AssignRole forces the role a player is dealt at the next start (host
+// only, lobby only); an empty role returns them to the random deal.
+// Returns the room's forced roles after the change.
+func (s *RoomService) AssignRole(code, hostID, playerID string, role entity.Role) (map[string]entity.Role, error) {
+	room, err := s.GetRoom(code)
+	if err != nil {
+		return nil, err
+	}
+
+	host := room.GetPlayer(hostID)
+	if host == nil {
+		return nil, entity.ErrPlayerNotFound
+	}
+	if !host.IsHost {
+		return nil, entity.ErrNotHost
+	}
+
+	if err := room.AssignRole(playerID, role); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("role assigned by host", "room", code, "player_id", playerID, "forced", role != "")
+	return room.GetForcedRoles(), nil
+}
+
 // handleReconnectTimeout handles when a disconnected player's timer expires
 func (s *RoomService) handleReconnectTimeout(code, playerID string) {
 	s.mu.Lock()
@@ -313,22 +749,32 @@ func (s *RoomService) handleReconnectTimeout(code, playerID string) {
 	}
 }
 
-// CanReconnect checks if a player can reconnect to a room
-func (s *RoomService) CanReconnect(playerID string) (*DisconnectedPlayer, bool) {
+// CanReconnect validates a reconnect token and returns the session it resumes.
+// Returns ErrReconnectUnauthorized for tampered, expired or mismatched tokens
+// and ErrNoReconnectSession when the player is not awaiting reconnection.
+func (s *RoomService) CanReconnect(token string) (*DisconnectedPlayer, error) {
+	playerID, roomCode, err := parseReconnectToken(s.reconnectKey, token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	dp, ok := s.disconnected[playerID]
 	if !ok {
-		return nil, false
+		return nil, ErrNoReconnectSession
+	}
+	if dp.RoomCode != roomCode {
+		return nil, ErrReconnectUnauthorized
 	}
 
 	// Check if not expired
 	if time.Now().After(dp.ExpiresAt) {
-		return nil, false
+		return nil, ErrNoReconnectSession
 	}
 
-	return dp, true
+	return dp, nil
 }
 
 // ReconnectPlayer restores a disconnected player's connection
@@ -383,3 +829,8 @@ func hashPassword(password string) string {
 	hash := sha256.Sum256([]byte(password))
 	return hex.EncodeToString(hash[:])
 }
+
+// checkPassword compares a password against a stored hash in constant time
+func checkPassword(password, passwordHash string) bool {
+	return hmac.Equal([]byte(hashPassword(password)), []byte(passwordHash))
+}
@@ -0,0 +1,427 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestApplyPreset(t *testing.T) {
+	rooms, _, _ := newTestServices()
+	room := seatPlayers(t, rooms, 8)
+	room.Settings.RevealRolesOnDeath = false
+	before := room.Settings
+
+	settings, changes, err := rooms.ApplyPreset(room.Code, "p0", "classic-8")
+	if err != nil {
+		t.Fatalf("apply classic-8: %v", err)
+	}
+	got := room.Settings
+	if got != settings {
+		t.Errorf("room settings %+v differ from returned %+v", got, settings)
+	}
+	if want := settings.Diff(before); len(changes) == 0 || !maps.Equal(changes, want) {
+		t.Errorf("preset changes = %v, want %v", changes, want)
+	}
+	if got.Villagers != 4 || got.Mafia != 2 || got.Godfather != 0 || got.Doctor != 1 || got.Detective != 1 || got.NightTimer != 60 {
+		t.Errorf("classic-8 applied as %+v", got)
+	}
+	if got.RevealRolesOnDeath {
+		t.Error("applying a preset reset a rule toggle")
+	}
+
+	if _, _, err := rooms.ApplyPreset(room.Code, "p0", "small-6"); err != entity.ErrPresetDoesNotFit {
+		t.Errorf("small-6 for 8 players: err = %v, want ErrPresetDoesNotFit", err)
+	}
+	if _, _, err := rooms.ApplyPreset(room.Code, "p0", "nope"); err != entity.ErrPresetNotFound {
+		t.Errorf("unknown preset: err = %v, want ErrPresetNotFound", err)
+	}
+	if _, _, err := rooms.ApplyPreset(room.Code, "p1", "chaos-12"); err == nil {
+		t.Error("non-host applied a preset")
+	}
+}
+
+func TestRoomPlayerCap(t *testing.T) {
+	rooms, games, _ := newTestServices()
+
+	for _, tt := range []struct {
+		maxPlayers int
+		want       int // players seated before the room is full
+	}{
+		{0, entity.MaxPlayers},
+		{8, 8},
+		{entity.MaxPlayersCeiling, entity.MaxPlayersCeiling},
+	} {
+		room, err := rooms.CreateRoom("", tt.maxPlayers)
+		if err != nil {
+			t.Fatalf("create room capped at %d: %v", tt.maxPlayers, err)
+		}
+		for i := range tt.want {
+			id := fmt.Sprintf("p%d", i)
+			if _, err := rooms.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+				t.Fatalf("cap %d: player %d rejected: %v", tt.maxPlayers, i+1, err)
+			}
+		}
+		if _, err := rooms.JoinRoom(room.Code, "", "extra", "Extra"); err != entity.ErrRoomFull {
+			t.Errorf("cap %d: player %d: err = %v, want ErrRoomFull", tt.maxPlayers, tt.want+1, err)
+		}
+	}
+
+	// A full room past the default cap still deals a valid game
+	room, _ := rooms.CreateRoom("", entity.MaxPlayersCeiling)
+	for i := range entity.MaxPlayersCeiling {
+		id := fmt.Sprintf("p%d", i)
+		rooms.JoinRoom(room.Code, "", id, "Player "+id)
+		rooms.SetReady(room.Code, id, true)
+	}
+	if _, _, err := rooms.ApplyRoles(room.Code, "p0", games.AutoBalance(entity.MaxPlayersCeiling)); err != nil {
+		t.Fatalf("balance %d players: %v", entity.MaxPlayersCeiling, err)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start with %d players: %v", entity.MaxPlayersCeiling, err)
+	}
+	t.Cleanup(func() { games.cancelPhaseTimer(room.Code) })
+	if n := len(games.GetGame(room.Code).Roles); n != entity.MaxPlayersCeiling {
+		t.Errorf("dealt %d roles, want %d", n, entity.MaxPlayersCeiling)
+	}
+
+	for _, maxPlayers := range []int{entity.MinPlayers - 1, entity.MaxPlayersCeiling + 1} {
+		if _, err := rooms.CreateRoom("", maxPlayers); err != entity.ErrInvalidMaxPlayers {
+			t.Errorf("create room capped at %d: err = %v, want ErrInvalidMaxPlayers", maxPlayers, err)
+		}
+	}
+}
+
+func TestReaperDeletesStuckRooms(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	rooms.SetActiveGameChecker(games.HasActiveGame)
+	var reaped []string
+	rooms.SetRoomReapedHandler(func(roomCode string) { reaped = append(reaped, roomCode) })
+
+	// started seats a game nobody is connected to, optionally losing the game
+	started := func(lost bool) *entity.Room {
+		room := seatPlayers(t, rooms, 5)
+		if err := games.StartGame(room.Code, "p0"); err != nil {
+			t.Fatalf("start game: %v", err)
+		}
+		t.Cleanup(func() { games.DiscardGame(room.Code) })
+		if lost {
+			games.DiscardGame(room.Code)
+		}
+		for _, id := range room.PlayerOrder {
+			room.GetPlayer(id).IsConnected = false
+		}
+		return room
+	}
+	stuck := started(true)
+	running := started(false)
+	watched := started(true)
+	watched.GetPlayer("p2").IsConnected = true
+	lobby := seatPlayers(t, rooms, 5)
+	for _, id := range lobby.PlayerOrder {
+		lobby.GetPlayer(id).IsConnected = false
+	}
+
+	stuckSince := make(map[string]time.Time)
+	start := time.Now()
+	rooms.reapStuckRooms(start, stuckSince)
+	rooms.reapStuckRooms(start.Add(ReapGracePeriod-time.Second), stuckSince)
+	if len(reaped) != 0 {
+		t.Fatalf("reaped %v before the grace period", reaped)
+	}
+
+	rooms.reapStuckRooms(start.Add(ReapGracePeriod), stuckSince)
+	if !slices.Equal(reaped, []string{stuck.Code}) {
+		t.Fatalf("reaped %v, want only the stuck room %s", reaped, stuck.Code)
+	}
+	if _, err := rooms.GetRoom(stuck.Code); err == nil {
+		t.Error("stuck room still exists")
+	}
+	for _, room := range []*entity.Room{running, watched, lobby} {
+		if _, err := rooms.GetRoom(room.Code); err != nil {
+			t.Errorf("room %s was deleted: %v", room.Code, err)
+		}
+	}
+}
+
+func TestChangeNickname(t *testing.T) {
+	rooms, games, _ := newTestServices()
+	room := seatPlayers(t, rooms, 6) // "Player p0" .. "Player p5"
+
+	got, err := rooms.ChangeNickname(room.Code, "p0", "  Ace Ventura ")
+	if err != nil {
+		t.Fatalf("change nickname: %v", err)
+	}
+	if got != "Ace Ventura" {
+		t.Errorf("nickname = %q, want %q", got, "Ace Ventura")
+	}
+	if p := room.GetPlayer("p0"); p.Nickname != "Ace Ventura" {
+		t.Errorf("stored nickname = %q, want %q", p.Nickname, "Ace Ventura")
+	}
+
+	// Keeping your own name is not a collision
+	if _, err := rooms.ChangeNickname(room.Code, "p0", "Ace Ventura"); err != nil {
+		t.Errorf("change to own name: %v", err)
+	}
+
+	if _, err := rooms.ChangeNickname(room.Code, "p0", "Player p1"); err != entity.ErrNicknameInUse {
+		t.Errorf("change to another player's name: err = %v, want ErrNicknameInUse", err)
+	}
+
+	for _, nickname := range []string{"", "   ", "\u200b", strings.Repeat("x", entity.MaxNicknameLength+1)} {
+		if _, err := rooms.ChangeNickname(room.Code, "p0", nickname); err != entity.ErrInvalidNickname {
+			t.Errorf("change to %q: err = %v, want ErrInvalidNickname", nickname, err)
+		}
+	}
+	if p := room.GetPlayer("p0"); p.Nickname != "Ace Ventura" {
+		t.Errorf("rejected changes left nickname %q, want %q", p.Nickname, "Ace Ventura")
+	}
+
+	if _, err := rooms.ChangeNickname(room.Code, "ghost", "Ghost"); err != entity.ErrPlayerNotFound {
+		t.Errorf("unknown player: err = %v, want ErrPlayerNotFound", err)
+	}
+
+	// Names are fixed once the game starts
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.DiscardGame(room.Code) })
+	if _, err := rooms.ChangeNickname(room.Code, "p1", "Late"); err != entity.ErrGameAlreadyStarted {
+		t.Errorf("change after start: err = %v, want ErrGameAlreadyStarted", err)
+	}
+}
+
+func TestJoinRoomIsIdempotent(t *testing.T) {
+	rooms, _, _ := newTestServices()
+	room, err := rooms.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+
+	// A double-click sends two joins at once with the same player ID
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = rooms.JoinRoom(room.Code, "", "p0", "Player p0")
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Errorf("join %d: %v", i+1, err)
+		}
+	}
+
+	// A later repeat returns the existing seat, even under another name
+	if _, err := rooms.JoinRoom(room.Code, "", "p0", "Someone Else"); err != nil {
+		t.Errorf("repeat join: %v", err)
+	}
+	if n := room.PlayerCount(); n != 1 {
+		t.Errorf("player count = %d, want 1", n)
+	}
+	if n := len(room.PlayerOrder); n != 1 {
+		t.Errorf("seat order has %d entries, want 1", n)
+	}
+	if p := room.GetPlayer("p0"); p.Nickname != "Player p0" || !p.IsHost {
+		t.Errorf("seat = %+v, want the original host seat", p)
+	}
+}
+
+func TestUpdateSettingsReportsOnlyChangedFields(t *testing.T) {
+	rooms, _, _ := newTestServices()
+	room := seatPlayers(t, rooms, 7)
+
+	settings := room.Settings
+	prevNight := settings.NightTimer
+	settings.NightTimer = prevNight + 30
+	settings.TieBreak = entity.TieBreakRandom
+	diff, err := rooms.UpdateSettings(room.Code, "p0", settings)
+	if err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+
+	want := entity.SettingsDiff{
+		"night_timer": {Old: prevNight, New: prevNight + 30},
+		"tie_break":   {Old: entity.TieBreakNoElimination, New: entity.TieBreakRandom},
+	}
+	if len(diff) != len(want) {
+		t.Errorf("diff = %v, want exactly %v", diff, want)
+	}
+	for name, change := range want {
+		if diff[name] != change {
+			t.Errorf("%s: %+v, want %+v", name, diff[name], change)
+		}
+	}
+
+	// Resubmitting the same settings changes nothing
+	if diff, _ := rooms.UpdateSettings(room.Code, "p0", settings); len(diff) != 0 {
+		t.Errorf("no-op update diff = %v, want empty", diff)
+	}
+
+	// Out-of-range rules are rejected rather than clamped later
+	settings.MaxRevotes = entity.MaxRevotesCeiling + 1
+	if _, err := rooms.UpdateSettings(room.Code, "p0", settings); err != entity.ErrInvalidMaxRevotes {
+		t.Errorf("max revotes over the ceiling: err = %v, want ErrInvalidMaxRevotes", err)
+	}
+	if room.Settings.MaxRevotes != 0 {
+		t.Errorf("rejected update stored max revotes %d", room.Settings.MaxRevotes)
+	}
+}
+
+func TestMafiaShareCheckedAgainstSeatedPlayersAtStart(t *testing.T) {
+	rooms, games, _ := newTestServices()
+
+	// A long villager list is no reason to refuse two mafia in a room of seven
+	room := seatPlayers(t, rooms, 7)
+	settings := room.Settings
+	settings.Villagers = 20
+	if _, err := rooms.UpdateSettings(room.Code, "p0", settings); err != nil {
+		t.Fatalf("update settings: %v", err)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start with 2 of 7 mafia: %v", err)
+	}
+	t.Cleanup(func() { games.cancelPhaseTimer(room.Code) })
+
+	// Settings that leave the seated table unbalanced are refused outright
+	room = seatPlayers(t, rooms, 6)
+	settings = room.Settings
+	settings.Villagers, settings.Mafia, settings.Doctor = 17, 3, 0
+	if _, err := rooms.UpdateSettings(room.Code, "p0", settings); err != entity.ErrUnbalancedTeams {
+		t.Errorf("update to 3 of 6 mafia: err = %v, want ErrUnbalancedTeams", err)
+	}
+	if room.Settings.Mafia != 2 {
+		t.Errorf("rejected update stored %d mafia", room.Settings.Mafia)
+	}
+
+	// A lobby still filling up has no table to check against yet
+	room = seatPlayers(t, rooms, entity.MinPlayers-1)
+	if _, err := rooms.UpdateSettings(room.Code, "p0", settings); err != nil {
+		t.Fatalf("update settings before the table fills: %v", err)
+	}
+
+	// Balanced settings are refused at start once players leave
+	room = seatPlayers(t, rooms, 6)
+	for _, id := range []string{"p4", "p5"} {
+		if _, _, err := rooms.LeaveLobby(room.Code, id); err != nil {
+			t.Fatalf("%s leaves: %v", id, err)
+		}
+	}
+	if err := games.StartGame(room.Code, "p0"); err != entity.ErrUnbalancedTeams {
+		t.Errorf("start with 2 of 4 mafia: err = %v, want ErrUnbalancedTeams", err)
+	}
+}
+
+func TestRoomPasswords(t *testing.T) {
+	rooms, _, _ := newTestServices()
+
+	long := strings.Repeat("x", entity.MaxPasswordLength+1)
+	for _, password := range []string{long, "tab\there"} {
+		if _, err := rooms.CreateRoom(password, 0); err != entity.ErrInvalidPassword {
+			t.Errorf("create with %q: err = %v, want ErrInvalidPassword", password, err)
+		}
+	}
+
+	room, err := rooms.CreateRoom("s3cret pass", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for _, tt := range []struct {
+		password string
+		want     error
+	}{
+		{"", entity.ErrWrongPassword},
+		{"s3cret", entity.ErrWrongPassword},
+		{"S3CRET PASS", entity.ErrWrongPassword},
+		{long, entity.ErrInvalidPassword},
+		{"s3cret pass", nil},
+	} {
+		if _, err := rooms.JoinRoom(room.Code, tt.password, "p0", "Player p0"); err != tt.want {
+			t.Errorf("join with %q: err = %v, want %v", tt.password, err, tt.want)
+		}
+	}
+	if room.GetPlayer("p0") == nil {
+		t.Error("correct password did not seat the player")
+	}
+
+	// The longest allowed password still works
+	maxed := strings.Repeat("y", entity.MaxPasswordLength)
+	room, err = rooms.CreateRoom(maxed, 0)
+	if err != nil {
+		t.Fatalf("create with a %d-character password: %v", entity.MaxPasswordLength, err)
+	}
+	if _, err := rooms.JoinRoom(room.Code, maxed, "p1", "Player p1"); err != nil {
+		t.Errorf("join with the maximum length password: %v", err)
+	}
+}
+
+func TestLeaveLobbyOnlyBeforeTheGameStarts(t *testing.T) {
+	rooms, _, _ := newTestServices()
+	room := seatPlayers(t, rooms, 4)
+
+	if _, _, err := rooms.LeaveLobby(room.Code, "p3"); err != nil {
+		t.Fatalf("leave lobby: %v", err)
+	}
+	if room.GetPlayer("p3") != nil {
+		t.Error("p3 still seated after leaving the lobby")
+	}
+
+	room.State = entity.RoomStatePlaying
+	if _, _, err := rooms.LeaveLobby(room.Code, "p2"); !errors.Is(err, entity.ErrGameAlreadyStarted) {
+		t.Errorf("leave lobby mid-game = %v, want ErrGameAlreadyStarted", err)
+	}
+	if room.GetPlayer("p2") == nil {
+		t.Error("p2 removed from a game in progress")
+	}
+}
+
+func TestSettingsOnlyChangeInTheLobby(t *testing.T) {
+	rooms, _, _ := newTestServices()
+	room := seatPlayers(t, rooms, 8)
+	before := room.Settings
+
+	for _, state := range []entity.RoomState{entity.RoomStatePlaying, entity.RoomStateEnded} {
+		room.State = state
+		changed := before
+		changed.NightTimer++
+		if _, err := rooms.UpdateSettings(room.Code, "p0", changed); !errors.Is(err, entity.ErrGameAlreadyStarted) {
+			t.Errorf("%s: update settings = %v, want ErrGameAlreadyStarted", state, err)
+		}
+		if _, _, err := rooms.ApplyPreset(room.Code, "p0", "small-6"); !errors.Is(err, entity.ErrGameAlreadyStarted) {
+			t.Errorf("%s: apply preset = %v, want ErrGameAlreadyStarted", state, err)
+		}
+		if _, _, err := rooms.ApplyRoles(room.Code, "p0", before.Balanced(8)); !errors.Is(err, entity.ErrGameAlreadyStarted) {
+			t.Errorf("%s: apply roles = %v, want ErrGameAlreadyStarted", state, err)
+		}
+		if room.Settings != before {
+			t.Errorf("%s: settings changed outside the lobby", state)
+		}
+
+		// The host can still cut voice mid-game
+		muted := before
+		muted.VoiceEnabled = !before.VoiceEnabled
+		if _, err := rooms.UpdateSettings(room.Code, "p0", muted); err != nil {
+			t.Errorf("%s: toggle voice = %v", state, err)
+		}
+		if _, err := rooms.UpdateSettings(room.Code, "p0", before); err != nil {
+			t.Errorf("%s: toggle voice back = %v", state, err)
+		}
+	}
+
+	if err := rooms.ReturnToLobby(room.Code, "p0"); err != nil {
+		t.Fatalf("return to lobby: %v", err)
+	}
+	if _, _, err := rooms.ApplyPreset(room.Code, "p0", "classic-8"); err != nil {
+		t.Errorf("apply preset back in the lobby: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"time"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// startSpeakingTurns gives the floor to one living player at a time while
+// voting is open, when the room plays with TurnBasedVoting
+func (s *GameService) startSpeakingTurns(roomCode string, game *entity.Game) {
+	if !game.Room.Settings.TurnBasedVoting {
+		return
+	}
+	s.announceSpeaker(roomCode, game, game.StartSpeakingTurns())
+}
+
+// PassSpeakingTurn ends the current speaker's turn early
+func (s *GameService) PassSpeakingTurn(roomCode, playerID string) error {
+	game := s.GetGame(roomCode)
+	if game == nil {
+		return entity.ErrGameNotStarted
+	}
+
+	next, err := game.PassSpeakingTurn(playerID)
+	if err != nil {
+		return err
+	}
+	s.announceSpeaker(roomCode, game, next)
+	return nil
+}
+
+// announceSpeaker tells the room whose turn it is and hands the floor on
+// when the turn runs out. A turn that was passed or outlived voting leaves
+// its timer with nothing to do.
+func (s *GameService) announceSpeaker(roomCode string, game *entity.Game, turn entity.SpeakingTurn) {
+	if turn.PlayerID == "" {
+		return
+	}
+
+	s.emitEvent(GameEvent{
+		Type:     EventSpeakingTurn,
+		RoomCode: roomCode,
+		Data: map[string]any{
+			"player_id": turn.PlayerID,
+			"turn":      turn.Turn,
+			"seconds":   entity.SpeakingTurnSeconds,
+		},
+	})
+
+	time.AfterFunc(time.Duration(entity.SpeakingTurnSeconds)*time.Second, func() {
+		if next, ok := game.AdvanceSpeaker(turn.Turn); ok {
+			s.announceSpeaker(roomCode, game, next)
+		}
+	})
+}
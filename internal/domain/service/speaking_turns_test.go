@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+func TestSpeakingTurnsCycleThroughLivingPlayers(t *testing.T) {
+	games, game, recorder := startTestGame(t, 5, func(s *entity.GameSettings) {
+		s.TurnBasedVoting = true
+	})
+	code := game.Room.Code
+	game.Room.GetPlayer("p1").Status = entity.PlayerStatusDead
+
+	games.cancelPhaseTimer(code)
+	games.startVoting(code, 60)
+
+	var speakers []string
+	for _, event := range recorder.ofType(EventSpeakingTurn) {
+		speakers = append(speakers, event.Data.(map[string]any)["player_id"].(string))
+	}
+	if len(speakers) != 1 || speakers[0] != "p0" {
+		t.Fatalf("speaking turns on opening the vote = %v, want p0", speakers)
+	}
+
+	if err := games.PassSpeakingTurn(code, "p2"); !errors.Is(err, entity.ErrNotYourTurn) {
+		t.Errorf("pass out of turn: %v, want %v", err, entity.ErrNotYourTurn)
+	}
+
+	// Passing and running out of time both move on, skipping the dead
+	if err := games.PassSpeakingTurn(code, "p0"); err != nil {
+		t.Fatalf("pass: %v", err)
+	}
+	turn := game.CurrentSpeaker()
+	if turn.PlayerID != "p2" {
+		t.Fatalf("after p0 passed, %q has the floor, want p2", turn.PlayerID)
+	}
+	for _, want := range []string{"p3", "p4", "p0"} {
+		next, ok := game.AdvanceSpeaker(turn.Turn)
+		if !ok || next.PlayerID != want {
+			t.Fatalf("turn after %s = %q (%v), want %s", turn.PlayerID, next.PlayerID, ok, want)
+		}
+		turn = next
+	}
+
+	// A timer for an overtaken turn does nothing
+	if _, ok := game.AdvanceSpeaker(turn.Turn - 1); ok {
+		t.Error("a stale turn handed the floor on")
+	}
+
+	// Closing the vote opens the floor again
+	game.ResolveDay()
+	if speaker := game.CurrentSpeaker(); speaker.PlayerID != "" {
+		t.Errorf("%s still has the floor after voting closed", speaker.PlayerID)
+	}
+	if _, ok := game.AdvanceSpeaker(turn.Turn); ok {
+		t.Error("turns carried on after voting closed")
+	}
+}
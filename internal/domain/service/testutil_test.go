@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/V4T54L/mafia/internal/domain/entity"
+)
+
+// discardLogger returns a logger that drops every record
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+// eventRecorder collects the events a GameService emits
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []GameEvent
+}
+
+func (r *eventRecorder) record(event GameEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// ofType returns the recorded events of one type, oldest first
+func (r *eventRecorder) ofType(eventType GameEventType) []GameEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var matched []GameEvent
+	for _, event := range r.events {
+		if event.Type == eventType {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// phaseTimerArmed reports whether a phase timer is pending for the room
+func (s *GameService) phaseTimerArmed(roomCode string) bool {
+	s.timerMu.Lock()
+	defer s.timerMu.Unlock()
+	_, armed := s.phaseTimers[roomCode]
+	return armed
+}
+
+// newTestServices returns fresh services whose game events are recorded
+func newTestServices() (*RoomService, *GameService, *eventRecorder) {
+	rooms, err := NewRoomService(discardLogger())
+	if err != nil {
+		panic(err)
+	}
+	games := NewGameService(rooms, discardLogger())
+	rooms.SetRoomDeletedHandler(games.RoomDeleted)
+	recorder := &eventRecorder{}
+	games.SetEventHandler(recorder.record)
+	return rooms, games, recorder
+}
+
+// seatPlayers creates a room with n ready players p0..p(n-1); p0 is host
+func seatPlayers(t *testing.T, rooms *RoomService, n int) *entity.Room {
+	t.Helper()
+	room, err := rooms.CreateRoom("", 0)
+	if err != nil {
+		t.Fatalf("create room: %v", err)
+	}
+	for i := range n {
+		id := fmt.Sprintf("p%d", i)
+		if _, err := rooms.JoinRoom(room.Code, "", id, "Player "+id); err != nil {
+			t.Fatalf("join %s: %v", id, err)
+		}
+		if err := rooms.SetReady(room.Code, id, true); err != nil {
+			t.Fatalf("ready %s: %v", id, err)
+		}
+	}
+	return room
+}
+
+// startTestGame seats n ready players and starts a game after tweak adjusts
+// the room's settings; its timers are stopped when the test ends
+func startTestGame(t *testing.T, n int, tweak func(*entity.GameSettings)) (*GameService, *entity.Game, *eventRecorder) {
+	t.Helper()
+	rooms, games, recorder := newTestServices()
+	room := seatPlayers(t, rooms, n)
+	if tweak != nil {
+		tweak(&room.Settings)
+	}
+	if err := games.StartGame(room.Code, "p0"); err != nil {
+		t.Fatalf("start game: %v", err)
+	}
+	t.Cleanup(func() { games.cancelPhaseTimer(room.Code) })
+	return games, games.GetGame(room.Code), recorder
+}
+
+// all returns every recorded event, oldest first
+func (r *eventRecorder) all() []GameEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]GameEvent(nil), r.events...)
+}
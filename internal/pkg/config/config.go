@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -10,6 +12,56 @@ type Config struct {
 	Host     string
 	StaticDir string
 	Env      string
+
+	// AuditLogPath is where game events are written as NDJSON (empty = disabled)
+	AuditLogPath string
+
+	// RoomReapInterval is how often stuck rooms are swept
+	RoomReapInterval time.Duration
+
+	// LobbyIdleTimeout kicks lobby players who send nothing for this long
+	// (0 = never)
+	LobbyIdleTimeout time.Duration
+
+	// MaxActiveGames caps games in progress at once (0 = no cap); with
+	// QueueGameStarts, starts over the cap wait for a slot instead of failing
+	MaxActiveGames  int
+	QueueGameStarts bool
+
+	// AllowedOrigins may open WebSockets besides same-origin pages
+	// (empty = any origin in development, same-origin only otherwise)
+	AllowedOrigins []string
+
+	// MaxConnsPerIP caps simultaneous WebSockets from one IP (0 = unlimited)
+	MaxConnsPerIP int
+
+	// TrustedProxies lists the IPs or CIDRs of reverse proxies whose
+	// X-Forwarded-For / X-Real-IP headers name the real client
+	TrustedProxies []string
+
+	// WSCompressThreshold is the smallest WebSocket write compressed with
+	// permessage-deflate, in bytes (0 = compression off)
+	WSCompressThreshold int
+
+	// WSPayloadLimits overrides the largest accepted payload per WebSocket
+	// message type, in bytes, from "type=bytes" pairs
+	WSPayloadLimits map[string]int
+
+	// SFURequired reports the server as degraded when voice chat failed to start
+	SFURequired bool
+
+	// ReconnectTokenKey signs reconnect tokens (empty = random key per process)
+	ReconnectTokenKey string
+
+	// OperatorToken unlocks private events in replay exports and operator
+	// actions such as force-ending games (empty = neither)
+	OperatorToken string
+
+	// HTTP server timeouts. WriteTimeout bounds plain HTTP responses only;
+	// the WebSocket handler clears it on upgrade so long-lived sockets survive.
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
 }
 
 func Load() *Config {
@@ -18,6 +70,27 @@ func Load() *Config {
 		Host:      getEnv("HOST", "0.0.0.0"),
 		StaticDir: getEnv("STATIC_DIR", "./web/dist"),
 		Env:       getEnv("ENV", "development"),
+
+		AuditLogPath:     getEnv("AUDIT_LOG_PATH", ""),
+		RoomReapInterval: getEnvDuration("ROOM_REAP_INTERVAL", time.Minute),
+		LobbyIdleTimeout: getEnvDuration("LOBBY_IDLE_TIMEOUT", 0),
+		MaxActiveGames:   getEnvInt("MAX_ACTIVE_GAMES", 0),
+		QueueGameStarts:  getEnvBool("QUEUE_GAME_STARTS", false),
+		AllowedOrigins:   getEnvList("ALLOWED_ORIGINS"),
+		SFURequired:      getEnvBool("SFU_REQUIRED", true),
+
+		MaxConnsPerIP:  getEnvInt("WS_MAX_CONNS_PER_IP", 10),
+		TrustedProxies: getEnvList("TRUSTED_PROXIES"),
+
+		WSCompressThreshold: getEnvInt("WS_COMPRESS_THRESHOLD", 1024),
+		WSPayloadLimits:     getEnvIntMap("WS_PAYLOAD_LIMITS"),
+
+		ReconnectTokenKey: getEnv("RECONNECT_TOKEN_KEY", ""),
+		OperatorToken:     getEnv("OPERATOR_TOKEN", ""),
+
+		ReadTimeout:  getEnvDuration("HTTP_READ_TIMEOUT", 10*time.Second),
+		WriteTimeout: getEnvDuration("HTTP_WRITE_TIMEOUT", 30*time.Second),
+		IdleTimeout:  getEnvDuration("HTTP_IDLE_TIMEOUT", 60*time.Second),
 	}
 }
 
@@ -36,6 +109,51 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+// getEnvList splits a comma-separated variable, dropping empty items
+func getEnvList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getEnvIntMap parses comma-separated "key=int" pairs, dropping malformed
+// or non-positive entries
+func getEnvIntMap(key string) map[string]int {
+	values := make(map[string]int)
+	for _, item := range getEnvList(key) {
+		name, val, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		if i, err := strconv.Atoi(strings.TrimSpace(val)); err == nil && i > 0 {
+			values[strings.TrimSpace(name)] = i
+		}
+	}
+	return values
+}
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	if val := os.Getenv(key); val != "" {
+		if d, err := time.ParseDuration(val); err == nil && d > 0 {
+			return d
+		}
+	}
+	return fallback
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	}
+	return fallback
+}
+
 func getEnvInt(key string, fallback int) int {
 	if val := os.Getenv(key); val != "" {
 		if i, err := strconv.Atoi(val); err == nil {
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHTTPTimeouts(t *testing.T) {
+	cfg := Load()
+	if cfg.ReadTimeout != 10*time.Second || cfg.WriteTimeout != 30*time.Second || cfg.IdleTimeout != 60*time.Second {
+		t.Errorf("defaults = %v/%v/%v, want 10s/30s/1m0s", cfg.ReadTimeout, cfg.WriteTimeout, cfg.IdleTimeout)
+	}
+
+	t.Setenv("HTTP_READ_TIMEOUT", "5s")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "2m")
+	t.Setenv("HTTP_IDLE_TIMEOUT", "90s")
+	cfg = Load()
+	if cfg.ReadTimeout != 5*time.Second || cfg.WriteTimeout != 2*time.Minute || cfg.IdleTimeout != 90*time.Second {
+		t.Errorf("overrides = %v/%v/%v, want 5s/2m0s/1m30s", cfg.ReadTimeout, cfg.WriteTimeout, cfg.IdleTimeout)
+	}
+
+	// Unparsable or non-positive values keep the default
+	t.Setenv("HTTP_READ_TIMEOUT", "soon")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "-1s")
+	cfg = Load()
+	if cfg.ReadTimeout != 10*time.Second || cfg.WriteTimeout != 30*time.Second {
+		t.Errorf("invalid values = %v/%v, want defaults", cfg.ReadTimeout, cfg.WriteTimeout)
+	}
+}
+
+func TestWSPayloadLimits(t *testing.T) {
+	t.Setenv("WS_PAYLOAD_LIMITS", "voice_offer=65536, day_chat = 800,bad,ghost_chat=-1,ready=x")
+	got := Load().WSPayloadLimits
+	if len(got) != 2 || got["voice_offer"] != 65536 || got["day_chat"] != 800 {
+		t.Errorf("limits = %v, want voice_offer=65536 day_chat=800", got)
+	}
+}
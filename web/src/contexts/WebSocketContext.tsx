@@ -10,6 +10,9 @@ export interface WSMessage {
 // Connection states
 export type ConnectionState = 'connecting' | 'connected' | 'disconnected' | 'error'
 
+// Protocol version requested as the WebSocket subprotocol
+const PROTOCOL_VERSION = 'mafia.v1'
+
 // Get WebSocket URL based on current location
 function getWebSocketUrl(): string {
   const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
@@ -244,7 +247,8 @@ export function WebSocketProvider({ children }: { children: ReactNode }) {
           break
         }
 
-        case 'player_left': {
+        case 'player_left':
+        case 'player_kicked': {
           const { player_id, new_host } = message.payload as {
             player_id: string
             new_host?: string
@@ -540,7 +544,7 @@ export function WebSocketProvider({ children }: { children: ReactNode }) {
     const url = getWebSocketUrl()
     console.log('[WS] Connecting to', url)
 
-    const ws = new WebSocket(url)
+    const ws = new WebSocket(url, PROTOCOL_VERSION)
     wsRef.current = ws
 
     ws.onopen = () => {